@@ -0,0 +1,350 @@
+package bmc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// biosAttributesPollInterval is the interval between checks of the BIOS attributes after a system reset.
+var biosAttributesPollInterval = 30 * time.Second
+
+// BIOSAttributeDiff holds the current and desired values of a BIOS attribute that differ.
+type BIOSAttributeDiff struct {
+	// Current is the current value of the attribute. It is nil if the BIOS does not have the attribute.
+	Current any
+	// Desired is the desired value of the attribute.
+	Desired any
+}
+
+// BIOSAttributes returns the current BIOS attributes of the system using the Redfish API.
+func (bmc *BMC) BIOSAttributes() (redfish.SettingsAttributes, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Info("Getting BIOS attributes from bmc's redfish endpoint")
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	bios, err := redfishGetBios(redfishClient, bmc.systemIndex)
+	if err != nil {
+		glog.V(100).Infof("Failed to get redfish system's bios: %v", err)
+
+		return nil, err
+	}
+
+	return bios.Attributes, nil
+}
+
+// PendingBIOSAttributes returns the BIOS attributes that were set but are not applied yet, using the Redfish Settings
+// resource of the BIOS. Only the attributes whose pending value differs from the current one are returned.
+func (bmc *BMC) PendingBIOSAttributes() (redfish.SettingsAttributes, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Info("Getting pending BIOS attributes from bmc's redfish endpoint")
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	bios, err := redfishGetBios(redfishClient, bmc.systemIndex)
+	if err != nil {
+		glog.V(100).Infof("Failed to get redfish system's bios: %v", err)
+
+		return nil, err
+	}
+
+	pending, err := redfishGetBiosSettingsAttributes(redfishClient, bios)
+	if err != nil {
+		glog.V(100).Infof("Failed to get pending bios attributes: %v", err)
+
+		return nil, err
+	}
+
+	pendingAttributes := redfish.SettingsAttributes{}
+
+	for name, diff := range DiffBIOSAttributes(bios.Attributes, pending) {
+		pendingAttributes[name] = diff.Desired
+	}
+
+	return pendingAttributes, nil
+}
+
+// SetBIOSAttributes sets the BIOS attributes of the system using the Redfish API. Only the attributes provided are
+// changed. If applyTime is empty, the BMC default is used, which usually requires a system reset for the attributes
+// to be applied.
+func (bmc *BMC) SetBIOSAttributes(attributes redfish.SettingsAttributes, applyTime common.ApplyTime) error {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Setting BIOS attributes %v with apply time %q", attributes, applyTime)
+
+	if len(attributes) == 0 {
+		glog.V(100).Info("The BIOS attributes are empty")
+
+		return fmt.Errorf("bios 'attributes' cannot be empty")
+	}
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	bios, err := redfishGetBios(redfishClient, bmc.systemIndex)
+	if err != nil {
+		glog.V(100).Infof("Failed to get redfish system's bios: %v", err)
+
+		return err
+	}
+
+	if applyTime != "" && !slices.Contains(bios.AllowedAttributeUpdateApplyTimes(), applyTime) {
+		glog.V(100).Infof("The BIOS does not support apply time %s", applyTime)
+
+		return fmt.Errorf("bios apply time %s is not supported, supported apply times are %v",
+			applyTime, bios.AllowedAttributeUpdateApplyTimes())
+	}
+
+	err = bios.UpdateBiosAttributesApplyAt(attributes, applyTime)
+	if err != nil {
+		glog.V(100).Infof("Failed to set bios attributes: %v", err)
+
+		return fmt.Errorf("failed to set bios attributes: %w", err)
+	}
+
+	return nil
+}
+
+// ResetBIOSToDefaults resets the BIOS attributes of the system to their default values using the Redfish API. A system
+// reset is usually required for the default values to be applied.
+func (bmc *BMC) ResetBIOSToDefaults() error {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return err
+	}
+
+	glog.V(100).Info("Resetting BIOS attributes to defaults from bmc's redfish endpoint")
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	bios, err := redfishGetBios(redfishClient, bmc.systemIndex)
+	if err != nil {
+		glog.V(100).Infof("Failed to get redfish system's bios: %v", err)
+
+		return err
+	}
+
+	err = bios.ResetBios()
+	if err != nil {
+		glog.V(100).Infof("Failed to reset bios attributes: %v", err)
+
+		return fmt.Errorf("failed to reset bios attributes: %w", err)
+	}
+
+	return nil
+}
+
+// BIOSAttributesDiff returns the attributes whose current value differs from the desired one, using the Redfish API.
+func (bmc *BMC) BIOSAttributesDiff(desired redfish.SettingsAttributes) (map[string]BIOSAttributeDiff, error) {
+	current, err := bmc.BIOSAttributes()
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffBIOSAttributes(current, desired), nil
+}
+
+// ApplyBIOSAttributes sets the BIOS attributes to be applied on the next reset, resets the system using
+// SystemForceReset, and waits up to timeout until the current attributes match the desired ones. Nothing is done if
+// the attributes already match.
+func (bmc *BMC) ApplyBIOSAttributes(attributes redfish.SettingsAttributes, timeout time.Duration) error {
+	glog.V(100).Infof("Applying BIOS attributes %v", attributes)
+
+	diff, err := bmc.BIOSAttributesDiff(attributes)
+	if err != nil {
+		return err
+	}
+
+	if len(diff) == 0 {
+		glog.V(100).Info("The BIOS attributes are already applied")
+
+		return nil
+	}
+
+	err = bmc.SetBIOSAttributes(attributes, common.OnResetApplyTime)
+	if err != nil {
+		return err
+	}
+
+	err = bmc.SystemForceReset()
+	if err != nil {
+		glog.V(100).Infof("Failed to reset the system: %v", err)
+
+		return fmt.Errorf("failed to reset the system: %w", err)
+	}
+
+	err = wait.PollUntilContextTimeout(
+		context.TODO(), biosAttributesPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+			diff, err = bmc.BIOSAttributesDiff(attributes)
+			if err != nil {
+				glog.V(100).Infof("Failed to get BIOS attributes: %v", err)
+
+				return false, nil
+			}
+
+			return len(diff) == 0, nil
+		})
+	if err != nil {
+		glog.V(100).Infof("BIOS attributes were not applied: %v", diff)
+
+		return fmt.Errorf("bios attributes were not applied, differences are %v: %w", diff, err)
+	}
+
+	return nil
+}
+
+// DiffBIOSAttributes returns the attributes whose current value differs from the desired one. Values are compared by
+// their string representation, since numbers decoded from JSON are float64.
+func DiffBIOSAttributes(current, desired redfish.SettingsAttributes) map[string]BIOSAttributeDiff {
+	diff := map[string]BIOSAttributeDiff{}
+
+	for name, desiredValue := range desired {
+		currentValue, found := current[name]
+
+		if !found || fmt.Sprint(currentValue) != fmt.Sprint(desiredValue) {
+			diff[name] = BIOSAttributeDiff{Current: currentValue, Desired: desiredValue}
+		}
+	}
+
+	return diff
+}
+
+// redfishGetBios uses the provided gofish APIClient and the system index to get the Bios resource of a system.
+func redfishGetBios(redfishClient *gofish.APIClient, systemIndex int) (*redfish.Bios, error) {
+	system, err := redfishGetSystem(redfishClient, systemIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redfish system: %w", err)
+	}
+
+	bios, err := system.Bios()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bios: %w", err)
+	}
+
+	if bios == nil {
+		return nil, fmt.Errorf("failed to get bios: system has no bios resource")
+	}
+
+	return bios, nil
+}
+
+// redfishGetBiosSettingsAttributes uses the provided gofish APIClient to get the attributes of the Settings resource
+// of the Bios. If the Bios has no Settings resource, its current attributes are returned.
+func redfishGetBiosSettingsAttributes(
+	redfishClient *gofish.APIClient, bios *redfish.Bios) (redfish.SettingsAttributes, error) {
+	var biosSettings struct {
+		Settings struct {
+			SettingsObject common.Link
+		} `json:"@Redfish.Settings"`
+	}
+
+	err := redfishGetJSON(redfishClient, bios.ODataID, &biosSettings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bios: %w", err)
+	}
+
+	settingsURI := biosSettings.Settings.SettingsObject.String()
+	if settingsURI == "" {
+		return bios.Attributes, nil
+	}
+
+	var settings struct {
+		Attributes redfish.SettingsAttributes
+	}
+
+	err = redfishGetJSON(redfishClient, settingsURI, &settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bios settings: %w", err)
+	}
+
+	return settings.Attributes, nil
+}
+
+// redfishGetJSON uses the provided gofish APIClient to get the resource at uri and unmarshal it into object.
+func redfishGetJSON(redfishClient *gofish.APIClient, uri string, object any) error {
+	response, err := redfishClient.Get(uri)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, object)
+}
@@ -0,0 +1,151 @@
+package bmc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBMCBIOSAttributes(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+
+	attributes, err := New(host).WithRedfishUser(defaultUsername, defaultPassword).BIOSAttributes()
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.SettingsAttributes(getDefaultBiosAttributes()), attributes)
+
+	_, err = New(host).BIOSAttributes()
+	assert.Equal(t, fmt.Errorf("cannot access redfish with nil user"), err)
+}
+
+func TestBMCSetBIOSAttributes(t *testing.T) {
+	testCases := []struct {
+		attributes      redfish.SettingsAttributes
+		applyTime       common.ApplyTime
+		expectedPending redfish.SettingsAttributes
+		expectedError   string
+	}{
+		{
+			attributes:      redfish.SettingsAttributes{"SriovGlobalEnable": "Enabled", "LogicalProc": "Enabled"},
+			applyTime:       common.OnResetApplyTime,
+			expectedPending: redfish.SettingsAttributes{"SriovGlobalEnable": "Enabled"},
+			expectedError:   "",
+		},
+		{
+			attributes:      redfish.SettingsAttributes{"ProcCStates": "Disabled"},
+			applyTime:       "",
+			expectedPending: redfish.SettingsAttributes{"ProcCStates": "Disabled"},
+			expectedError:   "",
+		},
+		{
+			attributes: redfish.SettingsAttributes{"ProcCStates": "Disabled"},
+			applyTime:  common.ImmediateApplyTime,
+			expectedError: "bios apply time Immediate is not supported, supported apply times are " +
+				"[OnReset AtMaintenanceWindowStart InMaintenanceWindowOnReset]",
+		},
+		{
+			attributes:    nil,
+			applyTime:     common.OnResetApplyTime,
+			expectedError: "bios 'attributes' cannot be empty",
+		},
+	}
+
+	for _, testCase := range testCases {
+		redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+		host := strings.Split(redfishServer.URL, "//")[1]
+		bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+		err := bmc.SetBIOSAttributes(testCase.attributes, testCase.applyTime)
+
+		if testCase.expectedError != "" {
+			assert.EqualError(t, err, testCase.expectedError)
+		} else {
+			assert.Nil(t, err)
+
+			pending, err := bmc.PendingBIOSAttributes()
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.expectedPending, pending)
+		}
+
+		redfishServer.Close()
+	}
+}
+
+func TestBMCResetBIOSToDefaults(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+	err := bmc.ApplyBIOSAttributes(redfish.SettingsAttributes{"WorkloadProfile": "TelcoOptimizedProfile"}, time.Second)
+	assert.Nil(t, err)
+
+	err = bmc.ResetBIOSToDefaults()
+	assert.Nil(t, err)
+
+	pending, err := bmc.PendingBIOSAttributes()
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.SettingsAttributes{"WorkloadProfile": "NotAvailable"}, pending)
+}
+
+func TestBMCApplyBIOSAttributes(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+	desired := redfish.SettingsAttributes{"ProcCStates": "Disabled", "LogicalProc": "Disabled"}
+
+	diff, err := bmc.BIOSAttributesDiff(desired)
+	assert.Nil(t, err)
+	assert.Len(t, diff, 2)
+
+	err = bmc.ApplyBIOSAttributes(desired, time.Second)
+	assert.Nil(t, err)
+
+	diff, err = bmc.BIOSAttributesDiff(desired)
+	assert.Nil(t, err)
+	assert.Empty(t, diff)
+
+	// Applying attributes that already match does not need a reset.
+	err = bmc.ApplyBIOSAttributes(desired, time.Second)
+	assert.Nil(t, err)
+}
+
+func TestDiffBIOSAttributes(t *testing.T) {
+	current := redfish.SettingsAttributes{"ProcCStates": "Enabled", "SerialPortAddress": float64(2)}
+
+	testCases := []struct {
+		desired      redfish.SettingsAttributes
+		expectedDiff map[string]BIOSAttributeDiff
+	}{
+		{
+			desired:      redfish.SettingsAttributes{"ProcCStates": "Enabled", "SerialPortAddress": 2},
+			expectedDiff: map[string]BIOSAttributeDiff{},
+		},
+		{
+			desired: redfish.SettingsAttributes{"ProcCStates": "Disabled"},
+			expectedDiff: map[string]BIOSAttributeDiff{
+				"ProcCStates": {Current: "Enabled", Desired: "Disabled"},
+			},
+		},
+		{
+			desired: redfish.SettingsAttributes{"LogicalProc": "Disabled"},
+			expectedDiff: map[string]BIOSAttributeDiff{
+				"LogicalProc": {Current: nil, Desired: "Disabled"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expectedDiff, DiffBIOSAttributes(current, testCase.desired))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/golang/glog"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/bmc/internal/ipmi"
 	"github.com/stmcginnis/gofish"
 	"github.com/stmcginnis/gofish/redfish"
 	"golang.org/x/crypto/ssh"
@@ -24,20 +25,15 @@ const (
 )
 
 var (
-	// DefaultTimeOuts holds the default redfish and ssh timeouts.
+	// DefaultTimeOuts holds the default redfish, ssh and ipmi timeouts.
 	DefaultTimeOuts = TimeOuts{
 		Redfish: defaultTimeOut,
 		SSH:     defaultTimeOut,
-	}
-
-	// CLI command to get the serial console (virtual serial port).
-	cliCmdSerialConsole = map[string]string{
-		manufacturerHPE:  "VSP",
-		manufacturerDell: "console com2",
+		IPMI:    defaultTimeOut,
 	}
 )
 
-// User holds the Name and Password for a user (ssh/redfish).
+// User holds the Name and Password for a user (ssh/redfish/ipmi).
 type User struct {
 	// Name holds the user's name
 	Name string
@@ -45,40 +41,49 @@ type User struct {
 	Password string
 }
 
-// TimeOuts holds the configured timeouts for Redfish and SSH acccess.
+// TimeOuts holds the configured timeouts for Redfish, SSH and IPMI acccess.
 type TimeOuts struct {
 	// Redfish timeout for the redfish api access.
 	Redfish time.Duration
 	// SSH timeout for the ssh access.
 	SSH time.Duration
+	// IPMI timeout for each request sent over ipmi.
+	IPMI time.Duration
 }
 
-// BMC is the holder struct for BMC access through redfish & ssh.
+// BMC is the holder struct for BMC access through redfish & ssh, or ipmi.
 type BMC struct {
 	host        string
+	backend     Backend
 	redfishUser *User
 	sshUser     *User
 	sshPort     uint16
+	ipmiUser    *User
+	ipmiPort    uint16
 	timeOuts    TimeOuts
 
 	systemIndex       int
 	powerControlIndex int
 
-	sshClientForSerialConsole *ssh.Client
+	sshClientForSerialConsole  *ssh.Client
+	ipmiClientForSerialConsole *ipmi.Client
 
 	errorMsg string
 }
 
 // New returns a BMC struct with the specified host. The host should be nonempty. WithRedfishUser and WithSSHUser must
 // be called before connecting to Redfish or over SSH, respectively. The SSH port and timeouts are set to DefaultSSHPort
-// and DefaultTimeOuts, with indices defaulting to 0.
+// and DefaultTimeOuts, with indices defaulting to 0. The backend defaults to BackendRedfish and can be changed with
+// WithBackend, in which case WithIPMIUser must be called instead of WithRedfishUser.
 func New(host string) *BMC {
 	glog.V(100).Infof(
 		"Creating new BMC structure with the following params: host: %s", host)
 
 	bmc := &BMC{
 		host:              host,
+		backend:           BackendRedfish,
 		sshPort:           defaultSSHPort,
+		ipmiPort:          ipmi.DefaultPort,
 		timeOuts:          DefaultTimeOuts,
 		systemIndex:       0,
 		powerControlIndex: 0,
@@ -424,8 +429,13 @@ func (bmc *BMC) SecureBootDisable() error {
 	return nil
 }
 
-// SystemResetAction performs the specified reset action against the system.
+// SystemResetAction performs the specified reset action against the system. With the IPMI backend, only the On,
+// ForceOff, ForceRestart, GracefulShutdown, PowerCycle and Nmi reset types are supported.
 func (bmc *BMC) SystemResetAction(action redfish.ResetType) error {
+	if bmc.usesIPMI() {
+		return bmc.ipmiSystemResetAction(action)
+	}
+
 	if valid, err := bmc.validateRedfish(); !valid {
 		return err
 	}
@@ -458,30 +468,35 @@ func (bmc *BMC) SystemResetAction(action redfish.ResetType) error {
 	return system.Reset(action)
 }
 
-// SystemForceReset performs a (non-graceful) forced system reset using Redfish API.
+// SystemForceReset performs a (non-graceful) forced system reset using Redfish API or IPMI.
 func (bmc *BMC) SystemForceReset() error {
 	return bmc.SystemResetAction(redfish.ForceRestartResetType)
 }
 
-// SystemGracefulShutdown performs a graceful shutdown using the Redfish API.
+// SystemGracefulShutdown performs a graceful shutdown using the Redfish API or IPMI.
 func (bmc *BMC) SystemGracefulShutdown() error {
 	return bmc.SystemResetAction(redfish.GracefulShutdownResetType)
 }
 
-// SystemPowerOn powers on the system using the Redfish API.
+// SystemPowerOn powers on the system using the Redfish API or IPMI.
 func (bmc *BMC) SystemPowerOn() error {
 	return bmc.SystemResetAction(redfish.OnResetType)
 }
 
-// SystemPowerOff performs a non-graceful power off of the system using the Redfish API.
+// SystemPowerOff performs a non-graceful power off of the system using the Redfish API or IPMI.
 func (bmc *BMC) SystemPowerOff() error {
 	return bmc.SystemResetAction(redfish.ForceOffResetType)
 }
 
 // SystemPowerCycle performs a power cycle in the system using the Redfish API. If PowerCycle reset type
 // is not supported, alternate PowerOff + On reset actions will be performed as fallback mechanism.
-// Use bmc.SystemResetAction(redfish.PowerCycleResetType) if this fallback mechanism is not needed/wanted.
+// Use bmc.SystemResetAction(redfish.PowerCycleResetType) if this fallback mechanism is not needed/wanted. With the IPMI
+// backend, the chassis power cycle control is used.
 func (bmc *BMC) SystemPowerCycle() error {
+	if bmc.usesIPMI() {
+		return bmc.ipmiSystemResetAction(redfish.PowerCycleResetType)
+	}
+
 	if valid, err := bmc.validateRedfish(); !valid {
 		return err
 	}
@@ -552,8 +567,12 @@ func (bmc *BMC) SystemPowerCycle() error {
 }
 
 // SystemPowerState returns the system's current power state using the Redfish API.
-// Returned string can be one of On/Off/Paused/PoweringOn/PoweringOff.
+// Returned string can be one of On/Off/Paused/PoweringOn/PoweringOff. With the IPMI backend, it is either On or Off.
 func (bmc *BMC) SystemPowerState() (string, error) {
+	if bmc.usesIPMI() {
+		return bmc.ipmiSystemPowerState()
+	}
+
 	if valid, err := bmc.validateRedfish(); !valid {
 		return "", err
 	}
@@ -588,7 +607,7 @@ func (bmc *BMC) SystemPowerState() (string, error) {
 
 // WaitForSystemPowerState waits up to timeout until the BMC returns the provided system power state.
 func (bmc *BMC) WaitForSystemPowerState(powerState redfish.PowerState, timeout time.Duration) error {
-	if valid, err := bmc.validateRedfish(); !valid {
+	if valid, err := bmc.validateBackend(); !valid {
 		return err
 	}
 
@@ -768,6 +787,58 @@ func (bmc *BMC) SetSystemBootOrderReferences(bootOrderReferences []string) error
 	return system.SetBoot(newBoot)
 }
 
+// SetBootOverride sets the boot source override of the system using the Redfish API, or the boot device override of
+// the chassis using IPMI. If mode is empty, the boot mode of the system is left unchanged with Redfish and legacy BIOS
+// is used with IPMI. With the IPMI backend, only the None, Pxe, Hdd, Cd, BiosSetup, Floppy and Diags targets are
+// supported.
+func (bmc *BMC) SetBootOverride(
+	target redfish.BootSourceOverrideTarget,
+	enabled redfish.BootSourceOverrideEnabled,
+	mode redfish.BootSourceOverrideMode) error {
+	if bmc.usesIPMI() {
+		return bmc.ipmiSetBootOverride(target, enabled, mode)
+	}
+
+	if valid, err := bmc.validateRedfish(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Setting boot source override to %s (%s) from redfish endpoint", target, enabled)
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	system, err := redfishGetSystem(redfishClient, bmc.systemIndex)
+	if err != nil {
+		glog.V(100).Infof("Failed to get redfish system: %v", err)
+
+		return fmt.Errorf("failed to get redfish system: %w", err)
+	}
+
+	newBoot := redfish.Boot{
+		BootSourceOverrideEnabled: enabled,
+		BootSourceOverrideTarget:  target,
+		BootSourceOverrideMode:    mode,
+	}
+
+	glog.V(100).Infof("Setting new Boot value: %+v", newBoot)
+
+	return system.SetBoot(newBoot)
+}
+
 // BootFromCD inserts the image available in isoUrl in the virtual media with virtualMediaID
 // and boots from it only once.
 func (bmc *BMC) BootFromCD(isoURL, virtualMediaID string) error {
@@ -924,10 +995,15 @@ func (bmc *BMC) RunCLICommand(
 // OpenSerialConsole opens the serial console port. The console is tunneled in an underlying (CLI) ssh session that is
 // opened in the BMC's ssh server. If openConsoleCliCmd is provided, it will be sent to the BMC's cli. Otherwise, a best
 // effort will be made to run the appropriate cli command based on the system manufacturer. This method requires both a
-// Redfish and SSH user configured.
+// Redfish and SSH user configured. With the IPMI backend, Serial over LAN is activated instead and openConsoleCliCmd is
+// ignored.
 //
 //nolint:funlen
 func (bmc *BMC) OpenSerialConsole(openConsoleCliCmd string) (io.Reader, io.WriteCloser, error) {
+	if bmc.usesIPMI() {
+		return bmc.ipmiOpenSerialConsole()
+	}
+
 	// We use both Redfish and SSH so make sure both are valid before continuing.
 	if valid, err := bmc.validateRedfish(); !valid {
 		return nil, nil, err
@@ -948,16 +1024,16 @@ func (bmc *BMC) OpenSerialConsole(openConsoleCliCmd string) (io.Reader, io.Write
 
 	if openConsoleCliCmd == "" {
 		// no cli command to get console port was provided, try to guess based on
-		// manufacturer.
-		manufacturer, err := bmc.SystemManufacturer()
+		// the vendor profile of the system.
+		manufacturer, model, err := bmc.getSystemManufacturerAndModel()
 		if err != nil {
 			glog.V(100).Infof("Failed to get redifsh system manufacturer for %v: %v", bmc.host, err)
 
 			return nil, nil, fmt.Errorf("failed to get redfish system manufacturer for %v: %w", bmc.host, err)
 		}
 
-		var found bool
-		if openConsoleCliCmd, found = cliCmdSerialConsole[manufacturer]; !found {
+		profile, _ := LookupVendorProfile(manufacturer, model)
+		if openConsoleCliCmd = profile.SerialConsoleCommand; openConsoleCliCmd == "" {
 			glog.V(100).Infof("CLI command to get serial console not found for manufacturer for %v: %v",
 				bmc.host, manufacturer)
 
@@ -1015,7 +1091,8 @@ func (bmc *BMC) OpenSerialConsole(openConsoleCliCmd string) (io.Reader, io.Write
 	return reader, writer, nil
 }
 
-// CloseSerialConsole closes the serial console's underlying ssh session.
+// CloseSerialConsole closes the serial console's underlying ssh session, or deactivates Serial over LAN and closes its
+// ipmi session.
 func (bmc *BMC) CloseSerialConsole() error {
 	if valid, err := bmc.validate(); !valid {
 		return err
@@ -1023,6 +1100,19 @@ func (bmc *BMC) CloseSerialConsole() error {
 
 	glog.V(100).Infof("Closing serial console for %v.", bmc.host)
 
+	if bmc.ipmiClientForSerialConsole != nil {
+		err := bmc.ipmiClientForSerialConsole.Close()
+		bmc.ipmiClientForSerialConsole = nil
+
+		if err != nil {
+			glog.V(100).Infof("Failed to close underlying ipmi session for %v: %v", bmc.host, err)
+
+			return fmt.Errorf("failed to close underlying ipmi session for %v: %w", bmc.host, err)
+		}
+
+		return nil
+	}
+
 	if bmc.sshClientForSerialConsole == nil {
 		glog.V(100).Infof("No underlying ssh session found for %v. Please use OpenSerialConsole() first.", bmc.host)
 
@@ -1138,6 +1228,15 @@ func (bmc *BMC) validateRedfish() (bool, error) {
 	return true, nil
 }
 
+// validateBackend performs the validations for the backend of the BMC, either validateRedfish or validateIPMI.
+func (bmc *BMC) validateBackend() (bool, error) {
+	if bmc.usesIPMI() {
+		return bmc.validateIPMI()
+	}
+
+	return bmc.validateRedfish()
+}
+
 func (bmc *BMC) validateSSH() (bool, error) {
 	if valid, err := bmc.validate(); !valid {
 		return false, err
@@ -1203,6 +1302,12 @@ func (bmc *BMC) getSupportedResetTypes() ([]redfish.ResetType, error) {
 		return nil, fmt.Errorf("failed to get redfish system: %w", err)
 	}
 
+	if len(system.SupportedResetTypes) == 0 {
+		glog.V(100).Infof("No supported reset types reported for %v. Using the ones of its vendor profile", bmc.host)
+
+		return getSystemVendorProfile(system).ResetTypes, nil
+	}
+
 	return system.SupportedResetTypes, nil
 }
 
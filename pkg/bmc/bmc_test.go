@@ -1,11 +1,13 @@
 package bmc
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"regexp"
 	"strings"
 	"time"
@@ -47,6 +49,21 @@ var redfishChassisNoPowerJSONResponse string
 //go:embed testdata/redfish_v1_power.json
 var redfishPowerJSONResponse string
 
+//go:embed testdata/redfish_v1_thermal.json
+var redfishThermalJSONResponse string
+
+//go:embed testdata/redfish_v1_sensors.json
+var redfishSensorsJSONResponse string
+
+//go:embed testdata/redfish_v1_sensor_inlet_temp.json
+var redfishSensorInletTempJSONResponse string
+
+//go:embed testdata/redfish_v1_sensor_fan.json
+var redfishSensorFanJSONResponse string
+
+//go:embed testdata/redfish_v1_sensor_voltage.json
+var redfishSensorVoltageJSONResponse string
+
 //go:embed testdata/redfish_v1_system_boot_options.json
 var redfishSystemBootOptionsJSONResponse string
 
@@ -65,6 +82,119 @@ var redfishSystemVirtualMedia1JSONResponse string
 //go:embed testdata/redfish_v1_system_virtual_media_2.json
 var redfishSystemVirtualMedia2JSONResponse string
 
+//go:embed testdata/redfish_v1_system_virtual_media_1_inserted.json
+var redfishSystemVirtualMedia1InsertedJSONResponse string
+
+//go:embed testdata/redfish_v1_system_bios.json
+var redfishSystemBiosJSONResponse string
+
+//go:embed testdata/redfish_v1_manager.json
+var redfishManagerJSONResponse string
+
+//go:embed testdata/redfish_v1_manager_log_services.json
+var redfishManagerLogServicesJSONResponse string
+
+//go:embed testdata/redfish_v1_manager_log_service_sel.json
+var redfishManagerLogServiceSelJSONResponse string
+
+// redfishManagerLogServiceSelEntriesJSONResponse is the response for the SEL entries collection, with its members
+// expanded.
+//
+//go:embed testdata/redfish_v1_manager_log_service_sel_entries.json
+var redfishManagerLogServiceSelEntriesJSONResponse string
+
+//go:embed testdata/redfish_v1_update_service.json
+var redfishUpdateServiceJSONResponse string
+
+//go:embed testdata/redfish_v1_firmware_inventory.json
+var redfishFirmwareInventoryJSONResponse string
+
+//go:embed testdata/redfish_v1_firmware_inventory_bios.json
+var redfishFirmwareInventoryBIOSJSONResponse string
+
+//go:embed testdata/redfish_v1_firmware_inventory_idrac.json
+var redfishFirmwareInventoryIDRACJSONResponse string
+
+// redfishTaskResetRequiredJSONResponse is the response for a firmware update task waiting for a system reset.
+//
+//go:embed testdata/redfish_v1_task_reset_required.json
+var redfishTaskResetRequiredJSONResponse string
+
+//go:embed testdata/redfish_v1_task_completed.json
+var redfishTaskCompletedJSONResponse string
+
+//go:embed testdata/redfish_v1_task_exception.json
+var redfishTaskExceptionJSONResponse string
+
+// redfishTaskCompletedResetRequiredJSONResponse is the response for a firmware update task that completed but is
+// awaiting activation by a system reset.
+//
+//go:embed testdata/redfish_v1_task_completed_reset_required.json
+var redfishTaskCompletedResetRequiredJSONResponse string
+
+// redfishTaskCompletedAfterResetJSONResponse is the response for a firmware update task that completed after a system
+// reset and still lists the earlier reset required message.
+//
+//go:embed testdata/redfish_v1_task_completed_after_reset.json
+var redfishTaskCompletedAfterResetJSONResponse string
+
+//go:embed testdata/redfish_v1_network_adapters.json
+var redfishNetworkAdaptersJSONResponse string
+
+//go:embed testdata/redfish_v1_network_adapter.json
+var redfishNetworkAdapterJSONResponse string
+
+//go:embed testdata/redfish_v1_network_ports.json
+var redfishNetworkPortsJSONResponse string
+
+//go:embed testdata/redfish_v1_network_port_1.json
+var redfishNetworkPort1JSONResponse string
+
+//go:embed testdata/redfish_v1_network_port_2.json
+var redfishNetworkPort2JSONResponse string
+
+//go:embed testdata/redfish_v1_network_device_functions.json
+var redfishNetworkDeviceFunctionsJSONResponse string
+
+//go:embed testdata/redfish_v1_network_device_function.json
+var redfishNetworkDeviceFunctionJSONResponse string
+
+//go:embed testdata/redfish_v1_pcie_function.json
+var redfishPCIeFunctionJSONResponse string
+
+//go:embed testdata/redfish_v1_storage_collection.json
+var redfishStorageCollectionJSONResponse string
+
+//go:embed testdata/redfish_v1_storage.json
+var redfishStorageJSONResponse string
+
+//go:embed testdata/redfish_v1_storage_controllers.json
+var redfishStorageControllersJSONResponse string
+
+//go:embed testdata/redfish_v1_storage_controller.json
+var redfishStorageControllerJSONResponse string
+
+//go:embed testdata/redfish_v1_drive_0.json
+var redfishDrive0JSONResponse string
+
+//go:embed testdata/redfish_v1_drive_1.json
+var redfishDrive1JSONResponse string
+
+//go:embed testdata/redfish_v1_processors.json
+var redfishProcessorsJSONResponse string
+
+//go:embed testdata/redfish_v1_processor_1.json
+var redfishProcessor1JSONResponse string
+
+//go:embed testdata/redfish_v1_processor_2.json
+var redfishProcessor2JSONResponse string
+
+//go:embed testdata/redfish_v1_memory_collection.json
+var redfishMemoryCollectionJSONResponse string
+
+//go:embed testdata/redfish_v1_memory.json
+var redfishMemoryJSONResponse string
+
 // redfishAuth is used to unmarshall the received login request redfish credentials.
 type redfishAuth struct {
 	UserName string
@@ -80,6 +210,14 @@ type redfishAPIResponseCallbacks struct {
 	virtualMedia func(r *http.Request)
 	chassis      func(r *http.Request)
 	power        func(r *http.Request)
+	simpleUpdate func(r *http.Request)
+	reset        func(r *http.Request)
+	// setBoot is called for PATCH requests to the system and returns whether they are accepted.
+	setBoot func(r *http.Request) bool
+	// thermal is called for GET requests to the Thermal resource and returns whether they succeed.
+	thermal func(r *http.Request) bool
+	// logServiceTime is called for GET requests to the SEL log service and returns whether it reports its time.
+	logServiceTime func(r *http.Request) bool
 }
 
 const (
@@ -831,6 +969,11 @@ func getAuthDataCallbackFn(t *testing.T, user *User) func(r *http.Request) {
 // for the secure boot api depending on wether we want it to be enabled or disabled for our test.
 func createFakeRedfishLocalServer(secureBootEnabled bool, callbacks redfishAPIResponseCallbacks) *httptest.Server { //nolint:funlen,lll
 	sbEnabled := secureBootEnabled
+	virtualMedia1Inserted := false
+	systemReset := false
+	biosAttributes := getDefaultBiosAttributes()
+	pendingBiosAttributes := map[string]any{}
+	selCleared := false
 	mux := http.NewServeMux()
 	mux.HandleFunc("/redfish/v1/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if callbacks.v1 != nil {
@@ -859,6 +1002,12 @@ func createFakeRedfishLocalServer(secureBootEnabled bool, callbacks redfishAPIRe
 	}))
 
 	mux.HandleFunc("/redfish/v1/Systems/System.Embedded.1", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch && callbacks.setBoot != nil && !callbacks.setBoot(r) {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
 		if callbacks.secureBoot != nil {
 			callbacks.secureBoot(r)
 		}
@@ -907,7 +1056,25 @@ func createFakeRedfishLocalServer(secureBootEnabled bool, callbacks redfishAPIRe
 
 	mux.HandleFunc("/redfish/v1/Systems/System.Embedded.1/VirtualMedia/1",
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			_, _ = w.Write([]byte(redfishSystemVirtualMedia1JSONResponse))
+			if virtualMedia1Inserted {
+				_, _ = w.Write([]byte(redfishSystemVirtualMedia1InsertedJSONResponse))
+			} else {
+				_, _ = w.Write([]byte(redfishSystemVirtualMedia1JSONResponse))
+			}
+		}))
+
+	mux.HandleFunc("POST /redfish/v1/Systems/System.Embedded.1/VirtualMedia/1/Actions/VirtualMedia.InsertMedia",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			virtualMedia1Inserted = true
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+	mux.HandleFunc("POST /redfish/v1/Systems/System.Embedded.1/VirtualMedia/1/Actions/VirtualMedia.EjectMedia",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			virtualMedia1Inserted = false
+
+			w.WriteHeader(http.StatusNoContent)
 		}))
 
 	mux.HandleFunc("/redfish/v1/Systems/System.Embedded.1/VirtualMedia/2",
@@ -915,6 +1082,197 @@ func createFakeRedfishLocalServer(secureBootEnabled bool, callbacks redfishAPIRe
 			_, _ = w.Write([]byte(redfishSystemVirtualMedia2JSONResponse))
 		}))
 
+	mux.HandleFunc("POST /redfish/v1/Systems/System.Embedded.1/Actions/ComputerSystem.Reset",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if callbacks.reset != nil {
+				callbacks.reset(r)
+			}
+
+			systemReset = true
+
+			maps.Copy(biosAttributes, pendingBiosAttributes)
+			clear(pendingBiosAttributes)
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Systems/System.Embedded.1/Bios",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bios := map[string]any{}
+			_ = json.Unmarshal([]byte(redfishSystemBiosJSONResponse), &bios)
+			bios["Attributes"] = biosAttributes
+
+			_ = json.NewEncoder(w).Encode(bios)
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Systems/System.Embedded.1/Bios/Settings",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"@odata.id":  "/redfish/v1/Systems/System.Embedded.1/Bios/Settings",
+				"Attributes": pendingBiosAttributes,
+			})
+		}))
+
+	mux.HandleFunc("PATCH /redfish/v1/Systems/System.Embedded.1/Bios/Settings",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			settings := struct {
+				Attributes map[string]any
+			}{}
+			_ = json.NewDecoder(r.Body).Decode(&settings)
+
+			maps.Copy(pendingBiosAttributes, settings.Attributes)
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+	mux.HandleFunc("POST /redfish/v1/Systems/System.Embedded.1/Bios/Actions/Bios.ResetBios",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			maps.Copy(pendingBiosAttributes, getDefaultBiosAttributes())
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Managers/iDRAC.Embedded.1",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishManagerJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Managers/iDRAC.Embedded.1/LogServices",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishManagerLogServicesJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Managers/iDRAC.Embedded.1/LogServices/Sel",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if callbacks.logServiceTime != nil && !callbacks.logServiceTime(r) {
+				logService := map[string]any{}
+				_ = json.Unmarshal([]byte(redfishManagerLogServiceSelJSONResponse), &logService)
+
+				delete(logService, "DateTime")
+				_ = json.NewEncoder(w).Encode(logService)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(redfishManagerLogServiceSelJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Managers/iDRAC.Embedded.1/LogServices/Sel/Entries",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if selCleared {
+				_, _ = w.Write([]byte(`{"Members": [], "Members@odata.count": 0}`))
+			} else {
+				_, _ = w.Write([]byte(redfishManagerLogServiceSelEntriesJSONResponse))
+			}
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Managers/iDRAC.Embedded.1/LogServices/Sel/Entries/{id}",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			entries := struct {
+				Members []map[string]any
+			}{}
+			_ = json.Unmarshal([]byte(redfishManagerLogServiceSelEntriesJSONResponse), &entries)
+
+			for _, entry := range entries.Members {
+				if entry["Id"] == r.PathValue("id") {
+					_ = json.NewEncoder(w).Encode(entry)
+
+					return
+				}
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+		}))
+
+	mux.HandleFunc("POST /redfish/v1/Managers/iDRAC.Embedded.1/LogServices/Sel/Actions/LogService.ClearLog",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			selCleared = true
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/UpdateService", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(redfishUpdateServiceJSONResponse))
+	}))
+
+	mux.HandleFunc("GET /redfish/v1/UpdateService/FirmwareInventory",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishFirmwareInventoryJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/UpdateService/FirmwareInventory/Installed-159-2.19.1",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishFirmwareInventoryBIOSJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/UpdateService/FirmwareInventory/Installed-25227-7.00.60.00",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishFirmwareInventoryIDRACJSONResponse))
+		}))
+
+	// Images with "invalid" in their URI are tracked by a task that fails, images with "staged" in their URI by a task
+	// that completes but awaits activation until the system is reset, images with "history" in their URI by a task that
+	// keeps its reset required message after completing, and other images by a task that requires a system reset to
+	// complete.
+	mux.HandleFunc("POST /redfish/v1/UpdateService/Actions/UpdateService.SimpleUpdate",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buff, _ := io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(buff))
+
+			if callbacks.simpleUpdate != nil {
+				callbacks.simpleUpdate(r)
+			}
+
+			taskID := "JID_100"
+
+			if strings.Contains(string(buff), "invalid") {
+				taskID = "JID_101"
+			}
+
+			if strings.Contains(string(buff), "staged") {
+				taskID = "JID_102"
+			}
+
+			if strings.Contains(string(buff), "history") {
+				taskID = "JID_103"
+			}
+
+			w.Header().Set("Location", "https://"+r.Host+"/redfish/v1/TaskService/Tasks/"+taskID)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/TaskService/Tasks/JID_100",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if systemReset {
+				_, _ = w.Write([]byte(redfishTaskCompletedJSONResponse))
+			} else {
+				_, _ = w.Write([]byte(redfishTaskResetRequiredJSONResponse))
+			}
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/TaskService/Tasks/JID_102",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if systemReset {
+				_, _ = w.Write([]byte(redfishTaskCompletedJSONResponse))
+			} else {
+				_, _ = w.Write([]byte(redfishTaskCompletedResetRequiredJSONResponse))
+			}
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/TaskService/Tasks/JID_103",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if systemReset {
+				_, _ = w.Write([]byte(redfishTaskCompletedAfterResetJSONResponse))
+			} else {
+				_, _ = w.Write([]byte(redfishTaskResetRequiredJSONResponse))
+			}
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/TaskService/Tasks/JID_101",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishTaskExceptionJSONResponse))
+		}))
+
 	mux.HandleFunc("GET /redfish/v1/Chassis", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if callbacks.chassis != nil {
 			callbacks.chassis(r)
@@ -942,6 +1300,162 @@ func createFakeRedfishLocalServer(secureBootEnabled bool, callbacks redfishAPIRe
 			_, _ = w.Write([]byte(redfishPowerJSONResponse))
 		}))
 
+	mux.HandleFunc("GET /redfish/v1/Chassis/System.Embedded.1/Thermal",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if callbacks.thermal != nil && !callbacks.thermal(r) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(redfishThermalJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Chassis/System.Embedded.1/Sensors",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishSensorsJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Chassis/System.Embedded.1/Sensors/{id}",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sensors := map[string]string{
+				"SystemBoardInletTemp": redfishSensorInletTempJSONResponse,
+				"SystemBoardFan1A":     redfishSensorFanJSONResponse,
+				"PS1Voltage1":          redfishSensorVoltageJSONResponse,
+			}
+
+			sensor, found := sensors[r.PathValue("id")]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(sensor))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Chassis/System.Embedded.1/NetworkAdapters",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishNetworkAdaptersJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Chassis/System.Embedded.1/NetworkAdapters/NIC.Integrated.1",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishNetworkAdapterJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Chassis/System.Embedded.1/NetworkAdapters/NIC.Integrated.1/NetworkPorts",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishNetworkPortsJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Chassis/System.Embedded.1/NetworkAdapters/NIC.Integrated.1/NetworkPorts/{id}",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resources := map[string]string{
+				"NIC.Integrated.1-1": redfishNetworkPort1JSONResponse,
+				"NIC.Integrated.1-2": redfishNetworkPort2JSONResponse,
+			}
+
+			resource, found := resources[r.PathValue("id")]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(resource))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Chassis/System.Embedded.1/NetworkAdapters/NIC.Integrated.1/NetworkDeviceFunctions",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishNetworkDeviceFunctionsJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Chassis/System.Embedded.1/NetworkAdapters/NIC.Integrated.1/NetworkDeviceFunctions/{id}",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.PathValue("id") != "NIC.Integrated.1-1-1" {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(redfishNetworkDeviceFunctionJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Chassis/System.Embedded.1/PCIeDevices/24-0/PCIeFunctions/24-0-0",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishPCIeFunctionJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Systems/System.Embedded.1/Storage",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishStorageCollectionJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Systems/System.Embedded.1/Storage/RAID.Integrated.1-1",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishStorageJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Systems/System.Embedded.1/Storage/RAID.Integrated.1-1/Controllers",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishStorageControllersJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Systems/System.Embedded.1/Storage/RAID.Integrated.1-1/Controllers/RAID.Integrated.1-1",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishStorageControllerJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Systems/System.Embedded.1/Storage/RAID.Integrated.1-1/Drives/{id}",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resources := map[string]string{
+				"Disk.Bay.0": redfishDrive0JSONResponse,
+				"Disk.Bay.1": redfishDrive1JSONResponse,
+			}
+
+			resource, found := resources[r.PathValue("id")]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(resource))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Systems/System.Embedded.1/Processors",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishProcessorsJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Systems/System.Embedded.1/Processors/{id}",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resources := map[string]string{
+				"CPU.Socket.1": redfishProcessor1JSONResponse,
+				"CPU.Socket.2": redfishProcessor2JSONResponse,
+			}
+
+			resource, found := resources[r.PathValue("id")]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(resource))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Systems/System.Embedded.1/Memory",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishMemoryCollectionJSONResponse))
+		}))
+
+	mux.HandleFunc("GET /redfish/v1/Systems/System.Embedded.1/Memory/DIMM.Socket.A1",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(redfishMemoryJSONResponse))
+		}))
+
 	redfishServer := httptest.NewUnstartedServer(mux)
 	redfishServer.EnableHTTP2 = true
 	redfishServer.StartTLS()
@@ -949,6 +1463,18 @@ func createFakeRedfishLocalServer(secureBootEnabled bool, callbacks redfishAPIRe
 	return redfishServer
 }
 
+// getDefaultBiosAttributes returns the attributes of the bios in the testdata folder, which the fake redfish server
+// uses as their default values.
+func getDefaultBiosAttributes() map[string]any {
+	bios := struct {
+		Attributes map[string]any
+	}{}
+
+	_ = json.Unmarshal([]byte(redfishSystemBiosJSONResponse), &bios)
+
+	return bios.Attributes
+}
+
 // testResetAction performs unit testing for a provided function that performs a reset action on the BMC.
 func testResetAction(t *testing.T, name string, resetFunction func(bmc *BMC) error) {
 	t.Helper()
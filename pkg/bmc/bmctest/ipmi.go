@@ -0,0 +1,640 @@
+package bmctest
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/bmc/internal/ipmi"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+const (
+	// ipmiSOLBufferSize is the size of the SOL buffers the IPMI server reports, including the SOL payload header.
+	ipmiSOLBufferSize = 68
+	// ipmiMaxPacketSize is the size of the buffer packets are read into.
+	ipmiMaxPacketSize = 1024
+)
+
+// ipmiResetTypes are the reset types recorded in the reset history for each chassis control action.
+var ipmiResetTypes = map[ipmi.ChassisControl]redfish.ResetType{
+	ipmi.ChassisControlPowerDown:           redfish.ForceOffResetType,
+	ipmi.ChassisControlPowerUp:             redfish.OnResetType,
+	ipmi.ChassisControlPowerCycle:          redfish.PowerCycleResetType,
+	ipmi.ChassisControlHardReset:           redfish.ForceRestartResetType,
+	ipmi.ChassisControlDiagnosticInterrupt: redfish.NmiResetType,
+	ipmi.ChassisControlSoftShutdown:        redfish.GracefulShutdownResetType,
+}
+
+// ipmiBootTargets are the boot source override targets reported for each boot device.
+var ipmiBootTargets = map[ipmi.BootDevice]redfish.BootSourceOverrideTarget{
+	ipmi.BootDeviceNone:        redfish.NoneBootSourceOverrideTarget,
+	ipmi.BootDevicePXE:         redfish.PxeBootSourceOverrideTarget,
+	ipmi.BootDeviceDisk:        redfish.HddBootSourceOverrideTarget,
+	ipmi.BootDeviceDiagnostic:  redfish.DiagsBootSourceOverrideTarget,
+	ipmi.BootDeviceCDROM:       redfish.CdBootSourceOverrideTarget,
+	ipmi.BootDeviceBIOSSetup:   redfish.BiosSetupBootSourceOverrideTarget,
+	ipmi.BootDeviceRemoteCDROM: redfish.CdBootSourceOverrideTarget,
+	ipmi.BootDeviceFloppy:      redfish.FloppyBootSourceOverrideTarget,
+}
+
+// IPMIServer is an in-process IPMI over LAN responder that models a single system, so code using the IPMI backend of
+// pkg/bmc can be tested end to end without hardware. It only supports cipher suite 3. Use NewIPMIServer to create one
+// and Host and Port to get the address to pass to bmc.New and BMC.WithIPMIPort.
+type IPMIServer struct {
+	conn *net.UDPConn
+	done chan struct{}
+
+	mutex         sync.Mutex
+	username      string
+	password      string
+	guid          [16]byte
+	sessions      map[uint32]*ipmiSession
+	nextSessionID uint32
+
+	powerState   redfish.PowerState
+	resetHistory []redfish.ResetType
+	bootFlags    ipmi.BootFlags
+
+	solSession   *ipmiSession
+	solSequence  uint8
+	consoleInput strings.Builder
+}
+
+// ipmiSession holds the state of a session of the IPMI server.
+type ipmiSession struct {
+	params      ipmi.RAKPParameters
+	keys        *ipmi.SessionKeys
+	address     *net.UDPAddr
+	sequence    uint32
+	established bool
+}
+
+// NewIPMIServer starts an IPMI server listening on UDP on the loopback interface with a system that is powered on and
+// has no boot device override. The server must be closed with Close once it is no longer used.
+func NewIPMIServer() *IPMIServer {
+	glog.V(100).Info("Starting IPMI simulator")
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		panic(fmt.Sprintf("bmctest: failed to listen for ipmi: %v", err))
+	}
+
+	server := &IPMIServer{
+		conn:          conn,
+		done:          make(chan struct{}),
+		username:      DefaultUsername,
+		password:      DefaultPassword,
+		sessions:      map[uint32]*ipmiSession{},
+		nextSessionID: 0x1000,
+		powerState:    redfish.OnPowerState,
+	}
+
+	_, _ = rand.Read(server.guid[:])
+
+	go server.serve()
+
+	return server
+}
+
+// WithCredentials sets the username and password the server accepts. Sessions opened with other credentials fail
+// during the RAKP exchange.
+func (server *IPMIServer) WithCredentials(username, password string) *IPMIServer {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.username = username
+	server.password = password
+
+	return server
+}
+
+// Host returns the host of the server, which can be passed to bmc.New.
+func (server *IPMIServer) Host() string {
+	return server.address().IP.String()
+}
+
+// Port returns the UDP port of the server, which can be passed to BMC.WithIPMIPort.
+func (server *IPMIServer) Port() uint16 {
+	return uint16(server.address().Port)
+}
+
+// Close shuts down the server.
+func (server *IPMIServer) Close() {
+	glog.V(100).Info("Stopping IPMI simulator")
+
+	_ = server.conn.Close()
+
+	<-server.done
+}
+
+// PowerState returns the current power state of the system, either On or Off.
+func (server *IPMIServer) PowerState() redfish.PowerState {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.powerState
+}
+
+// SetPowerState sets the power state of the system. Only On and Off are supported.
+func (server *IPMIServer) SetPowerState(powerState redfish.PowerState) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.powerState = powerState
+}
+
+// ResetHistory returns the reset types matching the chassis control actions the system received, in order.
+func (server *IPMIServer) ResetHistory() []redfish.ResetType {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return slices.Clone(server.resetHistory)
+}
+
+// BootOverride returns the boot device override of the system as a boot source override. Overrides that are not
+// persistent are disabled when the system boots.
+func (server *IPMIServer) BootOverride() BootOverride {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if !server.bootFlags.Valid {
+		return BootOverride{
+			Enabled: redfish.DisabledBootSourceOverrideEnabled,
+			Target:  redfish.NoneBootSourceOverrideTarget,
+			Mode:    redfish.LegacyBootSourceOverrideMode,
+		}
+	}
+
+	override := BootOverride{
+		Enabled: redfish.OnceBootSourceOverrideEnabled,
+		Target:  ipmiBootTargets[server.bootFlags.Device],
+		Mode:    redfish.LegacyBootSourceOverrideMode,
+	}
+
+	if server.bootFlags.Persistent {
+		override.Enabled = redfish.ContinuousBootSourceOverrideEnabled
+	}
+
+	if server.bootFlags.EFI {
+		override.Mode = redfish.UEFIBootSourceOverrideMode
+	}
+
+	return override
+}
+
+// SOLActive returns true if a session activated the SOL payload.
+func (server *IPMIServer) SOLActive() bool {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.solSession != nil
+}
+
+// WriteConsole sends the output to the session that activated the SOL payload, as if the system wrote it to its serial
+// console. It fails if the SOL payload is not active.
+func (server *IPMIServer) WriteConsole(output string) error {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if server.solSession == nil {
+		return fmt.Errorf("sol payload is not active")
+	}
+
+	for chunk := range slices.Chunk([]byte(output), ipmiSOLBufferSize-4) {
+		server.solSequence = server.solSequence%15 + 1
+
+		payload := ipmi.SOLPayload{Sequence: server.solSequence, Data: chunk}
+		if err := server.send(server.solSession, ipmi.PayloadTypeSOL, payload.Marshal()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ConsoleInput returns all the input the system received on its serial console through SOL.
+func (server *IPMIServer) ConsoleInput() string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.consoleInput.String()
+}
+
+// address returns the UDP address the server listens on.
+func (server *IPMIServer) address() *net.UDPAddr {
+	address, _ := server.conn.LocalAddr().(*net.UDPAddr)
+
+	return address
+}
+
+// serve reads and handles packets until the server is closed.
+func (server *IPMIServer) serve() {
+	defer close(server.done)
+
+	buffer := make([]byte, ipmiMaxPacketSize)
+
+	for {
+		length, address, err := server.conn.ReadFromUDP(buffer)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+
+		if err != nil {
+			continue
+		}
+
+		server.mutex.Lock()
+		err = server.handlePacket(slices.Clone(buffer[:length]), address)
+		server.mutex.Unlock()
+
+		if err != nil {
+			glog.V(100).Infof("Failed to handle ipmi packet from %s: %v", address, err)
+		}
+	}
+}
+
+// handlePacket handles a packet, dispatching it on its payload type. The mutex must be held.
+func (server *IPMIServer) handlePacket(data []byte, address *net.UDPAddr) error {
+	sessionID, err := ipmi.PacketSessionID(data)
+	if err != nil {
+		return server.handleLegacyPacket(data, address)
+	}
+
+	session := server.sessions[sessionID]
+
+	var keys *ipmi.SessionKeys
+	if session != nil {
+		keys = session.keys
+	}
+
+	packet, err := ipmi.UnmarshalPacket(data, keys)
+	if err != nil {
+		return err
+	}
+
+	switch packet.PayloadType {
+	case ipmi.PayloadTypeOpenSessionRequest:
+		return server.handleOpenSession(packet.Payload, address)
+	case ipmi.PayloadTypeRAKP1:
+		return server.handleRAKP1(packet.Payload, address)
+	case ipmi.PayloadTypeRAKP3:
+		return server.handleRAKP3(packet.Payload, address)
+	}
+
+	if session == nil || !session.established || !packet.Authenticated || !packet.Encrypted {
+		return fmt.Errorf("packet does not belong to an established session")
+	}
+
+	session.address = address
+
+	switch packet.PayloadType {
+	case ipmi.PayloadTypeIPMI:
+		request, err := ipmi.UnmarshalRequest(packet.Payload)
+		if err != nil {
+			return err
+		}
+
+		return server.send(session, ipmi.PayloadTypeIPMI, server.handleCommand(session, request).MarshalResponse())
+	case ipmi.PayloadTypeSOL:
+		return server.handleSOL(session, packet.Payload)
+	default:
+		return fmt.Errorf("unsupported payload type 0x%02x", packet.PayloadType)
+	}
+}
+
+// handleLegacyPacket answers Get Channel Authentication Capabilities, the only command sent outside of a session. The
+// mutex must be held.
+func (server *IPMIServer) handleLegacyPacket(data []byte, address *net.UDPAddr) error {
+	packet, err := ipmi.UnmarshalPacket(data, nil)
+	if err != nil {
+		return err
+	}
+
+	request, err := ipmi.UnmarshalRequest(packet.Payload)
+	if err != nil {
+		return err
+	}
+
+	response := ipmi.Message{NetFn: request.NetFn, Command: request.Command, Sequence: request.Sequence}
+
+	if request.NetFn == ipmi.NetFnApp && request.Command == ipmi.CommandGetChannelAuthenticationCapabilities {
+		// Channel 1 supports IPMI v2.0 with no per-message authentication and user level authentication disabled.
+		response.Data = []byte{0x01, 0x80, 0x18, 0x02, 0, 0, 0, 0}
+	} else {
+		response.CompletionCode = ipmi.CompletionCodeInvalidCommand
+	}
+
+	return server.write(ipmi.Packet{Legacy: true, Payload: response.MarshalResponse()}, nil, address)
+}
+
+// handleOpenSession creates a session for an Open Session Request if it requests cipher suite 3. The mutex must be
+// held.
+func (server *IPMIServer) handleOpenSession(payload []byte, address *net.UDPAddr) error {
+	request, err := ipmi.UnmarshalOpenSessionRequest(payload)
+	if err != nil {
+		return err
+	}
+
+	response := ipmi.OpenSessionResponse{Tag: request.Tag, ConsoleSessionID: request.ConsoleSessionID}
+
+	if request.AuthenticationAlgorithm != ipmi.AlgorithmRAKPHMACSHA1 ||
+		request.IntegrityAlgorithm != ipmi.AlgorithmHMACSHA196 ||
+		request.ConfidentialityAlgorithm != ipmi.AlgorithmAESCBC128 {
+		response.Status = ipmi.StatusNoCipherSuiteMatch
+	} else {
+		server.nextSessionID++
+
+		server.sessions[server.nextSessionID] = &ipmiSession{
+			params: ipmi.RAKPParameters{ConsoleSessionID: request.ConsoleSessionID, BMCSessionID: server.nextSessionID},
+		}
+
+		response.PrivilegeLevel = ipmi.PrivilegeLevelAdministrator
+		response.BMCSessionID = server.nextSessionID
+		response.AuthenticationAlgorithm = request.AuthenticationAlgorithm
+		response.IntegrityAlgorithm = request.IntegrityAlgorithm
+		response.ConfidentialityAlgorithm = request.ConfidentialityAlgorithm
+	}
+
+	return server.write(
+		ipmi.Packet{PayloadType: ipmi.PayloadTypeOpenSessionResponse, Payload: response.Marshal()}, nil, address)
+}
+
+// handleRAKP1 answers RAKP message 1 with RAKP message 2 if the user is the one the server accepts. The mutex must be
+// held.
+func (server *IPMIServer) handleRAKP1(payload []byte, address *net.UDPAddr) error {
+	request, err := ipmi.UnmarshalRAKPMessage1(payload)
+	if err != nil {
+		return err
+	}
+
+	response := ipmi.RAKPMessage2{Tag: request.Tag}
+	session := server.sessions[request.BMCSessionID]
+
+	switch {
+	case session == nil || session.established:
+		response.Status = ipmi.StatusInvalidSessionID
+	case subtle.ConstantTimeCompare([]byte(request.Username), []byte(server.username)) != 1:
+		response.Status = ipmi.StatusUnauthorizedName
+		response.ConsoleSessionID = session.params.ConsoleSessionID
+
+		delete(server.sessions, request.BMCSessionID)
+	default:
+		session.params.ConsoleRandom = request.ConsoleRandom
+		session.params.Role = request.Role
+		session.params.Username = request.Username
+		session.params.BMCGUID = server.guid
+		_, _ = rand.Read(session.params.BMCRandom[:])
+
+		response.ConsoleSessionID = session.params.ConsoleSessionID
+		response.BMCRandom = session.params.BMCRandom
+		response.BMCGUID = session.params.BMCGUID
+		response.AuthCode = session.params.RAKP2AuthCode(server.password)
+	}
+
+	return server.write(ipmi.Packet{PayloadType: ipmi.PayloadTypeRAKP2, Payload: response.Marshal()}, nil, address)
+}
+
+// handleRAKP3 establishes the session and answers with RAKP message 4 if the remote console proved it knows the
+// password. The mutex must be held.
+func (server *IPMIServer) handleRAKP3(payload []byte, address *net.UDPAddr) error {
+	request, err := ipmi.UnmarshalRAKPMessage3(payload)
+	if err != nil {
+		return err
+	}
+
+	response := ipmi.RAKPMessage4{Tag: request.Tag}
+	session := server.sessions[request.BMCSessionID]
+
+	switch {
+	case session == nil || session.established:
+		response.Status = ipmi.StatusInvalidSessionID
+	case !hmac.Equal(request.AuthCode, session.params.RAKP3AuthCode(server.password)):
+		response.Status = ipmi.StatusInvalidIntegrityCheckValue
+		response.ConsoleSessionID = session.params.ConsoleSessionID
+
+		delete(server.sessions, request.BMCSessionID)
+	default:
+		sik := session.params.SessionIntegrityKey(server.password)
+
+		session.keys = ipmi.NewSessionKeys(sik)
+		session.established = true
+
+		response.ConsoleSessionID = session.params.ConsoleSessionID
+		response.IntegrityCheck = session.params.RAKP4IntegrityCheck(sik)
+	}
+
+	return server.write(ipmi.Packet{PayloadType: ipmi.PayloadTypeRAKP4, Payload: response.Marshal()}, nil, address)
+}
+
+// handleCommand runs a command received on an established session and returns the response. The mutex must be held.
+func (server *IPMIServer) handleCommand(session *ipmiSession, request ipmi.Message) ipmi.Message {
+	response := ipmi.Message{NetFn: request.NetFn, Command: request.Command, Sequence: request.Sequence}
+
+	var (
+		data []byte
+		code uint8
+	)
+
+	switch request.NetFn {
+	case ipmi.NetFnApp:
+		data, code = server.handleAppCommand(session, request)
+	case ipmi.NetFnChassis:
+		data, code = server.handleChassisCommand(request)
+	default:
+		code = ipmi.CompletionCodeInvalidCommand
+	}
+
+	response.Data = data
+	response.CompletionCode = code
+
+	return response
+}
+
+// handleAppCommand runs a session or payload command, returning the response data and completion code. The mutex must
+// be held.
+func (server *IPMIServer) handleAppCommand(session *ipmiSession, request ipmi.Message) ([]byte, uint8) {
+	switch request.Command {
+	case ipmi.CommandSetSessionPrivilegeLevel:
+		if len(request.Data) < 1 || request.Data[0] > ipmi.PrivilegeLevelAdministrator {
+			return nil, ipmi.CompletionCodeInvalidData
+		}
+
+		return []byte{ipmi.PrivilegeLevelAdministrator}, ipmi.CompletionCodeOK
+	case ipmi.CommandCloseSession:
+		// The response is still sent using the keys of the session, which is only removed from the map.
+		delete(server.sessions, session.params.BMCSessionID)
+
+		if server.solSession == session {
+			server.solSession = nil
+		}
+
+		return nil, ipmi.CompletionCodeOK
+	case ipmi.CommandActivatePayload:
+		if len(request.Data) < 2 || ipmi.PayloadType(request.Data[0]) != ipmi.PayloadTypeSOL {
+			return nil, ipmi.CompletionCodeInvalidData
+		}
+
+		if server.solSession != nil {
+			return nil, ipmi.CompletionCodePayloadAlreadyActive
+		}
+
+		server.solSession = session
+		server.solSequence = 0
+
+		data := []byte{0, 0, 0, 0}
+		data = binary.LittleEndian.AppendUint16(data, ipmiSOLBufferSize)
+		data = binary.LittleEndian.AppendUint16(data, ipmiSOLBufferSize)
+		data = binary.LittleEndian.AppendUint16(data, server.Port())
+
+		return binary.LittleEndian.AppendUint16(data, 0xffff), ipmi.CompletionCodeOK
+	case ipmi.CommandDeactivatePayload:
+		if server.solSession != session {
+			// The payload is already deactivated, or activated by another session.
+			return nil, ipmi.CompletionCodePayloadAlreadyActive
+		}
+
+		server.solSession = nil
+
+		return nil, ipmi.CompletionCodeOK
+	default:
+		return nil, ipmi.CompletionCodeInvalidCommand
+	}
+}
+
+// handleChassisCommand runs a chassis command, returning the response data and completion code. The mutex must be
+// held.
+func (server *IPMIServer) handleChassisCommand(request ipmi.Message) ([]byte, uint8) {
+	switch request.Command {
+	case ipmi.CommandGetChassisStatus:
+		var powerState byte
+		if server.powerState == redfish.OnPowerState {
+			powerState = 0x01
+		}
+
+		return []byte{powerState, 0, 0}, ipmi.CompletionCodeOK
+	case ipmi.CommandChassisControl:
+		if len(request.Data) < 1 {
+			return nil, ipmi.CompletionCodeInvalidData
+		}
+
+		control := ipmi.ChassisControl(request.Data[0] & 0x0f)
+		if _, ok := ipmiResetTypes[control]; !ok {
+			return nil, ipmi.CompletionCodeInvalidData
+		}
+
+		server.chassisControl(control)
+
+		return nil, ipmi.CompletionCodeOK
+	case ipmi.CommandSetSystemBootOptions:
+		if len(request.Data) < 1 {
+			return nil, ipmi.CompletionCodeInvalidData
+		}
+
+		// Parameters other than the boot flags, such as set in progress, are accepted and ignored.
+		if request.Data[0]&0x7f == 0x05 {
+			flags, err := ipmi.UnmarshalBootFlags(request.Data[1:])
+			if err != nil {
+				return nil, ipmi.CompletionCodeInvalidData
+			}
+
+			server.bootFlags = flags
+		}
+
+		return nil, ipmi.CompletionCodeOK
+	case ipmi.CommandGetSystemBootOptions:
+		if len(request.Data) < 1 || request.Data[0]&0x7f != 0x05 {
+			return nil, ipmi.CompletionCodeInvalidData
+		}
+
+		return append([]byte{0x01, 0x05}, server.bootFlags.Marshal()...), ipmi.CompletionCodeOK
+	default:
+		return nil, ipmi.CompletionCodeInvalidCommand
+	}
+}
+
+// chassisControl records the chassis control action and changes the power state accordingly. When the system boots,
+// boot flags that are not persistent are cleared. The mutex must be held.
+func (server *IPMIServer) chassisControl(control ipmi.ChassisControl) {
+	glog.V(100).Infof("Performing chassis control action 0x%02x", control)
+
+	server.resetHistory = append(server.resetHistory, ipmiResetTypes[control])
+
+	wasOn := server.powerState == redfish.OnPowerState
+	booted := false
+
+	switch control {
+	case ipmi.ChassisControlPowerDown, ipmi.ChassisControlSoftShutdown:
+		server.powerState = redfish.OffPowerState
+	case ipmi.ChassisControlPowerUp:
+		server.powerState = redfish.OnPowerState
+		booted = !wasOn
+	case ipmi.ChassisControlPowerCycle, ipmi.ChassisControlHardReset:
+		booted = wasOn
+	default:
+	}
+
+	if booted && server.bootFlags.Valid && !server.bootFlags.Persistent {
+		glog.V(100).Infof("System booted from device 0x%02x, clearing boot flags", server.bootFlags.Device)
+
+		server.bootFlags = ipmi.BootFlags{}
+	}
+}
+
+// handleSOL records the character data of a SOL payload as console input and acknowledges it. The mutex must be
+// held.
+func (server *IPMIServer) handleSOL(session *ipmiSession, data []byte) error {
+	if server.solSession != session {
+		return fmt.Errorf("sol payload is not active on the session")
+	}
+
+	payload, err := ipmi.UnmarshalSOLPayload(data)
+	if err != nil {
+		return err
+	}
+
+	// Packets that only acknowledge console output need no answer.
+	if payload.Sequence == 0 {
+		return nil
+	}
+
+	server.consoleInput.Write(payload.Data)
+
+	ack := ipmi.SOLPayload{AckSequence: payload.Sequence, AcceptedCount: uint8(len(payload.Data))}
+
+	return server.send(session, ipmi.PayloadTypeSOL, ack.Marshal())
+}
+
+// send sends an authenticated and encrypted packet on the session. The mutex must be held.
+func (server *IPMIServer) send(session *ipmiSession, payloadType ipmi.PayloadType, payload []byte) error {
+	session.sequence++
+
+	return server.write(ipmi.Packet{
+		PayloadType:   payloadType,
+		Authenticated: true,
+		Encrypted:     true,
+		SessionID:     session.params.ConsoleSessionID,
+		Sequence:      session.sequence,
+		Payload:       payload,
+	}, session.keys, session.address)
+}
+
+// write encodes the packet and sends it to the address.
+func (server *IPMIServer) write(packet ipmi.Packet, keys *ipmi.SessionKeys, address *net.UDPAddr) error {
+	data, err := ipmi.MarshalPacket(packet, keys)
+	if err != nil {
+		return err
+	}
+
+	_, err = server.conn.WriteToUDP(data, address)
+
+	return err
+}
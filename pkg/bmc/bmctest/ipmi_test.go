@@ -0,0 +1,181 @@
+package bmctest
+
+import (
+	"bufio"
+	"testing"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/bmc"
+	"github.com/stmcginnis/gofish/redfish"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPMIServerPowerActions(t *testing.T) {
+	server := NewIPMIServer()
+	defer server.Close()
+
+	bmcClient := newTestIPMIBMC(server)
+
+	powerState, err := bmcClient.SystemPowerState()
+	assert.Nil(t, err)
+	assert.Equal(t, string(redfish.OnPowerState), powerState)
+
+	err = bmcClient.SystemPowerOff()
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.OffPowerState, server.PowerState())
+
+	powerState, err = bmcClient.SystemPowerState()
+	assert.Nil(t, err)
+	assert.Equal(t, string(redfish.OffPowerState), powerState)
+
+	err = bmcClient.SystemPowerOn()
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.OnPowerState, server.PowerState())
+
+	err = bmcClient.WaitForSystemPowerState(redfish.OnPowerState, time.Second)
+	assert.Nil(t, err)
+
+	err = bmcClient.SystemPowerCycle()
+	assert.Nil(t, err)
+
+	err = bmcClient.SystemForceReset()
+	assert.Nil(t, err)
+
+	err = bmcClient.SystemGracefulShutdown()
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.OffPowerState, server.PowerState())
+	assert.Equal(t, []redfish.ResetType{
+		redfish.ForceOffResetType, redfish.OnResetType, redfish.PowerCycleResetType, redfish.ForceRestartResetType,
+		redfish.GracefulShutdownResetType,
+	}, server.ResetHistory())
+}
+
+func TestIPMIServerCredentials(t *testing.T) {
+	server := NewIPMIServer().WithCredentials("operator", "secret")
+	defer server.Close()
+
+	_, err := newTestIPMIBMC(server).SystemPowerState()
+	assert.ErrorContains(t, err, "ipmi connection error")
+
+	_, err = bmc.New(server.Host()).
+		WithBackend(bmc.BackendIPMI).
+		WithIPMIUser("operator", DefaultPassword).
+		WithIPMIPort(server.Port()).
+		WithIPMITimeout(time.Second).
+		SystemPowerState()
+	assert.ErrorContains(t, err, "invalid rakp message 2 authentication code")
+
+	powerState, err := bmc.New(server.Host()).
+		WithBackend(bmc.BackendIPMI).
+		WithIPMIUser("operator", "secret").
+		WithIPMIPort(server.Port()).
+		SystemPowerState()
+	assert.Nil(t, err)
+	assert.Equal(t, string(redfish.OnPowerState), powerState)
+}
+
+func TestIPMIServerBootOverride(t *testing.T) {
+	server := NewIPMIServer()
+	defer server.Close()
+
+	bmcClient := newTestIPMIBMC(server)
+
+	err := bmcClient.SetBootOverride(
+		redfish.PxeBootSourceOverrideTarget, redfish.OnceBootSourceOverrideEnabled, redfish.UEFIBootSourceOverrideMode)
+	assert.Nil(t, err)
+	assert.Equal(t, BootOverride{
+		Enabled: redfish.OnceBootSourceOverrideEnabled,
+		Target:  redfish.PxeBootSourceOverrideTarget,
+		Mode:    redfish.UEFIBootSourceOverrideMode,
+	}, server.BootOverride())
+
+	// Overrides that apply once are cleared when the system boots.
+	err = bmcClient.SystemForceReset()
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.DisabledBootSourceOverrideEnabled, server.BootOverride().Enabled)
+
+	err = bmcClient.SetBootOverride(redfish.HddBootSourceOverrideTarget, redfish.ContinuousBootSourceOverrideEnabled, "")
+	assert.Nil(t, err)
+
+	err = bmcClient.SystemPowerCycle()
+	assert.Nil(t, err)
+	assert.Equal(t, BootOverride{
+		Enabled: redfish.ContinuousBootSourceOverrideEnabled,
+		Target:  redfish.HddBootSourceOverrideTarget,
+		Mode:    redfish.LegacyBootSourceOverrideMode,
+	}, server.BootOverride())
+}
+
+func TestIPMIServerSerialOverLAN(t *testing.T) {
+	server := NewIPMIServer()
+	defer server.Close()
+
+	bmcClient := newTestIPMIBMC(server)
+
+	reader, writer, err := bmcClient.OpenSerialConsole("")
+	assert.Nil(t, err)
+	assert.True(t, server.SOLActive())
+
+	_, _, err = bmcClient.OpenSerialConsole("")
+	assert.ErrorContains(t, err, "there is already a serial console opened")
+
+	err = server.WriteConsole("Red Hat Enterprise Linux CoreOS\nlocalhost login: ")
+	assert.Nil(t, err)
+
+	lines := bufio.NewReader(reader)
+
+	line, err := lines.ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "Red Hat Enterprise Linux CoreOS\n", line)
+
+	// Input longer than the SOL buffer of the server is split across several packets.
+	input := "core\r" + string(make([]byte, 100))
+
+	written, err := writer.Write([]byte(input))
+	assert.Nil(t, err)
+	assert.Equal(t, len(input), written)
+	assert.Equal(t, input, server.ConsoleInput())
+
+	err = bmcClient.CloseSerialConsole()
+	assert.Nil(t, err)
+	assert.False(t, server.SOLActive())
+
+	_, err = writer.Write([]byte("exit\r"))
+	assert.NotNil(t, err)
+
+	err = server.WriteConsole("ignored")
+	assert.NotNil(t, err)
+}
+
+func TestIPMIServerConsoleSession(t *testing.T) {
+	server := NewIPMIServer()
+	defer server.Close()
+
+	session, err := newTestIPMIBMC(server).OpenConsoleSession("")
+	assert.Nil(t, err)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+
+		_ = server.WriteConsole("Press F2 to enter setup")
+	}()
+
+	_, err = session.Expect("enter setup", time.Second)
+	assert.Nil(t, err)
+
+	err = session.Send(bmc.ConsoleKeyF2)
+	assert.Nil(t, err)
+	assert.Equal(t, string(bmc.ConsoleKeyF2), server.ConsoleInput())
+
+	err = session.Close()
+	assert.Nil(t, err)
+	assert.False(t, server.SOLActive())
+}
+
+func newTestIPMIBMC(server *IPMIServer) *bmc.BMC {
+	return bmc.New(server.Host()).
+		WithBackend(bmc.BackendIPMI).
+		WithIPMIUser(DefaultUsername, DefaultPassword).
+		WithIPMIPort(server.Port()).
+		WithIPMITimeout(time.Second)
+}
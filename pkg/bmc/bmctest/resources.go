@@ -0,0 +1,635 @@
+package bmctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+const (
+	serviceRootURI       = "/redfish/v1"
+	sessionsURI          = serviceRootURI + "/SessionService/Sessions"
+	systemsURI           = serviceRootURI + "/Systems"
+	systemURI            = systemsURI + "/" + SystemID
+	secureBootURI        = systemURI + "/SecureBoot"
+	virtualMediaURI      = systemURI + "/VirtualMedia"
+	chassisCollectionURI = serviceRootURI + "/Chassis"
+	chassisURI           = chassisCollectionURI + "/" + ChassisID
+	powerURI             = chassisURI + "/Power"
+	updateServiceURI     = serviceRootURI + "/UpdateService"
+	tasksURI             = serviceRootURI + "/TaskService/Tasks"
+	taskMonitorsURI      = serviceRootURI + "/TaskService/TaskMonitors"
+)
+
+// registerRoutes registers the handlers of the Redfish resources of the server.
+func (server *Server) registerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+serviceRootURI, server.getServiceRoot)
+	mux.HandleFunc("GET "+serviceRootURI+"/{$}", server.getServiceRoot)
+	mux.HandleFunc("POST "+sessionsURI, server.createSession)
+	mux.HandleFunc("DELETE "+sessionsURI+"/{id}", server.deleteSession)
+
+	mux.HandleFunc("GET "+systemsURI, getCollection("ComputerSystemCollection", systemURI))
+	mux.HandleFunc("GET "+systemURI, server.getSystem)
+	mux.HandleFunc("PATCH "+systemURI, server.patchSystem)
+	mux.HandleFunc("POST "+systemURI+"/Actions/ComputerSystem.Reset", server.resetSystem)
+	mux.HandleFunc("GET "+secureBootURI, server.getSecureBoot)
+	mux.HandleFunc("PATCH "+secureBootURI, server.patchSecureBoot)
+	mux.HandleFunc("GET "+virtualMediaURI, server.getVirtualMediaCollection)
+	mux.HandleFunc("GET "+virtualMediaURI+"/{id}", server.getVirtualMediaSlot)
+	mux.HandleFunc("POST "+virtualMediaURI+"/{id}/Actions/VirtualMedia.InsertMedia", server.insertVirtualMedia)
+	mux.HandleFunc("POST "+virtualMediaURI+"/{id}/Actions/VirtualMedia.EjectMedia", server.ejectVirtualMedia)
+
+	mux.HandleFunc("GET "+chassisCollectionURI, getCollection("ChassisCollection", chassisURI))
+	mux.HandleFunc("GET "+chassisURI, server.getChassis)
+	mux.HandleFunc("GET "+powerURI, server.getPower)
+
+	mux.HandleFunc("GET "+updateServiceURI, server.getUpdateService)
+	mux.HandleFunc("GET "+updateServiceURI+"/FirmwareInventory", getCollection("SoftwareInventoryCollection"))
+	mux.HandleFunc("POST "+updateServiceURI+"/Actions/UpdateService.SimpleUpdate", server.simpleUpdate)
+	mux.HandleFunc("GET "+tasksURI+"/{id}", server.getTask)
+	mux.HandleFunc("GET "+taskMonitorsURI+"/{id}", server.getTaskMonitor)
+}
+
+// getCollection returns a handler that serves a collection with the provided members.
+func getCollection(name string, members ...string) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		links := []map[string]any{}
+
+		for _, member := range members {
+			links = append(links, map[string]any{"@odata.id": member})
+		}
+
+		writeJSON(writer, http.StatusOK, map[string]any{
+			"@odata.id":           request.URL.Path,
+			"@odata.type":         fmt.Sprintf("#%s.%s", name, name),
+			"Name":                name,
+			"Members":             links,
+			"Members@odata.count": len(links),
+		})
+	}
+}
+
+// getServiceRoot serves the service root, which does not require authentication.
+func (server *Server) getServiceRoot(writer http.ResponseWriter, request *http.Request) {
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"@odata.id":      serviceRootURI,
+		"@odata.type":    "#ServiceRoot.v1_15_0.ServiceRoot",
+		"Id":             "RootService",
+		"Name":           "Root Service",
+		"RedfishVersion": "1.17.0",
+		"Systems":        map[string]any{"@odata.id": systemsURI},
+		"Chassis":        map[string]any{"@odata.id": chassisCollectionURI},
+		"UpdateService":  map[string]any{"@odata.id": updateServiceURI},
+		"SessionService": map[string]any{"@odata.id": serviceRootURI + "/SessionService"},
+		"Links": map[string]any{
+			"Sessions": map[string]any{"@odata.id": sessionsURI},
+		},
+	})
+}
+
+// createSession creates a session if the credentials are valid, returning its token in the X-Auth-Token header.
+func (server *Server) createSession(writer http.ResponseWriter, request *http.Request) {
+	var credentials struct {
+		UserName string
+		Password string
+	}
+
+	if err := json.NewDecoder(request.Body).Decode(&credentials); err != nil {
+		writeError(writer, http.StatusBadRequest, "Base.1.12.MalformedJSON", err.Error())
+
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if !server.checkCredentials(credentials.UserName, credentials.Password) {
+		glog.V(100).Infof("Rejecting session for user %s", credentials.UserName)
+
+		writeError(writer, http.StatusUnauthorized, "Base.1.12.InsufficientPrivilege", "invalid credentials")
+
+		return
+	}
+
+	sessionID := server.newID()
+	token := fmt.Sprintf("token-%s-%d", sessionID, time.Now().UnixNano())
+	server.sessions[token] = true
+
+	writer.Header().Set("X-Auth-Token", token)
+	writer.Header().Set("Location", sessionsURI+"/"+sessionID)
+	writeJSON(writer, http.StatusCreated, map[string]any{
+		"@odata.id": sessionsURI + "/" + sessionID,
+		"Id":        sessionID,
+		"UserName":  credentials.UserName,
+	})
+}
+
+// deleteSession deletes the session of the request.
+func (server *Server) deleteSession(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	delete(server.sessions, request.Header.Get("X-Auth-Token"))
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// getSystem serves the computer system.
+func (server *Server) getSystem(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"@odata.id":    systemURI,
+		"@odata.type":  "#ComputerSystem.v1_20_0.ComputerSystem",
+		"Id":           SystemID,
+		"Name":         "System",
+		"Manufacturer": server.manufacturer,
+		"Model":        server.model,
+		"PowerState":   server.powerState,
+		"Boot": map[string]any{
+			"BootSourceOverrideEnabled":                        server.bootOverride.Enabled,
+			"BootSourceOverrideTarget":                         server.bootOverride.Target,
+			"BootSourceOverrideMode":                           server.bootOverride.Mode,
+			"BootSourceOverrideTarget@Redfish.AllowableValues": supportedBootTargets,
+		},
+		"Actions": map[string]any{
+			"#ComputerSystem.Reset": map[string]any{
+				"target":                            systemURI + "/Actions/ComputerSystem.Reset",
+				"ResetType@Redfish.AllowableValues": supportedResetTypes,
+			},
+		},
+		"SecureBoot":   map[string]any{"@odata.id": secureBootURI},
+		"VirtualMedia": map[string]any{"@odata.id": virtualMediaURI},
+		"Links": map[string]any{
+			"Chassis": []map[string]any{{"@odata.id": chassisURI}},
+		},
+	})
+}
+
+// patchSystem updates the boot source override of the system.
+func (server *Server) patchSystem(writer http.ResponseWriter, request *http.Request) {
+	var patch struct {
+		Boot struct {
+			BootSourceOverrideEnabled redfish.BootSourceOverrideEnabled
+			BootSourceOverrideTarget  redfish.BootSourceOverrideTarget
+			BootSourceOverrideMode    redfish.BootSourceOverrideMode
+		}
+	}
+
+	if err := json.NewDecoder(request.Body).Decode(&patch); err != nil {
+		writeError(writer, http.StatusBadRequest, "Base.1.12.MalformedJSON", err.Error())
+
+		return
+	}
+
+	target := patch.Boot.BootSourceOverrideTarget
+	if target != "" && !slices.Contains(supportedBootTargets, target) {
+		writeError(writer, http.StatusBadRequest, "Base.1.12.PropertyValueNotInList",
+			fmt.Sprintf("boot source override target %s is not supported", target))
+
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if patch.Boot.BootSourceOverrideEnabled != "" {
+		server.bootOverride.Enabled = patch.Boot.BootSourceOverrideEnabled
+	}
+
+	if target != "" {
+		server.bootOverride.Target = target
+	}
+
+	if patch.Boot.BootSourceOverrideMode != "" {
+		server.bootOverride.Mode = patch.Boot.BootSourceOverrideMode
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// resetSystem performs a reset action, starting the matching power transition.
+func (server *Server) resetSystem(writer http.ResponseWriter, request *http.Request) {
+	var reset struct {
+		ResetType redfish.ResetType
+	}
+
+	if err := json.NewDecoder(request.Body).Decode(&reset); err != nil {
+		writeError(writer, http.StatusBadRequest, "Base.1.12.MalformedJSON", err.Error())
+
+		return
+	}
+
+	if !slices.Contains(supportedResetTypes, reset.ResetType) {
+		writeError(writer, http.StatusBadRequest, "Base.1.12.ActionParameterNotSupported",
+			fmt.Sprintf("reset type %s is not supported", reset.ResetType))
+
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	glog.V(100).Infof("Resetting system with reset type %s", reset.ResetType)
+
+	server.resetHistory = append(server.resetHistory, reset.ResetType)
+
+	switch reset.ResetType {
+	case redfish.OnResetType, redfish.ForceRestartResetType, redfish.GracefulRestartResetType,
+		redfish.PowerCycleResetType:
+		server.startPowerTransition(redfish.OnPowerState)
+	case redfish.ForceOffResetType:
+		server.powerState, server.pendingPowerState = redfish.OffPowerState, ""
+	case redfish.GracefulShutdownResetType:
+		server.startPowerTransition(redfish.OffPowerState)
+	case redfish.PushPowerButtonResetType:
+		if server.powerState == redfish.OffPowerState {
+			server.startPowerTransition(redfish.OnPowerState)
+		} else {
+			server.startPowerTransition(redfish.OffPowerState)
+		}
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// getSecureBoot serves the secure boot resource of the system.
+func (server *Server) getSecureBoot(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	secureBootCurrentBoot := "Disabled"
+	if server.secureBootEnabled {
+		secureBootCurrentBoot = "Enabled"
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"@odata.id":             secureBootURI,
+		"@odata.type":           "#SecureBoot.v1_1_0.SecureBoot",
+		"Id":                    "SecureBoot",
+		"Name":                  "UEFI Secure Boot",
+		"SecureBootEnable":      server.secureBootEnabled,
+		"SecureBootCurrentBoot": secureBootCurrentBoot,
+		"SecureBootMode":        "DeployedMode",
+	})
+}
+
+// patchSecureBoot enables or disables secure boot.
+func (server *Server) patchSecureBoot(writer http.ResponseWriter, request *http.Request) {
+	var patch struct {
+		SecureBootEnable *bool
+	}
+
+	if err := json.NewDecoder(request.Body).Decode(&patch); err != nil {
+		writeError(writer, http.StatusBadRequest, "Base.1.12.MalformedJSON", err.Error())
+
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if patch.SecureBootEnable != nil {
+		server.secureBootEnabled = *patch.SecureBootEnable
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// getVirtualMediaCollection serves the collection of virtual media slots.
+func (server *Server) getVirtualMediaCollection(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+
+	var members []string
+
+	for _, media := range server.virtualMedia {
+		members = append(members, virtualMediaURI+"/"+media.ID)
+	}
+
+	server.mutex.Unlock()
+
+	getCollection("VirtualMediaCollection", members...)(writer, request)
+}
+
+// getVirtualMediaSlot serves a virtual media slot.
+func (server *Server) getVirtualMediaSlot(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	media := server.getVirtualMedia(request.PathValue("id"))
+	if media == nil {
+		writeError(writer, http.StatusNotFound, "Base.1.12.ResourceMissingAtURI", "virtual media slot not found")
+
+		return
+	}
+
+	mediaURI := virtualMediaURI + "/" + media.ID
+	connectedVia := redfish.NotConnectedConnectedVia
+
+	if media.Inserted {
+		connectedVia = redfish.URIConnectedVia
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"@odata.id":      mediaURI,
+		"@odata.type":    "#VirtualMedia.v1_6_0.VirtualMedia",
+		"Id":             media.ID,
+		"Name":           "Virtual Media",
+		"MediaTypes":     media.MediaTypes,
+		"Image":          media.Image,
+		"Inserted":       media.Inserted,
+		"WriteProtected": media.WriteProtected,
+		"ConnectedVia":   connectedVia,
+		"Actions": map[string]any{
+			"#VirtualMedia.InsertMedia": map[string]any{"target": mediaURI + "/Actions/VirtualMedia.InsertMedia"},
+			"#VirtualMedia.EjectMedia":  map[string]any{"target": mediaURI + "/Actions/VirtualMedia.EjectMedia"},
+		},
+	})
+}
+
+// insertVirtualMedia inserts an image in a virtual media slot. It fails with 409 Conflict if the slot is in use.
+func (server *Server) insertVirtualMedia(writer http.ResponseWriter, request *http.Request) {
+	var insert struct {
+		Image          string
+		WriteProtected *bool
+	}
+
+	if err := json.NewDecoder(request.Body).Decode(&insert); err != nil {
+		writeError(writer, http.StatusBadRequest, "Base.1.12.MalformedJSON", err.Error())
+
+		return
+	}
+
+	if insert.Image == "" {
+		writeError(writer, http.StatusBadRequest, "Base.1.12.ActionParameterMissing", "image is required")
+
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	media := server.getVirtualMedia(request.PathValue("id"))
+	if media == nil {
+		writeError(writer, http.StatusNotFound, "Base.1.12.ResourceMissingAtURI", "virtual media slot not found")
+
+		return
+	}
+
+	if media.Inserted {
+		writeError(writer, http.StatusConflict, "Base.1.12.ResourceInUse", "virtual media is already inserted")
+
+		return
+	}
+
+	media.Image = insert.Image
+	media.Inserted = true
+	media.WriteProtected = insert.WriteProtected == nil || *insert.WriteProtected
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// ejectVirtualMedia ejects the image of a virtual media slot.
+func (server *Server) ejectVirtualMedia(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	media := server.getVirtualMedia(request.PathValue("id"))
+	if media == nil {
+		writeError(writer, http.StatusNotFound, "Base.1.12.ResourceMissingAtURI", "virtual media slot not found")
+
+		return
+	}
+
+	media.Image = ""
+	media.Inserted = false
+	media.WriteProtected = false
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// getChassis serves the chassis.
+func (server *Server) getChassis(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"@odata.id":   chassisURI,
+		"@odata.type": "#Chassis.v1_23_0.Chassis",
+		"Id":          ChassisID,
+		"Name":        "Computer System Chassis",
+		"ChassisType": "RackMount",
+		"PowerState":  server.powerState,
+		"Power":       map[string]any{"@odata.id": powerURI},
+		"Links": map[string]any{
+			"ComputerSystems": []map[string]any{{"@odata.id": systemURI}},
+		},
+	})
+}
+
+// getPower serves the power resource of the chassis. The system consumes no power unless it is on.
+func (server *Server) getPower(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	var consumedWatts float32
+
+	if server.powerState == redfish.OnPowerState {
+		consumedWatts = server.powerConsumedWatts
+	}
+
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"@odata.id":   powerURI,
+		"@odata.type": "#Power.v1_7_1.Power",
+		"Id":          "Power",
+		"Name":        "Power",
+		"PowerControl": []map[string]any{{
+			"@odata.id":          powerURI + "#/PowerControl/0",
+			"MemberId":           "0",
+			"Name":               "System Power Control",
+			"PowerConsumedWatts": consumedWatts,
+		}},
+	})
+}
+
+// getUpdateService serves the update service.
+func (server *Server) getUpdateService(writer http.ResponseWriter, request *http.Request) {
+	writeJSON(writer, http.StatusOK, map[string]any{
+		"@odata.id":         updateServiceURI,
+		"@odata.type":       "#UpdateService.v1_11_0.UpdateService",
+		"Id":                "UpdateService",
+		"Name":              "Update Service",
+		"ServiceEnabled":    true,
+		"FirmwareInventory": map[string]any{"@odata.id": updateServiceURI + "/FirmwareInventory"},
+		"Actions": map[string]any{
+			"#UpdateService.SimpleUpdate": map[string]any{
+				"target": updateServiceURI + "/Actions/UpdateService.SimpleUpdate",
+			},
+		},
+	})
+}
+
+// simpleUpdate creates a firmware update task and returns its URI in the Location header.
+func (server *Server) simpleUpdate(writer http.ResponseWriter, request *http.Request) {
+	var update struct {
+		ImageURI string
+	}
+
+	if err := json.NewDecoder(request.Body).Decode(&update); err != nil {
+		writeError(writer, http.StatusBadRequest, "Base.1.12.MalformedJSON", err.Error())
+
+		return
+	}
+
+	if update.ImageURI == "" {
+		writeError(writer, http.StatusBadRequest, "Base.1.12.ActionParameterMissing", "image URI is required")
+
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	taskID := server.newID()
+	server.tasks[taskID] = &task{
+		id:        taskID,
+		name:      "Firmware update " + update.ImageURI,
+		startTime: time.Now(),
+		duration:  server.taskDuration,
+		fail:      server.failTasks,
+	}
+
+	glog.V(100).Infof("Created task %s for firmware update %s", taskID, update.ImageURI)
+
+	writer.Header().Set("Location", tasksURI+"/"+taskID)
+	writer.WriteHeader(http.StatusAccepted)
+}
+
+// getTask serves a task.
+func (server *Server) getTask(writer http.ResponseWriter, request *http.Request) {
+	body, _, found := server.getTaskBody(request.PathValue("id"))
+	if !found {
+		writeError(writer, http.StatusNotFound, "Base.1.12.ResourceMissingAtURI", "task not found")
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, body)
+}
+
+// getTaskMonitor serves the task monitor of a task, which returns 202 Accepted while the task runs and 200 OK once it
+// finishes.
+func (server *Server) getTaskMonitor(writer http.ResponseWriter, request *http.Request) {
+	body, finished, found := server.getTaskBody(request.PathValue("id"))
+	if !found {
+		writeError(writer, http.StatusNotFound, "Base.1.12.ResourceMissingAtURI", "task not found")
+
+		return
+	}
+
+	if !finished {
+		writer.Header().Set("Location", taskMonitorsURI+"/"+request.PathValue("id"))
+		writeJSON(writer, http.StatusAccepted, body)
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, body)
+}
+
+// getTaskBody returns the body of the task with the provided ID, whether it finished, and whether it was found.
+func (server *Server) getTaskBody(taskID string) (map[string]any, bool, bool) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	task, found := server.tasks[taskID]
+	if !found {
+		return nil, false, false
+	}
+
+	elapsed := time.Since(task.startTime)
+	state, percent := redfish.RunningTaskState, 0
+	messages := []map[string]any{}
+
+	switch {
+	case elapsed < task.duration:
+		percent = int(100 * elapsed / task.duration)
+	case task.fail:
+		state, percent = redfish.ExceptionTaskState, 100
+		messages = append(messages, map[string]any{
+			"MessageId": "Base.1.12.InternalError",
+			"Message":   "The task failed",
+			"Severity":  "Critical",
+		})
+	default:
+		state, percent = redfish.CompletedTaskState, 100
+		messages = append(messages, map[string]any{
+			"MessageId": "Base.1.12.Success",
+			"Message":   "Successfully Completed Request",
+			"Severity":  "OK",
+		})
+	}
+
+	return map[string]any{
+		"@odata.id":       tasksURI + "/" + task.id,
+		"@odata.type":     "#Task.v1_7_0.Task",
+		"Id":              task.id,
+		"Name":            task.name,
+		"TaskState":       state,
+		"PercentComplete": percent,
+		"StartTime":       task.startTime.Format(time.RFC3339),
+		"TaskMonitor":     taskMonitorsURI + "/" + task.id,
+		"Messages":        messages,
+	}, state != redfish.RunningTaskState, true
+}
+
+// startPowerTransition starts a transition of the system to the target power state, which finishes after the power
+// transition delay. The mutex must be held.
+func (server *Server) startPowerTransition(target redfish.PowerState) {
+	server.pendingPowerState = target
+	server.powerTransitionAt = time.Now().Add(server.powerTransitionDelay)
+
+	if target == redfish.OnPowerState {
+		server.powerState = redfish.PoweringOnPowerState
+	} else {
+		server.powerState = redfish.PoweringOffPowerState
+	}
+
+	server.settlePowerState()
+}
+
+// settlePowerState finishes the power transition in progress if its delay passed. When the system powers on, a boot
+// source override that applies once is disabled. The mutex must be held.
+func (server *Server) settlePowerState() {
+	if server.pendingPowerState == "" || time.Now().Before(server.powerTransitionAt) {
+		return
+	}
+
+	server.powerState = server.pendingPowerState
+	server.pendingPowerState = ""
+
+	if server.powerState == redfish.OnPowerState &&
+		server.bootOverride.Enabled == redfish.OnceBootSourceOverrideEnabled {
+		glog.V(100).Infof("System booted from %s, disabling boot source override", server.bootOverride.Target)
+
+		server.bootOverride.Enabled = redfish.DisabledBootSourceOverrideEnabled
+		server.bootOverride.Target = redfish.NoneBootSourceOverrideTarget
+	}
+}
+
+// getVirtualMedia returns the virtual media slot with the provided ID, or nil if there is none. The mutex must be held.
+func (server *Server) getVirtualMedia(slot string) *VirtualMediaState {
+	for _, media := range server.virtualMedia {
+		if media.ID == slot {
+			return media
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,475 @@
+package bmctest
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+const (
+	// DefaultUsername is the username the server accepts unless WithCredentials is used.
+	DefaultUsername = "admin"
+	// DefaultPassword is the password the server accepts unless WithCredentials is used.
+	DefaultPassword = "password"
+	// DefaultManufacturer is the manufacturer of the system unless WithSystem is used.
+	DefaultManufacturer = "Dell Inc."
+	// DefaultModel is the model of the system unless WithSystem is used.
+	DefaultModel = "PowerEdge R750"
+	// DefaultPowerConsumedWatts is the power consumed by the system while it is on, unless SetPowerConsumedWatts is
+	// used.
+	DefaultPowerConsumedWatts = 360
+
+	// SystemID is the ID of the only system of the server.
+	SystemID = "System.Embedded.1"
+	// ChassisID is the ID of the only chassis of the server.
+	ChassisID = "System.Embedded.1"
+	// CDVirtualMediaSlot is the ID of the virtual media slot that supports CD and DVD images.
+	CDVirtualMediaSlot = "1"
+	// USBVirtualMediaSlot is the ID of the virtual media slot that supports USB stick and floppy images.
+	USBVirtualMediaSlot = "2"
+)
+
+// supportedResetTypes are the reset types the system supports.
+var supportedResetTypes = []redfish.ResetType{
+	redfish.OnResetType, redfish.ForceOffResetType, redfish.ForceRestartResetType, redfish.GracefulRestartResetType,
+	redfish.GracefulShutdownResetType, redfish.PushPowerButtonResetType, redfish.NmiResetType,
+	redfish.PowerCycleResetType,
+}
+
+// supportedBootTargets are the boot source override targets the system supports.
+var supportedBootTargets = []redfish.BootSourceOverrideTarget{
+	redfish.NoneBootSourceOverrideTarget, redfish.PxeBootSourceOverrideTarget, redfish.FloppyBootSourceOverrideTarget,
+	redfish.CdBootSourceOverrideTarget, redfish.HddBootSourceOverrideTarget, redfish.BiosSetupBootSourceOverrideTarget,
+	redfish.UtilitiesBootSourceOverrideTarget, redfish.UefiTargetBootSourceOverrideTarget,
+	redfish.UefiHTTPBootSourceOverrideTarget, redfish.UsbBootSourceOverrideTarget,
+}
+
+// Fault makes the server fail or delay the requests that match it.
+type Fault struct {
+	// Method is the HTTP method of the requests to match. If empty, every method matches.
+	Method string
+	// Path is the prefix of the path of the requests to match. If empty, every path matches.
+	Path string
+	// StatusCode is the status code returned for the matching requests. If zero, the requests are served normally
+	// after Delay.
+	StatusCode int
+	// Delay is the time the matching requests are delayed before being failed or served.
+	Delay time.Duration
+	// Count is the number of requests the fault applies to. If zero, it applies until ClearFaults is called.
+	Count int
+}
+
+// VirtualMediaState holds the state of a virtual media slot of the server.
+type VirtualMediaState struct {
+	// ID is the ID of the slot.
+	ID string
+	// MediaTypes are the media types the slot supports.
+	MediaTypes []redfish.VirtualMediaType
+	// Image is the URL of the image inserted in the slot.
+	Image string
+	// Inserted is true if an image is inserted in the slot.
+	Inserted bool
+	// WriteProtected is true if the inserted image is write protected.
+	WriteProtected bool
+}
+
+// BootOverride holds the boot source override of the system.
+type BootOverride struct {
+	// Enabled is whether the override is disabled, applies once or applies continuously.
+	Enabled redfish.BootSourceOverrideEnabled
+	// Target is the boot source to use instead of the normal boot order.
+	Target redfish.BootSourceOverrideTarget
+	// Mode is the boot mode to use with the override.
+	Mode redfish.BootSourceOverrideMode
+}
+
+// Server is an in-process Redfish server that models a single system, so code using pkg/bmc can be tested end to end
+// without hardware. Use NewServer to create one and Host to get the host to pass to bmc.New.
+type Server struct {
+	httpServer *httptest.Server
+
+	mutex    sync.Mutex
+	username string
+	password string
+	sessions map[string]bool
+	nextID   int
+
+	manufacturer         string
+	model                string
+	powerState           redfish.PowerState
+	pendingPowerState    redfish.PowerState
+	powerTransitionAt    time.Time
+	powerTransitionDelay time.Duration
+	powerConsumedWatts   float32
+	resetHistory         []redfish.ResetType
+	bootOverride         BootOverride
+	secureBootEnabled    bool
+	virtualMedia         []*VirtualMediaState
+
+	tasks        map[string]*task
+	taskDuration time.Duration
+	failTasks    bool
+
+	faults []*Fault
+}
+
+// task holds the state of a task created by the server.
+type task struct {
+	id        string
+	name      string
+	startTime time.Time
+	duration  time.Duration
+	fail      bool
+}
+
+// NewServer starts a Redfish server over TLS with a system that is powered on and has secure boot disabled. The
+// server must be closed with Close once it is no longer used.
+func NewServer() *Server {
+	glog.V(100).Info("Starting Redfish simulator")
+
+	server := &Server{
+		username:           DefaultUsername,
+		password:           DefaultPassword,
+		sessions:           map[string]bool{},
+		manufacturer:       DefaultManufacturer,
+		model:              DefaultModel,
+		powerState:         redfish.OnPowerState,
+		powerConsumedWatts: DefaultPowerConsumedWatts,
+		bootOverride: BootOverride{
+			Enabled: redfish.DisabledBootSourceOverrideEnabled,
+			Target:  redfish.NoneBootSourceOverrideTarget,
+			Mode:    redfish.UEFIBootSourceOverrideMode,
+		},
+		virtualMedia: []*VirtualMediaState{
+			{ID: CDVirtualMediaSlot, MediaTypes: []redfish.VirtualMediaType{redfish.CDMediaType, redfish.DVDMediaType}},
+			{
+				ID:         USBVirtualMediaSlot,
+				MediaTypes: []redfish.VirtualMediaType{redfish.USBStickMediaType, redfish.FloppyMediaType},
+			},
+		},
+		tasks: map[string]*task{},
+	}
+
+	mux := http.NewServeMux()
+	server.registerRoutes(mux)
+
+	server.httpServer = httptest.NewUnstartedServer(server.middleware(mux))
+	server.httpServer.EnableHTTP2 = true
+	server.httpServer.StartTLS()
+
+	return server
+}
+
+// WithCredentials sets the username and password the server accepts. Sessions created with other credentials fail
+// with 401 Unauthorized.
+func (server *Server) WithCredentials(username, password string) *Server {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.username = username
+	server.password = password
+
+	return server
+}
+
+// WithSystem sets the manufacturer and model reported by the system.
+func (server *Server) WithSystem(manufacturer, model string) *Server {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.manufacturer = manufacturer
+	server.model = model
+
+	return server
+}
+
+// WithPowerTransitionDelay sets the time the system stays in the PoweringOn or PoweringOff states after a reset
+// action. By default, power transitions are immediate.
+func (server *Server) WithPowerTransitionDelay(delay time.Duration) *Server {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.powerTransitionDelay = delay
+
+	return server
+}
+
+// WithTaskDuration sets the time tasks take to complete. By default, tasks complete immediately.
+func (server *Server) WithTaskDuration(duration time.Duration) *Server {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.taskDuration = duration
+
+	return server
+}
+
+// WithFailingTasks makes the tasks created from now on end in the Exception state instead of Completed.
+func (server *Server) WithFailingTasks(fail bool) *Server {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.failTasks = fail
+
+	return server
+}
+
+// Host returns the host of the server, which can be passed to bmc.New.
+func (server *Server) Host() string {
+	return strings.TrimPrefix(server.httpServer.URL, "https://")
+}
+
+// URL returns the base URL of the server.
+func (server *Server) URL() string {
+	return server.httpServer.URL
+}
+
+// Close shuts down the server.
+func (server *Server) Close() {
+	glog.V(100).Info("Stopping Redfish simulator")
+
+	server.httpServer.Close()
+}
+
+// InjectFault adds a fault to the server. Faults are checked in the order they were added and only the first matching
+// fault applies to a request.
+func (server *Server) InjectFault(fault Fault) {
+	glog.V(100).Infof("Injecting fault %+v", fault)
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.faults = append(server.faults, &fault)
+}
+
+// ClearFaults removes all the faults of the server.
+func (server *Server) ClearFaults() {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.faults = nil
+}
+
+// ExpireSessions invalidates all the sessions, so requests using them fail with 401 Unauthorized until a new session
+// is created.
+func (server *Server) ExpireSessions() {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.sessions = map[string]bool{}
+}
+
+// PowerState returns the current power state of the system.
+func (server *Server) PowerState() redfish.PowerState {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.settlePowerState()
+
+	return server.powerState
+}
+
+// SetPowerState sets the power state of the system, canceling any power transition in progress.
+func (server *Server) SetPowerState(powerState redfish.PowerState) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.powerState = powerState
+	server.pendingPowerState = ""
+}
+
+// ResetHistory returns the reset types of the reset actions the system received, in order.
+func (server *Server) ResetHistory() []redfish.ResetType {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return slices.Clone(server.resetHistory)
+}
+
+// SetPowerConsumedWatts sets the power the system consumes while it is on. The system consumes no power while off.
+func (server *Server) SetPowerConsumedWatts(watts float32) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.powerConsumedWatts = watts
+}
+
+// BootOverride returns the boot source override of the system. Overrides that apply once are disabled when the system
+// boots.
+func (server *Server) BootOverride() BootOverride {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.settlePowerState()
+
+	return server.bootOverride
+}
+
+// SecureBootEnabled returns whether secure boot is enabled.
+func (server *Server) SecureBootEnabled() bool {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.secureBootEnabled
+}
+
+// SetSecureBootEnabled sets whether secure boot is enabled.
+func (server *Server) SetSecureBootEnabled(enabled bool) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.secureBootEnabled = enabled
+}
+
+// VirtualMedia returns a copy of the state of the virtual media slot with the provided ID. The returned bool is false
+// if there is no such slot.
+func (server *Server) VirtualMedia(slot string) (VirtualMediaState, bool) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	media := server.getVirtualMedia(slot)
+	if media == nil {
+		return VirtualMediaState{}, false
+	}
+
+	state := *media
+	state.MediaTypes = slices.Clone(media.MediaTypes)
+
+	return state, true
+}
+
+// middleware applies the faults, checks the authentication, and settles the power state before serving a request.
+func (server *Server) middleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		fault := server.matchFault(request)
+
+		if fault != nil && fault.Delay > 0 {
+			time.Sleep(fault.Delay)
+		}
+
+		if fault != nil && fault.StatusCode != 0 {
+			glog.V(100).Infof("Failing %s %s with injected status %d", request.Method, request.URL.Path, fault.StatusCode)
+
+			writeError(writer, fault.StatusCode, "Base.1.12.GeneralError", "injected fault")
+
+			return
+		}
+
+		if !server.isAuthenticated(request) {
+			writeError(writer, http.StatusUnauthorized, "Base.1.12.NoValidSession", "no valid session found")
+
+			return
+		}
+
+		server.mutex.Lock()
+		server.settlePowerState()
+		server.mutex.Unlock()
+
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+// matchFault returns the first fault that matches the request, consuming one of its uses, or nil if none matches.
+func (server *Server) matchFault(request *http.Request) *Fault {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	for index, fault := range server.faults {
+		if fault.Method != "" && fault.Method != request.Method {
+			continue
+		}
+
+		if !strings.HasPrefix(request.URL.Path, fault.Path) {
+			continue
+		}
+
+		matched := *fault
+
+		if fault.Count > 0 {
+			fault.Count--
+
+			if fault.Count == 0 {
+				server.faults = slices.Delete(server.faults, index, index+1)
+			}
+		}
+
+		return &matched
+	}
+
+	return nil
+}
+
+// isAuthenticated returns true if the request does not need authentication or has a valid session token or basic
+// authentication credentials.
+func (server *Server) isAuthenticated(request *http.Request) bool {
+	path := strings.TrimSuffix(request.URL.Path, "/")
+
+	if path == "/redfish/v1" || path == "/redfish" ||
+		(request.Method == http.MethodPost && path == "/redfish/v1/SessionService/Sessions") {
+		return true
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if server.sessions[request.Header.Get("X-Auth-Token")] {
+		return true
+	}
+
+	username, password, ok := request.BasicAuth()
+
+	return ok && server.checkCredentials(username, password)
+}
+
+// checkCredentials returns true if the username and password are the ones the server accepts. The mutex must be held.
+func (server *Server) checkCredentials(username, password string) bool {
+	return subtle.ConstantTimeCompare([]byte(username), []byte(server.username)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(server.password)) == 1
+}
+
+// newID returns a new ID for a session or task. The mutex must be held.
+func (server *Server) newID() string {
+	server.nextID++
+
+	return fmt.Sprintf("%d", server.nextID)
+}
+
+// writeJSON writes the object as the JSON body of the response with the provided status code.
+func writeJSON(writer http.ResponseWriter, statusCode int, object any) {
+	body, err := json.Marshal(object)
+	if err != nil {
+		writeError(writer, http.StatusInternalServerError, "Base.1.12.InternalError", err.Error())
+
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_, _ = writer.Write(body)
+}
+
+// writeError writes a Redfish error response with the provided status code, message ID, and message.
+func writeError(writer http.ResponseWriter, statusCode int, messageID, message string) {
+	body, _ := json.Marshal(map[string]any{
+		"error": map[string]any{
+			"code":    messageID,
+			"message": message,
+			"@Message.ExtendedInfo": []map[string]any{
+				{"MessageId": messageID, "Message": message, "Severity": "Critical"},
+			},
+		},
+	})
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_, _ = writer.Write(body)
+}
@@ -0,0 +1,234 @@
+package bmctest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/bmc"
+	"github.com/stmcginnis/gofish/redfish"
+	"github.com/stretchr/testify/assert"
+)
+
+const defaultImageURL = "http://images.example.com/rhcos-live.iso"
+
+func TestServerPowerActions(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	bmcClient := newTestBMC(server)
+
+	powerState, err := bmcClient.SystemPowerState()
+	assert.Nil(t, err)
+	assert.Equal(t, string(redfish.OnPowerState), powerState)
+
+	err = bmcClient.SystemPowerOff()
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.OffPowerState, server.PowerState())
+
+	usage, err := bmcClient.PowerUsage()
+	assert.Nil(t, err)
+	assert.Equal(t, float32(0), usage)
+
+	err = bmcClient.SystemPowerOn()
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.OnPowerState, server.PowerState())
+
+	server.SetPowerConsumedWatts(512)
+
+	usage, err = bmcClient.PowerUsage()
+	assert.Nil(t, err)
+	assert.Equal(t, float32(512), usage)
+
+	err = bmcClient.SystemPowerCycle()
+	assert.Nil(t, err)
+	assert.Equal(t, []redfish.ResetType{
+		redfish.ForceOffResetType, redfish.OnResetType, redfish.PowerCycleResetType,
+	}, server.ResetHistory())
+}
+
+func TestServerPowerTransitionDelay(t *testing.T) {
+	server := NewServer().WithPowerTransitionDelay(100 * time.Millisecond)
+	defer server.Close()
+
+	bmcClient := newTestBMC(server)
+
+	err := bmcClient.SystemGracefulShutdown()
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.PoweringOffPowerState, server.PowerState())
+
+	err = bmcClient.WaitForSystemPowerState(redfish.OffPowerState, time.Second)
+	assert.NotNil(t, err)
+
+	assert.Eventually(t, func() bool {
+		return server.PowerState() == redfish.OffPowerState
+	}, time.Second, 10*time.Millisecond)
+
+	err = bmcClient.WaitForSystemPowerState(redfish.OffPowerState, time.Second)
+	assert.Nil(t, err)
+}
+
+func TestServerBootFromVirtualMedia(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	bmcClient := newTestBMC(server)
+
+	err := bmcClient.SystemPowerOff()
+	assert.Nil(t, err)
+
+	slot, err := bmcClient.BootFromVirtualMedia("", defaultImageURL, bmc.VirtualMediaOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, CDVirtualMediaSlot, slot)
+	assert.Equal(t, redfish.CdBootSourceOverrideTarget, server.BootOverride().Target)
+
+	media, found := server.VirtualMedia(CDVirtualMediaSlot)
+	assert.True(t, found)
+	assert.True(t, media.Inserted)
+	assert.Equal(t, defaultImageURL, media.Image)
+
+	// The slot is in use, so inserting again fails until the media is ejected.
+	_, err = bmcClient.InsertVirtualMedia(CDVirtualMediaSlot, defaultImageURL, bmc.VirtualMediaOptions{})
+	assert.ErrorContains(t, err, "409")
+
+	// A boot source override that applies once is disabled when the system boots.
+	err = bmcClient.SystemPowerOn()
+	assert.Nil(t, err)
+	assert.Equal(t, BootOverride{
+		Enabled: redfish.DisabledBootSourceOverrideEnabled,
+		Target:  redfish.NoneBootSourceOverrideTarget,
+		Mode:    redfish.UEFIBootSourceOverrideMode,
+	}, server.BootOverride())
+
+	err = bmcClient.EjectVirtualMedia(CDVirtualMediaSlot)
+	assert.Nil(t, err)
+
+	media, _ = server.VirtualMedia(CDVirtualMediaSlot)
+	assert.False(t, media.Inserted)
+
+	err = bmcClient.BootFromCD(defaultImageURL, CDVirtualMediaSlot)
+	assert.Nil(t, err)
+
+	_, found = server.VirtualMedia("3")
+	assert.False(t, found)
+}
+
+func TestServerSecureBoot(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	bmcClient := newTestBMC(server)
+
+	err := bmcClient.SecureBootEnable()
+	assert.Nil(t, err)
+	assert.True(t, server.SecureBootEnabled())
+
+	enabled, err := bmcClient.IsSecureBootEnabled()
+	assert.Nil(t, err)
+	assert.True(t, enabled)
+
+	err = bmcClient.SecureBootEnable()
+	assert.EqualError(t, err, "secure boot is already enabled")
+
+	server.SetSecureBootEnabled(false)
+
+	enabled, err = bmcClient.IsSecureBootEnabled()
+	assert.Nil(t, err)
+	assert.False(t, enabled)
+}
+
+func TestServerTasks(t *testing.T) {
+	server := NewServer().WithTaskDuration(200 * time.Millisecond)
+	defer server.Close()
+
+	bmcClient := newTestBMC(server)
+
+	task, err := bmcClient.SimpleUpdate(defaultImageURL, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.NewTaskState, task.State)
+
+	status, err := bmcClient.FirmwareUpdateStatus(task.URI)
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.RunningTaskState, status.State)
+
+	_, err = bmcClient.WaitForFirmwareUpdate(task.URI, false, 50*time.Millisecond)
+	assert.NotNil(t, err)
+
+	assert.Eventually(t, func() bool {
+		status, err = bmcClient.FirmwareUpdateStatus(task.URI)
+
+		return err == nil && status.State == redfish.CompletedTaskState
+	}, 2*time.Second, 20*time.Millisecond)
+
+	server.WithTaskDuration(0).WithFailingTasks(true)
+
+	task, err = bmcClient.SimpleUpdate(defaultImageURL, nil)
+	assert.Nil(t, err)
+
+	status, err = bmcClient.FirmwareUpdateStatus(task.URI)
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.ExceptionTaskState, status.State)
+	assert.Equal(t, []string{"The task failed"}, status.Messages)
+}
+
+func TestServerFaults(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	bmcClient := newTestBMC(server)
+
+	server.InjectFault(Fault{Method: http.MethodGet, Path: "/redfish/v1/Systems", StatusCode: 503, Count: 1})
+
+	_, err := bmcClient.SystemPowerState()
+	assert.ErrorContains(t, err, "503")
+
+	// The fault only applied to one request.
+	_, err = bmcClient.SystemPowerState()
+	assert.Nil(t, err)
+
+	server.InjectFault(Fault{Path: "/redfish/v1/Chassis", Delay: 200 * time.Millisecond})
+
+	_, err = bmc.New(server.Host()).
+		WithRedfishUser(DefaultUsername, DefaultPassword).
+		WithRedfishTimeout(100 * time.Millisecond).
+		PowerUsage()
+	assert.ErrorContains(t, err, "context deadline exceeded")
+
+	server.ClearFaults()
+
+	_, err = bmcClient.PowerUsage()
+	assert.Nil(t, err)
+}
+
+func TestServerAuthentication(t *testing.T) {
+	server := NewServer().WithCredentials("root", "calvin")
+	defer server.Close()
+
+	_, err := bmc.New(server.Host()).WithRedfishUser(DefaultUsername, DefaultPassword).SystemPowerState()
+	assert.ErrorContains(t, err, "401")
+
+	_, err = bmc.New(server.Host()).WithRedfishUser("root", "calvin").SystemPowerState()
+	assert.Nil(t, err)
+
+	request, err := http.NewRequest(http.MethodGet, server.URL()+"/redfish/v1/Systems", nil)
+	assert.Nil(t, err)
+
+	response, err := server.httpServer.Client().Do(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	_ = response.Body.Close()
+
+	request.SetBasicAuth("root", "calvin")
+
+	response, err = server.httpServer.Client().Do(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	_ = response.Body.Close()
+}
+
+// newTestBMC returns a bmc.BMC for the server that uses the default credentials.
+func newTestBMC(server *Server) *bmc.BMC {
+	return bmc.New(server.Host()).WithRedfishUser(DefaultUsername, DefaultPassword).WithRedfishTimeout(5 * time.Second)
+}
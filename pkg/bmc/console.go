@@ -0,0 +1,324 @@
+package bmc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// maxConsoleBufferSize is the maximum size of the console output kept for matching. When it is exceeded, the oldest
+// output is discarded.
+const maxConsoleBufferSize = 1 << 20
+
+// ConsoleKey is a key sequence that can be sent to the serial console. The function keys use the VT100+ sequences
+// understood by the BIOS console redirection of most servers.
+type ConsoleKey string
+
+const (
+	// ConsoleKeyEnter is the Enter key.
+	ConsoleKeyEnter ConsoleKey = "\r"
+	// ConsoleKeyEsc is the Esc key.
+	ConsoleKeyEsc ConsoleKey = "\x1b"
+	// ConsoleKeyTab is the Tab key.
+	ConsoleKeyTab ConsoleKey = "\t"
+	// ConsoleKeyBackspace is the Backspace key.
+	ConsoleKeyBackspace ConsoleKey = "\x7f"
+	// ConsoleKeyCtrlC is the Ctrl+C key combination.
+	ConsoleKeyCtrlC ConsoleKey = "\x03"
+	// ConsoleKeyCtrlX is the Ctrl+X key combination, used by GRUB to boot the edited entry.
+	ConsoleKeyCtrlX ConsoleKey = "\x18"
+	// ConsoleKeyUp is the Up arrow key.
+	ConsoleKeyUp ConsoleKey = "\x1b[A"
+	// ConsoleKeyDown is the Down arrow key.
+	ConsoleKeyDown ConsoleKey = "\x1b[B"
+	// ConsoleKeyRight is the Right arrow key.
+	ConsoleKeyRight ConsoleKey = "\x1b[C"
+	// ConsoleKeyLeft is the Left arrow key.
+	ConsoleKeyLeft ConsoleKey = "\x1b[D"
+	// ConsoleKeyF1 is the F1 key.
+	ConsoleKeyF1 ConsoleKey = "\x1b1"
+	// ConsoleKeyF2 is the F2 key, which usually enters the system setup.
+	ConsoleKeyF2 ConsoleKey = "\x1b2"
+	// ConsoleKeyF3 is the F3 key.
+	ConsoleKeyF3 ConsoleKey = "\x1b3"
+	// ConsoleKeyF4 is the F4 key.
+	ConsoleKeyF4 ConsoleKey = "\x1b4"
+	// ConsoleKeyF5 is the F5 key.
+	ConsoleKeyF5 ConsoleKey = "\x1b5"
+	// ConsoleKeyF6 is the F6 key.
+	ConsoleKeyF6 ConsoleKey = "\x1b6"
+	// ConsoleKeyF7 is the F7 key.
+	ConsoleKeyF7 ConsoleKey = "\x1b7"
+	// ConsoleKeyF8 is the F8 key.
+	ConsoleKeyF8 ConsoleKey = "\x1b8"
+	// ConsoleKeyF9 is the F9 key.
+	ConsoleKeyF9 ConsoleKey = "\x1b9"
+	// ConsoleKeyF10 is the F10 key.
+	ConsoleKeyF10 ConsoleKey = "\x1b0"
+	// ConsoleKeyF11 is the F11 key, which usually enters the boot manager.
+	ConsoleKeyF11 ConsoleKey = "\x1b!"
+	// ConsoleKeyF12 is the F12 key, which usually starts a PXE boot.
+	ConsoleKeyF12 ConsoleKey = "\x1b@"
+)
+
+// ConsoleSession provides expect-style scripting over a serial console. Output of the console is read in the
+// background, so it is not lost between calls to Expect. Use BMC.OpenConsoleSession to open one over the serial
+// console of the BMC, or NewConsoleSession to use any reader and writer.
+type ConsoleSession struct {
+	reader io.Reader
+	writer io.WriteCloser
+	bmc    *BMC
+
+	mutex      sync.Mutex
+	buffer     []byte
+	changed    chan struct{}
+	readErr    error
+	transcript io.WriteCloser
+	closed     bool
+}
+
+// OpenConsoleSession opens the serial console using OpenSerialConsole and returns a ConsoleSession over it. Closing the
+// session also closes the serial console.
+func (bmc *BMC) OpenConsoleSession(openConsoleCliCmd string) (*ConsoleSession, error) {
+	reader, writer, err := bmc.OpenSerialConsole(openConsoleCliCmd)
+	if err != nil {
+		return nil, err
+	}
+
+	session := NewConsoleSession(reader, writer)
+	session.bmc = bmc
+
+	return session, nil
+}
+
+// NewConsoleSession returns a ConsoleSession that reads the console output from reader and sends keys to writer. It
+// starts reading from reader immediately.
+func NewConsoleSession(reader io.Reader, writer io.WriteCloser) *ConsoleSession {
+	glog.V(100).Info("Creating new console session")
+
+	session := &ConsoleSession{
+		reader:  reader,
+		writer:  writer,
+		changed: make(chan struct{}),
+	}
+
+	go session.read()
+
+	return session
+}
+
+// RecordTranscript records all the output of the console received from now on to the file at path, which is created
+// or truncated. Recording stops when the session is closed or RecordTranscript is called again.
+func (session *ConsoleSession) RecordTranscript(path string) error {
+	glog.V(100).Infof("Recording console transcript to %s", path)
+
+	if path == "" {
+		glog.V(100).Info("The console transcript path is empty")
+
+		return fmt.Errorf("console transcript 'path' cannot be empty")
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		glog.V(100).Infof("Failed to create console transcript %s: %v", path, err)
+
+		return fmt.Errorf("failed to create console transcript %s: %w", path, err)
+	}
+
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	if session.transcript != nil {
+		_ = session.transcript.Close()
+	}
+
+	session.transcript = file
+
+	return nil
+}
+
+// Send sends the keys to the console as they are. Special keys are available as ConsoleKey constants.
+func (session *ConsoleSession) Send(keys ...ConsoleKey) error {
+	for _, key := range keys {
+		glog.V(100).Infof("Sending %q to console", key)
+
+		_, err := io.WriteString(session.writer, string(key))
+		if err != nil {
+			glog.V(100).Infof("Failed to send %q to console: %v", key, err)
+
+			return fmt.Errorf("failed to send %q to console: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// SendLine sends the line to the console followed by Enter.
+func (session *ConsoleSession) SendLine(line string) error {
+	return session.Send(ConsoleKey(line), ConsoleKeyEnter)
+}
+
+// Expect waits up to timeout for output of the console matching the regular expression pattern and returns the
+// matching text. The output up to the end of the match is consumed, so the next call only matches newer output.
+func (session *ConsoleSession) Expect(pattern string, timeout time.Duration) (string, error) {
+	_, match, err := session.ExpectAny([]string{pattern}, timeout)
+
+	return match, err
+}
+
+// ExpectAny waits up to timeout for output of the console matching any of the regular expression patterns and returns
+// the index of the pattern and the matching text. If several patterns match, the one whose match starts first is used.
+// The output up to the end of the match is consumed. If the timeout expires, the returned error wraps
+// os.ErrDeadlineExceeded.
+func (session *ConsoleSession) ExpectAny(patterns []string, timeout time.Duration) (int, string, error) {
+	glog.V(100).Infof("Expecting console output matching %q for %s", patterns, timeout)
+
+	if len(patterns) == 0 {
+		glog.V(100).Info("The console patterns are empty")
+
+		return -1, "", fmt.Errorf("console 'patterns' cannot be empty")
+	}
+
+	regexps := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			glog.V(100).Infof("Failed to compile console pattern %q: %v", pattern, err)
+
+			return -1, "", fmt.Errorf("failed to compile console pattern %q: %w", pattern, err)
+		}
+
+		regexps = append(regexps, compiled)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		session.mutex.Lock()
+		index, match := session.consumeMatch(regexps)
+		changed, readErr := session.changed, session.readErr
+		session.mutex.Unlock()
+
+		if index >= 0 {
+			return index, match, nil
+		}
+
+		if readErr != nil {
+			glog.V(100).Infof("Console closed before output matched %q: %v", patterns, readErr)
+
+			return -1, "", fmt.Errorf("console closed before output matched %q: %w", patterns, readErr)
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			glog.V(100).Infof("Timed out waiting for console output matching %q", patterns)
+
+			return -1, "", fmt.Errorf("timed out after %s waiting for console output matching %q: %w",
+				timeout, patterns, os.ErrDeadlineExceeded)
+		}
+	}
+}
+
+// Close closes the console session and stops recording its transcript. If the session was opened with
+// BMC.OpenConsoleSession, the serial console is closed too.
+func (session *ConsoleSession) Close() error {
+	glog.V(100).Info("Closing console session")
+
+	session.mutex.Lock()
+
+	if session.closed {
+		session.mutex.Unlock()
+
+		return nil
+	}
+
+	session.closed = true
+	transcript := session.transcript
+	session.transcript = nil
+	session.mutex.Unlock()
+
+	errs := []error{session.writer.Close()}
+
+	if transcript != nil {
+		errs = append(errs, transcript.Close())
+	}
+
+	if session.bmc != nil {
+		errs = append(errs, session.bmc.CloseSerialConsole())
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		glog.V(100).Infof("Failed to close console session: %v", err)
+
+		return fmt.Errorf("failed to close console session: %w", err)
+	}
+
+	return nil
+}
+
+// read reads the output of the console until the reader fails, appending it to the buffer and the transcript.
+func (session *ConsoleSession) read() {
+	chunk := make([]byte, 4096)
+
+	for {
+		count, err := session.reader.Read(chunk)
+
+		session.mutex.Lock()
+
+		if count > 0 {
+			session.buffer = append(session.buffer, chunk[:count]...)
+
+			if len(session.buffer) > maxConsoleBufferSize {
+				session.buffer = session.buffer[len(session.buffer)-maxConsoleBufferSize:]
+			}
+
+			if session.transcript != nil {
+				_, _ = session.transcript.Write(chunk[:count])
+			}
+		}
+
+		if err != nil {
+			session.readErr = err
+		}
+
+		close(session.changed)
+		session.changed = make(chan struct{})
+		session.mutex.Unlock()
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// consumeMatch returns the index of the regular expression whose match in the buffer starts first and the matching
+// text, removing the buffer up to the end of the match. It returns -1 if none matches. The mutex must be held.
+func (session *ConsoleSession) consumeMatch(regexps []*regexp.Regexp) (int, string) {
+	index, location := -1, []int(nil)
+
+	for regexpIndex, compiled := range regexps {
+		regexpLocation := compiled.FindIndex(session.buffer)
+		if regexpLocation != nil && (location == nil || regexpLocation[0] < location[0]) {
+			index, location = regexpIndex, regexpLocation
+		}
+	}
+
+	if index < 0 {
+		return -1, ""
+	}
+
+	match := string(session.buffer[location[0]:location[1]])
+	session.buffer = session.buffer[location[1]:]
+
+	return index, match
+}
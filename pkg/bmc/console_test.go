@@ -0,0 +1,119 @@
+package bmc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsoleSessionExpect(t *testing.T) {
+	session, consoleWriter, _ := newTestConsoleSession(t)
+
+	go func() {
+		_, _ = io.WriteString(consoleWriter, "Booting...\r\nPress F2 to enter setup\r\nlocalhost login: ")
+	}()
+
+	match, err := session.Expect(`F\d+`, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "F2", match)
+
+	// The output up to the end of the previous match was consumed.
+	_, err = session.Expect(`Booting`, 10*time.Millisecond)
+	assert.True(t, errors.Is(err, os.ErrDeadlineExceeded))
+
+	index, match, err := session.ExpectAny([]string{`[Pp]assword:`, `login:`, `setup`}, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, index)
+	assert.Equal(t, "setup", match)
+
+	index, _, err = session.ExpectAny([]string{`[Pp]assword:`, `login:`}, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, index)
+
+	_, _, err = session.ExpectAny(nil, time.Second)
+	assert.Equal(t, fmt.Errorf("console 'patterns' cannot be empty"), err)
+
+	_, err = session.Expect(`(`, time.Second)
+	assert.ErrorContains(t, err, "failed to compile console pattern \"(\"")
+
+	_ = consoleWriter.Close()
+
+	_, err = session.Expect(`login:`, time.Second)
+	assert.True(t, errors.Is(err, io.EOF))
+}
+
+func TestConsoleSessionSend(t *testing.T) {
+	session, _, keysReader := newTestConsoleSession(t)
+
+	received := make(chan string)
+
+	go func() {
+		keys, _ := io.ReadAll(keysReader)
+		received <- string(keys)
+	}()
+
+	err := session.Send(ConsoleKeyF2, ConsoleKeyDown, "root")
+	assert.Nil(t, err)
+
+	err = session.SendLine("password")
+	assert.Nil(t, err)
+
+	err = session.Close()
+	assert.Nil(t, err)
+	assert.Equal(t, "\x1b2\x1b[Broot"+"password\r", <-received)
+
+	// Closing the session again does nothing.
+	err = session.Close()
+	assert.Nil(t, err)
+
+	err = session.Send(ConsoleKeyEnter)
+	assert.ErrorContains(t, err, "failed to send \"\\r\" to console")
+}
+
+func TestConsoleSessionRecordTranscript(t *testing.T) {
+	session, consoleWriter, _ := newTestConsoleSession(t)
+	transcriptPath := filepath.Join(t.TempDir(), "console.log")
+
+	err := session.RecordTranscript("")
+	assert.Equal(t, fmt.Errorf("console transcript 'path' cannot be empty"), err)
+
+	err = session.RecordTranscript(transcriptPath)
+	assert.Nil(t, err)
+
+	go func() {
+		_, _ = io.WriteString(consoleWriter, "[    0.000000] Linux version 5.14.0\n")
+		_, _ = io.WriteString(consoleWriter, "localhost login: ")
+	}()
+
+	_, err = session.Expect(`login: `, time.Second)
+	assert.Nil(t, err)
+
+	err = session.Close()
+	assert.Nil(t, err)
+
+	transcript, err := os.ReadFile(transcriptPath)
+	assert.Nil(t, err)
+	assert.Equal(t, "[    0.000000] Linux version 5.14.0\nlocalhost login: ", string(transcript))
+}
+
+// newTestConsoleSession returns a ConsoleSession over pipes, along with the writer of the console output and the
+// reader of the keys sent to the console.
+func newTestConsoleSession(t *testing.T) (*ConsoleSession, *io.PipeWriter, *io.PipeReader) {
+	t.Helper()
+
+	outputReader, outputWriter := io.Pipe()
+	keysReader, keysWriter := io.Pipe()
+
+	t.Cleanup(func() {
+		_ = outputWriter.Close()
+		_ = keysReader.Close()
+	})
+
+	return NewConsoleSession(outputReader, keysWriter), outputWriter, keysReader
+}
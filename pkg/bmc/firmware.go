@@ -0,0 +1,412 @@
+package bmc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// firmwareUpdatePollInterval is the interval between checks of a firmware update task.
+var firmwareUpdatePollInterval = 10 * time.Second
+
+// resetRequiredMessageIDs are the message IDs, without registry prefix and version, that a firmware update task uses
+// to report that the update is only applied after the system is reset.
+var resetRequiredMessageIDs = []string{"ResetRequired", "AwaitingActivation"}
+
+// FirmwareComponent holds the firmware of a component of the system, as reported by the firmware inventory.
+type FirmwareComponent struct {
+	// ID is the identifier of the component in the firmware inventory.
+	ID string
+	// Name is the name of the component.
+	Name string
+	// Version is the version of the firmware installed on the component.
+	Version string
+	// SoftwareID is the implementation-specific identifier of the firmware.
+	SoftwareID string
+	// Updateable is true if the firmware can be updated through the UpdateService.
+	Updateable bool
+}
+
+// FirmwareUpdateTask holds the state of a firmware update submitted to the UpdateService.
+type FirmwareUpdateTask struct {
+	// URI is the Task or TaskMonitor URI used to track the update. It is empty if the BMC completed the update
+	// synchronously.
+	URI string
+	// State is the state of the task.
+	State redfish.TaskState
+	// PercentComplete is the progress of the task in percent.
+	PercentComplete int
+	// Messages are the messages reported by the task.
+	Messages []string
+	// ResetRequired is true if the latest message of the task reports that the update is only applied after the system
+	// is reset.
+	ResetRequired bool
+}
+
+// FirmwareInventory returns the firmware components of the UpdateService firmware inventory, sorted by ID, using the
+// Redfish API.
+func (bmc *BMC) FirmwareInventory() ([]FirmwareComponent, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Info("Getting firmware inventory from bmc's redfish endpoint")
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	updateService, err := redfishClient.GetService().UpdateService()
+	if err != nil {
+		glog.V(100).Infof("Failed to get redfish update service: %v", err)
+
+		return nil, fmt.Errorf("failed to get redfish update service: %w", err)
+	}
+
+	inventories, err := updateService.FirmwareInventories()
+	if err != nil {
+		glog.V(100).Infof("Failed to get firmware inventory: %v", err)
+
+		return nil, fmt.Errorf("failed to get firmware inventory: %w", err)
+	}
+
+	var components []FirmwareComponent
+
+	for _, inventory := range inventories {
+		components = append(components, FirmwareComponent{
+			ID:         inventory.ID,
+			Name:       inventory.Name,
+			Version:    inventory.Version,
+			SoftwareID: inventory.SoftwareID,
+			Updateable: inventory.Updateable,
+		})
+	}
+
+	slices.SortFunc(components, func(first, second FirmwareComponent) int {
+		return strings.Compare(first.ID, second.ID)
+	})
+
+	return components, nil
+}
+
+// SimpleUpdate submits the firmware image available at imageURI to the UpdateService SimpleUpdate action using the
+// Redfish API. The targets are URIs of the firmware inventory components, or other resources, to update. If targets
+// is empty, the BMC applies the image to every applicable component. The returned task can be tracked using
+// FirmwareUpdateStatus or WaitForFirmwareUpdate.
+func (bmc *BMC) SimpleUpdate(imageURI string, targets []string) (*FirmwareUpdateTask, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Submitting firmware update %s for targets %v", imageURI, targets)
+
+	if imageURI == "" {
+		glog.V(100).Info("The firmware update image URI is empty")
+
+		return nil, fmt.Errorf("firmware update 'imageURI' cannot be empty")
+	}
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	target, err := redfishGetSimpleUpdateTarget(redfishClient)
+	if err != nil {
+		glog.V(100).Infof("Failed to get SimpleUpdate action target: %v", err)
+
+		return nil, err
+	}
+
+	response, err := redfishClient.Post(target, &redfish.SimpleUpdateParameters{ImageURI: imageURI, Targets: targets})
+	if err != nil {
+		glog.V(100).Infof("Failed to submit firmware update: %v", err)
+
+		return nil, fmt.Errorf("failed to submit firmware update: %w", err)
+	}
+
+	defer response.Body.Close()
+
+	taskURI, err := getFirmwareUpdateTaskURI(response)
+	if err != nil {
+		glog.V(100).Infof("Failed to get firmware update task: %v", err)
+
+		return nil, err
+	}
+
+	if taskURI == "" {
+		glog.V(100).Info("Firmware update did not return a task, assuming it completed")
+
+		return &FirmwareUpdateTask{State: redfish.CompletedTaskState, PercentComplete: 100}, nil
+	}
+
+	glog.V(100).Infof("Firmware update is tracked by task %s", taskURI)
+
+	return &FirmwareUpdateTask{URI: taskURI, State: redfish.NewTaskState}, nil
+}
+
+// FirmwareUpdateStatus returns the state of the firmware update task with the provided Task or TaskMonitor URI using
+// the Redfish API. A TaskMonitor that no longer returns a task is reported as completed.
+func (bmc *BMC) FirmwareUpdateStatus(taskURI string) (*FirmwareUpdateTask, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting status of firmware update task %s", taskURI)
+
+	if taskURI == "" {
+		glog.V(100).Info("The firmware update task URI is empty")
+
+		return nil, fmt.Errorf("firmware update 'taskURI' cannot be empty")
+	}
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	response, err := redfishClient.Get(taskURI)
+	if err != nil {
+		glog.V(100).Infof("Failed to get firmware update task %s: %v", taskURI, err)
+
+		return nil, fmt.Errorf("failed to get firmware update task %s: %w", taskURI, err)
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read firmware update task %s: %w", taskURI, err)
+	}
+
+	updateTask := &FirmwareUpdateTask{URI: taskURI, State: redfish.CompletedTaskState, PercentComplete: 100}
+
+	if response.StatusCode == http.StatusNoContent || len(body) == 0 {
+		return updateTask, nil
+	}
+
+	task := &redfish.Task{}
+
+	err = json.Unmarshal(body, task)
+	if err != nil {
+		glog.V(100).Infof("Failed to unmarshal firmware update task %s: %v", taskURI, err)
+
+		return nil, fmt.Errorf("failed to unmarshal firmware update task %s: %w", taskURI, err)
+	}
+
+	// A TaskMonitor returns the response of the action instead of the task once the task is done.
+	if task.TaskState == "" {
+		return updateTask, nil
+	}
+
+	updateTask.State = task.TaskState
+	updateTask.PercentComplete = task.PercentComplete
+
+	for _, message := range task.Messages {
+		updateTask.Messages = append(updateTask.Messages, message.Message)
+	}
+
+	updateTask.ResetRequired = isFirmwareResetRequired(task.Messages)
+
+	return updateTask, nil
+}
+
+// WaitForFirmwareUpdate waits up to timeout until the firmware update task with the provided URI completes, returning
+// the last state of the task. An error is returned if the task finishes without completing. When the task reports that
+// the update is only applied after a reset and resetIfRequired is true, the system is reset once using
+// SystemForceReset and the task is polled until it completes without requiring a reset. If resetIfRequired is false, a
+// task that completes but still requires a reset is returned with ResetRequired set and an error.
+func (bmc *BMC) WaitForFirmwareUpdate(
+	taskURI string, resetIfRequired bool, timeout time.Duration) (*FirmwareUpdateTask, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Waiting up to %s until firmware update task %s completes", timeout, taskURI)
+
+	if taskURI == "" {
+		glog.V(100).Info("The firmware update task URI is empty")
+
+		return nil, fmt.Errorf("firmware update 'taskURI' cannot be empty")
+	}
+
+	var (
+		updateTask *FirmwareUpdateTask
+		reset      bool
+	)
+
+	err := wait.PollUntilContextTimeout(
+		context.TODO(), firmwareUpdatePollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+			task, err := bmc.FirmwareUpdateStatus(taskURI)
+			if err != nil {
+				glog.V(100).Infof("Failed to get firmware update task %s: %v", taskURI, err)
+
+				return false, nil
+			}
+
+			updateTask = task
+
+			glog.V(100).Infof("Firmware update task %s is %s (%d%%)", taskURI, task.State, task.PercentComplete)
+
+			// Tasks may complete while still requiring a reset, so the reset is checked before the task state.
+			if task.ResetRequired && resetIfRequired && !reset {
+				glog.V(100).Infof("Firmware update task %s requires a reset, resetting the system", taskURI)
+
+				err = bmc.SystemForceReset()
+				if err != nil {
+					glog.V(100).Infof("Failed to reset the system: %v", err)
+
+					return false, nil
+				}
+
+				reset = true
+
+				return false, nil
+			}
+
+			switch task.State {
+			case redfish.CompletedTaskState:
+				if !task.ResetRequired {
+					return true, nil
+				}
+
+				if !resetIfRequired {
+					return false, fmt.Errorf("firmware update task %s completed but requires a reset to apply", taskURI)
+				}
+			case redfish.ExceptionTaskState, redfish.KilledTaskState, redfish.CancelledTaskState:
+				return false, fmt.Errorf("firmware update task %s finished with state %s: %s",
+					taskURI, task.State, strings.Join(task.Messages, "; "))
+			}
+
+			return false, nil
+		})
+
+	if err != nil && reset && updateTask != nil && updateTask.ResetRequired {
+		glog.V(100).Infof("Firmware update task %s still requires a reset after the system was reset", taskURI)
+
+		return updateTask, fmt.Errorf("firmware update task %s still requires a reset after the system was reset: %w",
+			taskURI, err)
+	}
+
+	return updateTask, err
+}
+
+// isFirmwareResetRequired returns true if the latest of the task messages reports that a reset is required. Tasks keep
+// the messages of earlier steps, so a reset required message followed by other messages no longer applies.
+func isFirmwareResetRequired(messages []common.Message) bool {
+	if len(messages) == 0 {
+		return false
+	}
+
+	messageID := messages[len(messages)-1].MessageID
+	messageID = messageID[strings.LastIndex(messageID, ".")+1:]
+
+	return slices.Contains(resetRequiredMessageIDs, messageID)
+}
+
+// redfishGetSimpleUpdateTarget uses the provided gofish APIClient to get the target of the UpdateService SimpleUpdate
+// action.
+func redfishGetSimpleUpdateTarget(redfishClient *gofish.APIClient) (string, error) {
+	updateService, err := redfishClient.GetService().UpdateService()
+	if err != nil {
+		return "", fmt.Errorf("failed to get redfish update service: %w", err)
+	}
+
+	var actions struct {
+		Actions struct {
+			SimpleUpdate struct {
+				Target string `json:"target"`
+			} `json:"#UpdateService.SimpleUpdate"`
+		}
+	}
+
+	err = json.Unmarshal(updateService.RawData, &actions)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal redfish update service actions: %w", err)
+	}
+
+	if actions.Actions.SimpleUpdate.Target == "" {
+		return "", fmt.Errorf("redfish update service does not support SimpleUpdate")
+	}
+
+	return actions.Actions.SimpleUpdate.Target, nil
+}
+
+// getFirmwareUpdateTaskURI returns the path of the Task or TaskMonitor tracking the SimpleUpdate from the Location
+// header of the response or, if missing, from the task in the response body. An empty path is returned if the
+// response has neither.
+func getFirmwareUpdateTaskURI(response *http.Response) (string, error) {
+	location := response.Header.Get("Location")
+
+	if location == "" {
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			return "", fmt.Errorf("failed to read firmware update response: %w", err)
+		}
+
+		var task struct {
+			ODataID   string `json:"@odata.id"`
+			TaskState string
+		}
+
+		if len(body) == 0 || json.Unmarshal(body, &task) != nil || task.TaskState == "" {
+			return "", nil
+		}
+
+		return task.ODataID, nil
+	}
+
+	// The client prepends the endpoint to every request, so only the path of absolute URIs is kept.
+	locationURL, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse firmware update task location %q: %w", location, err)
+	}
+
+	return locationURL.RequestURI(), nil
+}
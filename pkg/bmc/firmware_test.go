@@ -0,0 +1,181 @@
+package bmc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish/redfish"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	defaultFirmwareImageURI = "http://10.0.0.1/BIOS_2.20.1.EXE"
+	defaultTaskURI          = "/redfish/v1/TaskService/Tasks/JID_100"
+)
+
+func TestBMCFirmwareInventory(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+
+	components, err := New(host).WithRedfishUser(defaultUsername, defaultPassword).FirmwareInventory()
+	assert.Nil(t, err)
+	assert.Equal(t, []FirmwareComponent{
+		{ID: "Installed-159-2.19.1", Name: "BIOS", Version: "2.19.1", SoftwareID: "159", Updateable: true},
+		{
+			ID:         "Installed-25227-7.00.60.00",
+			Name:       "Integrated Dell Remote Access Controller",
+			Version:    "7.00.60.00",
+			SoftwareID: "25227",
+			Updateable: false,
+		},
+	}, components)
+
+	_, err = New(host).FirmwareInventory()
+	assert.Equal(t, fmt.Errorf("cannot access redfish with nil user"), err)
+}
+
+func TestBMCSimpleUpdate(t *testing.T) {
+	var parameters redfish.SimpleUpdateParameters
+
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{
+		simpleUpdate: func(r *http.Request) {
+			buff, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(buff, &parameters)
+		},
+	})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+	targets := []string{"/redfish/v1/UpdateService/FirmwareInventory/Installed-159-2.19.1"}
+
+	task, err := bmc.SimpleUpdate(defaultFirmwareImageURI, targets)
+	assert.Nil(t, err)
+	assert.Equal(t, &FirmwareUpdateTask{URI: defaultTaskURI, State: redfish.NewTaskState}, task)
+	assert.Equal(t, defaultFirmwareImageURI, parameters.ImageURI)
+	assert.Equal(t, targets, parameters.Targets)
+
+	_, err = bmc.SimpleUpdate("", targets)
+	assert.Equal(t, fmt.Errorf("firmware update 'imageURI' cannot be empty"), err)
+}
+
+func TestBMCFirmwareUpdateStatus(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+	task, err := bmc.FirmwareUpdateStatus(defaultTaskURI)
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.RunningTaskState, task.State)
+	assert.Equal(t, 50, task.PercentComplete)
+	assert.True(t, task.ResetRequired)
+	assert.Len(t, task.Messages, 2)
+
+	err = bmc.SystemForceReset()
+	assert.Nil(t, err)
+
+	task, err = bmc.FirmwareUpdateStatus(defaultTaskURI)
+	assert.Nil(t, err)
+	assert.Equal(t, redfish.CompletedTaskState, task.State)
+	assert.Equal(t, 100, task.PercentComplete)
+	assert.False(t, task.ResetRequired)
+
+	_, err = bmc.FirmwareUpdateStatus("")
+	assert.Equal(t, fmt.Errorf("firmware update 'taskURI' cannot be empty"), err)
+}
+
+func TestBMCWaitForFirmwareUpdate(t *testing.T) {
+	firmwareUpdatePollInterval = 10 * time.Millisecond
+
+	defer func() {
+		firmwareUpdatePollInterval = 10 * time.Second
+	}()
+
+	testCases := []struct {
+		imageURI              string
+		resetIfRequired       bool
+		expectedState         redfish.TaskState
+		expectedResetRequired bool
+		expectedResets        int
+		expectedError         string
+	}{
+		{
+			imageURI:        defaultFirmwareImageURI,
+			resetIfRequired: true,
+			expectedState:   redfish.CompletedTaskState,
+			expectedResets:  1,
+			expectedError:   "",
+		},
+		{
+			imageURI:              defaultFirmwareImageURI,
+			resetIfRequired:       false,
+			expectedState:         redfish.RunningTaskState,
+			expectedResetRequired: true,
+			expectedError:         "context deadline exceeded",
+		},
+		{
+			imageURI:        "http://10.0.0.1/invalid.EXE",
+			resetIfRequired: true,
+			expectedState:   redfish.ExceptionTaskState,
+			expectedError: "firmware update task /redfish/v1/TaskService/Tasks/JID_101 finished with state Exception: " +
+				"Unable to verify the update package signature.",
+		},
+		{
+			imageURI:        "http://10.0.0.1/staged.EXE",
+			resetIfRequired: true,
+			expectedState:   redfish.CompletedTaskState,
+			expectedResets:  1,
+			expectedError:   "",
+		},
+		{
+			imageURI:              "http://10.0.0.1/staged.EXE",
+			resetIfRequired:       false,
+			expectedState:         redfish.CompletedTaskState,
+			expectedResetRequired: true,
+			expectedError: "firmware update task /redfish/v1/TaskService/Tasks/JID_102 completed but requires a " +
+				"reset to apply",
+		},
+		{
+			imageURI:        "http://10.0.0.1/history.EXE",
+			resetIfRequired: true,
+			expectedState:   redfish.CompletedTaskState,
+			expectedResets:  1,
+			expectedError:   "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		resets := 0
+		redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{
+			reset: func(r *http.Request) { resets++ },
+		})
+		host := strings.Split(redfishServer.URL, "//")[1]
+		bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+		task, err := bmc.SimpleUpdate(testCase.imageURI, nil)
+		assert.Nil(t, err)
+
+		task, err = bmc.WaitForFirmwareUpdate(task.URI, testCase.resetIfRequired, time.Second)
+
+		if testCase.expectedError != "" {
+			assert.EqualError(t, err, testCase.expectedError)
+		} else {
+			assert.Nil(t, err)
+		}
+
+		assert.Equal(t, testCase.expectedState, task.State)
+		assert.Equal(t, testCase.expectedResetRequired, task.ResetRequired)
+		assert.Equal(t, testCase.expectedResets, resets)
+
+		redfishServer.Close()
+	}
+}
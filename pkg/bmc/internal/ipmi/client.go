@@ -0,0 +1,518 @@
+package ipmi
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// maxPacketSize is the size of the buffer packets are read into.
+	maxPacketSize = 1024
+	// maxUsernameLength is the maximum length of an IPMI user name.
+	maxUsernameLength = 16
+	// commandRetries is the number of times a command is resent when the BMC does not respond in time.
+	commandRetries = 2
+	// channelCurrentExtended requests the authentication capabilities of the current channel, including IPMI v2.0.
+	channelCurrentExtended = 0x8e
+)
+
+// CompletionCodeError is returned when the BMC responds to a command with a completion code other than
+// CompletionCodeOK.
+type CompletionCodeError struct {
+	// NetFn is the network function of the command.
+	NetFn NetFn
+	// Command is the command.
+	Command uint8
+	// Code is the completion code of the response.
+	Code uint8
+}
+
+// Error returns the error message of the completion code error.
+func (err *CompletionCodeError) Error() string {
+	return fmt.Sprintf("ipmi command 0x%02x of netfn 0x%02x failed with completion code 0x%02x",
+		err.Command, uint8(err.NetFn), err.Code)
+}
+
+// ChassisStatus holds the power state reported by the Get Chassis Status command.
+type ChassisStatus struct {
+	// PowerOn is true if the system is powered on.
+	PowerOn bool
+	// PowerFault is true if a fault was detected in the power subsystem.
+	PowerFault bool
+}
+
+// Client is an RMCP+ session with a BMC using cipher suite 3. Use Dial to establish one and Close to close it.
+type Client struct {
+	conn    net.Conn
+	timeout time.Duration
+	keys    *SessionKeys
+
+	consoleSessionID uint32
+	bmcSessionID     uint32
+
+	// commandMutex serializes commands, so responses can be read from a single channel.
+	commandMutex sync.Mutex
+	responses    chan Message
+
+	// mutex protects the sequence numbers and the active SOL payload.
+	mutex           sync.Mutex
+	sequence        uint32
+	requestSequence uint8
+	sol             *SOL
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Dial establishes a session with administrator privilege with the BMC at address, in host:port form. The timeout
+// applies to every request sent to the BMC.
+func Dial(address, username, password string, timeout time.Duration) (*Client, error) {
+	glog.V(100).Infof("Establishing ipmi session with %s as user %s", address, username)
+
+	if len(username) > maxUsernameLength {
+		return nil, fmt.Errorf("ipmi username cannot be longer than %d characters", maxUsernameLength)
+	}
+
+	conn, err := net.DialTimeout("udp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{
+		conn:      conn,
+		timeout:   timeout,
+		responses: make(chan Message, 16),
+		done:      make(chan struct{}),
+	}
+
+	err = client.openSession(username, password)
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, err
+	}
+
+	go client.readLoop()
+
+	_, err = client.Command(NetFnApp, CommandSetSessionPrivilegeLevel, []byte{PrivilegeLevelAdministrator})
+	if err != nil {
+		_ = client.Close()
+
+		return nil, fmt.Errorf("failed to set session privilege level: %w", err)
+	}
+
+	return client, nil
+}
+
+// Command sends a command to the BMC and returns the data of the response, without the completion code. Responses
+// with a completion code other than CompletionCodeOK are returned as a CompletionCodeError.
+func (client *Client) Command(netFn NetFn, command uint8, data []byte) ([]byte, error) {
+	return client.command(netFn, command, data, commandRetries)
+}
+
+// ChassisStatus returns the power state of the chassis.
+func (client *Client) ChassisStatus() (ChassisStatus, error) {
+	data, err := client.Command(NetFnChassis, CommandGetChassisStatus, nil)
+	if err != nil {
+		return ChassisStatus{}, err
+	}
+
+	if len(data) < 1 {
+		return ChassisStatus{}, fmt.Errorf("chassis status response is empty")
+	}
+
+	return ChassisStatus{PowerOn: data[0]&0x01 != 0, PowerFault: data[0]&0x08 != 0}, nil
+}
+
+// ChassisControl powers the chassis up, down, cycles or resets it.
+func (client *Client) ChassisControl(control ChassisControl) error {
+	_, err := client.Command(NetFnChassis, CommandChassisControl, []byte{byte(control)})
+
+	return err
+}
+
+// SetBootFlags sets the boot flags system boot options parameter, which overrides the boot device.
+func (client *Client) SetBootFlags(flags BootFlags) error {
+	_, err := client.Command(
+		NetFnChassis, CommandSetSystemBootOptions, append([]byte{bootOptionBootFlags}, flags.Marshal()...))
+
+	return err
+}
+
+// BootFlags returns the boot flags system boot options parameter.
+func (client *Client) BootFlags() (BootFlags, error) {
+	data, err := client.Command(NetFnChassis, CommandGetSystemBootOptions, []byte{bootOptionBootFlags, 0, 0})
+	if err != nil {
+		return BootFlags{}, err
+	}
+
+	if len(data) < 2 {
+		return BootFlags{}, fmt.Errorf("system boot options response of %d bytes is too short", len(data))
+	}
+
+	return UnmarshalBootFlags(data[2:])
+}
+
+// Close deactivates SOL if it is active and closes the session. It is safe to call Close more than once.
+func (client *Client) Close() error {
+	var err error
+
+	client.closeOnce.Do(func() {
+		glog.V(100).Infof("Closing ipmi session 0x%08x", client.bmcSessionID)
+
+		client.mutex.Lock()
+		sol := client.sol
+		client.mutex.Unlock()
+
+		if sol != nil {
+			_ = sol.Close()
+		}
+
+		_, closeErr := client.command(
+			NetFnApp, CommandCloseSession, binary.LittleEndian.AppendUint32(nil, client.bmcSessionID), 0)
+		if closeErr != nil {
+			glog.V(100).Infof("Failed to close ipmi session: %v", closeErr)
+		}
+
+		err = client.conn.Close()
+
+		<-client.done
+	})
+
+	return err
+}
+
+// openSession establishes the RMCP+ session by exchanging the Open Session and RAKP messages and derives the session
+// keys.
+func (client *Client) openSession(username, password string) error {
+	err := client.getChannelAuthenticationCapabilities()
+	if err != nil {
+		return fmt.Errorf("failed to get channel authentication capabilities: %w", err)
+	}
+
+	params := RAKPParameters{Role: PrivilegeLookupNameOnly | PrivilegeLevelAdministrator, Username: username}
+
+	sessionID := make([]byte, 4)
+	if _, err := rand.Read(sessionID); err != nil {
+		return err
+	}
+
+	// A session ID of zero is reserved for packets sent outside of a session.
+	params.ConsoleSessionID = binary.LittleEndian.Uint32(sessionID) | 1
+
+	if _, err := rand.Read(params.ConsoleRandom[:]); err != nil {
+		return err
+	}
+
+	params.BMCSessionID, err = client.requestOpenSession(params.ConsoleSessionID)
+	if err != nil {
+		return err
+	}
+
+	sik, err := client.authenticate(&params, password)
+	if err != nil {
+		return err
+	}
+
+	client.keys = NewSessionKeys(sik)
+	client.consoleSessionID = params.ConsoleSessionID
+	client.bmcSessionID = params.BMCSessionID
+
+	return nil
+}
+
+// getChannelAuthenticationCapabilities checks that the BMC answers IPMI requests before the session is opened.
+func (client *Client) getChannelAuthenticationCapabilities() error {
+	request := Message{
+		NetFn:   NetFnApp,
+		Command: CommandGetChannelAuthenticationCapabilities,
+		Data:    []byte{channelCurrentExtended, PrivilegeLevelAdministrator},
+	}
+
+	packet, err := client.exchange(Packet{Legacy: true, Payload: request.MarshalRequest()}, PayloadTypeIPMI)
+	if err != nil {
+		return err
+	}
+
+	response, err := UnmarshalResponse(packet.Payload)
+	if err != nil {
+		return err
+	}
+
+	if response.CompletionCode != CompletionCodeOK {
+		return &CompletionCodeError{NetFn: request.NetFn, Command: request.Command, Code: response.CompletionCode}
+	}
+
+	return nil
+}
+
+// requestOpenSession sends the Open Session Request for cipher suite 3 and returns the session ID of the BMC.
+func (client *Client) requestOpenSession(consoleSessionID uint32) (uint32, error) {
+	request := OpenSessionRequest{
+		PrivilegeLevel:           PrivilegeLevelAdministrator,
+		ConsoleSessionID:         consoleSessionID,
+		AuthenticationAlgorithm:  AlgorithmRAKPHMACSHA1,
+		IntegrityAlgorithm:       AlgorithmHMACSHA196,
+		ConfidentialityAlgorithm: AlgorithmAESCBC128,
+	}
+
+	packet, err := client.exchange(
+		Packet{PayloadType: PayloadTypeOpenSessionRequest, Payload: request.Marshal()}, PayloadTypeOpenSessionResponse)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open ipmi session: %w", err)
+	}
+
+	response, err := UnmarshalOpenSessionResponse(packet.Payload)
+	if err != nil {
+		return 0, err
+	}
+
+	if response.Status != StatusOK {
+		return 0, fmt.Errorf("failed to open ipmi session: rmcp+ status code 0x%02x", response.Status)
+	}
+
+	if response.AuthenticationAlgorithm != request.AuthenticationAlgorithm ||
+		response.IntegrityAlgorithm != request.IntegrityAlgorithm ||
+		response.ConfidentialityAlgorithm != request.ConfidentialityAlgorithm {
+		return 0, fmt.Errorf("bmc does not support ipmi cipher suite 3")
+	}
+
+	return response.BMCSessionID, nil
+}
+
+// authenticate exchanges the RAKP messages, verifying the BMC knows the password of the user, and returns the session
+// integrity key.
+func (client *Client) authenticate(params *RAKPParameters, password string) ([]byte, error) {
+	rakp1 := RAKPMessage1{
+		BMCSessionID:  params.BMCSessionID,
+		ConsoleRandom: params.ConsoleRandom,
+		Role:          params.Role,
+		Username:      params.Username,
+	}
+
+	packet, err := client.exchange(Packet{PayloadType: PayloadTypeRAKP1, Payload: rakp1.Marshal()}, PayloadTypeRAKP2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange rakp messages 1 and 2: %w", err)
+	}
+
+	rakp2, err := UnmarshalRAKPMessage2(packet.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if rakp2.Status != StatusOK {
+		return nil, fmt.Errorf("bmc rejected rakp message 1 with rmcp+ status code 0x%02x", rakp2.Status)
+	}
+
+	params.BMCRandom = rakp2.BMCRandom
+	params.BMCGUID = rakp2.BMCGUID
+
+	if !hmac.Equal(rakp2.AuthCode, params.RAKP2AuthCode(password)) {
+		return nil, fmt.Errorf("invalid rakp message 2 authentication code, check the ipmi username and password")
+	}
+
+	rakp3 := RAKPMessage3{BMCSessionID: params.BMCSessionID, AuthCode: params.RAKP3AuthCode(password)}
+
+	packet, err = client.exchange(Packet{PayloadType: PayloadTypeRAKP3, Payload: rakp3.Marshal()}, PayloadTypeRAKP4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange rakp messages 3 and 4: %w", err)
+	}
+
+	rakp4, err := UnmarshalRAKPMessage4(packet.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if rakp4.Status != StatusOK {
+		return nil, fmt.Errorf("bmc rejected rakp message 3 with rmcp+ status code 0x%02x", rakp4.Status)
+	}
+
+	sik := params.SessionIntegrityKey(password)
+
+	if !hmac.Equal(rakp4.IntegrityCheck, params.RAKP4IntegrityCheck(sik)) {
+		return nil, fmt.Errorf("invalid rakp message 4 integrity check value")
+	}
+
+	return sik, nil
+}
+
+// exchange sends an unauthenticated packet and waits for a packet with the expected payload type. It is only used
+// before the session is established, when the read loop is not running.
+func (client *Client) exchange(packet Packet, expected PayloadType) (Packet, error) {
+	data, err := MarshalPacket(packet, nil)
+	if err != nil {
+		return Packet{}, err
+	}
+
+	if _, err := client.conn.Write(data); err != nil {
+		return Packet{}, err
+	}
+
+	if err := client.conn.SetReadDeadline(time.Now().Add(client.timeout)); err != nil {
+		return Packet{}, err
+	}
+
+	defer func() {
+		_ = client.conn.SetReadDeadline(time.Time{})
+	}()
+
+	buffer := make([]byte, maxPacketSize)
+
+	for {
+		length, err := client.conn.Read(buffer)
+		if err != nil {
+			return Packet{}, err
+		}
+
+		response, err := UnmarshalPacket(slices.Clone(buffer[:length]), nil)
+		if err == nil && response.Legacy == packet.Legacy && response.PayloadType == expected {
+			return response, nil
+		}
+	}
+}
+
+// command sends a command to the BMC, resending it up to retries times if the BMC does not respond in time.
+func (client *Client) command(netFn NetFn, command uint8, data []byte, retries int) ([]byte, error) {
+	client.commandMutex.Lock()
+	defer client.commandMutex.Unlock()
+
+	client.mutex.Lock()
+	client.requestSequence = (client.requestSequence + 1) & 0x3f
+	request := Message{NetFn: netFn, Command: command, Sequence: client.requestSequence, Data: data}
+	client.mutex.Unlock()
+
+	for range retries + 1 {
+		err := client.send(PayloadTypeIPMI, request.MarshalRequest())
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := client.waitResponse(request)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			glog.V(100).Infof("Timed out waiting for response to ipmi command 0x%02x", command)
+
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if response.CompletionCode != CompletionCodeOK {
+			return nil, &CompletionCodeError{NetFn: netFn, Command: command, Code: response.CompletionCode}
+		}
+
+		return response.Data, nil
+	}
+
+	return nil, fmt.Errorf("ipmi command 0x%02x was not answered after %d attempts: %w",
+		command, retries+1, os.ErrDeadlineExceeded)
+}
+
+// waitResponse waits for the response matching the request.
+func (client *Client) waitResponse(request Message) (Message, error) {
+	timer := time.NewTimer(client.timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case response := <-client.responses:
+			if response.NetFn == request.NetFn && response.Command == request.Command &&
+				response.Sequence == request.Sequence {
+				return response, nil
+			}
+		case <-timer.C:
+			return Message{}, os.ErrDeadlineExceeded
+		case <-client.done:
+			return Message{}, net.ErrClosed
+		}
+	}
+}
+
+// send sends an authenticated and encrypted packet on the session.
+func (client *Client) send(payloadType PayloadType, payload []byte) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	// Sequence numbers start at 1 and skip 0 when they wrap around.
+	client.sequence++
+	if client.sequence == 0 {
+		client.sequence = 1
+	}
+
+	data, err := MarshalPacket(Packet{
+		PayloadType:   payloadType,
+		Authenticated: true,
+		Encrypted:     true,
+		SessionID:     client.bmcSessionID,
+		Sequence:      client.sequence,
+		Payload:       payload,
+	}, client.keys)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.conn.Write(data)
+
+	return err
+}
+
+// readLoop reads the packets of the session until the connection is closed, passing IPMI responses to the pending
+// command and SOL payloads to the active SOL payload.
+func (client *Client) readLoop() {
+	defer close(client.done)
+
+	buffer := make([]byte, maxPacketSize)
+
+	for {
+		length, err := client.conn.Read(buffer)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+
+		if err != nil {
+			glog.V(100).Infof("Failed to read ipmi packet: %v", err)
+
+			continue
+		}
+
+		packet, err := UnmarshalPacket(slices.Clone(buffer[:length]), client.keys)
+		if err != nil || packet.Legacy || packet.SessionID != client.consoleSessionID {
+			glog.V(100).Infof("Ignoring ipmi packet that does not belong to the session: %v", err)
+
+			continue
+		}
+
+		switch packet.PayloadType {
+		case PayloadTypeIPMI:
+			response, err := UnmarshalResponse(packet.Payload)
+			if err != nil {
+				continue
+			}
+
+			select {
+			case client.responses <- response:
+			default:
+			}
+		case PayloadTypeSOL:
+			client.mutex.Lock()
+			sol := client.sol
+			client.mutex.Unlock()
+
+			if sol != nil {
+				sol.receive(packet.Payload)
+			}
+		default:
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// Package ipmi implements the parts of IPMI v2.0 over LAN (lanplus) used by pkg/bmc: RMCP+ sessions with cipher suite
+// 3 (RAKP-HMAC-SHA1, HMAC-SHA1-96 and AES-CBC-128), chassis commands and Serial over LAN. The encoding is shared by
+// the client in this package and the responder in pkg/bmc/bmctest.
+package ipmi
+
+import (
+	"fmt"
+)
+
+const (
+	// DefaultPort is the UDP port IPMI over LAN uses unless the BMC is configured otherwise.
+	DefaultPort = 623
+
+	// BMCAddress is the IPMB address of the BMC.
+	BMCAddress = 0x20
+	// RemoteConsoleAddress is the software ID used by the remote console.
+	RemoteConsoleAddress = 0x81
+
+	// PrivilegeLevelAdministrator is the administrator privilege level, required to control the chassis and
+	// activate SOL.
+	PrivilegeLevelAdministrator = 0x04
+	// PrivilegeLookupNameOnly is set in the requested role of RAKP message 1 to look up the user by name only.
+	PrivilegeLookupNameOnly = 0x10
+
+	// AlgorithmRAKPHMACSHA1 is the RAKP-HMAC-SHA1 authentication algorithm.
+	AlgorithmRAKPHMACSHA1 = 0x01
+	// AlgorithmHMACSHA196 is the HMAC-SHA1-96 integrity algorithm.
+	AlgorithmHMACSHA196 = 0x01
+	// AlgorithmAESCBC128 is the AES-CBC-128 confidentiality algorithm.
+	AlgorithmAESCBC128 = 0x01
+)
+
+// NetFn is the network function of an IPMI message. Responses use the request network function plus one.
+type NetFn uint8
+
+const (
+	// NetFnChassis is the network function of chassis commands.
+	NetFnChassis NetFn = 0x00
+	// NetFnApp is the network function of application commands, including session and payload commands.
+	NetFnApp NetFn = 0x06
+)
+
+const (
+	// CommandGetChassisStatus gets the power state of the chassis.
+	CommandGetChassisStatus = 0x01
+	// CommandChassisControl powers the chassis up, down, cycles or resets it.
+	CommandChassisControl = 0x02
+	// CommandSetSystemBootOptions sets a system boot options parameter, such as the boot flags.
+	CommandSetSystemBootOptions = 0x08
+	// CommandGetSystemBootOptions gets a system boot options parameter.
+	CommandGetSystemBootOptions = 0x09
+
+	// CommandGetDeviceID gets the ID of the BMC.
+	CommandGetDeviceID = 0x01
+	// CommandGetChannelAuthenticationCapabilities gets the authentication types the channel supports.
+	CommandGetChannelAuthenticationCapabilities = 0x38
+	// CommandSetSessionPrivilegeLevel raises the privilege level of the session.
+	CommandSetSessionPrivilegeLevel = 0x3b
+	// CommandCloseSession closes a session.
+	CommandCloseSession = 0x3c
+	// CommandActivatePayload activates a payload, such as SOL, on the session.
+	CommandActivatePayload = 0x48
+	// CommandDeactivatePayload deactivates a payload.
+	CommandDeactivatePayload = 0x49
+)
+
+const (
+	// CompletionCodeOK is the completion code of a successful command.
+	CompletionCodeOK = 0x00
+	// CompletionCodePayloadAlreadyActive is returned by Activate Payload if the payload is active on another session.
+	CompletionCodePayloadAlreadyActive = 0x80
+	// CompletionCodeInvalidCommand is returned for unsupported commands.
+	CompletionCodeInvalidCommand = 0xc1
+	// CompletionCodeInvalidData is returned for requests with invalid data.
+	CompletionCodeInvalidData = 0xcc
+)
+
+// ChassisControl is the action of the Chassis Control command.
+type ChassisControl uint8
+
+const (
+	// ChassisControlPowerDown forces the system off.
+	ChassisControlPowerDown ChassisControl = 0x00
+	// ChassisControlPowerUp powers the system on.
+	ChassisControlPowerUp ChassisControl = 0x01
+	// ChassisControlPowerCycle powers the system off and back on.
+	ChassisControlPowerCycle ChassisControl = 0x02
+	// ChassisControlHardReset resets the system without powering it off.
+	ChassisControlHardReset ChassisControl = 0x03
+	// ChassisControlDiagnosticInterrupt pulses a diagnostic interrupt (NMI) to the system.
+	ChassisControlDiagnosticInterrupt ChassisControl = 0x04
+	// ChassisControlSoftShutdown asks the operating system to shut down through ACPI.
+	ChassisControlSoftShutdown ChassisControl = 0x05
+)
+
+// BootDevice is the device selector of the boot flags.
+type BootDevice uint8
+
+const (
+	// BootDeviceNone does not override the boot device.
+	BootDeviceNone BootDevice = 0x0
+	// BootDevicePXE forces a PXE boot.
+	BootDevicePXE BootDevice = 0x1
+	// BootDeviceDisk forces a boot from the default hard drive.
+	BootDeviceDisk BootDevice = 0x2
+	// BootDeviceDiagnostic forces a boot from the default diagnostic partition.
+	BootDeviceDiagnostic BootDevice = 0x4
+	// BootDeviceCDROM forces a boot from the default CD or DVD drive.
+	BootDeviceCDROM BootDevice = 0x5
+	// BootDeviceBIOSSetup forces a boot into the BIOS setup.
+	BootDeviceBIOSSetup BootDevice = 0x6
+	// BootDeviceRemoteCDROM forces a boot from a remotely connected CD or DVD drive.
+	BootDeviceRemoteCDROM BootDevice = 0x8
+	// BootDeviceFloppy forces a boot from a floppy or primary removable media.
+	BootDeviceFloppy BootDevice = 0xf
+)
+
+// bootOptionBootFlags is the system boot options parameter that holds the boot flags.
+const bootOptionBootFlags = 0x05
+
+// Message is an IPMI request or response carried by the IPMI payload of a session.
+type Message struct {
+	// NetFn is the network function of the request. Responses are encoded with NetFn plus one.
+	NetFn NetFn
+	// Command is the command of the message.
+	Command uint8
+	// Sequence is the 6-bit sequence number used to match responses to requests.
+	Sequence uint8
+	// CompletionCode is the completion code of a response. It is not encoded in requests.
+	CompletionCode uint8
+	// Data is the request or response data, without the completion code.
+	Data []byte
+}
+
+// MarshalRequest encodes the message as a request from the remote console to the BMC.
+func (message Message) MarshalRequest() []byte {
+	header := []byte{BMCAddress, byte(message.NetFn) << 2}
+	body := append([]byte{RemoteConsoleAddress, message.Sequence << 2, message.Command}, message.Data...)
+
+	return appendMessage(header, body)
+}
+
+// MarshalResponse encodes the message as a response from the BMC to the remote console.
+func (message Message) MarshalResponse() []byte {
+	header := []byte{RemoteConsoleAddress, byte(message.NetFn+1) << 2}
+	body := append([]byte{BMCAddress, message.Sequence << 2, message.Command, message.CompletionCode}, message.Data...)
+
+	return appendMessage(header, body)
+}
+
+// UnmarshalRequest decodes a request sent by the remote console.
+func UnmarshalRequest(data []byte) (Message, error) {
+	if err := checkMessage(data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		NetFn:    NetFn(data[1] >> 2),
+		Sequence: data[4] >> 2,
+		Command:  data[5],
+		Data:     data[6 : len(data)-1],
+	}, nil
+}
+
+// UnmarshalResponse decodes a response sent by the BMC. The NetFn of the returned message is the request NetFn.
+func UnmarshalResponse(data []byte) (Message, error) {
+	if err := checkMessage(data); err != nil {
+		return Message{}, err
+	}
+
+	if len(data) < 8 {
+		return Message{}, fmt.Errorf("ipmi response of %d bytes has no completion code", len(data))
+	}
+
+	return Message{
+		NetFn:          NetFn(data[1]>>2) - 1,
+		Sequence:       data[4] >> 2,
+		Command:        data[5],
+		CompletionCode: data[6],
+		Data:           data[7 : len(data)-1],
+	}, nil
+}
+
+// BootFlags holds the boot flags system boot options parameter, which overrides the boot device.
+type BootFlags struct {
+	// Valid is true if the boot flags apply. The BMC clears it once the system boots unless Persistent is set.
+	Valid bool
+	// Persistent is true if the boot flags apply to every boot rather than the next one.
+	Persistent bool
+	// EFI is true if the system boots using UEFI rather than the legacy BIOS.
+	EFI bool
+	// Device is the boot device.
+	Device BootDevice
+}
+
+// Marshal encodes the boot flags as the 5 bytes of the parameter data.
+func (flags BootFlags) Marshal() []byte {
+	var first byte
+
+	if flags.Valid {
+		first |= 0x80
+	}
+
+	if flags.Persistent {
+		first |= 0x40
+	}
+
+	if flags.EFI {
+		first |= 0x20
+	}
+
+	return []byte{first, byte(flags.Device&0x0f) << 2, 0, 0, 0}
+}
+
+// UnmarshalBootFlags decodes the 5 bytes of the boot flags parameter data.
+func UnmarshalBootFlags(data []byte) (BootFlags, error) {
+	if len(data) < 2 {
+		return BootFlags{}, fmt.Errorf("boot flags of %d bytes are too short", len(data))
+	}
+
+	return BootFlags{
+		Valid:      data[0]&0x80 != 0,
+		Persistent: data[0]&0x40 != 0,
+		EFI:        data[0]&0x20 != 0,
+		Device:     BootDevice(data[1]>>2) & 0x0f,
+	}, nil
+}
+
+// appendMessage returns the header and body with their checksums appended.
+func appendMessage(header, body []byte) []byte {
+	message := append(header, checksum(header))
+	message = append(message, body...)
+
+	return append(message, checksum(body))
+}
+
+// checkMessage returns an error if the IPMI message is too short or either of its checksums is wrong.
+func checkMessage(data []byte) error {
+	if len(data) < 7 {
+		return fmt.Errorf("ipmi message of %d bytes is too short", len(data))
+	}
+
+	if checksum(data[:2]) != data[2] || checksum(data[3:len(data)-1]) != data[len(data)-1] {
+		return fmt.Errorf("ipmi message has an invalid checksum")
+	}
+
+	return nil
+}
+
+// checksum returns the two's complement checksum of the data, so that the sum of the data and the checksum is zero.
+func checksum(data []byte) byte {
+	var sum byte
+
+	for _, value := range data {
+		sum += value
+	}
+
+	return -sum
+}
@@ -0,0 +1,221 @@
+package ipmi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageMarshal(t *testing.T) {
+	request := Message{NetFn: NetFnChassis, Command: CommandChassisControl, Sequence: 5, Data: []byte{0x01}}
+
+	data := request.MarshalRequest()
+	assert.Equal(t, []byte{0x20, 0x00, 0xe0, 0x81, 0x14, 0x02, 0x01, 0x68}, data)
+
+	decoded, err := UnmarshalRequest(data)
+	assert.Nil(t, err)
+	assert.Equal(t, request, decoded)
+
+	response := Message{
+		NetFn: NetFnApp, Command: CommandGetDeviceID, Sequence: 63, CompletionCode: CompletionCodeInvalidData,
+		Data: []byte{},
+	}
+
+	decoded, err = UnmarshalResponse(response.MarshalResponse())
+	assert.Nil(t, err)
+	assert.Equal(t, response, decoded)
+
+	data[len(data)-1]++
+
+	_, err = UnmarshalRequest(data)
+	assert.EqualError(t, err, "ipmi message has an invalid checksum")
+
+	_, err = UnmarshalResponse(data[:4])
+	assert.EqualError(t, err, "ipmi message of 4 bytes is too short")
+}
+
+func TestBootFlagsMarshal(t *testing.T) {
+	flags := BootFlags{Valid: true, EFI: true, Device: BootDevicePXE}
+
+	data := flags.Marshal()
+	assert.Equal(t, []byte{0xa0, 0x04, 0, 0, 0}, data)
+
+	decoded, err := UnmarshalBootFlags(data)
+	assert.Nil(t, err)
+	assert.Equal(t, flags, decoded)
+
+	_, err = UnmarshalBootFlags(data[:1])
+	assert.EqualError(t, err, "boot flags of 1 bytes are too short")
+}
+
+func TestPacketMarshal(t *testing.T) {
+	keys := NewSessionKeys(bytes.Repeat([]byte{0x5a}, 20))
+	packet := Packet{
+		PayloadType:   PayloadTypeSOL,
+		Authenticated: true,
+		Encrypted:     true,
+		SessionID:     0x0200a4c1,
+		Sequence:      7,
+		Payload:       []byte("localhost login: "),
+	}
+
+	data, err := MarshalPacket(packet, keys)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "login")
+
+	sessionID, err := PacketSessionID(data)
+	assert.Nil(t, err)
+	assert.Equal(t, packet.SessionID, sessionID)
+
+	decoded, err := UnmarshalPacket(data, keys)
+	assert.Nil(t, err)
+	assert.Equal(t, packet, decoded)
+
+	_, err = UnmarshalPacket(data, NewSessionKeys(bytes.Repeat([]byte{0xa5}, 20)))
+	assert.EqualError(t, err, "rmcp+ session has an invalid integrity code")
+
+	_, err = UnmarshalPacket(data, nil)
+	assert.EqualError(t, err, "cannot unmarshal authenticated or encrypted packet without session keys")
+
+	legacy := Packet{Legacy: true, Payload: Message{NetFn: NetFnApp, Command: CommandGetDeviceID}.MarshalRequest()}
+
+	data, err = MarshalPacket(legacy, nil)
+	assert.Nil(t, err)
+
+	decoded, err = UnmarshalPacket(data, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, legacy, decoded)
+
+	_, err = PacketSessionID(data)
+	assert.EqualError(t, err, "packet is not an rmcp+ packet")
+}
+
+func TestRAKPExchange(t *testing.T) {
+	params := RAKPParameters{
+		ConsoleSessionID: 0xa0a2a3a4,
+		BMCSessionID:     0x0200a4c1,
+		Role:             PrivilegeLookupNameOnly | PrivilegeLevelAdministrator,
+		Username:         "admin",
+	}
+	params.ConsoleRandom[0] = 0x01
+	params.BMCRandom[0] = 0x02
+	params.BMCGUID[0] = 0x03
+
+	rakp1 := RAKPMessage1{
+		Tag:           1,
+		BMCSessionID:  params.BMCSessionID,
+		ConsoleRandom: params.ConsoleRandom,
+		Role:          params.Role,
+		Username:      params.Username,
+	}
+
+	decoded1, err := UnmarshalRAKPMessage1(rakp1.Marshal())
+	assert.Nil(t, err)
+	assert.Equal(t, rakp1, decoded1)
+
+	rakp2 := RAKPMessage2{
+		Tag:              1,
+		ConsoleSessionID: params.ConsoleSessionID,
+		BMCRandom:        params.BMCRandom,
+		BMCGUID:          params.BMCGUID,
+		AuthCode:         params.RAKP2AuthCode("password"),
+	}
+
+	decoded2, err := UnmarshalRAKPMessage2(rakp2.Marshal())
+	assert.Nil(t, err)
+	assert.Equal(t, rakp2, decoded2)
+	assert.Len(t, decoded2.AuthCode, 20)
+	assert.NotEqual(t, params.RAKP2AuthCode("wrong"), decoded2.AuthCode)
+	assert.NotEqual(t, params.RAKP2AuthCode("password"), params.RAKP3AuthCode("password"))
+
+	sik := params.SessionIntegrityKey("password")
+	rakp4 := RAKPMessage4{
+		Tag: 2, ConsoleSessionID: params.ConsoleSessionID, IntegrityCheck: params.RAKP4IntegrityCheck(sik),
+	}
+
+	decoded4, err := UnmarshalRAKPMessage4(rakp4.Marshal())
+	assert.Nil(t, err)
+	assert.Equal(t, rakp4, decoded4)
+	assert.Len(t, decoded4.IntegrityCheck, 12)
+}
+
+// The known-answer vectors below were computed independently of this package from the field tables of the IPMI v2.0
+// specification (sections 13.28 to 13.32), using HMAC-SHA1 from the Python standard library and AES-CBC-128 from
+// openssl, for user admin with password "password", console random 00..0f, BMC random 10..1f and BMC GUID 20..2f.
+func TestRAKPKnownAnswers(t *testing.T) {
+	params := RAKPParameters{
+		ConsoleSessionID: 0xa0a2a3a4,
+		BMCSessionID:     0x0200a4c1,
+		Role:             PrivilegeLookupNameOnly | PrivilegeLevelAdministrator,
+		Username:         "admin",
+	}
+
+	for index := range 16 {
+		params.ConsoleRandom[index] = byte(index)
+		params.BMCRandom[index] = byte(0x10 + index)
+		params.BMCGUID[index] = byte(0x20 + index)
+	}
+
+	assert.Equal(t, mustDecodeHex(t, "ead6d17ddc0b25f274d683c637ffcff30321de06"), params.RAKP2AuthCode("password"))
+	assert.Equal(t, mustDecodeHex(t, "4ab420258775814f120682c8ab9275c29997effd"), params.RAKP3AuthCode("password"))
+
+	sik := params.SessionIntegrityKey("password")
+	assert.Equal(t, mustDecodeHex(t, "122c77c4b11ccd93251cbae6c34a9cb6310da154"), sik)
+	assert.Equal(t, mustDecodeHex(t, "e8398932fb74b82f724e9a3d"), params.RAKP4IntegrityCheck(sik))
+
+	keys := NewSessionKeys(sik)
+	assert.Equal(t, mustDecodeHex(t, "e4472be78f9a81fa68297aab696a7be8c97fc9f8"), keys.integrityKey)
+	assert.Equal(t, mustDecodeHex(t, "2b6552012a2517cb3b5713901d757a6e"), keys.confidentialityKey)
+}
+
+func TestPacketKnownAnswer(t *testing.T) {
+	// Get Chassis Status request, authenticated with K1 and encrypted with K2 from TestRAKPKnownAnswers using IV 30..3f.
+	vector := mustDecodeHex(t, "0600ff0706c0c1a40002010000002000303132333435363738393a3b3c3d3e3f"+
+		"8ba22e8ebb53f69bc92f8d89fd9698a3ffff0207e665d62361296b51eee7175c")
+	keys := NewSessionKeys(mustDecodeHex(t, "122c77c4b11ccd93251cbae6c34a9cb6310da154"))
+	packet := Packet{
+		PayloadType:   PayloadTypeIPMI,
+		Authenticated: true,
+		Encrypted:     true,
+		SessionID:     0x0200a4c1,
+		Sequence:      1,
+		Payload:       Message{NetFn: NetFnChassis, Command: CommandGetChassisStatus, Sequence: 1}.MarshalRequest(),
+	}
+
+	decoded, err := UnmarshalPacket(vector, keys)
+	assert.Nil(t, err)
+	assert.Equal(t, packet, decoded)
+
+	defaultIVReader := ivReader
+	ivReader = bytes.NewReader(mustDecodeHex(t, "303132333435363738393a3b3c3d3e3f"))
+
+	defer func() { ivReader = defaultIVReader }()
+
+	data, err := MarshalPacket(packet, keys)
+	assert.Nil(t, err)
+	assert.Equal(t, vector, data)
+}
+
+func TestSOLPayloadMarshal(t *testing.T) {
+	payload := SOLPayload{Sequence: 3, AckSequence: 2, AcceptedCount: 4, Status: SOLStatusNack, Data: []byte("core")}
+
+	decoded, err := UnmarshalSOLPayload(payload.Marshal())
+	assert.Nil(t, err)
+	assert.Equal(t, payload, decoded)
+
+	_, err = UnmarshalSOLPayload([]byte{1, 2})
+	assert.EqualError(t, err, "sol payload of 2 bytes is too short")
+}
+
+func mustDecodeHex(t *testing.T, value string) []byte {
+	t.Helper()
+
+	data, err := hex.DecodeString(value)
+	if err != nil {
+		t.Fatalf("failed to decode hex %q: %v", value, err)
+	}
+
+	return data
+}
@@ -0,0 +1,333 @@
+package ipmi
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	algorithmPayloadAuthentication  = 0x00
+	algorithmPayloadIntegrity       = 0x01
+	algorithmPayloadConfidentiality = 0x02
+	algorithmPayloadLength          = 0x08
+)
+
+// OpenSessionRequest is the RMCP+ Open Session Request sent by the remote console.
+type OpenSessionRequest struct {
+	// Tag is used to match the response to the request.
+	Tag uint8
+	// PrivilegeLevel is the requested maximum privilege level.
+	PrivilegeLevel uint8
+	// ConsoleSessionID is the session ID chosen by the remote console.
+	ConsoleSessionID uint32
+	// AuthenticationAlgorithm is the requested authentication algorithm.
+	AuthenticationAlgorithm uint8
+	// IntegrityAlgorithm is the requested integrity algorithm.
+	IntegrityAlgorithm uint8
+	// ConfidentialityAlgorithm is the requested confidentiality algorithm.
+	ConfidentialityAlgorithm uint8
+}
+
+// OpenSessionResponse is the RMCP+ Open Session Response sent by the BMC.
+type OpenSessionResponse struct {
+	// Tag is the tag of the request.
+	Tag uint8
+	// Status is the RMCP+ status code. The other fields are only set if it is StatusOK.
+	Status uint8
+	// PrivilegeLevel is the maximum privilege level allowed for the session.
+	PrivilegeLevel uint8
+	// ConsoleSessionID is the session ID chosen by the remote console.
+	ConsoleSessionID uint32
+	// BMCSessionID is the session ID chosen by the BMC.
+	BMCSessionID uint32
+	// AuthenticationAlgorithm is the selected authentication algorithm.
+	AuthenticationAlgorithm uint8
+	// IntegrityAlgorithm is the selected integrity algorithm.
+	IntegrityAlgorithm uint8
+	// ConfidentialityAlgorithm is the selected confidentiality algorithm.
+	ConfidentialityAlgorithm uint8
+}
+
+// RAKPMessage1 is sent by the remote console to start authenticating the user.
+type RAKPMessage1 struct {
+	// Tag is used to match the response to the request.
+	Tag uint8
+	// BMCSessionID is the session ID chosen by the BMC.
+	BMCSessionID uint32
+	// ConsoleRandom is the random number of the remote console.
+	ConsoleRandom [16]byte
+	// Role is the requested privilege level and lookup bits.
+	Role uint8
+	// Username is the name of the user.
+	Username string
+}
+
+// RAKPMessage2 is sent by the BMC in response to RAKP message 1.
+type RAKPMessage2 struct {
+	// Tag is the tag of RAKP message 1.
+	Tag uint8
+	// Status is the RMCP+ status code. The other fields are only set if it is StatusOK.
+	Status uint8
+	// ConsoleSessionID is the session ID chosen by the remote console.
+	ConsoleSessionID uint32
+	// BMCRandom is the random number of the BMC.
+	BMCRandom [16]byte
+	// BMCGUID is the GUID of the BMC.
+	BMCGUID [16]byte
+	// AuthCode is the key exchange authentication code.
+	AuthCode []byte
+}
+
+// RAKPMessage3 is sent by the remote console to prove it knows the password of the user.
+type RAKPMessage3 struct {
+	// Tag is used to match the response to the request.
+	Tag uint8
+	// Status is the RMCP+ status code.
+	Status uint8
+	// BMCSessionID is the session ID chosen by the BMC.
+	BMCSessionID uint32
+	// AuthCode is the key exchange authentication code.
+	AuthCode []byte
+}
+
+// RAKPMessage4 is sent by the BMC in response to RAKP message 3, establishing the session.
+type RAKPMessage4 struct {
+	// Tag is the tag of RAKP message 3.
+	Tag uint8
+	// Status is the RMCP+ status code. The other fields are only set if it is StatusOK.
+	Status uint8
+	// ConsoleSessionID is the session ID chosen by the remote console.
+	ConsoleSessionID uint32
+	// IntegrityCheck is the integrity check value.
+	IntegrityCheck []byte
+}
+
+// SOLPayload is the payload of a Serial over LAN packet.
+type SOLPayload struct {
+	// Sequence is the 4-bit sequence number of a packet carrying data, or zero for packets that only acknowledge.
+	Sequence uint8
+	// AckSequence is the sequence number of the packet being acknowledged, or zero.
+	AckSequence uint8
+	// AcceptedCount is the number of characters accepted from the acknowledged packet.
+	AcceptedCount uint8
+	// Status holds the operation bits sent by the remote console or the status bits sent by the BMC.
+	Status uint8
+	// Data is the character data.
+	Data []byte
+}
+
+const (
+	// SOLStatusNack is set when the acknowledged packet was not accepted and must be resent later.
+	SOLStatusNack = 0x40
+	// SOLStatusDeactivating is set by the BMC when SOL is being deactivated.
+	SOLStatusDeactivating = 0x10
+)
+
+// Marshal encodes the Open Session Request.
+func (request OpenSessionRequest) Marshal() []byte {
+	data := []byte{request.Tag, request.PrivilegeLevel, 0, 0}
+	data = binary.LittleEndian.AppendUint32(data, request.ConsoleSessionID)
+
+	return appendAlgorithms(data,
+		request.AuthenticationAlgorithm, request.IntegrityAlgorithm, request.ConfidentialityAlgorithm)
+}
+
+// UnmarshalOpenSessionRequest decodes an Open Session Request.
+func UnmarshalOpenSessionRequest(data []byte) (OpenSessionRequest, error) {
+	if len(data) < 32 {
+		return OpenSessionRequest{}, fmt.Errorf("open session request of %d bytes is too short", len(data))
+	}
+
+	return OpenSessionRequest{
+		Tag:                      data[0],
+		PrivilegeLevel:           data[1],
+		ConsoleSessionID:         binary.LittleEndian.Uint32(data[4:8]),
+		AuthenticationAlgorithm:  data[12],
+		IntegrityAlgorithm:       data[20],
+		ConfidentialityAlgorithm: data[28],
+	}, nil
+}
+
+// Marshal encodes the Open Session Response.
+func (response OpenSessionResponse) Marshal() []byte {
+	data := []byte{response.Tag, response.Status, response.PrivilegeLevel, 0}
+	data = binary.LittleEndian.AppendUint32(data, response.ConsoleSessionID)
+	data = binary.LittleEndian.AppendUint32(data, response.BMCSessionID)
+
+	return appendAlgorithms(data,
+		response.AuthenticationAlgorithm, response.IntegrityAlgorithm, response.ConfidentialityAlgorithm)
+}
+
+// UnmarshalOpenSessionResponse decodes an Open Session Response.
+func UnmarshalOpenSessionResponse(data []byte) (OpenSessionResponse, error) {
+	if len(data) < 2 {
+		return OpenSessionResponse{}, fmt.Errorf("open session response of %d bytes is too short", len(data))
+	}
+
+	response := OpenSessionResponse{Tag: data[0], Status: data[1]}
+
+	if response.Status != StatusOK {
+		return response, nil
+	}
+
+	if len(data) < 36 {
+		return OpenSessionResponse{}, fmt.Errorf("open session response of %d bytes is too short", len(data))
+	}
+
+	response.PrivilegeLevel = data[2]
+	response.ConsoleSessionID = binary.LittleEndian.Uint32(data[4:8])
+	response.BMCSessionID = binary.LittleEndian.Uint32(data[8:12])
+	response.AuthenticationAlgorithm = data[16]
+	response.IntegrityAlgorithm = data[24]
+	response.ConfidentialityAlgorithm = data[32]
+
+	return response, nil
+}
+
+// Marshal encodes RAKP message 1.
+func (message RAKPMessage1) Marshal() []byte {
+	data := []byte{message.Tag, 0, 0, 0}
+	data = binary.LittleEndian.AppendUint32(data, message.BMCSessionID)
+	data = append(data, message.ConsoleRandom[:]...)
+	data = append(data, message.Role, 0, 0, byte(len(message.Username)))
+
+	return append(data, message.Username...)
+}
+
+// UnmarshalRAKPMessage1 decodes RAKP message 1.
+func UnmarshalRAKPMessage1(data []byte) (RAKPMessage1, error) {
+	if len(data) < 28 || len(data) < 28+int(data[27]) {
+		return RAKPMessage1{}, fmt.Errorf("rakp message 1 of %d bytes is too short", len(data))
+	}
+
+	message := RAKPMessage1{
+		Tag:          data[0],
+		BMCSessionID: binary.LittleEndian.Uint32(data[4:8]),
+		Role:         data[24],
+		Username:     string(data[28 : 28+int(data[27])]),
+	}
+
+	copy(message.ConsoleRandom[:], data[8:24])
+
+	return message, nil
+}
+
+// Marshal encodes RAKP message 2.
+func (message RAKPMessage2) Marshal() []byte {
+	data := []byte{message.Tag, message.Status, 0, 0}
+	data = binary.LittleEndian.AppendUint32(data, message.ConsoleSessionID)
+	data = append(data, message.BMCRandom[:]...)
+	data = append(data, message.BMCGUID[:]...)
+
+	return append(data, message.AuthCode...)
+}
+
+// UnmarshalRAKPMessage2 decodes RAKP message 2.
+func UnmarshalRAKPMessage2(data []byte) (RAKPMessage2, error) {
+	if len(data) < 2 {
+		return RAKPMessage2{}, fmt.Errorf("rakp message 2 of %d bytes is too short", len(data))
+	}
+
+	message := RAKPMessage2{Tag: data[0], Status: data[1]}
+
+	if message.Status != StatusOK {
+		return message, nil
+	}
+
+	if len(data) < 40 {
+		return RAKPMessage2{}, fmt.Errorf("rakp message 2 of %d bytes is too short", len(data))
+	}
+
+	message.ConsoleSessionID = binary.LittleEndian.Uint32(data[4:8])
+	message.AuthCode = data[40:]
+
+	copy(message.BMCRandom[:], data[8:24])
+	copy(message.BMCGUID[:], data[24:40])
+
+	return message, nil
+}
+
+// Marshal encodes RAKP message 3.
+func (message RAKPMessage3) Marshal() []byte {
+	data := []byte{message.Tag, message.Status, 0, 0}
+	data = binary.LittleEndian.AppendUint32(data, message.BMCSessionID)
+
+	return append(data, message.AuthCode...)
+}
+
+// UnmarshalRAKPMessage3 decodes RAKP message 3.
+func UnmarshalRAKPMessage3(data []byte) (RAKPMessage3, error) {
+	if len(data) < 8 {
+		return RAKPMessage3{}, fmt.Errorf("rakp message 3 of %d bytes is too short", len(data))
+	}
+
+	return RAKPMessage3{
+		Tag:          data[0],
+		Status:       data[1],
+		BMCSessionID: binary.LittleEndian.Uint32(data[4:8]),
+		AuthCode:     data[8:],
+	}, nil
+}
+
+// Marshal encodes RAKP message 4.
+func (message RAKPMessage4) Marshal() []byte {
+	data := []byte{message.Tag, message.Status, 0, 0}
+	data = binary.LittleEndian.AppendUint32(data, message.ConsoleSessionID)
+
+	return append(data, message.IntegrityCheck...)
+}
+
+// UnmarshalRAKPMessage4 decodes RAKP message 4.
+func UnmarshalRAKPMessage4(data []byte) (RAKPMessage4, error) {
+	if len(data) < 2 {
+		return RAKPMessage4{}, fmt.Errorf("rakp message 4 of %d bytes is too short", len(data))
+	}
+
+	message := RAKPMessage4{Tag: data[0], Status: data[1]}
+
+	if message.Status != StatusOK {
+		return message, nil
+	}
+
+	if len(data) < 8 {
+		return RAKPMessage4{}, fmt.Errorf("rakp message 4 of %d bytes is too short", len(data))
+	}
+
+	message.ConsoleSessionID = binary.LittleEndian.Uint32(data[4:8])
+	message.IntegrityCheck = data[8:]
+
+	return message, nil
+}
+
+// Marshal encodes the SOL payload.
+func (payload SOLPayload) Marshal() []byte {
+	return append([]byte{payload.Sequence, payload.AckSequence, payload.AcceptedCount, payload.Status}, payload.Data...)
+}
+
+// UnmarshalSOLPayload decodes a SOL payload.
+func UnmarshalSOLPayload(data []byte) (SOLPayload, error) {
+	if len(data) < 4 {
+		return SOLPayload{}, fmt.Errorf("sol payload of %d bytes is too short", len(data))
+	}
+
+	return SOLPayload{
+		Sequence:      data[0] & 0x0f,
+		AckSequence:   data[1] & 0x0f,
+		AcceptedCount: data[2],
+		Status:        data[3],
+		Data:          data[4:],
+	}, nil
+}
+
+// appendAlgorithms appends the authentication, integrity and confidentiality algorithm payloads.
+func appendAlgorithms(data []byte, authentication, integrity, confidentiality uint8) []byte {
+	for _, algorithm := range [][2]uint8{
+		{algorithmPayloadAuthentication, authentication},
+		{algorithmPayloadIntegrity, integrity},
+		{algorithmPayloadConfidentiality, confidentiality},
+	} {
+		data = append(data, algorithm[0], 0, 0, algorithmPayloadLength, algorithm[1], 0, 0, 0)
+	}
+
+	return data
+}
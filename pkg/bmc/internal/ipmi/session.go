@@ -0,0 +1,363 @@
+package ipmi
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	rmcpVersion       = 0x06
+	rmcpSequenceNoAck = 0xff
+	rmcpClassIPMI     = 0x07
+
+	authTypeNone     = 0x00
+	authTypeRMCPPlus = 0x06
+
+	payloadEncrypted     = 0x80
+	payloadAuthenticated = 0x40
+	payloadTypeMask      = 0x3f
+
+	nextHeader = 0x07
+
+	// integrityCodeLength is the length of an HMAC-SHA1-96 authentication code.
+	integrityCodeLength = 12
+)
+
+// PayloadType is the type of the payload of an RMCP+ session packet.
+type PayloadType uint8
+
+const (
+	// PayloadTypeIPMI carries an IPMI message.
+	PayloadTypeIPMI PayloadType = 0x00
+	// PayloadTypeSOL carries Serial over LAN data.
+	PayloadTypeSOL PayloadType = 0x01
+	// PayloadTypeOpenSessionRequest carries an RMCP+ Open Session Request.
+	PayloadTypeOpenSessionRequest PayloadType = 0x10
+	// PayloadTypeOpenSessionResponse carries an RMCP+ Open Session Response.
+	PayloadTypeOpenSessionResponse PayloadType = 0x11
+	// PayloadTypeRAKP1 carries RAKP message 1.
+	PayloadTypeRAKP1 PayloadType = 0x12
+	// PayloadTypeRAKP2 carries RAKP message 2.
+	PayloadTypeRAKP2 PayloadType = 0x13
+	// PayloadTypeRAKP3 carries RAKP message 3.
+	PayloadTypeRAKP3 PayloadType = 0x14
+	// PayloadTypeRAKP4 carries RAKP message 4.
+	PayloadTypeRAKP4 PayloadType = 0x15
+)
+
+const (
+	// StatusOK is the RMCP+ status code of a successful session setup step.
+	StatusOK = 0x00
+	// StatusInvalidSessionID is the RMCP+ status code for an unknown session ID.
+	StatusInvalidSessionID = 0x02
+	// StatusUnauthorizedName is the RMCP+ status code for an unknown user name.
+	StatusUnauthorizedName = 0x0d
+	// StatusInvalidIntegrityCheckValue is the RMCP+ status code for a wrong authentication code.
+	StatusInvalidIntegrityCheckValue = 0x0f
+	// StatusNoCipherSuiteMatch is the RMCP+ status code for unsupported algorithms.
+	StatusNoCipherSuiteMatch = 0x11
+)
+
+// Packet is an RMCP packet carrying an IPMI v1.5 session or an RMCP+ session payload.
+type Packet struct {
+	// Legacy is true for IPMI v1.5 packets without authentication, used before the RMCP+ session is established.
+	Legacy bool
+	// PayloadType is the type of the payload. Legacy packets always carry IPMI messages.
+	PayloadType PayloadType
+	// Authenticated is true if the packet has an HMAC-SHA1-96 integrity code.
+	Authenticated bool
+	// Encrypted is true if the payload is encrypted using AES-CBC-128.
+	Encrypted bool
+	// SessionID is the ID of the session of the receiver.
+	SessionID uint32
+	// Sequence is the session sequence number.
+	Sequence uint32
+	// Payload is the unencrypted payload.
+	Payload []byte
+}
+
+// SessionKeys holds the keys derived from the session integrity key of an established session.
+type SessionKeys struct {
+	integrityKey       []byte
+	confidentialityKey []byte
+}
+
+// NewSessionKeys returns the integrity key K1 and confidentiality key K2 derived from the session integrity key.
+func NewSessionKeys(sik []byte) *SessionKeys {
+	return &SessionKeys{
+		integrityKey:       hmacSHA1(sik, bytes.Repeat([]byte{0x01}, sha1.Size)),
+		confidentialityKey: hmacSHA1(sik, bytes.Repeat([]byte{0x02}, sha1.Size))[:aes.BlockSize],
+	}
+}
+
+// MarshalPacket encodes the packet. Keys are required for authenticated or encrypted packets.
+func MarshalPacket(packet Packet, keys *SessionKeys) ([]byte, error) {
+	data := []byte{rmcpVersion, 0x00, rmcpSequenceNoAck, rmcpClassIPMI}
+
+	if packet.Legacy {
+		data = append(data, authTypeNone)
+		data = binary.LittleEndian.AppendUint32(data, packet.Sequence)
+		data = binary.LittleEndian.AppendUint32(data, packet.SessionID)
+		data = append(data, byte(len(packet.Payload)))
+
+		return append(data, packet.Payload...), nil
+	}
+
+	if (packet.Authenticated || packet.Encrypted) && keys == nil {
+		return nil, fmt.Errorf("cannot marshal authenticated or encrypted packet without session keys")
+	}
+
+	payload := packet.Payload
+	payloadType := byte(packet.PayloadType)
+
+	if packet.Encrypted {
+		encrypted, err := keys.encrypt(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		payload = encrypted
+		payloadType |= payloadEncrypted
+	}
+
+	if packet.Authenticated {
+		payloadType |= payloadAuthenticated
+	}
+
+	session := []byte{authTypeRMCPPlus, payloadType}
+	session = binary.LittleEndian.AppendUint32(session, packet.SessionID)
+	session = binary.LittleEndian.AppendUint32(session, packet.Sequence)
+	session = binary.LittleEndian.AppendUint16(session, uint16(len(payload)))
+	session = append(session, payload...)
+
+	if packet.Authenticated {
+		// The integrity code covers the session from the authentication type through the next header, padded to a
+		// multiple of 4 bytes.
+		padLength := (4 - (len(session)+2)%4) % 4
+		session = append(session, bytes.Repeat([]byte{0xff}, padLength)...)
+		session = append(session, byte(padLength), nextHeader)
+		session = append(session, hmacSHA1(keys.integrityKey, session)[:integrityCodeLength]...)
+	}
+
+	return append(data, session...), nil
+}
+
+// UnmarshalPacket decodes a packet, verifying its integrity code and decrypting its payload using the keys.
+func UnmarshalPacket(data []byte, keys *SessionKeys) (Packet, error) {
+	if len(data) < 5 || data[0] != rmcpVersion || data[3] != rmcpClassIPMI {
+		return Packet{}, fmt.Errorf("packet is not an rmcp ipmi packet")
+	}
+
+	session := data[4:]
+
+	switch session[0] {
+	case authTypeNone:
+		return unmarshalLegacySession(session)
+	case authTypeRMCPPlus:
+		return unmarshalRMCPPlusSession(session, keys)
+	default:
+		return Packet{}, fmt.Errorf("packet has unsupported authentication type 0x%02x", session[0])
+	}
+}
+
+// PacketSessionID returns the session ID of an RMCP+ packet without verifying or decrypting it, so the receiver can
+// look up the keys of the session before calling UnmarshalPacket.
+func PacketSessionID(data []byte) (uint32, error) {
+	if len(data) < 10 || data[0] != rmcpVersion || data[3] != rmcpClassIPMI || data[4] != authTypeRMCPPlus {
+		return 0, fmt.Errorf("packet is not an rmcp+ packet")
+	}
+
+	return binary.LittleEndian.Uint32(data[6:10]), nil
+}
+
+// RAKPParameters holds the values exchanged while establishing a session that the RAKP authentication codes and the
+// session integrity key are computed over.
+type RAKPParameters struct {
+	// ConsoleSessionID is the session ID chosen by the remote console.
+	ConsoleSessionID uint32
+	// BMCSessionID is the session ID chosen by the BMC.
+	BMCSessionID uint32
+	// ConsoleRandom is the random number of the remote console, sent in RAKP message 1.
+	ConsoleRandom [16]byte
+	// BMCRandom is the random number of the BMC, sent in RAKP message 2.
+	BMCRandom [16]byte
+	// BMCGUID is the GUID of the BMC, sent in RAKP message 2.
+	BMCGUID [16]byte
+	// Role is the requested privilege level and lookup bits sent in RAKP message 1.
+	Role uint8
+	// Username is the name of the user.
+	Username string
+}
+
+// RAKP2AuthCode returns the key exchange authentication code of RAKP message 2.
+func (params RAKPParameters) RAKP2AuthCode(password string) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, params.ConsoleSessionID)
+	data = binary.LittleEndian.AppendUint32(data, params.BMCSessionID)
+	data = append(data, params.ConsoleRandom[:]...)
+	data = append(data, params.BMCRandom[:]...)
+	data = append(data, params.BMCGUID[:]...)
+	data = append(data, params.role()...)
+
+	return hmacSHA1([]byte(password), data)
+}
+
+// RAKP3AuthCode returns the key exchange authentication code of RAKP message 3.
+func (params RAKPParameters) RAKP3AuthCode(password string) []byte {
+	data := append([]byte{}, params.BMCRandom[:]...)
+	data = binary.LittleEndian.AppendUint32(data, params.ConsoleSessionID)
+	data = append(data, params.role()...)
+
+	return hmacSHA1([]byte(password), data)
+}
+
+// SessionIntegrityKey returns the session integrity key, using the password as the BMC key.
+func (params RAKPParameters) SessionIntegrityKey(password string) []byte {
+	data := append([]byte{}, params.ConsoleRandom[:]...)
+	data = append(data, params.BMCRandom[:]...)
+	data = append(data, params.role()...)
+
+	return hmacSHA1([]byte(password), data)
+}
+
+// RAKP4IntegrityCheck returns the integrity check value of RAKP message 4.
+func (params RAKPParameters) RAKP4IntegrityCheck(sik []byte) []byte {
+	data := append([]byte{}, params.ConsoleRandom[:]...)
+	data = binary.LittleEndian.AppendUint32(data, params.BMCSessionID)
+	data = append(data, params.BMCGUID[:]...)
+
+	return hmacSHA1(sik, data)[:integrityCodeLength]
+}
+
+// role returns the role, user name length and user name, which end most of the RAKP computations.
+func (params RAKPParameters) role() []byte {
+	return append([]byte{params.Role, byte(len(params.Username))}, params.Username...)
+}
+
+// ivReader is the source of the IVs used by encrypt. It is only replaced in tests that need deterministic packets.
+var ivReader io.Reader = rand.Reader
+
+// encrypt encrypts the payload using AES-CBC-128 with a random IV, which is prepended to the result.
+func (keys *SessionKeys) encrypt(payload []byte) ([]byte, error) {
+	block, err := aes.NewCipher(keys.confidentialityKey)
+	if err != nil {
+		return nil, err
+	}
+
+	padLength := (aes.BlockSize - (len(payload)+1)%aes.BlockSize) % aes.BlockSize
+	plaintext := append([]byte{}, payload...)
+
+	for index := range padLength {
+		plaintext = append(plaintext, byte(index+1))
+	}
+
+	plaintext = append(plaintext, byte(padLength))
+
+	encrypted := make([]byte, aes.BlockSize+len(plaintext))
+	if _, err := io.ReadFull(ivReader, encrypted[:aes.BlockSize]); err != nil {
+		return nil, err
+	}
+
+	cipher.NewCBCEncrypter(block, encrypted[:aes.BlockSize]).CryptBlocks(encrypted[aes.BlockSize:], plaintext)
+
+	return encrypted, nil
+}
+
+// decrypt decrypts a payload encrypted using encrypt and removes its padding.
+func (keys *SessionKeys) decrypt(payload []byte) ([]byte, error) {
+	if len(payload) < 2*aes.BlockSize || len(payload)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("encrypted payload of %d bytes has an invalid length", len(payload))
+	}
+
+	block, err := aes.NewCipher(keys.confidentialityKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(payload)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, payload[:aes.BlockSize]).CryptBlocks(plaintext, payload[aes.BlockSize:])
+
+	padLength := int(plaintext[len(plaintext)-1])
+	if padLength >= aes.BlockSize {
+		return nil, fmt.Errorf("encrypted payload has an invalid pad length %d", padLength)
+	}
+
+	return plaintext[:len(plaintext)-1-padLength], nil
+}
+
+// unmarshalLegacySession decodes an IPMI v1.5 session without authentication.
+func unmarshalLegacySession(session []byte) (Packet, error) {
+	if len(session) < 10 || len(session) < 10+int(session[9]) {
+		return Packet{}, fmt.Errorf("ipmi v1.5 session of %d bytes is too short", len(session))
+	}
+
+	return Packet{
+		Legacy:    true,
+		Sequence:  binary.LittleEndian.Uint32(session[1:5]),
+		SessionID: binary.LittleEndian.Uint32(session[5:9]),
+		Payload:   session[10 : 10+int(session[9])],
+	}, nil
+}
+
+// unmarshalRMCPPlusSession decodes an RMCP+ session, verifying its integrity code and decrypting its payload.
+func unmarshalRMCPPlusSession(session []byte, keys *SessionKeys) (Packet, error) {
+	if len(session) < 12 {
+		return Packet{}, fmt.Errorf("rmcp+ session of %d bytes is too short", len(session))
+	}
+
+	packet := Packet{
+		PayloadType:   PayloadType(session[1] & payloadTypeMask),
+		Authenticated: session[1]&payloadAuthenticated != 0,
+		Encrypted:     session[1]&payloadEncrypted != 0,
+		SessionID:     binary.LittleEndian.Uint32(session[2:6]),
+		Sequence:      binary.LittleEndian.Uint32(session[6:10]),
+	}
+
+	payloadEnd := 12 + int(binary.LittleEndian.Uint16(session[10:12]))
+	if len(session) < payloadEnd {
+		return Packet{}, fmt.Errorf("rmcp+ session of %d bytes is shorter than its payload", len(session))
+	}
+
+	if (packet.Authenticated || packet.Encrypted) && keys == nil {
+		return Packet{}, fmt.Errorf("cannot unmarshal authenticated or encrypted packet without session keys")
+	}
+
+	if packet.Authenticated {
+		covered := session[:len(session)-integrityCodeLength]
+		if len(covered) < payloadEnd+2 || covered[len(covered)-1] != nextHeader {
+			return Packet{}, fmt.Errorf("rmcp+ session has an invalid trailer")
+		}
+
+		expected := hmacSHA1(keys.integrityKey, covered)[:integrityCodeLength]
+		if !hmac.Equal(expected, session[len(covered):]) {
+			return Packet{}, fmt.Errorf("rmcp+ session has an invalid integrity code")
+		}
+	}
+
+	packet.Payload = session[12:payloadEnd]
+
+	if packet.Encrypted {
+		payload, err := keys.decrypt(packet.Payload)
+		if err != nil {
+			return Packet{}, err
+		}
+
+		packet.Payload = payload
+	}
+
+	return packet, nil
+}
+
+// hmacSHA1 returns the HMAC-SHA1 of the data using the key.
+func hmacSHA1(key, data []byte) []byte {
+	mac := hmac.New(sha1.New, key)
+	_, _ = mac.Write(data)
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,278 @@
+package ipmi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// solInstance is the payload instance of SOL. BMCs only support a single SOL instance.
+	solInstance = 1
+	// solActivationFlags requests encryption and authentication of the SOL payload and defers serial alerts while
+	// SOL is active.
+	solActivationFlags = 0xc4
+	// solRetries is the number of times character data is resent when the BMC does not acknowledge it in time.
+	solRetries = 3
+	// solDefaultMaxData is the amount of character data sent per packet if the BMC does not report its buffer size.
+	solDefaultMaxData = 64
+)
+
+// SOL is an active Serial over LAN payload. It reads the output of the serial console of the system and writes to its
+// input. Use Client.ActivateSOL to activate one and Close to deactivate it.
+type SOL struct {
+	client  *Client
+	maxData int
+
+	// writeMutex serializes writes, since only one packet of character data may be outstanding.
+	writeMutex sync.Mutex
+	sequence   uint8
+	acks       chan SOLPayload
+
+	// mutex protects the received data and the closed state.
+	mutex        sync.Mutex
+	buffer       bytes.Buffer
+	readable     chan struct{}
+	lastSequence uint8
+	closed       bool
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+// ActivateSOL activates the SOL payload on the session. The BMC must send the payload on the port of the session.
+func (client *Client) ActivateSOL() (*SOL, error) {
+	glog.V(100).Infof("Activating sol payload on ipmi session 0x%08x", client.bmcSessionID)
+
+	data, err := client.Command(
+		NetFnApp, CommandActivatePayload, []byte{byte(PayloadTypeSOL), solInstance, solActivationFlags, 0, 0, 0})
+	if err != nil {
+		return nil, fmt.Errorf("failed to activate sol payload: %w", err)
+	}
+
+	if len(data) < 12 {
+		return nil, fmt.Errorf("activate payload response of %d bytes is too short", len(data))
+	}
+
+	port := int(binary.LittleEndian.Uint16(data[8:10]))
+	if address, ok := client.conn.RemoteAddr().(*net.UDPAddr); ok && port != 0 && port != address.Port {
+		return nil, fmt.Errorf("sol payload port %d differs from the session port %d", port, address.Port)
+	}
+
+	sol := &SOL{
+		client:   client,
+		maxData:  int(binary.LittleEndian.Uint16(data[4:6])) - 4,
+		acks:     make(chan SOLPayload, 4),
+		readable: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	if sol.maxData <= 0 {
+		sol.maxData = solDefaultMaxData
+	}
+
+	client.mutex.Lock()
+	client.sol = sol
+	client.mutex.Unlock()
+
+	return sol, nil
+}
+
+// Read reads the output of the serial console. It blocks until output is available and returns io.EOF once the
+// payload is deactivated and all output has been read.
+func (sol *SOL) Read(data []byte) (int, error) {
+	for {
+		sol.mutex.Lock()
+
+		if sol.buffer.Len() > 0 {
+			length, _ := sol.buffer.Read(data)
+			sol.mutex.Unlock()
+
+			return length, nil
+		}
+
+		closed := sol.closed
+		sol.mutex.Unlock()
+
+		if closed {
+			return 0, io.EOF
+		}
+
+		select {
+		case <-sol.readable:
+		case <-sol.done:
+		case <-sol.client.done:
+			sol.markClosed()
+		}
+	}
+}
+
+// Write writes to the input of the serial console. It returns once the BMC acknowledged all of the data.
+func (sol *SOL) Write(data []byte) (int, error) {
+	sol.writeMutex.Lock()
+	defer sol.writeMutex.Unlock()
+
+	written := 0
+
+	for written < len(data) {
+		accepted, err := sol.sendData(data[written:min(len(data), written+sol.maxData)])
+		written += accepted
+
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// Close deactivates the SOL payload. It is safe to call Close more than once.
+func (sol *SOL) Close() error {
+	var err error
+
+	sol.closeOnce.Do(func() {
+		sol.client.mutex.Lock()
+		if sol.client.sol == sol {
+			sol.client.sol = nil
+		}
+		sol.client.mutex.Unlock()
+
+		// The payload does not need to be deactivated if the BMC already did.
+		if !sol.isClosed() {
+			_, err = sol.client.Command(
+				NetFnApp, CommandDeactivatePayload, []byte{byte(PayloadTypeSOL), solInstance, 0, 0, 0, 0})
+		}
+
+		sol.markClosed()
+	})
+
+	return err
+}
+
+// sendData sends a packet of character data and waits for the BMC to acknowledge it, returning the number of
+// characters it accepted.
+func (sol *SOL) sendData(chunk []byte) (int, error) {
+	sol.sequence = sol.sequence%15 + 1
+	payload := SOLPayload{Sequence: sol.sequence, Data: chunk}.Marshal()
+
+	for range solRetries + 1 {
+		if sol.isClosed() {
+			return 0, io.ErrClosedPipe
+		}
+
+		err := sol.client.send(PayloadTypeSOL, payload)
+		if err != nil {
+			return 0, err
+		}
+
+		ack, err := sol.waitAck(sol.sequence)
+		if errors.Is(err, os.ErrDeadlineExceeded) || (err == nil && ack.Status&SOLStatusNack != 0) {
+			continue
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		accepted := int(ack.AcceptedCount)
+		if accepted == 0 || accepted > len(chunk) {
+			accepted = len(chunk)
+		}
+
+		return accepted, nil
+	}
+
+	return 0, fmt.Errorf("sol data was not acknowledged after %d attempts: %w", solRetries+1, os.ErrDeadlineExceeded)
+}
+
+// waitAck waits for the acknowledgement of the packet with the sequence number.
+func (sol *SOL) waitAck(sequence uint8) (SOLPayload, error) {
+	timer := time.NewTimer(sol.client.timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case ack := <-sol.acks:
+			if ack.AckSequence == sequence {
+				return ack, nil
+			}
+		case <-timer.C:
+			return SOLPayload{}, os.ErrDeadlineExceeded
+		case <-sol.done:
+			return SOLPayload{}, io.ErrClosedPipe
+		case <-sol.client.done:
+			return SOLPayload{}, net.ErrClosed
+		}
+	}
+}
+
+// receive handles a SOL payload sent by the BMC. It is called by the read loop of the client.
+func (sol *SOL) receive(data []byte) {
+	payload, err := UnmarshalSOLPayload(data)
+	if err != nil {
+		glog.V(100).Infof("Ignoring invalid sol payload: %v", err)
+
+		return
+	}
+
+	if payload.AckSequence != 0 {
+		select {
+		case sol.acks <- payload:
+		default:
+		}
+	}
+
+	if payload.Sequence != 0 {
+		sol.mutex.Lock()
+
+		// Packets are resent with the same sequence number when the acknowledgement is lost.
+		if payload.Sequence != sol.lastSequence {
+			sol.lastSequence = payload.Sequence
+			sol.buffer.Write(payload.Data)
+
+			select {
+			case sol.readable <- struct{}{}:
+			default:
+			}
+		}
+
+		sol.mutex.Unlock()
+
+		ack := SOLPayload{AckSequence: payload.Sequence, AcceptedCount: uint8(len(payload.Data))}
+		if err := sol.client.send(PayloadTypeSOL, ack.Marshal()); err != nil {
+			glog.V(100).Infof("Failed to acknowledge sol payload: %v", err)
+		}
+	}
+
+	if payload.Status&SOLStatusDeactivating != 0 {
+		glog.V(100).Infof("Sol payload deactivated by the bmc")
+
+		sol.markClosed()
+	}
+}
+
+// isClosed returns true if the payload was closed or deactivated by the BMC.
+func (sol *SOL) isClosed() bool {
+	sol.mutex.Lock()
+	defer sol.mutex.Unlock()
+
+	return sol.closed
+}
+
+// markClosed marks the payload as closed, waking up pending reads and writes.
+func (sol *SOL) markClosed() {
+	sol.mutex.Lock()
+	defer sol.mutex.Unlock()
+
+	if !sol.closed {
+		sol.closed = true
+		close(sol.done)
+	}
+}
@@ -0,0 +1,654 @@
+package bmc
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/golang/glog"
+	bmhv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+	"k8s.io/utils/ptr"
+)
+
+// bytesPerGigabyte is the number of bytes in a gigabyte, as used by the MinSizeGigabytes root device hint.
+const bytesPerGigabyte = 1000 * 1000 * 1000
+
+// PCIeIDs holds the PCI IDs of a PCIe function. IDs are formatted as reported by the BMC, usually as 0x-prefixed
+// hexadecimal numbers.
+type PCIeIDs struct {
+	// VendorID is the PCI vendor ID.
+	VendorID string
+	// DeviceID is the PCI device ID.
+	DeviceID string
+	// SubsystemVendorID is the PCI subsystem vendor ID.
+	SubsystemVendorID string
+	// SubsystemID is the PCI subsystem ID.
+	SubsystemID string
+}
+
+// NetworkPort holds the inventory of a port of a network adapter.
+type NetworkPort struct {
+	// Chassis is the ID of the chassis the network adapter belongs to.
+	Chassis string
+	// Adapter is the ID of the network adapter.
+	Adapter string
+	// Manufacturer is the manufacturer of the network adapter.
+	Manufacturer string
+	// Model is the model of the network adapter.
+	Model string
+	// ID is the ID of the port.
+	ID string
+	// PhysicalPortNumber is the physical port number printed on the network adapter.
+	PhysicalPortNumber string
+	// MACAddress is the permanent MAC address of the port, in lowercase colon separated form.
+	MACAddress string
+	// LinkStatus is the link state of the port.
+	LinkStatus redfish.NetworkPortLinkStatus
+	// SpeedMbps is the current link speed of the port in Mbps.
+	SpeedMbps int
+	// MaxVirtualFunctions is the number of SR-IOV virtual functions the port supports, or 0 if unknown.
+	MaxVirtualFunctions int
+	// PCIe holds the PCI IDs of the function backing the port.
+	PCIe PCIeIDs
+}
+
+// StorageController holds the inventory of a storage controller.
+type StorageController struct {
+	// Storage is the ID of the storage subsystem the controller belongs to.
+	Storage string
+	// ID is the ID of the controller.
+	ID string
+	// Name is the name of the controller.
+	Name string
+	// Manufacturer is the manufacturer of the controller.
+	Manufacturer string
+	// Model is the model of the controller.
+	Model string
+	// SerialNumber is the serial number of the controller.
+	SerialNumber string
+	// FirmwareVersion is the version of the firmware of the controller.
+	FirmwareVersion string
+	// SpeedGbps is the speed of the controller interface in Gbps.
+	SpeedGbps float32
+}
+
+// Drive holds the inventory of a drive attached to a storage subsystem.
+type Drive struct {
+	// Storage is the ID of the storage subsystem the drive is attached to.
+	Storage string
+	// ID is the ID of the drive.
+	ID string
+	// Name is the name of the drive.
+	Name string
+	// Manufacturer is the manufacturer of the drive.
+	Manufacturer string
+	// Model is the model of the drive.
+	Model string
+	// SerialNumber is the serial number of the drive.
+	SerialNumber string
+	// WWN is the world wide name of the drive as reported by Linux, for example 0x5000c500a0b1c2d3 for NAA names
+	// or eui.0025388b91b3a2f1 for EUI names. It is empty if the BMC does not report it.
+	WWN string
+	// CapacityBytes is the size of the drive in bytes.
+	CapacityBytes int64
+	// MediaType is the media type of the drive, for example HDD or SSD.
+	MediaType redfish.MediaType
+	// Protocol is the protocol used to access the drive, for example SATA, SAS or NVMe.
+	Protocol common.Protocol
+}
+
+// Processor holds the inventory of a processor.
+type Processor struct {
+	// ID is the ID of the processor.
+	ID string
+	// Socket is the socket or location of the processor.
+	Socket string
+	// Type is the type of the processor, for example CPU or GPU.
+	Type redfish.ProcessorType
+	// Architecture is the architecture of the processor, for example x86.
+	Architecture redfish.ProcessorArchitecture
+	// Manufacturer is the manufacturer of the processor.
+	Manufacturer string
+	// Model is the model of the processor.
+	Model string
+	// TotalCores is the number of cores of the processor.
+	TotalCores int
+	// TotalThreads is the number of threads of the processor.
+	TotalThreads int
+	// MaxSpeedMHz is the maximum clock speed of the processor in MHz.
+	MaxSpeedMHz float32
+}
+
+// MemoryModule holds the inventory of a memory module.
+type MemoryModule struct {
+	// ID is the ID of the memory module.
+	ID string
+	// DeviceLocator is the location of the memory module, for example the DIMM slot.
+	DeviceLocator string
+	// Manufacturer is the manufacturer of the memory module.
+	Manufacturer string
+	// PartNumber is the part number of the memory module.
+	PartNumber string
+	// SerialNumber is the serial number of the memory module.
+	SerialNumber string
+	// DeviceType is the type of the memory module, for example DDR4.
+	DeviceType redfish.MemoryDeviceType
+	// CapacityMiB is the size of the memory module in MiB.
+	CapacityMiB int
+	// OperatingSpeedMhz is the speed of the memory module in MHz.
+	OperatingSpeedMhz int
+}
+
+// NetworkPorts returns the ports of the network adapters of every chassis, sorted by chassis, adapter and port ID,
+// using the Redfish API.
+func (bmc *BMC) NetworkPorts() ([]NetworkPort, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Info("Getting network ports from bmc's redfish endpoint")
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	ports, err := redfishGetNetworkPorts(redfishClient)
+	if err != nil {
+		glog.V(100).Infof("Failed to get network ports: %v", err)
+
+		return nil, err
+	}
+
+	return ports, nil
+}
+
+// StorageControllers returns the storage controllers of the system, sorted by storage subsystem and controller ID,
+// using the Redfish API.
+func (bmc *BMC) StorageControllers() ([]StorageController, error) {
+	controllers, _, err := bmc.getStorageInventory()
+	if err != nil {
+		return nil, err
+	}
+
+	return controllers, nil
+}
+
+// Drives returns the drives attached to the storage subsystems of the system, sorted by storage subsystem and drive
+// ID, using the Redfish API.
+func (bmc *BMC) Drives() ([]Drive, error) {
+	_, drives, err := bmc.getStorageInventory()
+	if err != nil {
+		return nil, err
+	}
+
+	return drives, nil
+}
+
+// Processors returns the processors of the system, sorted by ID, using the Redfish API.
+func (bmc *BMC) Processors() ([]Processor, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Info("Getting processors from bmc's redfish endpoint")
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	system, err := redfishGetSystem(redfishClient, bmc.systemIndex)
+	if err != nil {
+		glog.V(100).Infof("Failed to get redfish system: %v", err)
+
+		return nil, fmt.Errorf("failed to get redfish system: %w", err)
+	}
+
+	systemProcessors, err := system.Processors()
+	if err != nil {
+		glog.V(100).Infof("Failed to get processors: %v", err)
+
+		return nil, fmt.Errorf("failed to get processors: %w", err)
+	}
+
+	var processors []Processor
+
+	for _, processor := range systemProcessors {
+		processors = append(processors, Processor{
+			ID:           processor.ID,
+			Socket:       processor.Socket,
+			Type:         processor.ProcessorType,
+			Architecture: processor.ProcessorArchitecture,
+			Manufacturer: processor.Manufacturer,
+			Model:        processor.Model,
+			TotalCores:   processor.TotalCores,
+			TotalThreads: processor.TotalThreads,
+			MaxSpeedMHz:  processor.MaxSpeedMHz,
+		})
+	}
+
+	slices.SortFunc(processors, func(first, second Processor) int {
+		return strings.Compare(first.ID, second.ID)
+	})
+
+	return processors, nil
+}
+
+// MemoryModules returns the memory modules of the system, sorted by ID, using the Redfish API.
+func (bmc *BMC) MemoryModules() ([]MemoryModule, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Info("Getting memory modules from bmc's redfish endpoint")
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	system, err := redfishGetSystem(redfishClient, bmc.systemIndex)
+	if err != nil {
+		glog.V(100).Infof("Failed to get redfish system: %v", err)
+
+		return nil, fmt.Errorf("failed to get redfish system: %w", err)
+	}
+
+	systemMemory, err := system.Memory()
+	if err != nil {
+		glog.V(100).Infof("Failed to get memory: %v", err)
+
+		return nil, fmt.Errorf("failed to get memory: %w", err)
+	}
+
+	var modules []MemoryModule
+
+	for _, memory := range systemMemory {
+		modules = append(modules, MemoryModule{
+			ID:                memory.ID,
+			DeviceLocator:     memory.DeviceLocator,
+			Manufacturer:      memory.Manufacturer,
+			PartNumber:        memory.PartNumber,
+			SerialNumber:      memory.SerialNumber,
+			DeviceType:        memory.MemoryDeviceType,
+			CapacityMiB:       memory.CapacityMiB,
+			OperatingSpeedMhz: memory.OperatingSpeedMhz,
+		})
+	}
+
+	slices.SortFunc(modules, func(first, second MemoryModule) int {
+		return strings.Compare(first.ID, second.ID)
+	})
+
+	return modules, nil
+}
+
+// RootDeviceHints returns the bmh root device hints that select the drive. The most specific identifier the BMC
+// reports is used: the WWN, then the serial number, and otherwise the model and size. Rotational is set for HDD and
+// SSD drives. The result can be assigned to the RootDeviceHints of a bmh.BmhBuilder definition.
+func RootDeviceHints(drive Drive) *bmhv1alpha1.RootDeviceHints {
+	hints := &bmhv1alpha1.RootDeviceHints{}
+
+	switch {
+	case drive.WWN != "":
+		hints.WWN = drive.WWN
+	case drive.SerialNumber != "":
+		hints.SerialNumber = drive.SerialNumber
+	default:
+		hints.Model = drive.Model
+		hints.MinSizeGigabytes = int(drive.CapacityBytes / bytesPerGigabyte)
+	}
+
+	switch drive.MediaType {
+	case redfish.HDDMediaType:
+		hints.Rotational = ptr.To(true)
+	case redfish.SSDMediaType:
+		hints.Rotational = ptr.To(false)
+	default:
+	}
+
+	return hints
+}
+
+// NetworkPortsByMAC returns the ports keyed by their MAC address. Ports without a MAC address are skipped.
+func NetworkPortsByMAC(ports []NetworkPort) map[string]NetworkPort {
+	portsByMAC := make(map[string]NetworkPort)
+
+	for _, port := range ports {
+		if port.MACAddress != "" {
+			portsByMAC[port.MACAddress] = port
+		}
+	}
+
+	return portsByMAC
+}
+
+// MACToInterfaceMap returns the names of the host interfaces keyed by the MAC address of the ports, matching the
+// ports against the NICs found by bmh hardware inspection, for example to select the physical functions of a
+// sriov.PolicyBuilder. Ports without a matching NIC are skipped.
+func MACToInterfaceMap(ports []NetworkPort, nics []bmhv1alpha1.NIC) map[string]string {
+	interfaces := make(map[string]string)
+
+	for _, nic := range nics {
+		interfaces[normalizeMACAddress(nic.MAC)] = nic.Name
+	}
+
+	macToInterface := make(map[string]string)
+
+	for _, port := range ports {
+		if port.MACAddress == "" {
+			continue
+		}
+
+		if name, found := interfaces[port.MACAddress]; found {
+			macToInterface[port.MACAddress] = name
+		}
+	}
+
+	return macToInterface
+}
+
+// getStorageInventory returns the storage controllers and drives of the system using the Redfish API.
+func (bmc *BMC) getStorageInventory() ([]StorageController, []Drive, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, nil, err
+	}
+
+	glog.V(100).Info("Getting storage inventory from bmc's redfish endpoint")
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	system, err := redfishGetSystem(redfishClient, bmc.systemIndex)
+	if err != nil {
+		glog.V(100).Infof("Failed to get redfish system: %v", err)
+
+		return nil, nil, fmt.Errorf("failed to get redfish system: %w", err)
+	}
+
+	controllers, drives, err := redfishGetStorageInventory(system)
+	if err != nil {
+		glog.V(100).Infof("Failed to get storage inventory: %v", err)
+
+		return nil, nil, err
+	}
+
+	return controllers, drives, nil
+}
+
+// redfishGetStorageInventory returns the storage controllers and drives of the storage subsystems of the system,
+// sorted by storage subsystem and ID.
+func redfishGetStorageInventory(system *redfish.ComputerSystem) ([]StorageController, []Drive, error) {
+	storages, err := system.Storage()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get storage: %w", err)
+	}
+
+	var (
+		controllers []StorageController
+		drives      []Drive
+	)
+
+	for _, storage := range storages {
+		storageControllers, err := redfishGetStorageControllers(storage)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get controllers of storage %s: %w", storage.ID, err)
+		}
+
+		for _, controller := range storageControllers {
+			controllers = append(controllers, StorageController{
+				Storage:         storage.ID,
+				ID:              controller.ID,
+				Name:            controller.Name,
+				Manufacturer:    controller.Manufacturer,
+				Model:           controller.Model,
+				SerialNumber:    controller.SerialNumber,
+				FirmwareVersion: controller.FirmwareVersion,
+				SpeedGbps:       controller.SpeedGbps,
+			})
+		}
+
+		storageDrives, err := storage.Drives()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get drives of storage %s: %w", storage.ID, err)
+		}
+
+		for _, drive := range storageDrives {
+			drives = append(drives, Drive{
+				Storage:       storage.ID,
+				ID:            drive.ID,
+				Name:          drive.Name,
+				Manufacturer:  drive.Manufacturer,
+				Model:         drive.Model,
+				SerialNumber:  drive.SerialNumber,
+				WWN:           getDriveWWN(drive.Identifiers),
+				CapacityBytes: drive.CapacityBytes,
+				MediaType:     drive.MediaType,
+				Protocol:      drive.Protocol,
+			})
+		}
+	}
+
+	slices.SortFunc(controllers, func(first, second StorageController) int {
+		return cmp.Or(strings.Compare(first.Storage, second.Storage), strings.Compare(first.ID, second.ID))
+	})
+
+	slices.SortFunc(drives, func(first, second Drive) int {
+		return cmp.Or(strings.Compare(first.Storage, second.Storage), strings.Compare(first.ID, second.ID))
+	})
+
+	return controllers, drives, nil
+}
+
+// redfishGetNetworkPorts returns the ports of the network adapters of every chassis, sorted by chassis, adapter and
+// port ID. The MAC address, SR-IOV capabilities and PCI IDs come from the network device functions assigned to the
+// port, falling back to the addresses reported by the port itself.
+func redfishGetNetworkPorts(redfishClient *gofish.APIClient) ([]NetworkPort, error) {
+	chassisCollection, err := redfishClient.GetService().Chassis()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chassis collection: %w", err)
+	}
+
+	var ports []NetworkPort
+
+	for _, chassis := range chassisCollection {
+		adapters, err := chassis.NetworkAdapters()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get network adapters of chassis %s: %w", chassis.ID, err)
+		}
+
+		for _, adapter := range adapters {
+			adapterPorts, err := redfishGetNetworkAdapterPorts(chassis.ID, adapter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get ports of network adapter %s: %w", adapter.ID, err)
+			}
+
+			ports = append(ports, adapterPorts...)
+		}
+	}
+
+	slices.SortFunc(ports, func(first, second NetworkPort) int {
+		return cmp.Or(
+			strings.Compare(first.Chassis, second.Chassis),
+			strings.Compare(first.Adapter, second.Adapter),
+			strings.Compare(first.ID, second.ID))
+	})
+
+	return ports, nil
+}
+
+// redfishGetNetworkAdapterPorts returns the ports of a network adapter, including the details of the network device
+// functions assigned to them.
+func redfishGetNetworkAdapterPorts(chassisID string, adapter *redfish.NetworkAdapter) ([]NetworkPort, error) {
+	networkPorts, err := adapter.NetworkPorts()
+	if err != nil {
+		return nil, err
+	}
+
+	ports := make(map[string]*NetworkPort)
+
+	for _, networkPort := range networkPorts {
+		port := &NetworkPort{
+			Chassis:            chassisID,
+			Adapter:            adapter.ID,
+			Manufacturer:       adapter.Manufacturer,
+			Model:              adapter.Model,
+			ID:                 networkPort.ID,
+			PhysicalPortNumber: networkPort.PhysicalPortNumber,
+			LinkStatus:         networkPort.LinkStatus,
+			SpeedMbps:          networkPort.CurrentLinkSpeedMbps,
+		}
+
+		if len(networkPort.AssociatedNetworkAddresses) > 0 {
+			port.MACAddress = normalizeMACAddress(networkPort.AssociatedNetworkAddresses[0])
+		}
+
+		ports[networkPort.ODataID] = port
+	}
+
+	functions, err := adapter.NetworkDeviceFunctions()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, function := range functions {
+		networkPort, err := function.PhysicalPortAssignment()
+		if err != nil {
+			return nil, err
+		}
+
+		if networkPort == nil || ports[networkPort.ODataID] == nil {
+			continue
+		}
+
+		port := ports[networkPort.ODataID]
+		port.MaxVirtualFunctions = function.MaxVirtualFunctions
+
+		if macAddress := cmp.Or(function.Ethernet.PermanentMACAddress, function.Ethernet.MACAddress); macAddress != "" {
+			port.MACAddress = normalizeMACAddress(macAddress)
+		}
+
+		pcieFunction, err := function.PCIeFunction()
+		if err != nil {
+			return nil, err
+		}
+
+		if pcieFunction != nil {
+			port.PCIe = PCIeIDs{
+				VendorID:          pcieFunction.VendorID,
+				DeviceID:          pcieFunction.DeviceID,
+				SubsystemVendorID: pcieFunction.SubsystemVendorID,
+				SubsystemID:       pcieFunction.SubsystemID,
+			}
+		}
+	}
+
+	var adapterPorts []NetworkPort
+
+	for _, port := range ports {
+		adapterPorts = append(adapterPorts, *port)
+	}
+
+	return adapterPorts, nil
+}
+
+// redfishGetStorageControllers returns the controllers of a storage subsystem, using the Controllers collection if
+// the BMC reports it and the deprecated StorageControllers property otherwise.
+func redfishGetStorageControllers(storage *redfish.Storage) ([]redfish.StorageController, error) {
+	controllers, err := storage.Controllers()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(controllers) == 0 {
+		return storage.StorageControllers, nil
+	}
+
+	var storageControllers []redfish.StorageController
+
+	for _, controller := range controllers {
+		storageControllers = append(storageControllers, *controller)
+	}
+
+	return storageControllers, nil
+}
+
+// getDriveWWN returns the WWN of a drive from its identifiers in the form Linux reports it, or an empty string if the
+// drive has no NAA or EUI identifier.
+func getDriveWWN(identifiers []common.Identifier) string {
+	for _, identifier := range identifiers {
+		durableName := strings.ToLower(strings.ReplaceAll(identifier.DurableName, ":", ""))
+
+		switch identifier.DurableNameFormat {
+		case common.NAADurableNameFormat:
+			return "0x" + strings.TrimPrefix(durableName, "0x")
+		case common.EUIDurableNameFormat:
+			return "eui." + strings.TrimPrefix(durableName, "eui.")
+		default:
+		}
+	}
+
+	return ""
+}
+
+// normalizeMACAddress returns the MAC address in lowercase colon separated form, or lowercased if it cannot be parsed.
+func normalizeMACAddress(macAddress string) string {
+	hardwareAddr, err := net.ParseMAC(macAddress)
+	if err != nil {
+		return strings.ToLower(macAddress)
+	}
+
+	return hardwareAddr.String()
+}
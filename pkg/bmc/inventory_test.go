@@ -0,0 +1,201 @@
+package bmc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	bmhv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+func TestBMCNetworkPorts(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+
+	ports, err := New(host).WithRedfishUser(defaultUsername, defaultPassword).NetworkPorts()
+	assert.Nil(t, err)
+	assert.Equal(t, []NetworkPort{
+		{
+			Chassis:             "System.Embedded.1",
+			Adapter:             "NIC.Integrated.1",
+			Manufacturer:        "Intel Corporation",
+			Model:               "Intel(R) Ethernet 25G 2P E810-XXV OCP",
+			ID:                  "NIC.Integrated.1-1",
+			PhysicalPortNumber:  "1",
+			MACAddress:          "b4:96:91:c8:6a:20",
+			LinkStatus:          redfish.UpPortLinkStatus,
+			SpeedMbps:           25000,
+			MaxVirtualFunctions: 128,
+			PCIe: PCIeIDs{
+				VendorID:          "0x8086",
+				DeviceID:          "0x159b",
+				SubsystemVendorID: "0x8086",
+				SubsystemID:       "0x0001",
+			},
+		},
+		{
+			Chassis:            "System.Embedded.1",
+			Adapter:            "NIC.Integrated.1",
+			Manufacturer:       "Intel Corporation",
+			Model:              "Intel(R) Ethernet 25G 2P E810-XXV OCP",
+			ID:                 "NIC.Integrated.1-2",
+			PhysicalPortNumber: "2",
+			MACAddress:         "b4:96:91:c8:6a:21",
+			LinkStatus:         redfish.DownPortLinkStatus,
+		},
+	}, ports)
+
+	_, err = New(host).NetworkPorts()
+	assert.Equal(t, fmt.Errorf("cannot access redfish with nil user"), err)
+}
+
+func TestBMCStorageInventory(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+	controllers, err := bmc.StorageControllers()
+	assert.Nil(t, err)
+	assert.Equal(t, []StorageController{{
+		Storage:         "RAID.Integrated.1-1",
+		ID:              "RAID.Integrated.1-1",
+		Name:            "PERC H755 Front",
+		Manufacturer:    "DELL",
+		Model:           "PERC H755 Front",
+		FirmwareVersion: "52.16.1-4405",
+		SpeedGbps:       12,
+	}}, controllers)
+
+	drives, err := bmc.Drives()
+	assert.Nil(t, err)
+	assert.Equal(t, []Drive{
+		{
+			Storage:       "RAID.Integrated.1-1",
+			ID:            "Disk.Bay.0",
+			Name:          "Drive 0",
+			Manufacturer:  "SAMSUNG",
+			Model:         "SAMSUNG MZ7L3480HBLTAD3",
+			SerialNumber:  "S6KMNE0T401234",
+			WWN:           "0x5002538f41234567",
+			CapacityBytes: 480103981056,
+			MediaType:     redfish.SSDMediaType,
+			Protocol:      common.SATAProtocol,
+		},
+		{
+			Storage:       "RAID.Integrated.1-1",
+			ID:            "Disk.Bay.1",
+			Name:          "Drive 1",
+			Manufacturer:  "SEAGATE",
+			Model:         "SEAGATE ST2000NM015A",
+			SerialNumber:  "ZFN0ABCD",
+			CapacityBytes: 2000398934016,
+			MediaType:     redfish.HDDMediaType,
+			Protocol:      common.SASProtocol,
+		},
+	}, drives)
+
+	_, err = New(host).Drives()
+	assert.Equal(t, fmt.Errorf("cannot access redfish with nil user"), err)
+}
+
+func TestBMCProcessorsAndMemory(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+	processors, err := bmc.Processors()
+	assert.Nil(t, err)
+	assert.Len(t, processors, 2)
+	assert.Equal(t, Processor{
+		ID:           "CPU.Socket.1",
+		Socket:       "CPU.Socket.1",
+		Type:         redfish.CPUProcessorType,
+		Architecture: redfish.X86ProcessorArchitecture,
+		Manufacturer: "Intel",
+		Model:        "Intel(R) Xeon(R) Gold 6330 CPU @ 2.00GHz",
+		TotalCores:   28,
+		TotalThreads: 56,
+		MaxSpeedMHz:  4000,
+	}, processors[0])
+	assert.Equal(t, "CPU.Socket.2", processors[1].ID)
+
+	modules, err := bmc.MemoryModules()
+	assert.Nil(t, err)
+	assert.Equal(t, []MemoryModule{{
+		ID:                "DIMM.Socket.A1",
+		DeviceLocator:     "DIMM A1",
+		Manufacturer:      "Hynix Semiconductor",
+		PartNumber:        "HMA84GR7DJR4N-XN",
+		SerialNumber:      "3506F0D1",
+		DeviceType:        redfish.DDR4MemoryDeviceType,
+		CapacityMiB:       32768,
+		OperatingSpeedMhz: 3200,
+	}}, modules)
+
+	_, err = New(host).WithRedfishUser(defaultUsername, defaultPassword).WithRedfishSystemIndex(1).Processors()
+	assert.ErrorContains(t, err, "failed to get redfish system")
+}
+
+func TestRootDeviceHints(t *testing.T) {
+	testCases := []struct {
+		drive         Drive
+		expectedHints *bmhv1alpha1.RootDeviceHints
+	}{
+		{
+			drive:         Drive{WWN: "0x5002538f41234567", SerialNumber: "S6KMNE0T401234", MediaType: redfish.SSDMediaType},
+			expectedHints: &bmhv1alpha1.RootDeviceHints{WWN: "0x5002538f41234567", Rotational: ptr.To(false)},
+		},
+		{
+			drive:         Drive{SerialNumber: "ZFN0ABCD", MediaType: redfish.HDDMediaType},
+			expectedHints: &bmhv1alpha1.RootDeviceHints{SerialNumber: "ZFN0ABCD", Rotational: ptr.To(true)},
+		},
+		{
+			drive:         Drive{Model: "Virtual Disk", CapacityBytes: 480103981056},
+			expectedHints: &bmhv1alpha1.RootDeviceHints{Model: "Virtual Disk", MinSizeGigabytes: 480},
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expectedHints, RootDeviceHints(testCase.drive))
+	}
+}
+
+func TestMACToInterfaceMap(t *testing.T) {
+	ports := []NetworkPort{
+		{ID: "NIC.Integrated.1-1", MACAddress: "b4:96:91:c8:6a:20"},
+		{ID: "NIC.Integrated.1-2", MACAddress: "b4:96:91:c8:6a:21"},
+		{ID: "NIC.Slot.3-1"},
+	}
+
+	nics := []bmhv1alpha1.NIC{
+		{Name: "ens1f0", MAC: "B4:96:91:C8:6A:20"},
+		{Name: "eno1", MAC: "b4:96:91:c8:00:01"},
+	}
+
+	assert.Equal(t, map[string]string{"b4:96:91:c8:6a:20": "ens1f0"}, MACToInterfaceMap(ports, nics))
+
+	portsByMAC := NetworkPortsByMAC(ports)
+	assert.Len(t, portsByMAC, 2)
+	assert.Equal(t, "NIC.Integrated.1-2", portsByMAC["b4:96:91:c8:6a:21"].ID)
+}
+
+func TestGetDriveWWN(t *testing.T) {
+	assert.Equal(t, "0x5000c500a0b1c2d3", getDriveWWN([]common.Identifier{
+		{DurableName: "50:00:C5:00:A0:B1:C2:D3", DurableNameFormat: common.NAADurableNameFormat},
+	}))
+	assert.Equal(t, "eui.0025388b91b3a2f1", getDriveWWN([]common.Identifier{
+		{DurableName: "serial", DurableNameFormat: common.UUIDDurableNameFormat},
+		{DurableName: "0025388B91B3A2F1", DurableNameFormat: common.EUIDurableNameFormat},
+	}))
+	assert.Equal(t, "", getDriveWWN(nil))
+}
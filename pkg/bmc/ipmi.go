@@ -0,0 +1,336 @@
+package bmc
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/bmc/internal/ipmi"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+// Backend is the protocol the BMC uses for power control, boot device override, and the serial console.
+type Backend string
+
+const (
+	// BackendRedfish uses the Redfish API for power control and boot override, and the CLI over SSH for the serial
+	// console. It is the default backend.
+	BackendRedfish Backend = "redfish"
+	// BackendIPMI uses IPMI v2.0 over LAN (lanplus) for power control, boot override, and Serial over LAN for the
+	// serial console. It is meant for BMCs with broken or partial Redfish support.
+	BackendIPMI Backend = "ipmi"
+)
+
+// ipmiChassisControls are the chassis control actions used for each reset type with the IPMI backend.
+var ipmiChassisControls = map[redfish.ResetType]ipmi.ChassisControl{
+	redfish.OnResetType:               ipmi.ChassisControlPowerUp,
+	redfish.ForceOffResetType:         ipmi.ChassisControlPowerDown,
+	redfish.ForceRestartResetType:     ipmi.ChassisControlHardReset,
+	redfish.GracefulShutdownResetType: ipmi.ChassisControlSoftShutdown,
+	redfish.PowerCycleResetType:       ipmi.ChassisControlPowerCycle,
+	redfish.NmiResetType:              ipmi.ChassisControlDiagnosticInterrupt,
+}
+
+// ipmiBootDevices are the boot devices used for each boot source override target with the IPMI backend.
+var ipmiBootDevices = map[redfish.BootSourceOverrideTarget]ipmi.BootDevice{
+	redfish.NoneBootSourceOverrideTarget:      ipmi.BootDeviceNone,
+	redfish.PxeBootSourceOverrideTarget:       ipmi.BootDevicePXE,
+	redfish.HddBootSourceOverrideTarget:       ipmi.BootDeviceDisk,
+	redfish.CdBootSourceOverrideTarget:        ipmi.BootDeviceCDROM,
+	redfish.BiosSetupBootSourceOverrideTarget: ipmi.BootDeviceBIOSSetup,
+	redfish.FloppyBootSourceOverrideTarget:    ipmi.BootDeviceFloppy,
+	redfish.DiagsBootSourceOverrideTarget:     ipmi.BootDeviceDiagnostic,
+}
+
+// WithBackend selects the protocol used for power control, boot override, and the serial console. It should be either
+// BackendRedfish or BackendIPMI. Other methods, such as the ones for firmware and virtual media, always use Redfish.
+func (bmc *BMC) WithBackend(backend Backend) *BMC {
+	if valid, _ := bmc.validate(); !valid {
+		return bmc
+	}
+
+	glog.V(100).Infof("Setting BMC backend to %s", backend)
+
+	if backend != BackendRedfish && backend != BackendIPMI {
+		glog.V(100).Infof("The BMC backend %s is not supported", backend)
+
+		bmc.errorMsg = fmt.Sprintf("bmc 'backend' %q is not supported", backend)
+
+		return bmc
+	}
+
+	bmc.backend = backend
+
+	return bmc
+}
+
+// WithIPMIUser provides the credentials to use when connecting to the BMC over IPMI. The username should not be empty
+// or longer than 16 characters and the password should not be empty or longer than 20 characters, the limit for keys in
+// IPMI v2.0.
+func (bmc *BMC) WithIPMIUser(username, password string) *BMC {
+	if valid, _ := bmc.validate(); !valid {
+		return bmc
+	}
+
+	glog.V(100).Infof("Setting BMC IPMI username to %s", username)
+
+	if username == "" {
+		glog.V(100).Info("The IPMI username is empty")
+
+		bmc.errorMsg = "ipmi 'username' cannot be empty"
+
+		return bmc
+	}
+
+	if len(username) > 16 {
+		glog.V(100).Infof("The IPMI username %s is longer than 16 characters", username)
+
+		bmc.errorMsg = "ipmi 'username' cannot be longer than 16 characters"
+
+		return bmc
+	}
+
+	if password == "" {
+		glog.V(100).Info("The IPMI password is empty")
+
+		bmc.errorMsg = "ipmi 'password' cannot be empty"
+
+		return bmc
+	}
+
+	if len(password) > 20 {
+		glog.V(100).Info("The IPMI password is longer than 20 characters")
+
+		bmc.errorMsg = "ipmi 'password' cannot be longer than 20 characters"
+
+		return bmc
+	}
+
+	bmc.ipmiUser = &User{
+		Name:     username,
+		Password: password,
+	}
+
+	return bmc
+}
+
+// WithIPMIPort provides the UDP port to use when connecting to the BMC over IPMI. It should not be zero.
+func (bmc *BMC) WithIPMIPort(port uint16) *BMC {
+	if valid, _ := bmc.validate(); !valid {
+		return bmc
+	}
+
+	glog.V(100).Infof("Setting IPMI port to %d", port)
+
+	if port == 0 {
+		glog.V(100).Infof("The IPMI port is zero")
+
+		bmc.errorMsg = "ipmi 'port' cannot be zero"
+
+		return bmc
+	}
+
+	bmc.ipmiPort = port
+
+	return bmc
+}
+
+// WithIPMITimeout provides the timeout for each request sent to the BMC over IPMI. It should not be zero or negative.
+func (bmc *BMC) WithIPMITimeout(timeout time.Duration) *BMC {
+	if valid, _ := bmc.validate(); !valid {
+		return bmc
+	}
+
+	if timeout <= 0 {
+		glog.V(100).Infof("The IPMI timeout %s is less than or equal to zero", timeout)
+
+		bmc.errorMsg = "ipmi 'timeout' cannot be less than or equal to zero"
+
+		return bmc
+	}
+
+	bmc.timeOuts.IPMI = timeout
+
+	return bmc
+}
+
+// usesIPMI returns true if IPMI is the backend of the BMC.
+func (bmc *BMC) usesIPMI() bool {
+	return bmc != nil && bmc.backend == BackendIPMI
+}
+
+// ipmiSystemResetAction performs the chassis control action matching the reset type.
+func (bmc *BMC) ipmiSystemResetAction(action redfish.ResetType) error {
+	if valid, err := bmc.validateIPMI(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Performing reset action %v over ipmi", action)
+
+	control, ok := ipmiChassisControls[action]
+	if !ok {
+		glog.V(100).Infof("Reset type %v is not supported over ipmi", action)
+
+		return fmt.Errorf("reset type %v is not supported over ipmi", action)
+	}
+
+	client, err := bmc.ipmiConnect()
+	if err != nil {
+		return err
+	}
+
+	defer client.Close()
+
+	err = client.ChassisControl(control)
+	if err != nil {
+		glog.V(100).Infof("Failed to perform chassis control action: %v", err)
+
+		return fmt.Errorf("failed to perform chassis control action: %w", err)
+	}
+
+	return nil
+}
+
+// ipmiSystemPowerState returns the power state of the chassis, either On or Off.
+func (bmc *BMC) ipmiSystemPowerState() (string, error) {
+	if valid, err := bmc.validateIPMI(); !valid {
+		return "", err
+	}
+
+	glog.V(100).Info("Collecting current power state over ipmi")
+
+	client, err := bmc.ipmiConnect()
+	if err != nil {
+		return "", err
+	}
+
+	defer client.Close()
+
+	status, err := client.ChassisStatus()
+	if err != nil {
+		glog.V(100).Infof("Failed to get chassis status: %v", err)
+
+		return "", fmt.Errorf("failed to get chassis status: %w", err)
+	}
+
+	if status.PowerOn {
+		return string(redfish.OnPowerState), nil
+	}
+
+	return string(redfish.OffPowerState), nil
+}
+
+// ipmiSetBootOverride sets the boot flags matching the boot source override.
+func (bmc *BMC) ipmiSetBootOverride(
+	target redfish.BootSourceOverrideTarget,
+	enabled redfish.BootSourceOverrideEnabled,
+	mode redfish.BootSourceOverrideMode) error {
+	if valid, err := bmc.validateIPMI(); !valid {
+		return err
+	}
+
+	device, ok := ipmiBootDevices[target]
+	if !ok {
+		glog.V(100).Infof("Boot source override target %s is not supported over ipmi", target)
+
+		return fmt.Errorf("boot source override target %s is not supported over ipmi", target)
+	}
+
+	flags := ipmi.BootFlags{
+		Valid:      enabled != redfish.DisabledBootSourceOverrideEnabled,
+		Persistent: enabled == redfish.ContinuousBootSourceOverrideEnabled,
+		EFI:        mode == redfish.UEFIBootSourceOverrideMode,
+		Device:     device,
+	}
+
+	glog.V(100).Infof("Setting boot flags over ipmi: %+v", flags)
+
+	client, err := bmc.ipmiConnect()
+	if err != nil {
+		return err
+	}
+
+	defer client.Close()
+
+	err = client.SetBootFlags(flags)
+	if err != nil {
+		glog.V(100).Infof("Failed to set boot flags: %v", err)
+
+		return fmt.Errorf("failed to set boot flags: %w", err)
+	}
+
+	return nil
+}
+
+// ipmiOpenSerialConsole activates Serial over LAN on a new session, which is kept open until CloseSerialConsole is
+// called.
+func (bmc *BMC) ipmiOpenSerialConsole() (io.Reader, io.WriteCloser, error) {
+	if valid, err := bmc.validateIPMI(); !valid {
+		return nil, nil, err
+	}
+
+	glog.V(100).Infof("Opening serial over lan console on %v.", bmc.host)
+
+	if bmc.ipmiClientForSerialConsole != nil {
+		glog.V(100).Infof("There is already a serial console opened for %v's BMC.", bmc.host)
+
+		return nil, nil, fmt.Errorf("there is already a serial console opened for %v's BMC", bmc.host)
+	}
+
+	client, err := bmc.ipmiConnect()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sol, err := client.ActivateSOL()
+	if err != nil {
+		glog.V(100).Infof("Failed to activate serial over lan on %v: %v", bmc.host, err)
+
+		_ = client.Close()
+
+		return nil, nil, fmt.Errorf("failed to activate serial over lan on %v: %w", bmc.host, err)
+	}
+
+	bmc.ipmiClientForSerialConsole = client
+
+	return sol, sol, nil
+}
+
+// ipmiConnect establishes an IPMI session with the BMC. The port of the host, if any, is replaced by the IPMI port.
+func (bmc *BMC) ipmiConnect() (*ipmi.Client, error) {
+	host := bmc.host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+
+	client, err := ipmi.Dial(
+		net.JoinHostPort(host, strconv.Itoa(int(bmc.ipmiPort))),
+		bmc.ipmiUser.Name,
+		bmc.ipmiUser.Password,
+		bmc.timeOuts.IPMI)
+	if err != nil {
+		glog.V(100).Infof("IPMI connection error: %v", err)
+
+		return nil, fmt.Errorf("ipmi connection error: %w", err)
+	}
+
+	return client, nil
+}
+
+// validateIPMI performs the same validations as in validate but also checks for a valid ipmi user.
+func (bmc *BMC) validateIPMI() (bool, error) {
+	if valid, err := bmc.validate(); !valid {
+		return false, err
+	}
+
+	if bmc.ipmiUser == nil {
+		glog.V(100).Info("The BMC's IPMI user is nil")
+
+		return false, fmt.Errorf("cannot access ipmi with nil user")
+	}
+
+	return true, nil
+}
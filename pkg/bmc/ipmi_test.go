@@ -0,0 +1,146 @@
+package bmc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/bmc/internal/ipmi"
+	"github.com/stmcginnis/gofish/redfish"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBMCWithBackend(t *testing.T) {
+	testCases := []struct {
+		name           string
+		backend        Backend
+		expectedErrMsg string
+	}{
+		{
+			name:           "redfish backend",
+			backend:        BackendRedfish,
+			expectedErrMsg: "",
+		},
+		{
+			name:           "ipmi backend",
+			backend:        BackendIPMI,
+			expectedErrMsg: "",
+		},
+		{
+			name:           "unsupported backend",
+			backend:        "snmp",
+			expectedErrMsg: "bmc 'backend' \"snmp\" is not supported",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			bmc := New(defaultHost).WithBackend(testCase.backend)
+
+			assert.Equal(t, testCase.expectedErrMsg, bmc.errorMsg)
+
+			if testCase.expectedErrMsg == "" {
+				assert.Equal(t, testCase.backend, bmc.backend)
+			}
+		})
+	}
+}
+
+func TestBMCWithIPMIUser(t *testing.T) {
+	testCases := []struct {
+		name           string
+		username       string
+		password       string
+		expectedErrMsg string
+	}{
+		{
+			name:           "everything alright",
+			username:       defaultUsername,
+			password:       defaultPassword,
+			expectedErrMsg: "",
+		},
+		{
+			name:           "empty username",
+			username:       "",
+			password:       defaultPassword,
+			expectedErrMsg: "ipmi 'username' cannot be empty",
+		},
+		{
+			name:           "username too long",
+			username:       "administrator-user",
+			password:       defaultPassword,
+			expectedErrMsg: "ipmi 'username' cannot be longer than 16 characters",
+		},
+		{
+			name:           "empty password",
+			username:       defaultUsername,
+			password:       "",
+			expectedErrMsg: "ipmi 'password' cannot be empty",
+		},
+		{
+			name:           "password too long",
+			username:       defaultUsername,
+			password:       "a-password-of-21-char",
+			expectedErrMsg: "ipmi 'password' cannot be longer than 20 characters",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			bmc := New(defaultHost).WithIPMIUser(testCase.username, testCase.password)
+
+			assert.Equal(t, testCase.expectedErrMsg, bmc.errorMsg)
+
+			if testCase.expectedErrMsg == "" {
+				assert.Equal(t, &User{Name: testCase.username, Password: testCase.password}, bmc.ipmiUser)
+			}
+		})
+	}
+}
+
+func TestBMCWithIPMIPort(t *testing.T) {
+	assert.Equal(t, uint16(ipmi.DefaultPort), New(defaultHost).ipmiPort)
+
+	bmc := New(defaultHost).WithIPMIPort(6230)
+	assert.Equal(t, "", bmc.errorMsg)
+	assert.Equal(t, uint16(6230), bmc.ipmiPort)
+
+	bmc = New(defaultHost).WithIPMIPort(0)
+	assert.Equal(t, "ipmi 'port' cannot be zero", bmc.errorMsg)
+}
+
+func TestBMCWithIPMITimeout(t *testing.T) {
+	assert.Equal(t, defaultTimeOut, New(defaultHost).timeOuts.IPMI)
+
+	bmc := New(defaultHost).WithIPMITimeout(time.Second)
+	assert.Equal(t, "", bmc.errorMsg)
+	assert.Equal(t, time.Second, bmc.timeOuts.IPMI)
+
+	bmc = New(defaultHost).WithIPMITimeout(-1 * time.Second)
+	assert.Equal(t, "ipmi 'timeout' cannot be less than or equal to zero", bmc.errorMsg)
+}
+
+func TestBMCIPMIValidation(t *testing.T) {
+	bmc := New(defaultHost).WithBackend(BackendIPMI)
+
+	err := bmc.SystemPowerOn()
+	assert.Equal(t, fmt.Errorf("cannot access ipmi with nil user"), err)
+
+	_, err = bmc.SystemPowerState()
+	assert.Equal(t, fmt.Errorf("cannot access ipmi with nil user"), err)
+
+	err = bmc.WaitForSystemPowerState(redfish.OnPowerState, time.Second)
+	assert.Equal(t, fmt.Errorf("cannot access ipmi with nil user"), err)
+
+	_, _, err = bmc.OpenSerialConsole("")
+	assert.Equal(t, fmt.Errorf("cannot access ipmi with nil user"), err)
+
+	bmc = bmc.WithIPMIUser(defaultUsername, defaultPassword)
+
+	err = bmc.SystemResetAction(redfish.GracefulRestartResetType)
+	assert.Equal(t, fmt.Errorf("reset type GracefulRestart is not supported over ipmi"), err)
+
+	err = bmc.SetBootOverride(
+		redfish.UefiHTTPBootSourceOverrideTarget, redfish.OnceBootSourceOverrideEnabled, redfish.UEFIBootSourceOverrideMode)
+	assert.Equal(t, fmt.Errorf("boot source override target UefiHttp is not supported over ipmi"), err)
+}
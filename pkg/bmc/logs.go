@@ -0,0 +1,412 @@
+package bmc
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+// logCaptureClockSkew is how far before the local time entries are captured when the log service reports neither its
+// time nor any entry, to account for the clock of the BMC being behind the local one.
+const logCaptureClockSkew = 5 * time.Minute
+
+// LogService holds the information of a log service of the system or of its managers, such as the System Event Log.
+type LogService struct {
+	// ID is the identifier of the log service in the Redfish API, for example Sel.
+	ID string
+	// Name is the name of the log service.
+	Name string
+	// EntryType is the type of the entries of the log service, for example SEL or Event.
+	EntryType redfish.LogEntryTypes
+	// Enabled is true if the log service is enabled.
+	Enabled bool
+	// Manager is true if the log service belongs to a manager instead of the system.
+	Manager bool
+}
+
+// LogEntry holds a log entry of a log service.
+type LogEntry struct {
+	// ID is the identifier of the entry in the Redfish API.
+	ID string
+	// Created is the time the entry was created. It is zero if the BMC reported an invalid time.
+	Created time.Time
+	// Severity is the severity of the entry.
+	Severity redfish.EventSeverity
+	// MessageID is the identifier of the message in its message registry.
+	MessageID string
+	// Message is the human readable message of the entry.
+	Message string
+	// EntryType is the type of the entry, for example SEL or Event.
+	EntryType redfish.LogEntryType
+}
+
+// LogServices returns the log services of the system, followed by the log services of its managers, using the Redfish
+// API.
+func (bmc *BMC) LogServices() ([]LogService, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Info("Getting log services from bmc's redfish endpoint")
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	systemLogServices, managerLogServices, err := redfishGetLogServices(redfishClient, bmc.systemIndex)
+	if err != nil {
+		glog.V(100).Infof("Failed to get log services: %v", err)
+
+		return nil, err
+	}
+
+	var logServices []LogService
+
+	for _, logService := range systemLogServices {
+		logServices = append(logServices, newLogService(logService, false))
+	}
+
+	for _, logService := range managerLogServices {
+		logServices = append(logServices, newLogService(logService, true))
+	}
+
+	return logServices, nil
+}
+
+// LogEntries returns the entries of the log service with the provided ID created at or after since, sorted by creation
+// time, using the Redfish API. If since is zero, every entry is returned. Entries whose creation time could not be
+// parsed cannot be compared with since, so they are always returned, before the others. When the system and a manager
+// have a log service with the same ID, the one of the system is used.
+func (bmc *BMC) LogEntries(service string, since time.Time) ([]LogEntry, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting entries of log service %s since %s", service, since)
+
+	if service == "" {
+		glog.V(100).Info("The log service is empty")
+
+		return nil, fmt.Errorf("log 'service' cannot be empty")
+	}
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	logService, err := redfishGetLogService(redfishClient, bmc.systemIndex, service)
+	if err != nil {
+		glog.V(100).Infof("Failed to get log service %s: %v", service, err)
+
+		return nil, err
+	}
+
+	entries, err := logService.Entries()
+	if err != nil {
+		glog.V(100).Infof("Failed to get entries of log service %s: %v", service, err)
+
+		return nil, fmt.Errorf("failed to get entries of log service %s: %w", service, err)
+	}
+
+	var logEntries []LogEntry
+
+	for _, entry := range entries {
+		logEntry := newLogEntry(entry)
+
+		if !since.IsZero() && !logEntry.Created.IsZero() && logEntry.Created.Before(since) {
+			continue
+		}
+
+		logEntries = append(logEntries, logEntry)
+	}
+
+	slices.SortStableFunc(logEntries, func(first, second LogEntry) int {
+		return first.Created.Compare(second.Created)
+	})
+
+	return logEntries, nil
+}
+
+// ClearLog deletes all the entries of the log service with the provided ID using the Redfish API.
+func (bmc *BMC) ClearLog(service string) error {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Clearing log service %s", service)
+
+	if service == "" {
+		glog.V(100).Info("The log service is empty")
+
+		return fmt.Errorf("log 'service' cannot be empty")
+	}
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	logService, err := redfishGetLogService(redfishClient, bmc.systemIndex, service)
+	if err != nil {
+		glog.V(100).Infof("Failed to get log service %s: %v", service, err)
+
+		return err
+	}
+
+	err = logService.ClearLog()
+	if err != nil {
+		glog.V(100).Infof("Failed to clear log service %s: %v", service, err)
+
+		return fmt.Errorf("failed to clear log service %s: %w", service, err)
+	}
+
+	return nil
+}
+
+// LogServiceTime returns the current time of the log service with the provided ID using the Redfish API. Since the
+// clock of the BMC may differ from the local one, this time should be used when filtering the entries of the service.
+func (bmc *BMC) LogServiceTime(service string) (time.Time, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return time.Time{}, err
+	}
+
+	glog.V(100).Infof("Getting current time of log service %s", service)
+
+	if service == "" {
+		glog.V(100).Info("The log service is empty")
+
+		return time.Time{}, fmt.Errorf("log 'service' cannot be empty")
+	}
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return time.Time{}, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	logService, err := redfishGetLogService(redfishClient, bmc.systemIndex, service)
+	if err != nil {
+		glog.V(100).Infof("Failed to get log service %s: %v", service, err)
+
+		return time.Time{}, err
+	}
+
+	serviceTime, err := time.Parse(time.RFC3339, logService.DateTime)
+	if err != nil {
+		glog.V(100).Infof("Failed to parse time %q of log service %s: %v", logService.DateTime, service, err)
+
+		return time.Time{}, fmt.Errorf("failed to parse time of log service %s: %w", service, err)
+	}
+
+	return serviceTime, nil
+}
+
+// CaptureLogEntries runs action and returns the entries of the log service with the provided ID created while it ran,
+// according to the clock of the log service. If the log service does not report its time, entries created after its
+// newest entry are returned instead, and if it has no entries either, entries created since logCaptureClockSkew
+// before action ran according to the local clock. The entries are returned even if action fails, along with its
+// error, so they can be attached to the failure. If the entries cannot be read after action ran, its error is joined
+// with the one of the log service.
+func (bmc *BMC) CaptureLogEntries(service string, action func() error) ([]LogEntry, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Capturing entries of log service %s", service)
+
+	if service == "" {
+		glog.V(100).Info("The log service is empty")
+
+		return nil, fmt.Errorf("log 'service' cannot be empty")
+	}
+
+	if action == nil {
+		glog.V(100).Info("The log capture action is nil")
+
+		return nil, fmt.Errorf("log capture 'action' cannot be nil")
+	}
+
+	start, existingIDs := bmc.getLogCaptureStart(service)
+
+	actionErr := action()
+
+	entries, err := bmc.LogEntries(service, start)
+	if err != nil {
+		return nil, errors.Join(actionErr, err)
+	}
+
+	entries = slices.DeleteFunc(entries, func(entry LogEntry) bool {
+		return existingIDs[entry.ID]
+	})
+
+	return entries, actionErr
+}
+
+// FilterLogEntries returns the entries with any of the provided severities.
+func FilterLogEntries(entries []LogEntry, severities ...redfish.EventSeverity) []LogEntry {
+	var filtered []LogEntry
+
+	for _, entry := range entries {
+		if slices.Contains(severities, entry.Severity) {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return filtered
+}
+
+// getLogCaptureStart returns the time from which entries of the log service are captured. The time of the log service
+// is used when it reports one. Otherwise, the creation time of its newest entry is used, along with the IDs of the
+// existing entries so that they can be left out. If neither is known, the local time minus logCaptureClockSkew is used.
+func (bmc *BMC) getLogCaptureStart(service string) (time.Time, map[string]bool) {
+	serviceTime, err := bmc.LogServiceTime(service)
+	if err == nil {
+		return serviceTime, nil
+	}
+
+	glog.V(100).Infof("Failed to get time of log service %s, using its newest entry instead: %v", service, err)
+
+	entries, err := bmc.LogEntries(service, time.Time{})
+	if err == nil && len(entries) > 0 && !entries[len(entries)-1].Created.IsZero() {
+		existingIDs := map[string]bool{}
+
+		for _, entry := range entries {
+			existingIDs[entry.ID] = true
+		}
+
+		return entries[len(entries)-1].Created, existingIDs
+	}
+
+	glog.V(100).Infof("Failed to get newest entry of log service %s, using the local time instead: %v", service, err)
+
+	return time.Now().Add(-logCaptureClockSkew), nil
+}
+
+// newLogService converts the gofish log service to a LogService.
+func newLogService(logService *redfish.LogService, manager bool) LogService {
+	return LogService{
+		ID:        logService.ID,
+		Name:      logService.Name,
+		EntryType: logService.LogEntryType,
+		Enabled:   logService.ServiceEnabled,
+		Manager:   manager,
+	}
+}
+
+// newLogEntry converts the gofish log entry to a LogEntry.
+func newLogEntry(entry *redfish.LogEntry) LogEntry {
+	created, err := time.Parse(time.RFC3339, entry.Created)
+	if err != nil {
+		glog.V(100).Infof("Failed to parse creation time %q of log entry %s: %v", entry.Created, entry.ID, err)
+	}
+
+	return LogEntry{
+		ID:        entry.ID,
+		Created:   created,
+		Severity:  entry.Severity,
+		MessageID: entry.MessageID,
+		Message:   entry.Message,
+		EntryType: entry.EntryType,
+	}
+}
+
+// redfishGetLogServices uses the provided gofish APIClient and the system index to get the log services of a system
+// and of its managers.
+func redfishGetLogServices(
+	redfishClient *gofish.APIClient, systemIndex int) ([]*redfish.LogService, []*redfish.LogService, error) {
+	system, err := redfishGetSystem(redfishClient, systemIndex)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get redfish system: %w", err)
+	}
+
+	systemLogServices, err := system.LogServices()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get system log services: %w", err)
+	}
+
+	managers, err := system.ManagedBy()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get system managers: %w", err)
+	}
+
+	var managerLogServices []*redfish.LogService
+
+	for _, manager := range managers {
+		logServices, err := manager.LogServices()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get log services of manager %s: %w", manager.ID, err)
+		}
+
+		managerLogServices = append(managerLogServices, logServices...)
+	}
+
+	return systemLogServices, managerLogServices, nil
+}
+
+// redfishGetLogService uses the provided gofish APIClient and the system index to get the log service with the
+// provided ID, looking first at the system log services and then at the ones of its managers.
+func redfishGetLogService(
+	redfishClient *gofish.APIClient, systemIndex int, service string) (*redfish.LogService, error) {
+	systemLogServices, managerLogServices, err := redfishGetLogServices(redfishClient, systemIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, logService := range append(systemLogServices, managerLogServices...) {
+		if logService.ID == service {
+			return logService, nil
+		}
+	}
+
+	return nil, fmt.Errorf("log service %s not found", service)
+}
@@ -0,0 +1,169 @@
+package bmc
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish/redfish"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBMCLogServices(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+
+	logServices, err := New(host).WithRedfishUser(defaultUsername, defaultPassword).LogServices()
+	assert.Nil(t, err)
+	assert.Equal(t, []LogService{{
+		ID: "Sel", Name: "SEL Log Service", EntryType: redfish.SELLogEntryTypes, Enabled: true, Manager: true,
+	}}, logServices)
+
+	_, err = New(host).LogServices()
+	assert.Equal(t, fmt.Errorf("cannot access redfish with nil user"), err)
+}
+
+func TestBMCLogEntries(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+
+	testCases := []struct {
+		service       string
+		since         time.Time
+		expectedIDs   []string
+		expectedError error
+	}{
+		{
+			service:       "Sel",
+			since:         time.Time{},
+			expectedIDs:   []string{"4", "1", "2", "3"},
+			expectedError: nil,
+		},
+		{
+			service:       "Sel",
+			since:         time.Date(2024, 2, 21, 17, 15, 30, 0, time.UTC),
+			expectedIDs:   []string{"4", "2", "3"},
+			expectedError: nil,
+		},
+		{
+			service:       "",
+			expectedError: fmt.Errorf("log 'service' cannot be empty"),
+		},
+		{
+			service:       "Lclog",
+			expectedError: fmt.Errorf("log service Lclog not found"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+		entries, err := bmc.LogEntries(testCase.service, testCase.since)
+
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			var ids []string
+
+			for _, entry := range entries {
+				ids = append(ids, entry.ID)
+			}
+
+			assert.Equal(t, testCase.expectedIDs, ids)
+		}
+	}
+}
+
+func TestBMCClearLog(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+	err := bmc.ClearLog("")
+	assert.Equal(t, fmt.Errorf("log 'service' cannot be empty"), err)
+
+	err = bmc.ClearLog("Sel")
+	assert.Nil(t, err)
+
+	entries, err := bmc.LogEntries("Sel", time.Time{})
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestBMCCaptureLogEntries(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+	entries, err := bmc.CaptureLogEntries("Sel", bmc.SystemForceReset)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 3)
+	assert.True(t, entries[0].Created.IsZero())
+
+	critical := FilterLogEntries(entries, redfish.CriticalEventSeverity)
+	assert.Len(t, critical, 1)
+	assert.Equal(t, "TMP0120", critical[0].MessageID)
+	assert.Equal(t, time.Date(2024, 2, 21, 17, 16, 10, 0, time.UTC), critical[0].Created.UTC())
+
+	entries, err = bmc.CaptureLogEntries("Sel", func() error { return fmt.Errorf("reboot failed") })
+	assert.Equal(t, fmt.Errorf("reboot failed"), err)
+	assert.Len(t, entries, 3)
+
+	_, err = bmc.CaptureLogEntries("Sel", nil)
+	assert.Equal(t, fmt.Errorf("log capture 'action' cannot be nil"), err)
+
+	actionErr := fmt.Errorf("bmc went away")
+
+	_, err = bmc.CaptureLogEntries("Sel", func() error {
+		redfishServer.Close()
+
+		return actionErr
+	})
+	assert.ErrorIs(t, err, actionErr)
+	assert.ErrorContains(t, err, "redfish connection error")
+}
+
+func TestBMCCaptureLogEntriesWithoutServiceTime(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{
+		logServiceTime: func(r *http.Request) bool { return false },
+	})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+	actionRuns := 0
+	action := func() error {
+		actionRuns++
+
+		return nil
+	}
+
+	// The entries that existed before the action are left out, even the ones with an invalid creation time.
+	entries, err := bmc.CaptureLogEntries("Sel", action)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+	assert.Equal(t, 1, actionRuns)
+
+	err = bmc.ClearLog("Sel")
+	assert.Nil(t, err)
+
+	// Without any entry, the local time is used and the action still runs.
+	entries, err = bmc.CaptureLogEntries("Sel", action)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+	assert.Equal(t, 2, actionRuns)
+
+	_, err = bmc.CaptureLogEntries("", action)
+	assert.Equal(t, fmt.Errorf("log 'service' cannot be empty"), err)
+	assert.Equal(t, 2, actionRuns)
+}
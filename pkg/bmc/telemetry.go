@@ -0,0 +1,573 @@
+package bmc
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/common"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+// telemetryReadingTypes are the reading types of the Sensors collection that are sampled.
+var telemetryReadingTypes = []redfish.ReadingType{
+	redfish.PowerReadingType, redfish.TemperatureReadingType, redfish.RotationalReadingType,
+}
+
+// SensorReading holds a reading of a power, temperature or fan sensor.
+type SensorReading struct {
+	// Chassis is the ID of the chassis the sensor belongs to.
+	Chassis string
+	// Name is the name of the sensor.
+	Name string
+	// Type is the type of the reading. Fans use Rotational, or Percent if the BMC reports their speed in percent.
+	Type redfish.ReadingType
+	// Value is the value of the reading.
+	Value float64
+	// Units are the units of the value, for example W, Cel or RPM.
+	Units string
+}
+
+// TelemetrySample holds the readings of every sensor at a point in time.
+type TelemetrySample struct {
+	// Time is the time the sample was taken.
+	Time time.Time
+	// Readings are the readings of the sample.
+	Readings []SensorReading
+}
+
+// SensorStatistics holds the statistics of the readings of a sensor across the samples.
+type SensorStatistics struct {
+	// Chassis is the ID of the chassis the sensor belongs to.
+	Chassis string
+	// Name is the name of the sensor.
+	Name string
+	// Type is the type of the readings.
+	Type redfish.ReadingType
+	// Units are the units of the readings.
+	Units string
+	// Count is the number of readings.
+	Count int
+	// Min is the minimum reading.
+	Min float64
+	// Max is the maximum reading.
+	Max float64
+	// Avg is the average of the readings.
+	Avg float64
+	// P50 is the median of the readings.
+	P50 float64
+	// P90 is the 90th percentile of the readings.
+	P90 float64
+	// P99 is the 99th percentile of the readings.
+	P99 float64
+}
+
+// TelemetrySampler polls the power, temperature and fan sensors of the BMC at a fixed interval in the background.
+// Use BMC.StartTelemetrySampler to create one.
+type TelemetrySampler struct {
+	bmc      *BMC
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	// redfishClient is the Redfish session shared by the samples, or nil if there is none. It is only used by the
+	// sampling goroutine and is replaced when a sample fails.
+	redfishClient *gofish.APIClient
+	redfishCancel context.CancelFunc
+
+	mutex   sync.Mutex
+	samples []TelemetrySample
+	lastErr error
+}
+
+// redfishPowerReadings holds the readings of a Power resource. Readings are pointers so that the ones the BMC does not
+// report can be told apart from zero readings.
+type redfishPowerReadings struct {
+	PowerControl []struct {
+		Name               string
+		PowerConsumedWatts *float64
+	}
+}
+
+// redfishThermalReadings holds the readings of a Thermal resource. Readings are pointers so that the ones the BMC does
+// not report can be told apart from zero readings.
+type redfishThermalReadings struct {
+	Temperatures []struct {
+		Name           string
+		ReadingCelsius *float64
+	}
+	Fans []struct {
+		Name         string
+		Reading      *float64
+		ReadingUnits redfish.ReadingUnits
+	}
+}
+
+// SensorReadings returns the current readings of the power, temperature and fan sensors of every chassis using the
+// Redfish API. Readings come from the Sensors collection of the chassis and, for the reading types it does not report,
+// from the Power and Thermal resources.
+func (bmc *BMC) SensorReadings() ([]SensorReading, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Info("Collecting sensor readings from bmc's redfish endpoint")
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	readings, err := redfishGetSensorReadings(redfishClient)
+	if err != nil {
+		glog.V(100).Infof("Failed to get sensor readings: %v", err)
+
+		return nil, err
+	}
+
+	return readings, nil
+}
+
+// StartTelemetrySampler starts sampling the sensor readings every interval in the background, taking the first sample
+// immediately. The samples share a single Redfish session, which is replaced when a sample fails. The sampler stops
+// when ctx is done or Stop is called. Samples that fail are skipped and their error is available through LastError.
+func (bmc *BMC) StartTelemetrySampler(ctx context.Context, interval time.Duration) (*TelemetrySampler, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Starting telemetry sampler with interval %s", interval)
+
+	if ctx == nil {
+		glog.V(100).Info("The telemetry sampler context is nil")
+
+		return nil, fmt.Errorf("telemetry sampler 'ctx' cannot be nil")
+	}
+
+	if interval <= 0 {
+		glog.V(100).Infof("The telemetry sampler interval %s is not positive", interval)
+
+		return nil, fmt.Errorf("telemetry sampler 'interval' must be positive")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	sampler := &TelemetrySampler{
+		bmc:      bmc,
+		interval: interval,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	go sampler.run(ctx)
+
+	return sampler, nil
+}
+
+// Stop stops the sampler and waits until the sample being taken, if any, finishes. It is safe to call Stop more than
+// once.
+func (sampler *TelemetrySampler) Stop() {
+	glog.V(100).Info("Stopping telemetry sampler")
+
+	sampler.cancel()
+	<-sampler.done
+}
+
+// Done returns a channel that is closed once the sampler stops.
+func (sampler *TelemetrySampler) Done() <-chan struct{} {
+	return sampler.done
+}
+
+// Samples returns a copy of the samples taken so far.
+func (sampler *TelemetrySampler) Samples() []TelemetrySample {
+	sampler.mutex.Lock()
+	defer sampler.mutex.Unlock()
+
+	return slices.Clone(sampler.samples)
+}
+
+// LastError returns the error of the last sample that failed, or nil if no sample failed.
+func (sampler *TelemetrySampler) LastError() error {
+	sampler.mutex.Lock()
+	defer sampler.mutex.Unlock()
+
+	return sampler.lastErr
+}
+
+// Statistics returns the statistics of each sensor across the samples taken so far, in the order the sensors were
+// first seen.
+func (sampler *TelemetrySampler) Statistics() []SensorStatistics {
+	return GetSensorStatistics(sampler.Samples())
+}
+
+// WriteCSV writes the samples taken so far to writer in CSV format, with one row per reading and the columns time,
+// chassis, name, type, value and units.
+func (sampler *TelemetrySampler) WriteCSV(writer io.Writer) error {
+	if writer == nil {
+		return fmt.Errorf("telemetry sampler csv 'writer' cannot be nil")
+	}
+
+	csvWriter := csv.NewWriter(writer)
+
+	err := csvWriter.Write([]string{"time", "chassis", "name", "type", "value", "units"})
+	if err != nil {
+		return err
+	}
+
+	for _, sample := range sampler.Samples() {
+		for _, reading := range sample.Readings {
+			err = csvWriter.Write([]string{
+				sample.Time.Format(time.RFC3339Nano),
+				reading.Chassis,
+				reading.Name,
+				string(reading.Type),
+				strconv.FormatFloat(reading.Value, 'f', -1, 64),
+				reading.Units,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// GetSensorStatistics returns the statistics of each sensor across the samples, in the order the sensors were first
+// seen. Percentiles use the nearest-rank method.
+func GetSensorStatistics(samples []TelemetrySample) []SensorStatistics {
+	type sensorKey struct {
+		chassis, name string
+		readingType   redfish.ReadingType
+	}
+
+	var (
+		keys       []sensorKey
+		statistics = map[sensorKey]*SensorStatistics{}
+		values     = map[sensorKey][]float64{}
+	)
+
+	for _, sample := range samples {
+		for _, reading := range sample.Readings {
+			key := sensorKey{chassis: reading.Chassis, name: reading.Name, readingType: reading.Type}
+
+			if _, found := statistics[key]; !found {
+				keys = append(keys, key)
+				statistics[key] = &SensorStatistics{
+					Chassis: reading.Chassis, Name: reading.Name, Type: reading.Type, Units: reading.Units,
+				}
+			}
+
+			values[key] = append(values[key], reading.Value)
+		}
+	}
+
+	var sensorStatistics []SensorStatistics
+
+	for _, key := range keys {
+		sensorValues := values[key]
+		slices.Sort(sensorValues)
+
+		sum := 0.0
+
+		for _, value := range sensorValues {
+			sum += value
+		}
+
+		stats := statistics[key]
+		stats.Count = len(sensorValues)
+		stats.Min = sensorValues[0]
+		stats.Max = sensorValues[len(sensorValues)-1]
+		stats.Avg = sum / float64(len(sensorValues))
+		stats.P50 = getPercentile(sensorValues, 50)
+		stats.P90 = getPercentile(sensorValues, 90)
+		stats.P99 = getPercentile(sensorValues, 99)
+
+		sensorStatistics = append(sensorStatistics, *stats)
+	}
+
+	return sensorStatistics
+}
+
+// run takes samples until ctx is done.
+func (sampler *TelemetrySampler) run(ctx context.Context) {
+	defer close(sampler.done)
+	defer sampler.disconnect()
+
+	ticker := time.NewTicker(sampler.interval)
+	defer ticker.Stop()
+
+	for {
+		sampler.sample()
+
+		select {
+		case <-ctx.Done():
+			glog.V(100).Infof("Telemetry sampler stopped: %v", ctx.Err())
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sample takes a sample and stores it, or stores the error if it failed. A sample that fails using an existing session
+// is retried once with a new session, since the session may have expired since the previous sample.
+func (sampler *TelemetrySampler) sample() {
+	sampleTime := time.Now()
+	reusedSession := sampler.redfishClient != nil
+	readings, err := sampler.getSensorReadings()
+
+	if err != nil && reusedSession {
+		glog.V(100).Infof("Failed to take telemetry sample, retrying with a new redfish session: %v", err)
+
+		sampler.disconnect()
+		readings, err = sampler.getSensorReadings()
+	}
+
+	if err != nil {
+		sampler.disconnect()
+	}
+
+	sampler.mutex.Lock()
+	defer sampler.mutex.Unlock()
+
+	if err != nil {
+		glog.V(100).Infof("Failed to take telemetry sample: %v", err)
+
+		sampler.lastErr = err
+
+		return
+	}
+
+	sampler.samples = append(sampler.samples, TelemetrySample{Time: sampleTime, Readings: readings})
+}
+
+// getSensorReadings returns the sensor readings using the Redfish session of the sampler, connecting first if there
+// is none.
+func (sampler *TelemetrySampler) getSensorReadings() ([]SensorReading, error) {
+	if sampler.redfishClient == nil {
+		redfishClient, cancel, err := redfishConnect(
+			sampler.bmc.host,
+			sampler.bmc.redfishUser.Name,
+			sampler.bmc.redfishUser.Password,
+			sampler.bmc.timeOuts.Redfish)
+		if err != nil {
+			return nil, fmt.Errorf("redfish connection error: %w", err)
+		}
+
+		sampler.redfishClient = redfishClient
+		sampler.redfishCancel = cancel
+	}
+
+	return redfishGetSensorReadings(sampler.redfishClient)
+}
+
+// disconnect logs out of the Redfish session of the sampler, if any.
+func (sampler *TelemetrySampler) disconnect() {
+	if sampler.redfishClient == nil {
+		return
+	}
+
+	sampler.redfishClient.Logout()
+	sampler.redfishCancel()
+
+	sampler.redfishClient = nil
+	sampler.redfishCancel = nil
+}
+
+// getPercentile returns the percentile of the sorted values using the nearest-rank method.
+func getPercentile(sortedValues []float64, percentile float64) float64 {
+	rank := int(math.Ceil(percentile / 100 * float64(len(sortedValues))))
+
+	return sortedValues[max(rank, 1)-1]
+}
+
+// redfishGetSensorReadings uses the provided gofish APIClient to get the power, temperature and fan readings of every
+// chassis.
+func redfishGetSensorReadings(redfishClient *gofish.APIClient) ([]SensorReading, error) {
+	chassisCollection, err := redfishClient.GetService().Chassis()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chassis collection: %w", err)
+	}
+
+	var readings []SensorReading
+
+	for _, chassis := range chassisCollection {
+		sensors, err := chassis.Sensors()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sensors of chassis %s: %w", chassis.ID, err)
+		}
+
+		power, thermal, err := redfishGetPowerThermalReadings(redfishClient, chassis)
+		if err != nil {
+			return nil, err
+		}
+
+		sensorReadings := getSensorsReadings(chassis.ID, sensors)
+		readings = append(readings, sensorReadings...)
+
+		for _, reading := range getPowerThermalReadings(chassis.ID, power, thermal) {
+			if !slices.ContainsFunc(sensorReadings, func(sensorReading SensorReading) bool {
+				return sensorReading.Type == reading.Type
+			}) {
+				readings = append(readings, reading)
+			}
+		}
+	}
+
+	return readings, nil
+}
+
+// getSensorsReadings returns the power, temperature and fan readings of the Sensors collection of a chassis, sorted by
+// sensor ID.
+func getSensorsReadings(chassisID string, sensors []*redfish.Sensor) []SensorReading {
+	var readings []SensorReading
+
+	sensors = slices.Clone(sensors)
+	slices.SortFunc(sensors, func(first, second *redfish.Sensor) int {
+		return strings.Compare(first.ID, second.ID)
+	})
+
+	for _, sensor := range sensors {
+		if !slices.Contains(telemetryReadingTypes, sensor.ReadingType) {
+			continue
+		}
+
+		readings = append(readings, SensorReading{
+			Chassis: chassisID,
+			Name:    sensor.Name,
+			Type:    sensor.ReadingType,
+			Value:   float64(sensor.Reading),
+			Units:   sensor.ReadingUnits,
+		})
+	}
+
+	return readings
+}
+
+// redfishGetPowerThermalReadings uses the provided gofish APIClient to get the readings of the Power and Thermal
+// resources of the chassis. Either is nil if the chassis does not link to it.
+func redfishGetPowerThermalReadings(
+	redfishClient *gofish.APIClient, chassis *redfish.Chassis) (*redfishPowerReadings, *redfishThermalReadings, error) {
+	var links struct {
+		Power   common.Link
+		Thermal common.Link
+	}
+
+	err := json.Unmarshal(chassis.RawData, &links)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal links of chassis %s: %w", chassis.ID, err)
+	}
+
+	var (
+		power   *redfishPowerReadings
+		thermal *redfishThermalReadings
+	)
+
+	if links.Power.String() != "" {
+		power = &redfishPowerReadings{}
+
+		err = redfishGetJSON(redfishClient, links.Power.String(), power)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get power of chassis %s: %w", chassis.ID, err)
+		}
+	}
+
+	if links.Thermal.String() != "" {
+		thermal = &redfishThermalReadings{}
+
+		err = redfishGetJSON(redfishClient, links.Thermal.String(), thermal)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get thermal of chassis %s: %w", chassis.ID, err)
+		}
+	}
+
+	return power, thermal, nil
+}
+
+// getPowerThermalReadings returns the power, temperature and fan readings of the Power and Thermal resources of a
+// chassis. Either resource may be nil. Readings the BMC does not report a value for are skipped.
+func getPowerThermalReadings(
+	chassisID string, power *redfishPowerReadings, thermal *redfishThermalReadings) []SensorReading {
+	var readings []SensorReading
+
+	if power != nil {
+		for _, powerControl := range power.PowerControl {
+			if powerControl.PowerConsumedWatts == nil {
+				continue
+			}
+
+			readings = append(readings, SensorReading{
+				Chassis: chassisID,
+				Name:    powerControl.Name,
+				Type:    redfish.PowerReadingType,
+				Value:   *powerControl.PowerConsumedWatts,
+				Units:   "W",
+			})
+		}
+	}
+
+	if thermal == nil {
+		return readings
+	}
+
+	for _, temperature := range thermal.Temperatures {
+		if temperature.ReadingCelsius == nil {
+			continue
+		}
+
+		readings = append(readings, SensorReading{
+			Chassis: chassisID,
+			Name:    temperature.Name,
+			Type:    redfish.TemperatureReadingType,
+			Value:   *temperature.ReadingCelsius,
+			Units:   "Cel",
+		})
+	}
+
+	for _, fan := range thermal.Fans {
+		if fan.Reading == nil {
+			continue
+		}
+
+		reading := SensorReading{
+			Chassis: chassisID,
+			Name:    fan.Name,
+			Type:    redfish.RotationalReadingType,
+			Value:   *fan.Reading,
+			Units:   string(fan.ReadingUnits),
+		}
+
+		if fan.ReadingUnits == redfish.PercentReadingUnits {
+			reading.Type = redfish.PercentReadingType
+			reading.Units = "%"
+		}
+
+		readings = append(readings, reading)
+	}
+
+	return readings
+}
@@ -0,0 +1,198 @@
+package bmc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish/redfish"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBMCSensorReadings(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+
+	readings, err := New(host).WithRedfishUser(defaultUsername, defaultPassword).SensorReadings()
+	assert.Nil(t, err)
+	assert.Equal(t, []SensorReading{
+		{
+			Chassis: "System.Embedded.1", Name: "System Board Fan1A", Type: redfish.RotationalReadingType,
+			Value: 6240, Units: "RPM",
+		},
+		{
+			Chassis: "System.Embedded.1", Name: "System Board Inlet Temp", Type: redfish.TemperatureReadingType,
+			Value: 23, Units: "Cel",
+		},
+		{
+			Chassis: "System.Embedded.1", Name: "System Power Control", Type: redfish.PowerReadingType,
+			Value: 360, Units: "W",
+		},
+		{
+			Chassis: "System.Embedded.1", Name: "System Board Fan1B", Type: redfish.PercentReadingType,
+			Value: 35, Units: "%",
+		},
+	}, readings)
+
+	_, err = New(host).SensorReadings()
+	assert.Equal(t, fmt.Errorf("cannot access redfish with nil user"), err)
+}
+
+func TestBMCStartTelemetrySampler(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+	//nolint:staticcheck // A nil context is what is being tested.
+	_, err := bmc.StartTelemetrySampler(nil, time.Second)
+	assert.Equal(t, fmt.Errorf("telemetry sampler 'ctx' cannot be nil"), err)
+
+	_, err = bmc.StartTelemetrySampler(context.TODO(), 0)
+	assert.Equal(t, fmt.Errorf("telemetry sampler 'interval' must be positive"), err)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	sampler, err := bmc.StartTelemetrySampler(ctx, 10*time.Millisecond)
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool { return len(sampler.Samples()) >= 2 }, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-sampler.Done()
+
+	// Stopping a sampler that already stopped returns immediately.
+	sampler.Stop()
+
+	samples := sampler.Samples()
+	assert.Nil(t, sampler.LastError())
+	assert.True(t, samples[0].Time.Before(samples[1].Time))
+
+	statistics := sampler.Statistics()
+	assert.Len(t, statistics, 4)
+	assert.Equal(t, len(samples), statistics[0].Count)
+
+	var csvBuffer bytes.Buffer
+
+	err = sampler.WriteCSV(&csvBuffer)
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(csvBuffer.String()), "\n")
+	assert.Len(t, lines, 1+4*len(samples))
+	assert.Equal(t, "time,chassis,name,type,value,units", lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], ",System.Embedded.1,System Board Fan1A,Rotational,6240,RPM"))
+
+	err = sampler.WriteCSV(nil)
+	assert.Equal(t, fmt.Errorf("telemetry sampler csv 'writer' cannot be nil"), err)
+}
+
+func TestTelemetrySamplerSession(t *testing.T) {
+	var (
+		mutex           sync.Mutex
+		sessions        int
+		thermalRequests int
+	)
+
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{
+		sessions: func(r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if r.Method == http.MethodPost {
+				sessions++
+			}
+		},
+		// The third request fails, as it would once the session expires.
+		thermal: func(r *http.Request) bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			thermalRequests++
+
+			return thermalRequests != 3
+		},
+	})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+	sampler, err := bmc.StartTelemetrySampler(context.TODO(), 10*time.Millisecond)
+	assert.Nil(t, err)
+
+	assert.Eventually(t, func() bool { return len(sampler.Samples()) >= 4 }, 5*time.Second, 10*time.Millisecond)
+	sampler.Stop()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	// The failed sample is retried with a new session and every other sample reuses the session.
+	assert.Equal(t, 2, sessions)
+	assert.Nil(t, sampler.LastError())
+}
+
+func TestGetSensorStatistics(t *testing.T) {
+	var samples []TelemetrySample
+
+	for value := 1; value <= 10; value++ {
+		samples = append(samples, TelemetrySample{
+			Time: time.Unix(int64(value), 0),
+			Readings: []SensorReading{
+				{Name: "Power", Type: redfish.PowerReadingType, Value: float64(value * 10), Units: "W"},
+				{Name: "Inlet", Type: redfish.TemperatureReadingType, Value: 25, Units: "Cel"},
+			},
+		})
+	}
+
+	assert.Equal(t, []SensorStatistics{
+		{
+			Name: "Power", Type: redfish.PowerReadingType, Units: "W",
+			Count: 10, Min: 10, Max: 100, Avg: 55, P50: 50, P90: 90, P99: 100,
+		},
+		{
+			Name: "Inlet", Type: redfish.TemperatureReadingType, Units: "Cel",
+			Count: 10, Min: 25, Max: 25, Avg: 25, P50: 25, P90: 25, P99: 25,
+		},
+	}, GetSensorStatistics(samples))
+
+	assert.Empty(t, GetSensorStatistics(nil))
+}
+
+func TestGetPowerThermalReadings(t *testing.T) {
+	var thermal redfishThermalReadings
+
+	err := json.Unmarshal([]byte(redfishThermalJSONResponse), &thermal)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []SensorReading{
+		{Chassis: "1", Name: "System Board Inlet Temp", Type: redfish.TemperatureReadingType, Value: 24, Units: "Cel"},
+		{Chassis: "1", Name: "CPU1 Temp", Type: redfish.TemperatureReadingType, Value: 52, Units: "Cel"},
+		{Chassis: "1", Name: "System Board Fan1A", Type: redfish.RotationalReadingType, Value: 6120, Units: "RPM"},
+		{Chassis: "1", Name: "System Board Fan1B", Type: redfish.PercentReadingType, Value: 35, Units: "%"},
+	}, getPowerThermalReadings("1", nil, &thermal))
+
+	assert.Empty(t, getPowerThermalReadings("1", nil, nil))
+
+	var (
+		power         redfishPowerReadings
+		absentThermal redfishThermalReadings
+	)
+
+	err = json.Unmarshal([]byte(`{"PowerControl": [{"Name": "System Power Control", "PowerConsumedWatts": null}]}`),
+		&power)
+	assert.Nil(t, err)
+
+	err = json.Unmarshal([]byte(`{"Temperatures": [{"Name": "CPU1 Temp", "ReadingCelsius": null}], `+
+		`"Fans": [{"Name": "System Board Fan1A", "ReadingUnits": "RPM"}]}`), &absentThermal)
+	assert.Nil(t, err)
+
+	assert.Empty(t, getPowerThermalReadings("1", &power, &absentThermal))
+}
@@ -0,0 +1,230 @@
+package bmc
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/stmcginnis/gofish/redfish"
+)
+
+const (
+	manufacturerSupermicro = "Supermicro"
+	manufacturerLenovo     = "Lenovo"
+)
+
+var (
+	// vendorProfilesMutex protects vendorProfiles.
+	vendorProfilesMutex sync.RWMutex
+
+	// vendorProfiles holds the registered vendor profiles, with the most recently registered first.
+	vendorProfiles = []VendorProfile{
+		{
+			Name:                 "Dell iDRAC",
+			Manufacturer:         manufacturerDell,
+			SerialConsoleCommand: "console com2",
+			VirtualMediaSlot:     "1",
+			ResetTypes: []redfish.ResetType{
+				redfish.OnResetType, redfish.ForceOffResetType, redfish.ForceRestartResetType,
+				redfish.GracefulRestartResetType, redfish.GracefulShutdownResetType, redfish.PushPowerButtonResetType,
+				redfish.NmiResetType, redfish.PowerCycleResetType,
+			},
+		},
+		{
+			Name:                 "HPE iLO",
+			Manufacturer:         manufacturerHPE,
+			SerialConsoleCommand: "VSP",
+			VirtualMediaSlot:     "2",
+			ResetTypes: []redfish.ResetType{
+				redfish.OnResetType, redfish.ForceOffResetType, redfish.GracefulShutdownResetType,
+				redfish.ForceRestartResetType, redfish.NmiResetType, redfish.PushPowerButtonResetType,
+				redfish.GracefulRestartResetType,
+			},
+		},
+		{
+			Name:                 "Supermicro",
+			Manufacturer:         manufacturerSupermicro,
+			SerialConsoleCommand: "start /system1/sol1",
+			VirtualMediaSlot:     "CD1",
+			ResetTypes: []redfish.ResetType{
+				redfish.OnResetType, redfish.ForceOffResetType, redfish.GracefulShutdownResetType,
+				redfish.GracefulRestartResetType, redfish.ForceRestartResetType, redfish.NmiResetType,
+				redfish.ForceOnResetType,
+			},
+			BootOverride: BootOverrideQuirks{
+				VirtualMediaTarget: "UsbCd",
+				Mode:               redfish.UEFIBootSourceOverrideMode,
+			},
+		},
+		{
+			Name:                 "Lenovo XCC",
+			Manufacturer:         manufacturerLenovo,
+			SerialConsoleCommand: "console 1",
+			VirtualMediaSlot:     "EXT1",
+			ResetTypes: []redfish.ResetType{
+				redfish.OnResetType, redfish.NmiResetType, redfish.GracefulShutdownResetType,
+				redfish.GracefulRestartResetType, redfish.ForceOnResetType, redfish.ForceOffResetType,
+				redfish.ForceRestartResetType,
+			},
+		},
+	}
+)
+
+// BootOverrideQuirks holds the differences between vendors when overriding the boot source.
+type BootOverrideQuirks struct {
+	// VirtualMediaTarget is the boot source override target used to boot from a virtual CD or DVD. If empty, Cd is
+	// used.
+	VirtualMediaTarget redfish.BootSourceOverrideTarget
+	// Mode is the boot source override mode that must be set along with the target. If empty, the mode is not set.
+	Mode redfish.BootSourceOverrideMode
+}
+
+// VendorProfile holds the vendor specific settings of a BMC. Profiles are matched against the manufacturer and model
+// reported by the Redfish system.
+type VendorProfile struct {
+	// Name is a human readable name of the profile.
+	Name string
+	// Manufacturer is the manufacturer of the system, compared case-insensitively.
+	Manufacturer string
+	// ModelPattern is a regular expression matched against the model of the system. If empty, every model matches.
+	ModelPattern string
+	// SerialConsoleCommand is the BMC CLI command that opens the serial console.
+	SerialConsoleCommand string
+	// VirtualMediaSlot is the ID of the virtual media slot preferred for CD and DVD images.
+	VirtualMediaSlot string
+	// ResetTypes are the reset types the BMC supports, used when the BMC does not report them.
+	ResetTypes []redfish.ResetType
+	// BootOverride holds the quirks of the BMC when overriding the boot source.
+	BootOverride BootOverrideQuirks
+}
+
+// RegisterVendorProfile registers a vendor profile. Registered profiles take precedence over the built-in ones and
+// replace any profile with the same manufacturer and model pattern.
+func RegisterVendorProfile(profile VendorProfile) error {
+	glog.V(100).Infof("Registering vendor profile %q for manufacturer %q and model pattern %q",
+		profile.Name, profile.Manufacturer, profile.ModelPattern)
+
+	if profile.Manufacturer == "" {
+		glog.V(100).Info("The vendor profile manufacturer is empty")
+
+		return fmt.Errorf("vendor profile 'manufacturer' cannot be empty")
+	}
+
+	if _, err := regexp.Compile(profile.ModelPattern); err != nil {
+		glog.V(100).Infof("Failed to compile vendor profile model pattern %q: %v", profile.ModelPattern, err)
+
+		return fmt.Errorf("failed to compile vendor profile model pattern %q: %w", profile.ModelPattern, err)
+	}
+
+	vendorProfilesMutex.Lock()
+	defer vendorProfilesMutex.Unlock()
+
+	vendorProfiles = slices.DeleteFunc(vendorProfiles, func(registered VendorProfile) bool {
+		return strings.EqualFold(registered.Manufacturer, profile.Manufacturer) &&
+			registered.ModelPattern == profile.ModelPattern
+	})
+	vendorProfiles = slices.Insert(vendorProfiles, 0, profile)
+
+	return nil
+}
+
+// VendorProfiles returns the registered vendor profiles, including the built-in ones, in order of precedence.
+func VendorProfiles() []VendorProfile {
+	vendorProfilesMutex.RLock()
+	defer vendorProfilesMutex.RUnlock()
+
+	return slices.Clone(vendorProfiles)
+}
+
+// LookupVendorProfile returns the vendor profile for the manufacturer and model. Profiles whose model pattern matches
+// take precedence over profiles without a model pattern. The returned bool is false if no profile matches.
+func LookupVendorProfile(manufacturer, model string) (VendorProfile, bool) {
+	glog.V(100).Infof("Looking up vendor profile for manufacturer %q and model %q", manufacturer, model)
+
+	var (
+		fallback      VendorProfile
+		foundFallback bool
+	)
+
+	for _, profile := range VendorProfiles() {
+		if !strings.EqualFold(profile.Manufacturer, strings.TrimSpace(manufacturer)) {
+			continue
+		}
+
+		if profile.ModelPattern == "" {
+			if !foundFallback {
+				fallback, foundFallback = profile, true
+			}
+
+			continue
+		}
+
+		if regexp.MustCompile(profile.ModelPattern).MatchString(model) {
+			return profile, true
+		}
+	}
+
+	return fallback, foundFallback
+}
+
+// VendorProfile returns the vendor profile for the manufacturer and model of the system using the Redfish API.
+func (bmc *BMC) VendorProfile() (VendorProfile, error) {
+	glog.V(100).Info("Getting vendor profile from bmc's redfish endpoint")
+
+	manufacturer, model, err := bmc.getSystemManufacturerAndModel()
+	if err != nil {
+		return VendorProfile{}, err
+	}
+
+	profile, found := LookupVendorProfile(manufacturer, model)
+	if !found {
+		glog.V(100).Infof("No vendor profile found for manufacturer %q and model %q", manufacturer, model)
+
+		return VendorProfile{}, fmt.Errorf("no vendor profile found for manufacturer %q and model %q",
+			manufacturer, model)
+	}
+
+	return profile, nil
+}
+
+// getSystemManufacturerAndModel returns the manufacturer and model of the system using the Redfish API.
+func (bmc *BMC) getSystemManufacturerAndModel() (string, string, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return "", "", err
+	}
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return "", "", fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	system, err := redfishGetSystem(redfishClient, bmc.systemIndex)
+	if err != nil {
+		glog.V(100).Infof("Failed to get redfish system: %v", err)
+
+		return "", "", fmt.Errorf("failed to get redfish system: %w", err)
+	}
+
+	return system.Manufacturer, system.Model, nil
+}
+
+// getSystemVendorProfile returns the vendor profile of the system, or an empty profile if none matches.
+func getSystemVendorProfile(system *redfish.ComputerSystem) VendorProfile {
+	profile, _ := LookupVendorProfile(system.Manufacturer, system.Model)
+
+	return profile
+}
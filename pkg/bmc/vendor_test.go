@@ -0,0 +1,156 @@
+package bmc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/redfish"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupVendorProfile(t *testing.T) {
+	testCases := []struct {
+		manufacturer  string
+		model         string
+		expectedName  string
+		expectedFound bool
+	}{
+		{
+			manufacturer:  "Dell Inc.",
+			model:         "PowerEdge R750",
+			expectedName:  "Dell iDRAC",
+			expectedFound: true,
+		},
+		{
+			manufacturer:  "hpe",
+			model:         "ProLiant DL380 Gen10 Plus",
+			expectedName:  "HPE iLO",
+			expectedFound: true,
+		},
+		{
+			manufacturer:  "Supermicro ",
+			model:         "SYS-110P-WTR",
+			expectedName:  "Supermicro",
+			expectedFound: true,
+		},
+		{
+			manufacturer:  "Lenovo",
+			model:         "ThinkSystem SR650 V2",
+			expectedName:  "Lenovo XCC",
+			expectedFound: true,
+		},
+		{
+			manufacturer:  "Unknown",
+			model:         "",
+			expectedName:  "",
+			expectedFound: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		profile, found := LookupVendorProfile(testCase.manufacturer, testCase.model)
+
+		assert.Equal(t, testCase.expectedFound, found)
+		assert.Equal(t, testCase.expectedName, profile.Name)
+	}
+}
+
+func TestRegisterVendorProfile(t *testing.T) {
+	restoreVendorProfiles(t)
+
+	err := RegisterVendorProfile(VendorProfile{Name: "No manufacturer"})
+	assert.Equal(t, fmt.Errorf("vendor profile 'manufacturer' cannot be empty"), err)
+
+	err = RegisterVendorProfile(VendorProfile{Name: "Invalid", Manufacturer: "Dell Inc.", ModelPattern: "("})
+	assert.ErrorContains(t, err, "failed to compile vendor profile model pattern \"(\"")
+
+	err = RegisterVendorProfile(VendorProfile{Name: "Dell R750", Manufacturer: "Dell Inc.", ModelPattern: "R750$"})
+	assert.Nil(t, err)
+
+	profile, _ := LookupVendorProfile("Dell Inc.", "PowerEdge R750")
+	assert.Equal(t, "Dell R750", profile.Name)
+
+	profile, _ = LookupVendorProfile("Dell Inc.", "PowerEdge R640")
+	assert.Equal(t, "Dell iDRAC", profile.Name)
+
+	// Registering a profile with the same manufacturer and model pattern replaces it.
+	err = RegisterVendorProfile(VendorProfile{Name: "Dell", Manufacturer: "DELL INC."})
+	assert.Nil(t, err)
+
+	profile, _ = LookupVendorProfile("Dell Inc.", "PowerEdge R640")
+	assert.Equal(t, "Dell", profile.Name)
+	assert.Len(t, VendorProfiles(), 5)
+}
+
+func TestBMCVendorProfile(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+
+	profile, err := New(host).WithRedfishUser(defaultUsername, defaultPassword).VendorProfile()
+	assert.Nil(t, err)
+	assert.Equal(t, "console com2", profile.SerialConsoleCommand)
+
+	_, err = New(host).VendorProfile()
+	assert.Equal(t, fmt.Errorf("cannot access redfish with nil user"), err)
+}
+
+func TestBMCBootFromVirtualMediaVendorQuirks(t *testing.T) {
+	restoreVendorProfiles(t)
+
+	err := RegisterVendorProfile(VendorProfile{
+		Name:             "Dell quirks",
+		Manufacturer:     manufacturerDell,
+		VirtualMediaSlot: "2",
+		BootOverride: BootOverrideQuirks{
+			VirtualMediaTarget: "UsbCd",
+			Mode:               redfish.UEFIBootSourceOverrideMode,
+		},
+	})
+	assert.Nil(t, err)
+
+	var boot redfish.Boot
+
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{
+		secureBoot: func(r *http.Request) {
+			if r.Method != http.MethodPatch {
+				return
+			}
+
+			body, _ := io.ReadAll(r.Body)
+			patch := struct{ Boot redfish.Boot }{}
+			_ = json.Unmarshal(body, &patch)
+			boot = patch.Boot
+		},
+	})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+	// The preferred slot 2 does not allow inserting media, so slot 1 is used.
+	slot, err := bmc.BootFromVirtualMedia("", defaultImageURL, VirtualMediaOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "1", slot)
+	assert.Equal(t, redfish.BootSourceOverrideTarget("UsbCd"), boot.BootSourceOverrideTarget)
+	assert.Equal(t, redfish.UEFIBootSourceOverrideMode, boot.BootSourceOverrideMode)
+}
+
+// restoreVendorProfiles restores the registered vendor profiles once the test finishes.
+func restoreVendorProfiles(t *testing.T) {
+	t.Helper()
+
+	profiles := VendorProfiles()
+
+	t.Cleanup(func() {
+		vendorProfilesMutex.Lock()
+		defer vendorProfilesMutex.Unlock()
+
+		vendorProfiles = profiles
+	})
+}
@@ -0,0 +1,496 @@
+package bmc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/redfish"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// VirtualMediaSlot holds the state of a virtual media slot of the system.
+type VirtualMediaSlot struct {
+	// ID is the identifier of the slot in the Redfish API.
+	ID string
+	// MediaTypes are the types of media the slot can emulate.
+	MediaTypes []redfish.VirtualMediaType
+	// Image is the URI of the inserted image. It is empty when no image is inserted.
+	Image string
+	// Inserted is true when media is present in the slot.
+	Inserted bool
+	// ConnectedVia is how the inserted media is connected to the slot.
+	ConnectedVia redfish.ConnectedVia
+	// WriteProtected is true if the media cannot be written to by the system.
+	WriteProtected bool
+	// SupportsInsert is true if media can be inserted in the slot through the Redfish API.
+	SupportsInsert bool
+	// SupportsEject is true if media can be ejected from the slot through the Redfish API.
+	SupportsEject bool
+}
+
+// VirtualMediaOptions holds the optional parameters used when inserting virtual media. The zero value inserts a CD
+// using the defaults of the BMC for everything else.
+type VirtualMediaOptions struct {
+	// MediaType is the type of the inserted media. It is used to discover a slot when none is provided and to choose
+	// the boot target in BootFromVirtualMedia. Defaults to CD.
+	MediaType redfish.VirtualMediaType
+	// WriteProtected requests the media to be write protected. If false, the BMC default is used.
+	WriteProtected bool
+	// TransferMethod is how the BMC transfers the image. If empty, the BMC default is used.
+	TransferMethod redfish.TransferMethod
+	// TransferProtocolType is the protocol used to get the image. If empty, the BMC infers it from the URL.
+	TransferProtocolType redfish.TransferProtocolType
+	// UserName is the user used to access the image URL, if it requires authentication.
+	UserName string
+	// Password is the password used to access the image URL, if it requires authentication.
+	Password string
+}
+
+// ListVirtualMedia returns the virtual media slots of the system, sorted by ID, using the Redfish API.
+func (bmc *BMC) ListVirtualMedia() ([]VirtualMediaSlot, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Info("Listing virtual media slots from bmc's redfish endpoint")
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	virtualMedia, err := redfishGetVirtualMedia(redfishClient, bmc.systemIndex)
+	if err != nil {
+		glog.V(100).Infof("Failed to get redfish system's virtual media: %v", err)
+
+		return nil, err
+	}
+
+	var slots []VirtualMediaSlot
+
+	for _, media := range virtualMedia {
+		slots = append(slots, newVirtualMediaSlot(media))
+	}
+
+	slices.SortFunc(slots, func(first, second VirtualMediaSlot) int {
+		return strings.Compare(first.ID, second.ID)
+	})
+
+	return slots, nil
+}
+
+// VirtualMediaStatus returns the state of the virtual media slot with the provided ID using the Redfish API.
+func (bmc *BMC) VirtualMediaStatus(slot string) (*VirtualMediaSlot, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting status of virtual media slot %s from bmc's redfish endpoint", slot)
+
+	if slot == "" {
+		glog.V(100).Info("The virtual media slot is empty")
+
+		return nil, fmt.Errorf("virtual media 'slot' cannot be empty")
+	}
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return nil, fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	media, err := redfishGetVirtualMediaSlot(redfishClient, bmc.systemIndex, slot)
+	if err != nil {
+		glog.V(100).Infof("Failed to get virtual media slot %s: %v", slot, err)
+
+		return nil, err
+	}
+
+	status := newVirtualMediaSlot(media)
+
+	return &status, nil
+}
+
+// InsertVirtualMedia inserts the image available at imageURL in the virtual media slot with the provided ID using the
+// Redfish API. If slot is empty, the first slot that supports options.MediaType, allows inserting media, and has no
+// media inserted is used, preferring the virtual media slot of the vendor profile of the system for CD and DVD images.
+// The ID of the slot the image was inserted in is returned.
+func (bmc *BMC) InsertVirtualMedia(slot, imageURL string, options VirtualMediaOptions) (string, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return "", err
+	}
+
+	if options.MediaType == "" {
+		options.MediaType = redfish.CDMediaType
+	}
+
+	glog.V(100).Infof("Inserting %s virtual media %s in slot %q", options.MediaType, imageURL, slot)
+
+	if imageURL == "" {
+		glog.V(100).Info("The virtual media image URL is empty")
+
+		return "", fmt.Errorf("virtual media 'imageURL' cannot be empty")
+	}
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return "", fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	media, err := redfishFindVirtualMediaSlot(redfishClient, bmc.systemIndex, slot, options.MediaType)
+	if err != nil {
+		glog.V(100).Infof("Failed to find virtual media slot: %v", err)
+
+		return "", err
+	}
+
+	err = media.InsertMediaConfig(redfish.VirtualMediaConfig{
+		Image:                imageURL,
+		Inserted:             true,
+		Password:             options.Password,
+		TransferMethod:       options.TransferMethod,
+		TransferProtocolType: options.TransferProtocolType,
+		UserName:             options.UserName,
+		WriteProtected:       options.WriteProtected,
+	})
+	if err != nil {
+		glog.V(100).Infof("Failed to insert virtual media in slot %s: %v", media.ID, err)
+
+		return "", fmt.Errorf("failed to insert virtual media in slot %s: %w", media.ID, err)
+	}
+
+	return media.ID, nil
+}
+
+// EjectVirtualMedia ejects the media from the virtual media slot with the provided ID using the Redfish API. Ejecting
+// from a slot without media inserted is not an error.
+func (bmc *BMC) EjectVirtualMedia(slot string) error {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Ejecting virtual media from slot %s", slot)
+
+	if slot == "" {
+		glog.V(100).Info("The virtual media slot is empty")
+
+		return fmt.Errorf("virtual media 'slot' cannot be empty")
+	}
+
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	media, err := redfishGetVirtualMediaSlot(redfishClient, bmc.systemIndex, slot)
+	if err != nil {
+		glog.V(100).Infof("Failed to get virtual media slot %s: %v", slot, err)
+
+		return err
+	}
+
+	if !media.Inserted {
+		glog.V(100).Infof("Virtual media slot %s has no media inserted", slot)
+
+		return nil
+	}
+
+	err = media.EjectMedia()
+	if err != nil {
+		glog.V(100).Infof("Failed to eject virtual media from slot %s: %v", slot, err)
+
+		return fmt.Errorf("failed to eject virtual media from slot %s: %w", slot, err)
+	}
+
+	return nil
+}
+
+// WaitForVirtualMediaInserted waits up to timeout until the virtual media slot with the provided ID has media
+// inserted and connected through a URI.
+func (bmc *BMC) WaitForVirtualMediaInserted(slot string, timeout time.Duration) error {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Waiting up to %s until virtual media slot %s has media inserted", timeout, slot)
+
+	if slot == "" {
+		glog.V(100).Info("The virtual media slot is empty")
+
+		return fmt.Errorf("virtual media 'slot' cannot be empty")
+	}
+
+	return wait.PollUntilContextTimeout(
+		context.TODO(), 10*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+			status, err := bmc.VirtualMediaStatus(slot)
+			if err != nil {
+				glog.V(100).Infof("Failed to get status of virtual media slot %s: %v", slot, err)
+
+				return false, nil
+			}
+
+			return status.Inserted && status.ConnectedVia == redfish.URIConnectedVia, nil
+		})
+}
+
+// BootFromVirtualMedia inserts the image available at imageURL in a virtual media slot, as InsertVirtualMedia does,
+// and sets the system to boot from it only once. The boot target depends on options.MediaType and, for CD and DVD
+// images, on the boot override quirks of the vendor profile of the system. The ID of the slot the image was inserted
+// in is returned. If the boot override cannot be set, the image is ejected again.
+func (bmc *BMC) BootFromVirtualMedia(slot, imageURL string, options VirtualMediaOptions) (string, error) {
+	if valid, err := bmc.validateRedfish(); !valid {
+		return "", err
+	}
+
+	if options.MediaType == "" {
+		options.MediaType = redfish.CDMediaType
+	}
+
+	glog.V(100).Infof("Setting to boot from %s virtual media %s", options.MediaType, imageURL)
+
+	bootTarget, err := getVirtualMediaBootTarget(options.MediaType)
+	if err != nil {
+		glog.V(100).Infof("Failed to get boot target: %v", err)
+
+		return "", err
+	}
+
+	slot, err = bmc.InsertVirtualMedia(slot, imageURL, options)
+	if err != nil {
+		return "", err
+	}
+
+	err = bmc.setVirtualMediaBootOverride(bootTarget)
+	if err != nil {
+		// The image is ejected so that a failed call does not leave the slot occupied.
+		ejectErr := bmc.EjectVirtualMedia(slot)
+		if ejectErr != nil {
+			glog.V(100).Infof("Failed to eject virtual media from slot %s: %v", slot, ejectErr)
+
+			return "", errors.Join(err, ejectErr)
+		}
+
+		return "", err
+	}
+
+	return slot, nil
+}
+
+// setVirtualMediaBootOverride sets the system to boot from the virtual media boot target only once, applying the boot
+// override quirks of the vendor profile of the system.
+func (bmc *BMC) setVirtualMediaBootOverride(bootTarget redfish.BootSourceOverrideTarget) error {
+	redfishClient, cancel, err := redfishConnect(
+		bmc.host,
+		bmc.redfishUser.Name,
+		bmc.redfishUser.Password,
+		bmc.timeOuts.Redfish)
+	if err != nil {
+		glog.V(100).Infof("Redfish connection error: %v", err)
+
+		return fmt.Errorf("redfish connection error: %w", err)
+	}
+
+	defer func() {
+		redfishClient.Logout()
+		cancel()
+	}()
+
+	system, err := redfishGetSystem(redfishClient, bmc.systemIndex)
+	if err != nil {
+		glog.V(100).Infof("Failed to get redfish system: %v", err)
+
+		return fmt.Errorf("failed to get redfish system: %w", err)
+	}
+
+	newBoot := redfish.Boot{
+		BootSourceOverrideEnabled: redfish.OnceBootSourceOverrideEnabled,
+		BootSourceOverrideTarget:  bootTarget,
+	}
+
+	quirks := getSystemVendorProfile(system).BootOverride
+	if quirks.VirtualMediaTarget != "" && bootTarget == redfish.CdBootSourceOverrideTarget {
+		newBoot.BootSourceOverrideTarget = quirks.VirtualMediaTarget
+	}
+
+	newBoot.BootSourceOverrideMode = quirks.Mode
+
+	glog.V(100).Infof("Setting new Boot value: %+v", newBoot)
+
+	err = system.SetBoot(newBoot)
+	if err != nil {
+		glog.V(100).Infof("Failed to set boot override: %v", err)
+
+		return fmt.Errorf("failed to set boot override: %w", err)
+	}
+
+	return nil
+}
+
+// newVirtualMediaSlot converts the gofish virtual media to a VirtualMediaSlot.
+func newVirtualMediaSlot(media *redfish.VirtualMedia) VirtualMediaSlot {
+	return VirtualMediaSlot{
+		ID:             media.ID,
+		MediaTypes:     media.MediaTypes,
+		Image:          media.Image,
+		Inserted:       media.Inserted,
+		ConnectedVia:   media.ConnectedVia,
+		WriteProtected: media.WriteProtected,
+		SupportsInsert: media.SupportsMediaInsert,
+		SupportsEject:  media.SupportsMediaEject,
+	}
+}
+
+// getVirtualMediaBootTarget returns the one-time boot target used to boot from the media type.
+func getVirtualMediaBootTarget(mediaType redfish.VirtualMediaType) (redfish.BootSourceOverrideTarget, error) {
+	switch mediaType {
+	case redfish.CDMediaType, redfish.DVDMediaType:
+		return redfish.CdBootSourceOverrideTarget, nil
+	case redfish.USBStickMediaType:
+		return redfish.UsbBootSourceOverrideTarget, nil
+	case redfish.FloppyMediaType:
+		return redfish.FloppyBootSourceOverrideTarget, nil
+	default:
+		return "", fmt.Errorf("cannot boot from unknown virtual media type %s", mediaType)
+	}
+}
+
+// redfishGetVirtualMedia uses the provided gofish APIClient and the system index to get the virtual media of a system.
+func redfishGetVirtualMedia(redfishClient *gofish.APIClient, systemIndex int) ([]*redfish.VirtualMedia, error) {
+	system, err := redfishGetSystem(redfishClient, systemIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redfish system: %w", err)
+	}
+
+	virtualMedia, err := system.VirtualMedia()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get virtual media: %w", err)
+	}
+
+	return virtualMedia, nil
+}
+
+// redfishGetVirtualMediaSlot uses the provided gofish APIClient and the system index to get the virtual media slot
+// with the provided ID.
+func redfishGetVirtualMediaSlot(
+	redfishClient *gofish.APIClient, systemIndex int, slot string) (*redfish.VirtualMedia, error) {
+	virtualMedia, err := redfishGetVirtualMedia(redfishClient, systemIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, media := range virtualMedia {
+		if media.ID == slot {
+			return media, nil
+		}
+	}
+
+	return nil, fmt.Errorf("virtual media slot %s not found", slot)
+}
+
+// redfishFindVirtualMediaSlot uses the provided gofish APIClient and the system index to get the virtual media slot
+// with the provided ID, checking it supports the media type. If slot is empty, the first slot supporting the media
+// type and media insertion, with no media inserted, is returned.
+func redfishFindVirtualMediaSlot(
+	redfishClient *gofish.APIClient,
+	systemIndex int,
+	slot string,
+	mediaType redfish.VirtualMediaType) (*redfish.VirtualMedia, error) {
+	if slot != "" {
+		media, err := redfishGetVirtualMediaSlot(redfishClient, systemIndex, slot)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(media.MediaTypes, mediaType) {
+			return nil, fmt.Errorf("virtual media slot %s does not support media type %s", slot, mediaType)
+		}
+
+		return media, nil
+	}
+
+	system, err := redfishGetSystem(redfishClient, systemIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redfish system: %w", err)
+	}
+
+	virtualMedia, err := system.VirtualMedia()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get virtual media: %w", err)
+	}
+
+	preferredSlot := getSystemVendorProfile(system).VirtualMediaSlot
+	if preferredSlot != "" && (mediaType == redfish.CDMediaType || mediaType == redfish.DVDMediaType) {
+		for _, media := range virtualMedia {
+			if media.ID == preferredSlot && isVirtualMediaSlotAvailable(media, mediaType) {
+				glog.V(100).Infof("Found preferred virtual media slot %s for media type %s", media.ID, mediaType)
+
+				return media, nil
+			}
+		}
+	}
+
+	for _, media := range virtualMedia {
+		if isVirtualMediaSlotAvailable(media, mediaType) {
+			glog.V(100).Infof("Found virtual media slot %s for media type %s", media.ID, mediaType)
+
+			return media, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no available virtual media slot found for media type %s", mediaType)
+}
+
+// isVirtualMediaSlotAvailable returns true if the virtual media slot supports the media type, allows inserting media,
+// and has no media inserted.
+func isVirtualMediaSlotAvailable(media *redfish.VirtualMedia, mediaType redfish.VirtualMediaType) bool {
+	return media.SupportsMediaInsert && !media.Inserted && slices.Contains(media.MediaTypes, mediaType)
+}
@@ -0,0 +1,270 @@
+package bmc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish/redfish"
+	"github.com/stretchr/testify/assert"
+)
+
+const defaultImageURL = "http://10.0.0.1/discovery.iso"
+
+func TestBMCListVirtualMedia(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+
+	slots, err := New(host).WithRedfishUser(defaultUsername, defaultPassword).ListVirtualMedia()
+	assert.Nil(t, err)
+	assert.Len(t, slots, 2)
+	assert.Equal(t, "1", slots[0].ID)
+	assert.True(t, slots[0].SupportsInsert)
+	assert.Equal(t, "2", slots[1].ID)
+	assert.False(t, slots[1].SupportsInsert)
+	assert.Equal(t,
+		[]redfish.VirtualMediaType{redfish.CDMediaType, redfish.DVDMediaType, redfish.USBStickMediaType},
+		slots[1].MediaTypes)
+
+	_, err = New(host).ListVirtualMedia()
+	assert.Equal(t, fmt.Errorf("cannot access redfish with nil user"), err)
+}
+
+func TestBMCVirtualMediaStatus(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+
+	testCases := []struct {
+		slot          string
+		expectedError error
+	}{
+		{
+			slot:          "1",
+			expectedError: nil,
+		},
+		{
+			slot:          "",
+			expectedError: fmt.Errorf("virtual media 'slot' cannot be empty"),
+		},
+		{
+			slot:          "4",
+			expectedError: fmt.Errorf("virtual media slot 4 not found"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+		status, err := bmc.VirtualMediaStatus(testCase.slot)
+
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, testCase.slot, status.ID)
+			assert.False(t, status.Inserted)
+		}
+	}
+}
+
+func TestBMCInsertVirtualMedia(t *testing.T) {
+	testCases := []struct {
+		slot          string
+		imageURL      string
+		mediaType     redfish.VirtualMediaType
+		expectedSlot  string
+		expectedError string
+	}{
+		{
+			slot:          "",
+			imageURL:      defaultImageURL,
+			expectedSlot:  "1",
+			expectedError: "",
+		},
+		{
+			slot:          "1",
+			imageURL:      defaultImageURL,
+			mediaType:     redfish.USBStickMediaType,
+			expectedSlot:  "1",
+			expectedError: "",
+		},
+		{
+			slot:          "",
+			imageURL:      "",
+			expectedError: "virtual media 'imageURL' cannot be empty",
+		},
+		{
+			slot:          "",
+			imageURL:      defaultImageURL,
+			mediaType:     "Tape",
+			expectedError: "no available virtual media slot found for media type Tape",
+		},
+		{
+			slot:          "2",
+			imageURL:      defaultImageURL,
+			mediaType:     redfish.FloppyMediaType,
+			expectedError: "virtual media slot 2 does not support media type Floppy",
+		},
+		{
+			slot:     "2",
+			imageURL: defaultImageURL,
+			expectedError: "failed to insert virtual media in slot 2: " +
+				"redfish service does not support VirtualMedia.InsertMedia calls",
+		},
+	}
+
+	for _, testCase := range testCases {
+		redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+		host := strings.Split(redfishServer.URL, "//")[1]
+
+		bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+		slot, err := bmc.InsertVirtualMedia(
+			testCase.slot, testCase.imageURL, VirtualMediaOptions{MediaType: testCase.mediaType})
+
+		if testCase.expectedError != "" {
+			assert.EqualError(t, err, testCase.expectedError)
+		} else {
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.expectedSlot, slot)
+
+			status, err := bmc.VirtualMediaStatus(slot)
+			assert.Nil(t, err)
+			assert.True(t, status.Inserted)
+			assert.Equal(t, defaultImageURL, status.Image)
+		}
+
+		redfishServer.Close()
+	}
+}
+
+func TestBMCEjectVirtualMedia(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+	err := bmc.EjectVirtualMedia("")
+	assert.Equal(t, fmt.Errorf("virtual media 'slot' cannot be empty"), err)
+
+	// Ejecting from an empty slot is a no-op.
+	err = bmc.EjectVirtualMedia("1")
+	assert.Nil(t, err)
+
+	_, err = bmc.InsertVirtualMedia("1", defaultImageURL, VirtualMediaOptions{})
+	assert.Nil(t, err)
+
+	err = bmc.EjectVirtualMedia("1")
+	assert.Nil(t, err)
+
+	status, err := bmc.VirtualMediaStatus("1")
+	assert.Nil(t, err)
+	assert.False(t, status.Inserted)
+}
+
+func TestBMCWaitForVirtualMediaInserted(t *testing.T) {
+	redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{})
+	defer redfishServer.Close()
+
+	host := strings.Split(redfishServer.URL, "//")[1]
+	bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+
+	err := bmc.WaitForVirtualMediaInserted("1", time.Second)
+	assert.NotNil(t, err)
+
+	_, err = bmc.InsertVirtualMedia("", defaultImageURL, VirtualMediaOptions{})
+	assert.Nil(t, err)
+
+	err = bmc.WaitForVirtualMediaInserted("1", time.Second)
+	assert.Nil(t, err)
+
+	err = bmc.WaitForVirtualMediaInserted("", time.Second)
+	assert.Equal(t, fmt.Errorf("virtual media 'slot' cannot be empty"), err)
+}
+
+func TestBMCBootFromVirtualMedia(t *testing.T) {
+	testCases := []struct {
+		mediaType       redfish.VirtualMediaType
+		rejectBoot      bool
+		expectedTarget  redfish.BootSourceOverrideTarget
+		expectedError   string
+		expectedPatches int
+	}{
+		{
+			mediaType:       "",
+			expectedTarget:  redfish.CdBootSourceOverrideTarget,
+			expectedError:   "",
+			expectedPatches: 1,
+		},
+		{
+			mediaType:       redfish.USBStickMediaType,
+			expectedTarget:  redfish.UsbBootSourceOverrideTarget,
+			expectedError:   "",
+			expectedPatches: 1,
+		},
+		{
+			mediaType:       redfish.FloppyMediaType,
+			expectedTarget:  redfish.FloppyBootSourceOverrideTarget,
+			expectedError:   "",
+			expectedPatches: 1,
+		},
+		{
+			mediaType:       "Tape",
+			expectedError:   "cannot boot from unknown virtual media type Tape",
+			expectedPatches: 0,
+		},
+		{
+			mediaType:       redfish.CDMediaType,
+			rejectBoot:      true,
+			expectedTarget:  redfish.CdBootSourceOverrideTarget,
+			expectedError:   "failed to set boot override",
+			expectedPatches: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var boots []redfish.Boot
+
+		redfishServer := createFakeRedfishLocalServer(false, redfishAPIResponseCallbacks{
+			setBoot: func(r *http.Request) bool {
+				patch := struct {
+					Boot redfish.Boot
+				}{}
+				_ = json.NewDecoder(r.Body).Decode(&patch)
+				boots = append(boots, patch.Boot)
+
+				return !testCase.rejectBoot
+			},
+		})
+		host := strings.Split(redfishServer.URL, "//")[1]
+
+		bmc := New(host).WithRedfishUser(defaultUsername, defaultPassword)
+		slot, err := bmc.BootFromVirtualMedia("", defaultImageURL, VirtualMediaOptions{MediaType: testCase.mediaType})
+
+		if testCase.expectedError != "" {
+			assert.ErrorContains(t, err, testCase.expectedError)
+		} else {
+			assert.Nil(t, err)
+			assert.Equal(t, "1", slot)
+		}
+
+		assert.Len(t, boots, testCase.expectedPatches)
+
+		if testCase.expectedPatches > 0 {
+			assert.Equal(t, redfish.OnceBootSourceOverrideEnabled, boots[0].BootSourceOverrideEnabled)
+			assert.Equal(t, testCase.expectedTarget, boots[0].BootSourceOverrideTarget)
+		}
+
+		// The image is only left inserted if the system was set to boot from it.
+		status, err := bmc.VirtualMediaStatus("1")
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedError == "", status.Inserted)
+
+		redfishServer.Close()
+	}
+}
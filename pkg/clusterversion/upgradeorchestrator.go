@@ -0,0 +1,606 @@
+package clusterversion
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/golang/glog"
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clusteroperator"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/configmap"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/mco"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// AdminGatesConfigMapName is the name of the configmap listing the admin-gates that must be acknowledged before
+	// updating to the next minor version.
+	AdminGatesConfigMapName = "admin-gates"
+	// AdminGatesConfigMapNamespace is the namespace of the admin-gates configmap.
+	AdminGatesConfigMapNamespace = "openshift-config-managed"
+	// AdminAcksConfigMapName is the name of the configmap where admin-gates are acknowledged.
+	AdminAcksConfigMapName = "admin-acks"
+	// AdminAcksConfigMapNamespace is the namespace of the admin-acks configmap.
+	AdminAcksConfigMapNamespace = "openshift-config"
+
+	upgradeHopPollInterval     = 10 * time.Second
+	upgradeUpdatesPollInterval = time.Second
+	// defaultDegradedTolerance covers the short Degraded conditions clusteroperators report while they are updated.
+	defaultDegradedTolerance = 5 * time.Minute
+)
+
+// UpgradeHop is a single update of the cluster to a release.
+type UpgradeHop struct {
+	// Version is the version of the release to update to.
+	Version string
+	// Image is the pull spec of the release. If empty, it is taken from the available updates of the cluster.
+	Image string
+	// Channel is set on the clusterversion before the update if not empty, for example to move to the next EUS
+	// channel.
+	Channel string
+	// Force updates to the release even if it is not signed or the cluster reports it is not upgradeable.
+	Force bool
+}
+
+// UpgradeHopTimeline records when and how a hop was performed.
+type UpgradeHopTimeline struct {
+	// FromVersion is the version of the cluster before the hop.
+	FromVersion string
+	// ToVersion is the version the hop updated to.
+	ToVersion string
+	// Image is the release image the hop updated to.
+	Image string
+	// AcknowledgedGates contains the admin-gates acknowledged before the hop.
+	AcknowledgedGates []string
+	// StartTime is when the desired update was set on the clusterversion.
+	StartTime time.Time
+	// CompletionTime is when the clusterversion reported the update completed. It is zero if the hop failed.
+	CompletionTime time.Time
+}
+
+// Duration returns how long the hop took, or zero if it did not complete.
+func (hop UpgradeHopTimeline) Duration() time.Duration {
+	if hop.CompletionTime.IsZero() {
+		return 0
+	}
+
+	return hop.CompletionTime.Sub(hop.StartTime)
+}
+
+// UpgradeTimeline is returned by UpgradeOrchestrator.Run with the timeline of each hop and of the paused pools.
+type UpgradeTimeline struct {
+	// Hops contains a timeline entry for each hop that was started, in order.
+	Hops []UpgradeHopTimeline
+	// PausedPools contains the MachineConfigPools paused for the duration of the upgrade.
+	PausedPools []string
+	// PoolsUnpausedTime is when the paused MachineConfigPools were unpaused.
+	PoolsUnpausedTime time.Time
+	// PoolsUpdatedTime is when every unpaused MachineConfigPool finished rolling out the new config.
+	PoolsUpdatedTime time.Time
+}
+
+// DegradedClusterOperator describes a clusteroperator that reported it is degraded.
+type DegradedClusterOperator struct {
+	// Name is the name of the clusteroperator.
+	Name string
+	// Reason is the reason of the Degraded condition.
+	Reason string
+	// Message is the message of the Degraded condition.
+	Message string
+	// Since is when the clusteroperator became degraded.
+	Since time.Time
+}
+
+// UpgradeStalledError is returned by UpgradeOrchestrator.Run when clusteroperators stay degraded during a hop.
+type UpgradeStalledError struct {
+	// Version is the version of the hop that stalled.
+	Version string
+	// DegradedOperators contains the clusteroperators degraded for longer than the tolerance.
+	DegradedOperators []DegradedClusterOperator
+}
+
+// Error returns a summary of the degraded clusteroperators.
+func (stalledErr *UpgradeStalledError) Error() string {
+	var reasons []string
+
+	for _, operator := range stalledErr.DegradedOperators {
+		reasons = append(reasons, fmt.Sprintf("clusteroperator %s is degraded since %s (%s: %s)",
+			operator.Name, operator.Since.UTC().Format(time.RFC3339), operator.Reason, operator.Message))
+	}
+
+	return fmt.Sprintf("upgrade to %s stalled: %s", stalledErr.Version, strings.Join(reasons, "; "))
+}
+
+// UpgradeOrchestrator updates the cluster through a list of versions, such as the intermediate version of an
+// EUS-to-EUS upgrade followed by the target version. Worker pools can be kept paused for the whole upgrade so their
+// nodes are rebooted once, and admin-gates can be acknowledged before each hop.
+type UpgradeOrchestrator struct {
+	// Hops are the updates to perform, in order.
+	Hops []UpgradeHop
+	// pausedPools are paused before the first hop and unpaused after the last one.
+	pausedPools []string
+	// acknowledgeAdminGates acknowledges the admin-gates of the current version before each hop.
+	acknowledgeAdminGates bool
+	// acceptConditionalUpdates allows resolving hop images from conditional updates.
+	acceptConditionalUpdates bool
+	// degradedTolerance is how long a clusteroperator may stay degraded before the upgrade is stalled.
+	degradedTolerance time.Duration
+	// api client to interact with the cluster.
+	apiClient *clients.Settings
+	// errorMsg is processed before the upgrade is run.
+	errorMsg string
+}
+
+// NewUpgradeOrchestrator creates a new instance of UpgradeOrchestrator updating the cluster through the hops.
+func NewUpgradeOrchestrator(apiClient *clients.Settings, hops ...UpgradeHop) *UpgradeOrchestrator {
+	glog.V(100).Infof("Initializing new UpgradeOrchestrator with %d hops", len(hops))
+
+	if apiClient == nil {
+		glog.V(100).Infof("The apiClient cannot be nil")
+
+		return nil
+	}
+
+	orchestrator := &UpgradeOrchestrator{
+		Hops:              hops,
+		degradedTolerance: defaultDegradedTolerance,
+		apiClient:         apiClient,
+	}
+
+	if len(hops) == 0 {
+		glog.V(100).Infof("The hops of the UpgradeOrchestrator are empty")
+
+		orchestrator.errorMsg = "upgradeOrchestrator 'hops' cannot be empty"
+
+		return orchestrator
+	}
+
+	for _, hop := range hops {
+		if hop.Version == "" {
+			glog.V(100).Infof("The UpgradeOrchestrator has a hop without version")
+
+			orchestrator.errorMsg = "upgradeOrchestrator hop 'Version' cannot be empty"
+
+			return orchestrator
+		}
+	}
+
+	return orchestrator
+}
+
+// WithPausedPools pauses the MachineConfigPools before the first hop and unpauses them once the last hop completes,
+// as done for the worker pools during an EUS-to-EUS upgrade.
+func (orchestrator *UpgradeOrchestrator) WithPausedPools(poolNames ...string) *UpgradeOrchestrator {
+	if valid, _ := orchestrator.validate(); !valid {
+		return orchestrator
+	}
+
+	glog.V(100).Infof("Setting UpgradeOrchestrator paused pools to %v", poolNames)
+
+	if len(poolNames) == 0 || slices.Contains(poolNames, "") {
+		glog.V(100).Infof("The UpgradeOrchestrator paused pools are invalid")
+
+		orchestrator.errorMsg = "upgradeOrchestrator paused pool names cannot be empty"
+
+		return orchestrator
+	}
+
+	orchestrator.pausedPools = poolNames
+
+	return orchestrator
+}
+
+// WithAdminGateAcknowledgement acknowledges the admin-gates that apply to the current version before each hop.
+func (orchestrator *UpgradeOrchestrator) WithAdminGateAcknowledgement() *UpgradeOrchestrator {
+	if valid, _ := orchestrator.validate(); !valid {
+		return orchestrator
+	}
+
+	glog.V(100).Infof("Enabling UpgradeOrchestrator admin-gate acknowledgement")
+
+	orchestrator.acknowledgeAdminGates = true
+
+	return orchestrator
+}
+
+// WithConditionalUpdates allows hops without an image to be resolved from the conditional updates of the cluster.
+func (orchestrator *UpgradeOrchestrator) WithConditionalUpdates() *UpgradeOrchestrator {
+	if valid, _ := orchestrator.validate(); !valid {
+		return orchestrator
+	}
+
+	glog.V(100).Infof("Enabling UpgradeOrchestrator conditional updates")
+
+	orchestrator.acceptConditionalUpdates = true
+
+	return orchestrator
+}
+
+// WithDegradedTolerance sets how long a clusteroperator may stay degraded during a hop before the upgrade is
+// considered stalled. It defaults to 5 minutes. Clusteroperators already degraded when the hop starts are counted from
+// the start of the hop.
+func (orchestrator *UpgradeOrchestrator) WithDegradedTolerance(tolerance time.Duration) *UpgradeOrchestrator {
+	if valid, _ := orchestrator.validate(); !valid {
+		return orchestrator
+	}
+
+	glog.V(100).Infof("Setting UpgradeOrchestrator degraded tolerance to %s", tolerance)
+
+	if tolerance < 0 {
+		orchestrator.errorMsg = "upgradeOrchestrator degraded tolerance cannot be negative"
+
+		return orchestrator
+	}
+
+	orchestrator.degradedTolerance = tolerance
+
+	return orchestrator
+}
+
+// Run performs the hops in order, waiting up to hopTimeout for each of them to be resolved and to complete, then
+// unpauses the paused pools and waits up to poolTimeout for their rollout. If a clusteroperator stays degraded during
+// a hop, an *UpgradeStalledError is returned. The timeline is returned whenever it is available, including when an
+// error is returned. The pools are left paused if a hop fails.
+func (orchestrator *UpgradeOrchestrator) Run(hopTimeout, poolTimeout time.Duration) (*UpgradeTimeline, error) {
+	if valid, err := orchestrator.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Running upgrade through versions %v", orchestrator.getHopVersions())
+
+	clusterVersion, err := Pull(orchestrator.apiClient)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := &UpgradeTimeline{}
+
+	for _, poolName := range orchestrator.pausedPools {
+		err = orchestrator.setPoolPaused(poolName, true)
+		if err != nil {
+			return timeline, err
+		}
+
+		timeline.PausedPools = append(timeline.PausedPools, poolName)
+	}
+
+	fromVersion := clusterVersion.Object.Status.Desired.Version
+
+	for _, hop := range orchestrator.Hops {
+		hopTimeline, err := orchestrator.runHop(clusterVersion, hop, fromVersion, hopTimeout)
+		if hopTimeline != nil {
+			timeline.Hops = append(timeline.Hops, *hopTimeline)
+		}
+
+		if err != nil {
+			return timeline, err
+		}
+
+		fromVersion = hop.Version
+	}
+
+	if len(orchestrator.pausedPools) == 0 {
+		return timeline, nil
+	}
+
+	err = orchestrator.unpausePools(timeline, poolTimeout)
+
+	return timeline, err
+}
+
+// runHop updates the cluster to the hop release and waits for the update to complete.
+func (orchestrator *UpgradeOrchestrator) runHop(
+	clusterVersion *Builder, hop UpgradeHop, fromVersion string, timeout time.Duration) (*UpgradeHopTimeline, error) {
+	glog.V(100).Infof("Updating cluster from %s to %s", fromVersion, hop.Version)
+
+	deadline := time.Now().Add(timeout)
+	hopTimeline := &UpgradeHopTimeline{FromVersion: fromVersion, ToVersion: hop.Version}
+
+	if hop.Channel != "" {
+		_, err := clusterVersion.WithDesiredUpdateChannel(hop.Channel).Update()
+		if err != nil {
+			return nil, fmt.Errorf("failed to set channel %s before update to %s: %w", hop.Channel, hop.Version, err)
+		}
+	}
+
+	if orchestrator.acknowledgeAdminGates {
+		acknowledgedGates, err := orchestrator.acknowledgeGates(fromVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acknowledge admin-gates before update to %s: %w", hop.Version, err)
+		}
+
+		hopTimeline.AcknowledgedGates = acknowledgedGates
+	}
+
+	image, err := orchestrator.resolveHopImage(clusterVersion, hop, time.Until(deadline))
+	if err != nil {
+		return nil, err
+	}
+
+	hopTimeline.Image = image
+	hopTimeline.StartTime = time.Now()
+
+	_, err = clusterVersion.WithDesiredUpdateImage(image, hop.Force).Update()
+	if err != nil {
+		return hopTimeline, fmt.Errorf("failed to set desired update to %s: %w", hop.Version, err)
+	}
+
+	err = orchestrator.waitForHop(clusterVersion, hop.Version, image, hopTimeline.StartTime, time.Until(deadline))
+	if err != nil {
+		return hopTimeline, err
+	}
+
+	hopTimeline.CompletionTime = time.Now()
+
+	return hopTimeline, nil
+}
+
+// resolveHopImage returns the image of the hop, waiting up to timeout for the hop version to be listed in the updates
+// of the cluster if the image is not set. The cluster-version operator retrieves the updates asynchronously, so they
+// may be missing right after the channel changed or a previous hop completed.
+func (orchestrator *UpgradeOrchestrator) resolveHopImage(
+	clusterVersion *Builder, hop UpgradeHop, timeout time.Duration) (string, error) {
+	if hop.Image != "" {
+		return hop.Image, nil
+	}
+
+	var image string
+
+	err := wait.PollUntilContextTimeout(
+		context.TODO(), upgradeUpdatesPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+			if !clusterVersion.Exists() || clusterVersion.Object == nil {
+				return false, nil
+			}
+
+			image = orchestrator.findUpdateImage(clusterVersion.Object, hop.Version)
+
+			return image != "", nil
+		})
+	if err != nil {
+		return "", fmt.Errorf("version %s is not an available update of the cluster: %w", hop.Version, err)
+	}
+
+	return image, nil
+}
+
+// findUpdateImage returns the image of the version in the updates of the clusterversion, or an empty string if the
+// version is not listed.
+func (orchestrator *UpgradeOrchestrator) findUpdateImage(
+	clusterVersion *configv1.ClusterVersion, version string) string {
+	for _, availableUpdate := range clusterVersion.Status.AvailableUpdates {
+		if availableUpdate.Version == version {
+			return availableUpdate.Image
+		}
+	}
+
+	if orchestrator.acceptConditionalUpdates {
+		for _, conditionalUpdate := range clusterVersion.Status.ConditionalUpdates {
+			if conditionalUpdate.Release.Version == version {
+				return conditionalUpdate.Release.Image
+			}
+		}
+	}
+
+	return ""
+}
+
+// waitForHop waits up to timeout for the update to image started at startTime to complete, failing early if
+// clusteroperators stay degraded for longer than the tolerance.
+func (orchestrator *UpgradeOrchestrator) waitForHop(
+	clusterVersion *Builder, version, image string, startTime time.Time, timeout time.Duration) error {
+	var stalledErr *UpgradeStalledError
+
+	err := wait.PollUntilContextTimeout(
+		context.TODO(), upgradeHopPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+			stalledErr = orchestrator.getStalledError(version, startTime)
+			if stalledErr != nil {
+				return true, nil
+			}
+
+			if !clusterVersion.Exists() || clusterVersion.Object == nil {
+				return false, nil
+			}
+
+			for _, updateHistory := range clusterVersion.Object.Status.History {
+				if updateHistory.Image == image && updateHistory.State == configv1.CompletedUpdate {
+					return true, nil
+				}
+			}
+
+			return false, nil
+		})
+
+	if stalledErr != nil {
+		return stalledErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("update to %s did not complete: %w", version, err)
+	}
+
+	return nil
+}
+
+// getStalledError returns an error listing the clusteroperators degraded for longer than the tolerance, or nil if
+// there are none or the clusteroperators cannot be listed. Clusteroperators degraded before startTime are counted
+// from startTime, so that a degradation predating the hop does not stall it right away.
+func (orchestrator *UpgradeOrchestrator) getStalledError(version string, startTime time.Time) *UpgradeStalledError {
+	clusterOperators, err := clusteroperator.List(orchestrator.apiClient)
+	if err != nil {
+		glog.V(100).Infof("Failed to list clusteroperators during update to %s: %v", version, err)
+
+		return nil
+	}
+
+	stalledErr := &UpgradeStalledError{Version: version}
+
+	for _, clusterOperator := range clusterOperators {
+		for _, condition := range clusterOperator.Object.Status.Conditions {
+			if condition.Type != configv1.OperatorDegraded || condition.Status != configv1.ConditionTrue {
+				continue
+			}
+
+			degradedSince := condition.LastTransitionTime.Time
+			if degradedSince.Before(startTime) {
+				degradedSince = startTime
+			}
+
+			if time.Since(degradedSince) < orchestrator.degradedTolerance {
+				continue
+			}
+
+			glog.V(100).Infof("Clusteroperator %s is degraded during update to %s: %s",
+				clusterOperator.Object.Name, version, condition.Message)
+
+			stalledErr.DegradedOperators = append(stalledErr.DegradedOperators, DegradedClusterOperator{
+				Name:    clusterOperator.Object.Name,
+				Reason:  condition.Reason,
+				Message: condition.Message,
+				Since:   condition.LastTransitionTime.Time,
+			})
+		}
+	}
+
+	if len(stalledErr.DegradedOperators) == 0 {
+		return nil
+	}
+
+	return stalledErr
+}
+
+// acknowledgeGates acknowledges in the admin-acks configmap every admin-gate that applies to updates from
+// fromVersion. It returns the acknowledged gates, sorted by name.
+func (orchestrator *UpgradeOrchestrator) acknowledgeGates(fromVersion string) ([]string, error) {
+	adminGates := configmap.NewBuilder(orchestrator.apiClient, AdminGatesConfigMapName, AdminGatesConfigMapNamespace)
+	if !adminGates.Exists() || adminGates.Object == nil {
+		glog.V(100).Infof("The admin-gates configmap does not exist, there are no gates to acknowledge")
+
+		return nil, nil
+	}
+
+	version, err := semver.NewVersion(fromVersion)
+	if err != nil {
+		return nil, fmt.Errorf("the version %s is invalid: %w", fromVersion, err)
+	}
+
+	gatePrefix := fmt.Sprintf("ack-%d.%d-", version.Major(), version.Minor())
+
+	var gates []string
+
+	for gate := range adminGates.Object.Data {
+		if strings.HasPrefix(gate, gatePrefix) {
+			gates = append(gates, gate)
+		}
+	}
+
+	if len(gates) == 0 {
+		return nil, nil
+	}
+
+	slices.Sort(gates)
+
+	adminAcks, err := configmap.Pull(orchestrator.apiClient, AdminAcksConfigMapName, AdminAcksConfigMapNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	acks := maps.Clone(adminAcks.Object.Data)
+	if acks == nil {
+		acks = map[string]string{}
+	}
+
+	for _, gate := range gates {
+		glog.V(100).Infof("Acknowledging admin-gate %s: %s", gate, adminGates.Object.Data[gate])
+
+		acks[gate] = "true"
+	}
+
+	_, err = adminAcks.WithData(acks).Update()
+	if err != nil {
+		return nil, err
+	}
+
+	return gates, nil
+}
+
+// unpausePools unpauses the paused pools and waits up to timeout for each of them to roll out its new config.
+func (orchestrator *UpgradeOrchestrator) unpausePools(timeline *UpgradeTimeline, timeout time.Duration) error {
+	for _, poolName := range orchestrator.pausedPools {
+		err := orchestrator.setPoolPaused(poolName, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	timeline.PoolsUnpausedTime = time.Now()
+
+	for _, poolName := range orchestrator.pausedPools {
+		mcp, err := mco.Pull(orchestrator.apiClient, poolName)
+		if err != nil {
+			return err
+		}
+
+		_, err = mcp.TrackRollout(context.TODO(), mco.MCPRolloutOptions{Timeout: timeout})
+		if err != nil {
+			return err
+		}
+	}
+
+	timeline.PoolsUpdatedTime = time.Now()
+
+	return nil
+}
+
+// setPoolPaused pauses or unpauses the MachineConfigPool.
+func (orchestrator *UpgradeOrchestrator) setPoolPaused(poolName string, paused bool) error {
+	mcp, err := mco.Pull(orchestrator.apiClient, poolName)
+	if err != nil {
+		return err
+	}
+
+	if paused {
+		return mcp.Pause()
+	}
+
+	return mcp.Unpause()
+}
+
+// getHopVersions returns the versions of the hops, in order.
+func (orchestrator *UpgradeOrchestrator) getHopVersions() []string {
+	var versions []string
+
+	for _, hop := range orchestrator.Hops {
+		versions = append(versions, hop.Version)
+	}
+
+	return versions
+}
+
+// validate will check that the orchestrator is properly initialized before running the upgrade.
+func (orchestrator *UpgradeOrchestrator) validate() (bool, error) {
+	if orchestrator == nil {
+		glog.V(100).Infof("The UpgradeOrchestrator is uninitialized")
+
+		return false, fmt.Errorf("error: received nil UpgradeOrchestrator")
+	}
+
+	if orchestrator.apiClient == nil {
+		glog.V(100).Infof("The UpgradeOrchestrator apiclient is nil")
+
+		return false, fmt.Errorf("upgradeOrchestrator cannot have nil apiClient")
+	}
+
+	if orchestrator.errorMsg != "" {
+		glog.V(100).Infof("The UpgradeOrchestrator has error message: %s", orchestrator.errorMsg)
+
+		return false, fmt.Errorf("%s", orchestrator.errorMsg)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,414 @@
+package clusterversion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mcv1 "github.com/openshift/api/machineconfiguration/v1"
+	clientConfigV1 "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/configmap"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/mco"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultUpgradeFromVersion = "4.14.10"
+	defaultUpgradePool        = "worker"
+	defaultUpgradeGate414     = "ack-4.14-kube-1.28-api-removals-in-4.15"
+	defaultUpgradeGate415     = "ack-4.15-kube-1.29-api-removals-in-4.16"
+)
+
+var (
+	defaultUpgradeHops = []UpgradeHop{
+		{Version: "4.15.5", Channel: "eus-4.16"},
+		{Version: "4.16.3", Image: "quay.io/test/release:4.16.3"},
+	}
+	upgradeTestSchemes = []clients.SchemeAttacher{
+		configv1.Install,
+		mcv1.Install,
+	}
+)
+
+func TestNewUpgradeOrchestrator(t *testing.T) {
+	testCases := []struct {
+		hops          []UpgradeHop
+		client        bool
+		expectedError string
+	}{
+		{
+			hops:          defaultUpgradeHops,
+			client:        true,
+			expectedError: "",
+		},
+		{
+			hops:          nil,
+			client:        true,
+			expectedError: "upgradeOrchestrator 'hops' cannot be empty",
+		},
+		{
+			hops:          []UpgradeHop{{Image: "quay.io/test/release:4.15.5"}},
+			client:        true,
+			expectedError: "upgradeOrchestrator hop 'Version' cannot be empty",
+		},
+		{
+			hops:          defaultUpgradeHops,
+			client:        false,
+			expectedError: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		var testSettings *clients.Settings
+
+		if testCase.client {
+			testSettings = clients.GetTestClients(clients.TestClientParams{SchemeAttachers: upgradeTestSchemes})
+		}
+
+		orchestrator := NewUpgradeOrchestrator(testSettings, testCase.hops...)
+
+		if !testCase.client {
+			assert.Nil(t, orchestrator)
+
+			continue
+		}
+
+		assert.Equal(t, testCase.expectedError, orchestrator.errorMsg)
+	}
+}
+
+func TestUpgradeOrchestratorOptions(t *testing.T) {
+	testSettings := clients.GetTestClients(clients.TestClientParams{SchemeAttachers: upgradeTestSchemes})
+
+	orchestrator := NewUpgradeOrchestrator(testSettings, defaultUpgradeHops...).
+		WithPausedPools(defaultUpgradePool).
+		WithAdminGateAcknowledgement().
+		WithConditionalUpdates().
+		WithDegradedTolerance(time.Minute)
+	assert.Equal(t, "", orchestrator.errorMsg)
+	assert.Equal(t, []string{defaultUpgradePool}, orchestrator.pausedPools)
+	assert.True(t, orchestrator.acknowledgeAdminGates)
+	assert.True(t, orchestrator.acceptConditionalUpdates)
+	assert.Equal(t, time.Minute, orchestrator.degradedTolerance)
+
+	orchestrator = NewUpgradeOrchestrator(testSettings, defaultUpgradeHops...)
+	assert.Equal(t, defaultDegradedTolerance, orchestrator.degradedTolerance)
+
+	orchestrator = NewUpgradeOrchestrator(testSettings, defaultUpgradeHops...).WithPausedPools("")
+	assert.Equal(t, "upgradeOrchestrator paused pool names cannot be empty", orchestrator.errorMsg)
+
+	orchestrator = NewUpgradeOrchestrator(testSettings, defaultUpgradeHops...).WithDegradedTolerance(-time.Minute)
+	assert.Equal(t, "upgradeOrchestrator degraded tolerance cannot be negative", orchestrator.errorMsg)
+}
+
+func TestUpgradeOrchestratorRun(t *testing.T) {
+	degradedSince := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	recentlyDegraded := time.Now()
+
+	testCases := []struct {
+		hops              []UpgradeHop
+		degradedSince     *time.Time
+		degradedTolerance *time.Duration
+		expectedHops      int
+		expectedError     error
+	}{
+		{
+			hops:          defaultUpgradeHops,
+			expectedHops:  2,
+			expectedError: nil,
+		},
+		{
+			// A clusteroperator degraded before the hop started is tolerated for the default tolerance from the start
+			// of the hop.
+			hops:          defaultUpgradeHops,
+			degradedSince: &degradedSince,
+			expectedHops:  2,
+			expectedError: nil,
+		},
+		{
+			hops:              defaultUpgradeHops,
+			degradedSince:     &degradedSince,
+			degradedTolerance: ptr.To(time.Duration(0)),
+			expectedHops:      1,
+			expectedError: &UpgradeStalledError{Version: "4.15.5", DegradedOperators: []DegradedClusterOperator{{
+				Name: "etcd", Reason: "EtcdMembersDegraded", Message: "2 of 3 members are available", Since: degradedSince,
+			}}},
+		},
+		{
+			hops:              defaultUpgradeHops,
+			degradedSince:     &recentlyDegraded,
+			degradedTolerance: ptr.To(time.Hour),
+			expectedHops:      2,
+			expectedError:     nil,
+		},
+		{
+			hops:         []UpgradeHop{{Version: "4.15.99"}},
+			expectedHops: 0,
+			expectedError: fmt.Errorf("version 4.15.99 is not an available update of the cluster: %w",
+				context.DeadlineExceeded),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := buildTestClientWithDummyUpgradeObjects(testCase.degradedSince)
+
+		orchestrator := NewUpgradeOrchestrator(testSettings, testCase.hops...).
+			WithPausedPools(defaultUpgradePool).
+			WithAdminGateAcknowledgement()
+
+		if testCase.degradedTolerance != nil {
+			orchestrator = orchestrator.WithDegradedTolerance(*testCase.degradedTolerance)
+		}
+
+		timeline, err := orchestrator.Run(time.Second, time.Second)
+
+		assert.Len(t, timeline.Hops, testCase.expectedHops)
+		assert.Equal(t, []string{defaultUpgradePool}, timeline.PausedPools)
+
+		mcp, pullErr := mco.Pull(testSettings, defaultUpgradePool)
+		assert.Nil(t, pullErr)
+
+		if testCase.expectedError != nil {
+			assert.EqualError(t, err, testCase.expectedError.Error())
+			assert.True(t, mcp.Object.Spec.Paused)
+
+			var stalledErr *UpgradeStalledError
+
+			if errors.As(testCase.expectedError, &stalledErr) {
+				assert.True(t, errors.As(err, &stalledErr))
+				assert.True(t, timeline.Hops[0].CompletionTime.IsZero())
+			}
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.False(t, mcp.Object.Spec.Paused)
+		assertCompletedUpgrade(t, testSettings, timeline)
+	}
+}
+
+func TestUpgradeOrchestratorResolveHopImage(t *testing.T) {
+	testCases := []struct {
+		hop                 UpgradeHop
+		updatesDelay        time.Duration
+		conditionalUpdates  bool
+		expectedImage       string
+		expectedErrorString string
+	}{
+		{
+			hop:           UpgradeHop{Version: "4.15.5"},
+			expectedImage: "quay.io/test/release:4.15.5",
+		},
+		{
+			hop:           UpgradeHop{Version: "4.15.9", Image: "quay.io/test/release:custom"},
+			expectedImage: "quay.io/test/release:custom",
+		},
+		{
+			// The updates of a new channel are listed by the cluster-version operator after the channel is set.
+			hop:           UpgradeHop{Version: "4.16.1", Channel: "eus-4.16"},
+			updatesDelay:  100 * time.Millisecond,
+			expectedImage: "quay.io/test/release:4.16.1",
+		},
+		{
+			hop:                UpgradeHop{Version: "4.15.7"},
+			conditionalUpdates: true,
+			expectedImage:      "quay.io/test/release:4.15.7",
+		},
+		{
+			hop:                 UpgradeHop{Version: "4.15.7"},
+			expectedErrorString: "version 4.15.7 is not an available update of the cluster: context deadline exceeded",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := buildTestClientWithDummyUpgradeObjects(nil)
+
+		clusterVersion, err := Pull(testSettings)
+		assert.Nil(t, err)
+
+		clusterVersion.Object.Status.ConditionalUpdates = []configv1.ConditionalUpdate{{
+			Release: configv1.Release{Version: "4.15.7", Image: "quay.io/test/release:4.15.7"},
+		}}
+
+		err = testSettings.Update(context.TODO(), clusterVersion.Object)
+		assert.Nil(t, err)
+
+		if testCase.updatesDelay > 0 {
+			go func() {
+				time.Sleep(testCase.updatesDelay)
+
+				clusterVersion, _ := Pull(testSettings)
+				clusterVersion.Object.Status.AvailableUpdates = append(clusterVersion.Object.Status.AvailableUpdates,
+					configv1.Release{Version: "4.16.1", Image: "quay.io/test/release:4.16.1"})
+				_ = testSettings.Update(context.TODO(), clusterVersion.Object)
+			}()
+		}
+
+		orchestrator := NewUpgradeOrchestrator(testSettings, testCase.hop)
+		if testCase.conditionalUpdates {
+			orchestrator = orchestrator.WithConditionalUpdates()
+		}
+
+		image, err := orchestrator.resolveHopImage(clusterVersion, testCase.hop, 2*time.Second)
+
+		if testCase.expectedErrorString != "" {
+			assert.EqualError(t, err, testCase.expectedErrorString)
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedImage, image)
+	}
+}
+
+// assertCompletedUpgrade asserts that the timeline and the cluster show both hops of defaultUpgradeHops completed with
+// their admin-gates acknowledged.
+func assertCompletedUpgrade(t *testing.T, testSettings *clients.Settings, timeline *UpgradeTimeline) {
+	t.Helper()
+
+	assert.False(t, timeline.PoolsUpdatedTime.IsZero())
+	assert.Equal(t, UpgradeHopTimeline{
+		FromVersion:       defaultUpgradeFromVersion,
+		ToVersion:         "4.15.5",
+		Image:             "quay.io/test/release:4.15.5",
+		AcknowledgedGates: []string{defaultUpgradeGate414},
+		StartTime:         timeline.Hops[0].StartTime,
+		CompletionTime:    timeline.Hops[0].CompletionTime,
+	}, timeline.Hops[0])
+	assert.Equal(t, "4.15.5", timeline.Hops[1].FromVersion)
+	assert.Equal(t, []string{defaultUpgradeGate415}, timeline.Hops[1].AcknowledgedGates)
+
+	adminAcks, pullErr := configmap.Pull(testSettings, AdminAcksConfigMapName, AdminAcksConfigMapNamespace)
+	assert.Nil(t, pullErr)
+	assert.Equal(t, map[string]string{defaultUpgradeGate414: "true", defaultUpgradeGate415: "true"},
+		adminAcks.Object.Data)
+
+	clusterVersion, pullErr := Pull(testSettings)
+	assert.Nil(t, pullErr)
+	assert.Equal(t, "eus-4.16", clusterVersion.Object.Spec.Channel)
+	assert.Equal(t, "quay.io/test/release:4.16.3", clusterVersion.Object.Spec.DesiredUpdate.Image)
+}
+
+// fakeConfigV1Client serves clusteroperators from the runtime client, since the test clients do not provide a
+// config clientset.
+type fakeConfigV1Client struct {
+	clientConfigV1.ConfigV1Interface
+	runtimeClient runtimeclient.Client
+}
+
+// ClusterOperators returns a client listing the clusteroperators of the runtime client.
+func (client *fakeConfigV1Client) ClusterOperators() clientConfigV1.ClusterOperatorInterface {
+	return &fakeClusterOperatorClient{runtimeClient: client.runtimeClient}
+}
+
+// fakeClusterOperatorClient lists clusteroperators from the runtime client.
+type fakeClusterOperatorClient struct {
+	clientConfigV1.ClusterOperatorInterface
+	runtimeClient runtimeclient.Client
+}
+
+// List returns the clusteroperators of the runtime client.
+func (client *fakeClusterOperatorClient) List(
+	ctx context.Context, _ metav1.ListOptions) (*configv1.ClusterOperatorList, error) {
+	clusterOperatorList := &configv1.ClusterOperatorList{}
+
+	return clusterOperatorList, client.runtimeClient.List(ctx, clusterOperatorList)
+}
+
+// buildTestClientWithDummyUpgradeObjects returns a client with a cluster on defaultUpgradeFromVersion whose history
+// shows the updates of defaultUpgradeHops as completed, an updated worker pool with one node, the admin-gates and
+// admin-acks configmaps, and an etcd clusteroperator that is degraded since degradedSince if it is not nil.
+func buildTestClientWithDummyUpgradeObjects(degradedSince *time.Time) *clients.Settings {
+	etcdOperator := &configv1.ClusterOperator{ObjectMeta: metav1.ObjectMeta{Name: "etcd"}}
+
+	if degradedSince != nil {
+		etcdOperator.Status.Conditions = []configv1.ClusterOperatorStatusCondition{{
+			Type:               configv1.OperatorDegraded,
+			Status:             configv1.ConditionTrue,
+			Reason:             "EtcdMembersDegraded",
+			Message:            "2 of 3 members are available",
+			LastTransitionTime: metav1.NewTime(*degradedSince),
+		}}
+	}
+
+	testSettings := clients.GetTestClients(clients.TestClientParams{
+		K8sMockObjects: []runtime.Object{
+			buildDummyUpgradeClusterVersion(),
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: AdminGatesConfigMapName, Namespace: AdminGatesConfigMapNamespace},
+				Data: map[string]string{
+					defaultUpgradeGate414: "Kubernetes 1.28 removes several APIs.",
+					defaultUpgradeGate415: "Kubernetes 1.29 removes several APIs.",
+				},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: AdminAcksConfigMapName, Namespace: AdminAcksConfigMapNamespace},
+			},
+			&mcv1.MachineConfigPool{
+				ObjectMeta: metav1.ObjectMeta{Name: defaultUpgradePool},
+				Spec: mcv1.MachineConfigPoolSpec{
+					NodeSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"node-role.kubernetes.io/worker": ""},
+					},
+					Configuration: mcv1.MachineConfigPoolStatusConfiguration{
+						ObjectReference: corev1.ObjectReference{Name: "rendered-worker-2"},
+					},
+				},
+				Status: mcv1.MachineConfigPoolStatus{
+					MachineCount: 1,
+					Conditions: []mcv1.MachineConfigPoolCondition{
+						{Type: mcv1.MachineConfigPoolUpdated, Status: corev1.ConditionTrue},
+					},
+				},
+			},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{
+				Name:   "worker-0",
+				Labels: map[string]string{"node-role.kubernetes.io/worker": ""},
+				Annotations: map[string]string{
+					mco.CurrentConfigAnnotation:            "rendered-worker-2",
+					mco.DesiredConfigAnnotation:            "rendered-worker-2",
+					mco.MachineConfigDaemonStateAnnotation: string(mco.MachineConfigDaemonStateDone),
+				},
+			}},
+		},
+		SchemeAttachers: upgradeTestSchemes,
+	})
+
+	// ClusterOperators passed as mock objects are added to the runtime client twice, so it is created afterwards.
+	_ = testSettings.Create(context.TODO(), etcdOperator)
+
+	testSettings.ConfigV1Interface = &fakeConfigV1Client{runtimeClient: testSettings.Client}
+
+	return testSettings
+}
+
+// buildDummyUpgradeClusterVersion returns a clusterversion on defaultUpgradeFromVersion with 4.15.5 as available
+// update and a history where the updates to 4.15.5 and 4.16.3 are completed.
+func buildDummyUpgradeClusterVersion() *configv1.ClusterVersion {
+	return &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterVersionName},
+		Spec:       configv1.ClusterVersionSpec{Channel: "eus-4.14"},
+		Status: configv1.ClusterVersionStatus{
+			Desired: configv1.Release{
+				Version: defaultUpgradeFromVersion,
+				Image:   "quay.io/test/release:" + defaultUpgradeFromVersion,
+			},
+			AvailableUpdates: []configv1.Release{{Version: "4.15.5", Image: "quay.io/test/release:4.15.5"}},
+			History: []configv1.UpdateHistory{
+				{State: configv1.CompletedUpdate, Version: "4.16.3", Image: "quay.io/test/release:4.16.3"},
+				{State: configv1.CompletedUpdate, Version: "4.15.5", Image: "quay.io/test/release:4.15.5"},
+			},
+		},
+	}
+}
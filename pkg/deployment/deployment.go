@@ -12,6 +12,7 @@ import (
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/msg"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -585,6 +586,96 @@ func (builder *Builder) IsReady(timeout time.Duration) bool {
 	return err == nil
 }
 
+// GetScale returns the scale subresource of the deployment.
+func (builder *Builder) GetScale() (*autoscalingv1.Scale, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting scale of deployment %s in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	return builder.getScale(context.TODO())
+}
+
+// Scale sets the number of replicas of the deployment using the scale subresource. Unlike WithReplicas followed by
+// Update, only the replica count is sent to the cluster, so the rest of the deployment spec is left untouched.
+func (builder *Builder) Scale(ctx context.Context, replicas int32) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Scaling deployment %s in namespace %s to %d replicas",
+		builder.Definition.Name, builder.Definition.Namespace, replicas)
+
+	if replicas < 0 {
+		glog.V(100).Infof("The replicas of the deployment cannot be negative")
+
+		return fmt.Errorf("deployment 'replicas' cannot be negative")
+	}
+
+	scale, err := builder.getScale(ctx)
+	if err != nil {
+		return err
+	}
+
+	scale.Spec.Replicas = replicas
+
+	_, err = builder.apiClient.Deployments(builder.Definition.Namespace).UpdateScale(
+		ctx, builder.Definition.Name, scale, metav1.UpdateOptions{})
+	if err != nil {
+		glog.V(100).Infof("Failed to scale deployment %s in namespace %s: %v",
+			builder.Definition.Name, builder.Definition.Namespace, err)
+
+		return fmt.Errorf("failed to scale deployment %s in namespace %s: %w",
+			builder.Definition.Name, builder.Definition.Namespace, err)
+	}
+
+	builder.Object, err = builder.apiClient.Deployments(builder.Definition.Namespace).Get(
+		ctx, builder.Definition.Name, metav1.GetOptions{})
+
+	return err
+}
+
+// WaitUntilScaled waits for the duration of the defined timeout or until the deployment has been observed with the
+// provided number of replicas and all of them are ready.
+func (builder *Builder) WaitUntilScaled(replicas int32, timeout time.Duration) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Waiting for the defined period until deployment %s in namespace %s has %d ready replicas",
+		builder.Definition.Name, builder.Definition.Namespace, replicas)
+
+	if !builder.Exists() {
+		return fmt.Errorf("cannot wait for deployment %s in namespace %s to scale because it does not exist",
+			builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	return wait.PollUntilContextTimeout(
+		context.TODO(), time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+			var err error
+			builder.Object, err = builder.apiClient.Deployments(builder.Definition.Namespace).Get(
+				context.TODO(), builder.Definition.Name, metav1.GetOptions{})
+
+			if err != nil {
+				glog.V(100).Infof("Failed to get deployment from cluster. Error is: '%s'", err.Error())
+
+				return false, nil
+			}
+
+			if builder.Object.Status.ObservedGeneration < builder.Object.Generation {
+				return false, nil
+			}
+
+			if builder.Object.Spec.Replicas == nil || *builder.Object.Spec.Replicas != replicas {
+				return false, nil
+			}
+
+			return builder.Object.Status.Replicas == replicas && builder.Object.Status.ReadyReplicas == replicas, nil
+		})
+}
+
 // DeleteAndWait deletes a deployment and waits until it is removed from the cluster.
 func (builder *Builder) DeleteAndWait(timeout time.Duration) error {
 	if valid, err := builder.validate(); !valid {
@@ -718,6 +809,17 @@ func (builder *Builder) validate() (bool, error) {
 	return true, nil
 }
 
+// getScale returns the scale subresource of the deployment using the provided context.
+func (builder *Builder) getScale(ctx context.Context) (*autoscalingv1.Scale, error) {
+	if !builder.Exists() {
+		return nil, fmt.Errorf("cannot get scale of deployment %s in namespace %s because it does not exist",
+			builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	return builder.apiClient.Deployments(builder.Definition.Namespace).GetScale(
+		ctx, builder.Definition.Name, metav1.GetOptions{})
+}
+
 // WithToleration applies a toleration to the deployment's definition.
 func (builder *Builder) WithToleration(toleration corev1.Toleration) *Builder {
 	if valid, _ := builder.validate(); !valid {
@@ -10,10 +10,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	multus "gopkg.in/k8snetworkplumbingwg/multus-cni.v4/pkg/types"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 //nolint:funlen
@@ -939,3 +941,211 @@ func TestWithTerminationGracePeriodSeconds(t *testing.T) {
 		}
 	}
 }
+
+func TestDeploymentGetScale(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		expectedError error
+	}{
+		{
+			exists:        true,
+			expectedError: nil,
+		},
+		{
+			exists: false,
+			expectedError: fmt.Errorf(
+				"cannot get scale of deployment test-name in namespace test-namespace because it does not exist"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyScaledDeployment(3, 3))
+		}
+
+		testBuilder := buildTestBuilderWithScaleReactor(runtimeObjects)
+
+		scale, err := testBuilder.GetScale()
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, int32(3), scale.Spec.Replicas)
+		}
+	}
+}
+
+func TestDeploymentScale(t *testing.T) {
+	testCases := []struct {
+		replicas      int32
+		exists        bool
+		expectedError error
+	}{
+		{
+			replicas:      5,
+			exists:        true,
+			expectedError: nil,
+		},
+		{
+			replicas:      0,
+			exists:        true,
+			expectedError: nil,
+		},
+		{
+			replicas:      -1,
+			exists:        true,
+			expectedError: fmt.Errorf("deployment 'replicas' cannot be negative"),
+		},
+		{
+			replicas: 5,
+			exists:   false,
+			expectedError: fmt.Errorf(
+				"cannot get scale of deployment test-name in namespace test-namespace because it does not exist"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyScaledDeployment(3, 3))
+		}
+
+		testBuilder := buildTestBuilderWithScaleReactor(runtimeObjects)
+
+		err := testBuilder.Scale(context.TODO(), testCase.replicas)
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, testCase.replicas, *testBuilder.Object.Spec.Replicas)
+		}
+	}
+}
+
+func TestDeploymentWaitUntilScaled(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		readyReplicas int32
+		expectedError error
+	}{
+		{
+			exists:        true,
+			readyReplicas: 3,
+			expectedError: nil,
+		},
+		{
+			exists:        true,
+			readyReplicas: 1,
+			expectedError: context.DeadlineExceeded,
+		},
+		{
+			exists: false,
+			expectedError: fmt.Errorf(
+				"cannot wait for deployment test-name in namespace test-namespace to scale because it does not exist"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyScaledDeployment(3, testCase.readyReplicas))
+		}
+
+		testBuilder := buildTestBuilderWithFakeObjects(runtimeObjects)
+
+		err := testBuilder.WaitUntilScaled(3, time.Second)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+// buildDummyScaledDeployment returns a deployment with the provided number of desired and ready replicas.
+func buildDummyScaledDeployment(replicas, readyReplicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-name",
+			Namespace: "test-namespace",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+		},
+		Status: appsv1.DeploymentStatus{
+			Replicas:      replicas,
+			ReadyReplicas: readyReplicas,
+		},
+	}
+}
+
+// buildTestBuilderWithScaleReactor returns a Builder whose fake client serves the deployment scale subresource from
+// the underlying deployment objects, since the fake clientset does not do this on its own.
+func buildTestBuilderWithScaleReactor(objects []runtime.Object) *Builder {
+	fakeClient := k8sfake.NewSimpleClientset(objects...)
+	deploymentGVR := appsv1.SchemeGroupVersion.WithResource("deployments")
+
+	fakeClient.PrependReactor("get", "deployments",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "scale" {
+				return false, nil, nil
+			}
+
+			getAction, ok := action.(k8stesting.GetAction)
+			if !ok {
+				return false, nil, nil
+			}
+
+			object, err := fakeClient.Tracker().Get(deploymentGVR, getAction.GetNamespace(), getAction.GetName())
+			if err != nil {
+				return true, nil, err
+			}
+
+			deployment, ok := object.(*appsv1.Deployment)
+			if !ok {
+				return true, nil, fmt.Errorf("unexpected object type %T", object)
+			}
+
+			return true, &autoscalingv1.Scale{
+				ObjectMeta: metav1.ObjectMeta{Name: deployment.Name, Namespace: deployment.Namespace},
+				Spec:       autoscalingv1.ScaleSpec{Replicas: *deployment.Spec.Replicas},
+			}, nil
+		})
+
+	fakeClient.PrependReactor("update", "deployments",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "scale" {
+				return false, nil, nil
+			}
+
+			updateAction, ok := action.(k8stesting.UpdateAction)
+			if !ok {
+				return false, nil, nil
+			}
+
+			scale, ok := updateAction.GetObject().(*autoscalingv1.Scale)
+			if !ok {
+				return false, nil, nil
+			}
+
+			object, err := fakeClient.Tracker().Get(deploymentGVR, scale.Namespace, scale.Name)
+			if err != nil {
+				return true, nil, err
+			}
+
+			deployment, ok := object.(*appsv1.Deployment)
+			if !ok {
+				return true, nil, fmt.Errorf("unexpected object type %T", object)
+			}
+
+			deployment.Spec.Replicas = &scale.Spec.Replicas
+
+			return true, scale, fakeClient.Tracker().Update(deploymentGVR, deployment, scale.Namespace)
+		})
+
+	return NewBuilder(&clients.Settings{
+		AppsV1Interface: fakeClient.AppsV1(),
+	}, "test-name", "test-namespace", map[string]string{
+		"test-key": "test-value",
+	}, corev1.Container{
+		Name: "test-container",
+	})
+}
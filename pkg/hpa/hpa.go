@@ -0,0 +1,699 @@
+package hpa
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/msg"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// maxPolicyPeriodSeconds is the upper bound the API server accepts for a scaling policy period.
+	maxPolicyPeriodSeconds int32 = 1800
+	// maxStabilizationWindowSeconds is the upper bound the API server accepts for a stabilization window.
+	maxStabilizationWindowSeconds int32 = 3600
+)
+
+// Builder provides a struct to interface with autoscaling/v2 HorizontalPodAutoscaler resources on a specific
+// cluster.
+type Builder struct {
+	// Definition of the HorizontalPodAutoscaler used to create the resource.
+	Definition *autoscalingv2.HorizontalPodAutoscaler
+	// Object of the HorizontalPodAutoscaler as it is on the cluster.
+	Object *autoscalingv2.HorizontalPodAutoscaler
+	// apiClient used to interact with the cluster.
+	apiClient runtimeclient.Client
+	// errorMsg used to store latest error message from functions that do not return errors.
+	errorMsg string
+}
+
+// NewBuilder creates a new instance of a HorizontalPodAutoscaler builder. The scaleTargetRef identifies the
+// resource being scaled and maxReplicas is the upper limit the autoscaler can scale it to.
+func NewBuilder(
+	apiClient runtimeclient.Client,
+	name, nsname string,
+	scaleTargetRef autoscalingv2.CrossVersionObjectReference,
+	maxReplicas int32) *Builder {
+	glog.V(100).Infof(
+		"Initializing new HorizontalPodAutoscaler structure with the following params: "+
+			"name: %s, namespace: %s, scaleTargetRef: %v, maxReplicas: %d",
+		name, nsname, scaleTargetRef, maxReplicas)
+
+	// Since we accept an interface, providing a nil *clients.Settings results in an interface with a nil concrete
+	// type, which must be checked using reflection.
+	if apiClient == nil || reflect.ValueOf(apiClient).IsNil() {
+		glog.V(100).Infof("The apiClient of the HorizontalPodAutoscaler is nil")
+
+		return nil
+	}
+
+	err := autoscalingv2.AddToScheme(apiClient.Scheme())
+	if err != nil {
+		glog.V(100).Infof("Failed to add autoscaling v2 scheme to client schemes: %v", err)
+
+		return nil
+	}
+
+	builder := &Builder{
+		apiClient: apiClient,
+		Definition: &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: nsname,
+			},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: scaleTargetRef,
+				MaxReplicas:    maxReplicas,
+			},
+		},
+	}
+
+	if name == "" {
+		glog.V(100).Infof("The name of the HorizontalPodAutoscaler is empty")
+
+		builder.errorMsg = "horizontalPodAutoscaler 'name' cannot be empty"
+
+		return builder
+	}
+
+	if nsname == "" {
+		glog.V(100).Infof("The namespace of the HorizontalPodAutoscaler is empty")
+
+		builder.errorMsg = "horizontalPodAutoscaler 'nsname' cannot be empty"
+
+		return builder
+	}
+
+	if scaleTargetRef.Kind == "" || scaleTargetRef.Name == "" {
+		glog.V(100).Infof("The scaleTargetRef of the HorizontalPodAutoscaler is incomplete: %v", scaleTargetRef)
+
+		builder.errorMsg = "horizontalPodAutoscaler 'scaleTargetRef' must have both kind and name set"
+
+		return builder
+	}
+
+	if maxReplicas < 1 {
+		glog.V(100).Infof("The maxReplicas of the HorizontalPodAutoscaler is less than 1")
+
+		builder.errorMsg = "horizontalPodAutoscaler 'maxReplicas' must be at least 1"
+
+		return builder
+	}
+
+	return builder
+}
+
+// Pull pulls an existing HorizontalPodAutoscaler into a Builder struct.
+func Pull(apiClient runtimeclient.Client, name, nsname string) (*Builder, error) {
+	glog.V(100).Infof("Pulling existing HorizontalPodAutoscaler %s in namespace %s from cluster", name, nsname)
+
+	// Since we accept an interface, providing a nil *clients.Settings results in an interface with a nil concrete
+	// type, which must be checked using reflection.
+	if apiClient == nil || reflect.ValueOf(apiClient).IsNil() {
+		glog.V(100).Infof("The apiClient of the HorizontalPodAutoscaler is nil")
+
+		return nil, fmt.Errorf("horizontalPodAutoscaler 'apiClient' cannot be nil")
+	}
+
+	err := autoscalingv2.AddToScheme(apiClient.Scheme())
+	if err != nil {
+		glog.V(100).Infof("Failed to add autoscaling v2 scheme to client schemes: %v", err)
+
+		return nil, err
+	}
+
+	builder := &Builder{
+		apiClient: apiClient,
+		Definition: &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: nsname,
+			},
+		},
+	}
+
+	if name == "" {
+		glog.V(100).Infof("The name of the HorizontalPodAutoscaler is empty")
+
+		return nil, fmt.Errorf("horizontalPodAutoscaler 'name' cannot be empty")
+	}
+
+	if nsname == "" {
+		glog.V(100).Infof("The namespace of the HorizontalPodAutoscaler is empty")
+
+		return nil, fmt.Errorf("horizontalPodAutoscaler 'nsname' cannot be empty")
+	}
+
+	if !builder.Exists() {
+		glog.V(100).Infof("The HorizontalPodAutoscaler %s does not exist in namespace %s", name, nsname)
+
+		return nil, fmt.Errorf("horizontalPodAutoscaler object %s does not exist in namespace %s", name, nsname)
+	}
+
+	builder.Definition = builder.Object
+
+	return builder, nil
+}
+
+// Get returns the HorizontalPodAutoscaler object if found.
+func (builder *Builder) Get() (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting HorizontalPodAutoscaler %s in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	hpaObj := &autoscalingv2.HorizontalPodAutoscaler{}
+	err := builder.apiClient.Get(context.TODO(), runtimeclient.ObjectKey{
+		Name:      builder.Definition.Name,
+		Namespace: builder.Definition.Namespace,
+	}, hpaObj)
+
+	if err != nil {
+		glog.V(100).Infof("Failed to get HorizontalPodAutoscaler %s in namespace %s: %v",
+			builder.Definition.Name, builder.Definition.Namespace, err)
+
+		return nil, err
+	}
+
+	return hpaObj, nil
+}
+
+// Exists checks whether the given HorizontalPodAutoscaler exists.
+func (builder *Builder) Exists() bool {
+	if valid, _ := builder.validate(); !valid {
+		return false
+	}
+
+	glog.V(100).Infof("Checking if HorizontalPodAutoscaler %s exists in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	var err error
+	builder.Object, err = builder.Get()
+
+	return err == nil || !k8serrors.IsNotFound(err)
+}
+
+// Create makes a HorizontalPodAutoscaler in the cluster and stores the created object in struct.
+func (builder *Builder) Create() (*Builder, error) {
+	if valid, err := builder.validate(); !valid {
+		return builder, err
+	}
+
+	glog.V(100).Infof("Creating the HorizontalPodAutoscaler %s in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	if builder.Exists() {
+		return builder, nil
+	}
+
+	err := builder.apiClient.Create(context.TODO(), builder.Definition)
+	if err != nil {
+		glog.V(100).Infof("Failed to create HorizontalPodAutoscaler %s in namespace %s: %v",
+			builder.Definition.Name, builder.Definition.Namespace, err)
+
+		return builder, err
+	}
+
+	builder.Object = builder.Definition
+
+	return builder, nil
+}
+
+// Update changes the existing HorizontalPodAutoscaler resource on the cluster to match the builder Definition.
+func (builder *Builder) Update() (*Builder, error) {
+	if valid, err := builder.validate(); !valid {
+		return builder, err
+	}
+
+	glog.V(100).Infof("Updating the HorizontalPodAutoscaler %s in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	if !builder.Exists() {
+		glog.V(100).Infof("HorizontalPodAutoscaler %s does not exist in namespace %s",
+			builder.Definition.Name, builder.Definition.Namespace)
+
+		return builder, fmt.Errorf("cannot update non-existent horizontalPodAutoscaler")
+	}
+
+	builder.Definition.ResourceVersion = builder.Object.ResourceVersion
+
+	err := builder.apiClient.Update(context.TODO(), builder.Definition)
+	if err != nil {
+		glog.V(100).Infof(
+			msg.FailToUpdateError("horizontalPodAutoscaler", builder.Definition.Name, builder.Definition.Namespace))
+
+		return builder, err
+	}
+
+	builder.Object = builder.Definition
+
+	return builder, nil
+}
+
+// Delete removes a HorizontalPodAutoscaler from the cluster if it exists.
+func (builder *Builder) Delete() error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Deleting the HorizontalPodAutoscaler %s in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	if !builder.Exists() {
+		glog.V(100).Infof("HorizontalPodAutoscaler %s in namespace %s does not exist",
+			builder.Definition.Name, builder.Definition.Namespace)
+
+		builder.Object = nil
+
+		return nil
+	}
+
+	err := builder.apiClient.Delete(context.TODO(), builder.Definition)
+	if err != nil {
+		return fmt.Errorf("failed to delete horizontalPodAutoscaler: %w", err)
+	}
+
+	builder.Object = nil
+
+	return nil
+}
+
+// WithMinReplicas sets the lower limit for the number of replicas the autoscaler can scale down to.
+func (builder *Builder) WithMinReplicas(minReplicas int32) *Builder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Setting minReplicas of HorizontalPodAutoscaler %s in namespace %s to %d",
+		builder.Definition.Name, builder.Definition.Namespace, minReplicas)
+
+	if minReplicas < 1 {
+		glog.V(100).Infof("The minReplicas of the HorizontalPodAutoscaler is less than 1")
+
+		builder.errorMsg = "horizontalPodAutoscaler 'minReplicas' must be at least 1"
+
+		return builder
+	}
+
+	if minReplicas > builder.Definition.Spec.MaxReplicas {
+		glog.V(100).Infof("The minReplicas %d is greater than maxReplicas %d",
+			minReplicas, builder.Definition.Spec.MaxReplicas)
+
+		builder.errorMsg = "horizontalPodAutoscaler 'minReplicas' cannot be greater than 'maxReplicas'"
+
+		return builder
+	}
+
+	builder.Definition.Spec.MinReplicas = &minReplicas
+
+	return builder
+}
+
+// WithResourceMetric appends a resource metric to the HorizontalPodAutoscaler, targeting the provided average
+// utilization of the resource, as a percentage of the requested value, across all pods.
+func (builder *Builder) WithResourceMetric(resourceName corev1.ResourceName, averageUtilization int32) *Builder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Adding resource metric %s with averageUtilization %d to HorizontalPodAutoscaler %s in "+
+		"namespace %s", resourceName, averageUtilization, builder.Definition.Name, builder.Definition.Namespace)
+
+	if resourceName == "" {
+		glog.V(100).Infof("The resourceName of the resource metric is empty")
+
+		builder.errorMsg = "horizontalPodAutoscaler resource metric 'resourceName' cannot be empty"
+
+		return builder
+	}
+
+	if averageUtilization < 1 {
+		glog.V(100).Infof("The averageUtilization of the resource metric is less than 1")
+
+		builder.errorMsg = "horizontalPodAutoscaler resource metric 'averageUtilization' must be at least 1"
+
+		return builder
+	}
+
+	builder.Definition.Spec.Metrics = append(builder.Definition.Spec.Metrics, autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: resourceName,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: &averageUtilization,
+			},
+		},
+	})
+
+	return builder
+}
+
+// WithPodsMetric appends a pods metric to the HorizontalPodAutoscaler, targeting the provided average value of the
+// custom metric across all pods.
+func (builder *Builder) WithPodsMetric(metricName string, averageValue resource.Quantity) *Builder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Adding pods metric %s with averageValue %s to HorizontalPodAutoscaler %s in namespace %s",
+		metricName, averageValue.String(), builder.Definition.Name, builder.Definition.Namespace)
+
+	if metricName == "" {
+		glog.V(100).Infof("The metricName of the pods metric is empty")
+
+		builder.errorMsg = "horizontalPodAutoscaler pods metric 'metricName' cannot be empty"
+
+		return builder
+	}
+
+	if averageValue.Sign() <= 0 {
+		glog.V(100).Infof("The averageValue of the pods metric is not positive")
+
+		builder.errorMsg = "horizontalPodAutoscaler pods metric 'averageValue' must be positive"
+
+		return builder
+	}
+
+	builder.Definition.Spec.Metrics = append(builder.Definition.Spec.Metrics, autoscalingv2.MetricSpec{
+		Type: autoscalingv2.PodsMetricSourceType,
+		Pods: &autoscalingv2.PodsMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{Name: metricName},
+			Target: autoscalingv2.MetricTarget{
+				Type:         autoscalingv2.AverageValueMetricType,
+				AverageValue: &averageValue,
+			},
+		},
+	})
+
+	return builder
+}
+
+// WithExternalMetric appends an external metric to the HorizontalPodAutoscaler. The selector is optional and may be
+// nil. The target must set its type along with the matching value field.
+func (builder *Builder) WithExternalMetric(
+	metricName string, selector *metav1.LabelSelector, target autoscalingv2.MetricTarget) *Builder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Adding external metric %s with target %v to HorizontalPodAutoscaler %s in namespace %s",
+		metricName, target, builder.Definition.Name, builder.Definition.Namespace)
+
+	if metricName == "" {
+		glog.V(100).Infof("The metricName of the external metric is empty")
+
+		builder.errorMsg = "horizontalPodAutoscaler external metric 'metricName' cannot be empty"
+
+		return builder
+	}
+
+	if err := validateMetricTarget(target); err != nil {
+		glog.V(100).Infof("The target of the external metric is invalid: %v", err)
+
+		builder.errorMsg = fmt.Sprintf("horizontalPodAutoscaler external metric has invalid target: %v", err)
+
+		return builder
+	}
+
+	builder.Definition.Spec.Metrics = append(builder.Definition.Spec.Metrics, autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ExternalMetricSourceType,
+		External: &autoscalingv2.ExternalMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{
+				Name:     metricName,
+				Selector: selector,
+			},
+			Target: target,
+		},
+	})
+
+	return builder
+}
+
+// WithScaleUpBehavior sets the rules the autoscaler follows when scaling the target up.
+func (builder *Builder) WithScaleUpBehavior(rules autoscalingv2.HPAScalingRules) *Builder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Setting scale up behavior of HorizontalPodAutoscaler %s in namespace %s to %v",
+		builder.Definition.Name, builder.Definition.Namespace, rules)
+
+	if err := validateScalingRules(rules); err != nil {
+		glog.V(100).Infof("The scale up rules are invalid: %v", err)
+
+		builder.errorMsg = fmt.Sprintf("horizontalPodAutoscaler has invalid scale up behavior: %v", err)
+
+		return builder
+	}
+
+	if builder.Definition.Spec.Behavior == nil {
+		builder.Definition.Spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{}
+	}
+
+	builder.Definition.Spec.Behavior.ScaleUp = &rules
+
+	return builder
+}
+
+// WithScaleDownBehavior sets the rules the autoscaler follows when scaling the target down.
+func (builder *Builder) WithScaleDownBehavior(rules autoscalingv2.HPAScalingRules) *Builder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Setting scale down behavior of HorizontalPodAutoscaler %s in namespace %s to %v",
+		builder.Definition.Name, builder.Definition.Namespace, rules)
+
+	if err := validateScalingRules(rules); err != nil {
+		glog.V(100).Infof("The scale down rules are invalid: %v", err)
+
+		builder.errorMsg = fmt.Sprintf("horizontalPodAutoscaler has invalid scale down behavior: %v", err)
+
+		return builder
+	}
+
+	if builder.Definition.Spec.Behavior == nil {
+		builder.Definition.Spec.Behavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{}
+	}
+
+	builder.Definition.Spec.Behavior.ScaleDown = &rules
+
+	return builder
+}
+
+// GetCurrentReplicas returns the number of replicas the autoscaler last observed on the scale target.
+func (builder *Builder) GetCurrentReplicas() (int32, error) {
+	if valid, err := builder.validate(); !valid {
+		return 0, err
+	}
+
+	glog.V(100).Infof("Getting current replicas of HorizontalPodAutoscaler %s in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	if !builder.Exists() {
+		return 0, fmt.Errorf("horizontalPodAutoscaler object %s does not exist in namespace %s",
+			builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	return builder.Object.Status.CurrentReplicas, nil
+}
+
+// GetDesiredReplicas returns the number of replicas the autoscaler last calculated for the scale target.
+func (builder *Builder) GetDesiredReplicas() (int32, error) {
+	if valid, err := builder.validate(); !valid {
+		return 0, err
+	}
+
+	glog.V(100).Infof("Getting desired replicas of HorizontalPodAutoscaler %s in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	if !builder.Exists() {
+		return 0, fmt.Errorf("horizontalPodAutoscaler object %s does not exist in namespace %s",
+			builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	return builder.Object.Status.DesiredReplicas, nil
+}
+
+// GetCondition returns the status condition of the provided type. An error is returned if the HorizontalPodAutoscaler
+// does not exist or does not report the condition yet.
+func (builder *Builder) GetCondition(
+	conditionType autoscalingv2.HorizontalPodAutoscalerConditionType) (
+	*autoscalingv2.HorizontalPodAutoscalerCondition, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting condition %s of HorizontalPodAutoscaler %s in namespace %s",
+		conditionType, builder.Definition.Name, builder.Definition.Namespace)
+
+	if !builder.Exists() {
+		return nil, fmt.Errorf("horizontalPodAutoscaler object %s does not exist in namespace %s",
+			builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	condition := findCondition(builder.Object.Status.Conditions, conditionType)
+	if condition == nil {
+		return nil, fmt.Errorf("horizontalPodAutoscaler %s in namespace %s does not have condition %s",
+			builder.Definition.Name, builder.Definition.Namespace, conditionType)
+	}
+
+	return condition, nil
+}
+
+// IsScalingLimited returns true if the autoscaler reports that the desired replica count was capped by the min or
+// max replicas. A missing ScalingLimited condition is treated as not limited.
+func (builder *Builder) IsScalingLimited() (bool, error) {
+	if valid, err := builder.validate(); !valid {
+		return false, err
+	}
+
+	glog.V(100).Infof("Checking if HorizontalPodAutoscaler %s in namespace %s is scaling limited",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	if !builder.Exists() {
+		return false, fmt.Errorf("horizontalPodAutoscaler object %s does not exist in namespace %s",
+			builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	condition := findCondition(builder.Object.Status.Conditions, autoscalingv2.ScalingLimited)
+
+	return condition != nil && condition.Status == corev1.ConditionTrue, nil
+}
+
+// WaitUntilDesiredReplicas waits up to timeout until the autoscaler has settled on the provided number of desired
+// replicas and the scale target reports that number as its current replicas.
+func (builder *Builder) WaitUntilDesiredReplicas(replicas int32, timeout time.Duration) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Waiting up to %s until HorizontalPodAutoscaler %s in namespace %s has %d desired replicas",
+		timeout, builder.Definition.Name, builder.Definition.Namespace, replicas)
+
+	if !builder.Exists() {
+		return fmt.Errorf("cannot wait for non-existent horizontalPodAutoscaler %s in namespace %s",
+			builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	return wait.PollUntilContextTimeout(
+		context.TODO(), time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+			var err error
+			builder.Object, err = builder.Get()
+
+			if err != nil {
+				glog.V(100).Infof("Failed to get HorizontalPodAutoscaler %s in namespace %s: %v",
+					builder.Definition.Name, builder.Definition.Namespace, err)
+
+				return false, nil
+			}
+
+			status := builder.Object.Status
+
+			return status.DesiredReplicas == replicas && status.CurrentReplicas == replicas, nil
+		})
+}
+
+// validate checks that the builder, definition, and apiClient are properly initialized and there is no errorMsg.
+func (builder *Builder) validate() (bool, error) {
+	resourceCRD := "horizontalPodAutoscaler"
+
+	if builder == nil {
+		glog.V(100).Infof("The %s builder is uninitialized", resourceCRD)
+
+		return false, fmt.Errorf("error: received nil %s builder", resourceCRD)
+	}
+
+	if builder.Definition == nil {
+		glog.V(100).Infof("The %s is uninitialized", resourceCRD)
+
+		return false, fmt.Errorf("%s", msg.UndefinedCrdObjectErrString(resourceCRD))
+	}
+
+	if builder.apiClient == nil {
+		glog.V(100).Infof("The %s builder apiClient is nil", resourceCRD)
+
+		return false, fmt.Errorf("%s builder cannot have nil apiClient", resourceCRD)
+	}
+
+	if builder.errorMsg != "" {
+		glog.V(100).Infof("The %s builder has error message %s", resourceCRD, builder.errorMsg)
+
+		return false, fmt.Errorf("%s", builder.errorMsg)
+	}
+
+	return true, nil
+}
+
+// validateMetricTarget checks that the target type is set and that the value field matching the type is provided.
+func validateMetricTarget(target autoscalingv2.MetricTarget) error {
+	switch target.Type {
+	case autoscalingv2.ValueMetricType:
+		if target.Value == nil {
+			return fmt.Errorf("target of type %s must set value", target.Type)
+		}
+	case autoscalingv2.AverageValueMetricType:
+		if target.AverageValue == nil {
+			return fmt.Errorf("target of type %s must set averageValue", target.Type)
+		}
+	case autoscalingv2.UtilizationMetricType:
+		if target.AverageUtilization == nil {
+			return fmt.Errorf("target of type %s must set averageUtilization", target.Type)
+		}
+	default:
+		return fmt.Errorf("unknown target type %q", target.Type)
+	}
+
+	return nil
+}
+
+// validateScalingRules checks the scaling rules against the same bounds the API server enforces so that errors are
+// reported before Create or Update is called.
+func validateScalingRules(rules autoscalingv2.HPAScalingRules) error {
+	if rules.StabilizationWindowSeconds != nil &&
+		(*rules.StabilizationWindowSeconds < 0 || *rules.StabilizationWindowSeconds > maxStabilizationWindowSeconds) {
+		return fmt.Errorf("stabilizationWindowSeconds must be between 0 and %d", maxStabilizationWindowSeconds)
+	}
+
+	for _, policy := range rules.Policies {
+		if policy.Type != autoscalingv2.PodsScalingPolicy && policy.Type != autoscalingv2.PercentScalingPolicy {
+			return fmt.Errorf("policy has unknown type %q", policy.Type)
+		}
+
+		if policy.Value < 1 {
+			return fmt.Errorf("policy value must be greater than zero")
+		}
+
+		if policy.PeriodSeconds < 1 || policy.PeriodSeconds > maxPolicyPeriodSeconds {
+			return fmt.Errorf("policy periodSeconds must be between 1 and %d", maxPolicyPeriodSeconds)
+		}
+	}
+
+	return nil
+}
+
+// findCondition returns the condition of the provided type or nil if it is not present.
+func findCondition(
+	conditions []autoscalingv2.HorizontalPodAutoscalerCondition,
+	conditionType autoscalingv2.HorizontalPodAutoscalerConditionType) *autoscalingv2.HorizontalPodAutoscalerCondition {
+	for _, condition := range conditions {
+		if condition.Type == conditionType {
+			return &condition
+		}
+	}
+
+	return nil
+}
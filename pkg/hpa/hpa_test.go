@@ -0,0 +1,711 @@
+package hpa
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/stretchr/testify/assert"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	defaultHPAName        = "test-hpa"
+	defaultHPANamespace   = "test-ns"
+	defaultHPAMaxReplicas = int32(5)
+)
+
+var (
+	defaultScaleTargetRef = autoscalingv2.CrossVersionObjectReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       "test-deployment",
+	}
+
+	hpaTestSchemes = []clients.SchemeAttacher{
+		autoscalingv2.AddToScheme,
+	}
+)
+
+func TestNewBuilder(t *testing.T) {
+	testCases := []struct {
+		name           string
+		nsname         string
+		scaleTargetRef autoscalingv2.CrossVersionObjectReference
+		maxReplicas    int32
+		client         bool
+		expectedError  string
+	}{
+		{
+			name:           defaultHPAName,
+			nsname:         defaultHPANamespace,
+			scaleTargetRef: defaultScaleTargetRef,
+			maxReplicas:    defaultHPAMaxReplicas,
+			client:         true,
+			expectedError:  "",
+		},
+		{
+			name:           "",
+			nsname:         defaultHPANamespace,
+			scaleTargetRef: defaultScaleTargetRef,
+			maxReplicas:    defaultHPAMaxReplicas,
+			client:         true,
+			expectedError:  "horizontalPodAutoscaler 'name' cannot be empty",
+		},
+		{
+			name:           defaultHPAName,
+			nsname:         "",
+			scaleTargetRef: defaultScaleTargetRef,
+			maxReplicas:    defaultHPAMaxReplicas,
+			client:         true,
+			expectedError:  "horizontalPodAutoscaler 'nsname' cannot be empty",
+		},
+		{
+			name:           defaultHPAName,
+			nsname:         defaultHPANamespace,
+			scaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment"},
+			maxReplicas:    defaultHPAMaxReplicas,
+			client:         true,
+			expectedError:  "horizontalPodAutoscaler 'scaleTargetRef' must have both kind and name set",
+		},
+		{
+			name:           defaultHPAName,
+			nsname:         defaultHPANamespace,
+			scaleTargetRef: defaultScaleTargetRef,
+			maxReplicas:    0,
+			client:         true,
+			expectedError:  "horizontalPodAutoscaler 'maxReplicas' must be at least 1",
+		},
+		{
+			name:           defaultHPAName,
+			nsname:         defaultHPANamespace,
+			scaleTargetRef: defaultScaleTargetRef,
+			maxReplicas:    defaultHPAMaxReplicas,
+			client:         false,
+			expectedError:  "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		var testSettings *clients.Settings
+
+		if testCase.client {
+			testSettings = clients.GetTestClients(clients.TestClientParams{})
+		}
+
+		testBuilder := NewBuilder(
+			testSettings, testCase.name, testCase.nsname, testCase.scaleTargetRef, testCase.maxReplicas)
+
+		if testCase.client {
+			assert.NotNil(t, testBuilder)
+			assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+			if testCase.expectedError == "" {
+				assert.Equal(t, testCase.name, testBuilder.Definition.Name)
+				assert.Equal(t, testCase.nsname, testBuilder.Definition.Namespace)
+				assert.Equal(t, testCase.scaleTargetRef, testBuilder.Definition.Spec.ScaleTargetRef)
+				assert.Equal(t, testCase.maxReplicas, testBuilder.Definition.Spec.MaxReplicas)
+			}
+		} else {
+			assert.Nil(t, testBuilder)
+		}
+	}
+}
+
+func TestPull(t *testing.T) {
+	testCases := []struct {
+		name                string
+		nsname              string
+		addToRuntimeObjects bool
+		client              bool
+		expectedError       error
+	}{
+		{
+			name:                defaultHPAName,
+			nsname:              defaultHPANamespace,
+			addToRuntimeObjects: true,
+			client:              true,
+			expectedError:       nil,
+		},
+		{
+			name:                "",
+			nsname:              defaultHPANamespace,
+			addToRuntimeObjects: true,
+			client:              true,
+			expectedError:       fmt.Errorf("horizontalPodAutoscaler 'name' cannot be empty"),
+		},
+		{
+			name:                defaultHPAName,
+			nsname:              "",
+			addToRuntimeObjects: true,
+			client:              true,
+			expectedError:       fmt.Errorf("horizontalPodAutoscaler 'nsname' cannot be empty"),
+		},
+		{
+			name:                defaultHPAName,
+			nsname:              defaultHPANamespace,
+			addToRuntimeObjects: false,
+			client:              true,
+			expectedError: fmt.Errorf(
+				"horizontalPodAutoscaler object %s does not exist in namespace %s", defaultHPAName, defaultHPANamespace),
+		},
+		{
+			name:                defaultHPAName,
+			nsname:              defaultHPANamespace,
+			addToRuntimeObjects: true,
+			client:              false,
+			expectedError:       fmt.Errorf("horizontalPodAutoscaler 'apiClient' cannot be nil"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var (
+			runtimeObjects []runtime.Object
+			testSettings   *clients.Settings
+		)
+
+		if testCase.addToRuntimeObjects {
+			runtimeObjects = append(runtimeObjects, buildDummyHPA(autoscalingv2.HorizontalPodAutoscalerStatus{}))
+		}
+
+		if testCase.client {
+			testSettings = clients.GetTestClients(clients.TestClientParams{
+				K8sMockObjects:  runtimeObjects,
+				SchemeAttachers: hpaTestSchemes,
+			})
+		}
+
+		testBuilder, err := Pull(testSettings, testCase.name, testCase.nsname)
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, testCase.name, testBuilder.Definition.Name)
+			assert.Equal(t, testCase.nsname, testBuilder.Definition.Namespace)
+		}
+	}
+}
+
+func TestHPACreate(t *testing.T) {
+	testCases := []struct {
+		testBuilder   *Builder
+		expectedError error
+	}{
+		{
+			testBuilder:   buildValidHPATestBuilder(buildTestClientWithHPAScheme()),
+			expectedError: nil,
+		},
+		{
+			testBuilder:   buildValidHPATestBuilder(buildTestClientWithDummyHPA(autoscalingv2.HorizontalPodAutoscalerStatus{})),
+			expectedError: nil,
+		},
+		{
+			testBuilder:   buildInvalidHPATestBuilder(buildTestClientWithHPAScheme()),
+			expectedError: fmt.Errorf("horizontalPodAutoscaler 'maxReplicas' must be at least 1"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder, err := testCase.testBuilder.Create()
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, testBuilder.Definition.Name, testBuilder.Object.Name)
+			assert.Equal(t, testBuilder.Definition.Namespace, testBuilder.Object.Namespace)
+		}
+	}
+}
+
+func TestHPAUpdate(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		expectedError error
+	}{
+		{
+			exists:        true,
+			expectedError: nil,
+		},
+		{
+			exists:        false,
+			expectedError: fmt.Errorf("cannot update non-existent horizontalPodAutoscaler"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := buildTestClientWithHPAScheme()
+
+		if testCase.exists {
+			testSettings = buildTestClientWithDummyHPA(autoscalingv2.HorizontalPodAutoscalerStatus{})
+		}
+
+		testBuilder := buildValidHPATestBuilder(testSettings).WithMinReplicas(2)
+
+		testBuilder, err := testBuilder.Update()
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, int32(2), *testBuilder.Object.Spec.MinReplicas)
+		}
+	}
+}
+
+func TestHPADelete(t *testing.T) {
+	testCases := []struct {
+		testBuilder   *Builder
+		expectedError error
+	}{
+		{
+			testBuilder:   buildValidHPATestBuilder(buildTestClientWithDummyHPA(autoscalingv2.HorizontalPodAutoscalerStatus{})),
+			expectedError: nil,
+		},
+		{
+			testBuilder:   buildValidHPATestBuilder(buildTestClientWithHPAScheme()),
+			expectedError: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.testBuilder.Delete()
+		assert.Equal(t, testCase.expectedError, err)
+		assert.Nil(t, testCase.testBuilder.Object)
+	}
+}
+
+func TestHPAWithMinReplicas(t *testing.T) {
+	testCases := []struct {
+		minReplicas   int32
+		expectedError string
+	}{
+		{
+			minReplicas:   2,
+			expectedError: "",
+		},
+		{
+			minReplicas:   0,
+			expectedError: "horizontalPodAutoscaler 'minReplicas' must be at least 1",
+		},
+		{
+			minReplicas:   defaultHPAMaxReplicas + 1,
+			expectedError: "horizontalPodAutoscaler 'minReplicas' cannot be greater than 'maxReplicas'",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidHPATestBuilder(buildTestClientWithHPAScheme()).WithMinReplicas(testCase.minReplicas)
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+		if testCase.expectedError == "" {
+			assert.Equal(t, testCase.minReplicas, *testBuilder.Definition.Spec.MinReplicas)
+		}
+	}
+}
+
+func TestHPAWithResourceMetric(t *testing.T) {
+	testCases := []struct {
+		resourceName       corev1.ResourceName
+		averageUtilization int32
+		expectedError      string
+	}{
+		{
+			resourceName:       corev1.ResourceCPU,
+			averageUtilization: 80,
+			expectedError:      "",
+		},
+		{
+			resourceName:       "",
+			averageUtilization: 80,
+			expectedError:      "horizontalPodAutoscaler resource metric 'resourceName' cannot be empty",
+		},
+		{
+			resourceName:       corev1.ResourceMemory,
+			averageUtilization: 0,
+			expectedError:      "horizontalPodAutoscaler resource metric 'averageUtilization' must be at least 1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidHPATestBuilder(buildTestClientWithHPAScheme()).
+			WithResourceMetric(testCase.resourceName, testCase.averageUtilization)
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+		if testCase.expectedError == "" {
+			assert.Len(t, testBuilder.Definition.Spec.Metrics, 1)
+
+			metric := testBuilder.Definition.Spec.Metrics[0]
+			assert.Equal(t, autoscalingv2.ResourceMetricSourceType, metric.Type)
+			assert.Equal(t, testCase.resourceName, metric.Resource.Name)
+			assert.Equal(t, testCase.averageUtilization, *metric.Resource.Target.AverageUtilization)
+		}
+	}
+}
+
+func TestHPAWithPodsMetric(t *testing.T) {
+	testCases := []struct {
+		metricName    string
+		averageValue  resource.Quantity
+		expectedError string
+	}{
+		{
+			metricName:    "requests_per_second",
+			averageValue:  resource.MustParse("100"),
+			expectedError: "",
+		},
+		{
+			metricName:    "",
+			averageValue:  resource.MustParse("100"),
+			expectedError: "horizontalPodAutoscaler pods metric 'metricName' cannot be empty",
+		},
+		{
+			metricName:    "requests_per_second",
+			averageValue:  resource.MustParse("0"),
+			expectedError: "horizontalPodAutoscaler pods metric 'averageValue' must be positive",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidHPATestBuilder(buildTestClientWithHPAScheme()).
+			WithPodsMetric(testCase.metricName, testCase.averageValue)
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+		if testCase.expectedError == "" {
+			assert.Len(t, testBuilder.Definition.Spec.Metrics, 1)
+
+			metric := testBuilder.Definition.Spec.Metrics[0]
+			assert.Equal(t, autoscalingv2.PodsMetricSourceType, metric.Type)
+			assert.Equal(t, testCase.metricName, metric.Pods.Metric.Name)
+			assert.True(t, testCase.averageValue.Equal(*metric.Pods.Target.AverageValue))
+		}
+	}
+}
+
+func TestHPAWithExternalMetric(t *testing.T) {
+	queueLength := resource.MustParse("30")
+
+	testCases := []struct {
+		metricName    string
+		target        autoscalingv2.MetricTarget
+		expectedError string
+	}{
+		{
+			metricName:    "queue_length",
+			target:        autoscalingv2.MetricTarget{Type: autoscalingv2.ValueMetricType, Value: &queueLength},
+			expectedError: "",
+		},
+		{
+			metricName:    "",
+			target:        autoscalingv2.MetricTarget{Type: autoscalingv2.ValueMetricType, Value: &queueLength},
+			expectedError: "horizontalPodAutoscaler external metric 'metricName' cannot be empty",
+		},
+		{
+			metricName: "queue_length",
+			target:     autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType},
+			expectedError: "horizontalPodAutoscaler external metric has invalid target: " +
+				"target of type AverageValue must set averageValue",
+		},
+		{
+			metricName:    "queue_length",
+			target:        autoscalingv2.MetricTarget{},
+			expectedError: "horizontalPodAutoscaler external metric has invalid target: unknown target type \"\"",
+		},
+	}
+
+	for _, testCase := range testCases {
+		selector := &metav1.LabelSelector{MatchLabels: map[string]string{"queue": "orders"}}
+		testBuilder := buildValidHPATestBuilder(buildTestClientWithHPAScheme()).
+			WithExternalMetric(testCase.metricName, selector, testCase.target)
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+		if testCase.expectedError == "" {
+			assert.Len(t, testBuilder.Definition.Spec.Metrics, 1)
+
+			metric := testBuilder.Definition.Spec.Metrics[0]
+			assert.Equal(t, autoscalingv2.ExternalMetricSourceType, metric.Type)
+			assert.Equal(t, testCase.metricName, metric.External.Metric.Name)
+			assert.Equal(t, selector, metric.External.Metric.Selector)
+			assert.Equal(t, testCase.target, metric.External.Target)
+		}
+	}
+}
+
+func TestHPAWithScalingBehavior(t *testing.T) {
+	testCases := []struct {
+		rules         autoscalingv2.HPAScalingRules
+		expectedError string
+	}{
+		{
+			rules: autoscalingv2.HPAScalingRules{
+				StabilizationWindowSeconds: ptr.To[int32](60),
+				Policies: []autoscalingv2.HPAScalingPolicy{
+					{Type: autoscalingv2.PodsScalingPolicy, Value: 2, PeriodSeconds: 30},
+					{Type: autoscalingv2.PercentScalingPolicy, Value: 50, PeriodSeconds: 60},
+				},
+			},
+			expectedError: "",
+		},
+		{
+			rules: autoscalingv2.HPAScalingRules{StabilizationWindowSeconds: ptr.To[int32](3601)},
+			expectedError: "horizontalPodAutoscaler has invalid %s behavior: " +
+				"stabilizationWindowSeconds must be between 0 and 3600",
+		},
+		{
+			rules: autoscalingv2.HPAScalingRules{
+				Policies: []autoscalingv2.HPAScalingPolicy{{Type: "Nodes", Value: 1, PeriodSeconds: 30}},
+			},
+			expectedError: "horizontalPodAutoscaler has invalid %s behavior: policy has unknown type \"Nodes\"",
+		},
+		{
+			rules: autoscalingv2.HPAScalingRules{
+				Policies: []autoscalingv2.HPAScalingPolicy{
+					{Type: autoscalingv2.PodsScalingPolicy, Value: 0, PeriodSeconds: 30},
+				},
+			},
+			expectedError: "horizontalPodAutoscaler has invalid %s behavior: policy value must be greater than zero",
+		},
+		{
+			rules: autoscalingv2.HPAScalingRules{
+				Policies: []autoscalingv2.HPAScalingPolicy{
+					{Type: autoscalingv2.PodsScalingPolicy, Value: 1, PeriodSeconds: 1801},
+				},
+			},
+			expectedError: "horizontalPodAutoscaler has invalid %s behavior: " +
+				"policy periodSeconds must be between 1 and 1800",
+		},
+	}
+
+	for _, testCase := range testCases {
+		scaleUpBuilder := buildValidHPATestBuilder(buildTestClientWithHPAScheme()).WithScaleUpBehavior(testCase.rules)
+		scaleDownBuilder := buildValidHPATestBuilder(buildTestClientWithHPAScheme()).WithScaleDownBehavior(testCase.rules)
+
+		if testCase.expectedError == "" {
+			assert.Empty(t, scaleUpBuilder.errorMsg)
+			assert.Empty(t, scaleDownBuilder.errorMsg)
+			assert.Equal(t, testCase.rules, *scaleUpBuilder.Definition.Spec.Behavior.ScaleUp)
+			assert.Equal(t, testCase.rules, *scaleDownBuilder.Definition.Spec.Behavior.ScaleDown)
+		} else {
+			assert.Equal(t, fmt.Sprintf(testCase.expectedError, "scale up"), scaleUpBuilder.errorMsg)
+			assert.Equal(t, fmt.Sprintf(testCase.expectedError, "scale down"), scaleDownBuilder.errorMsg)
+		}
+	}
+}
+
+func TestHPAGetReplicas(t *testing.T) {
+	testCases := []struct {
+		exists          bool
+		currentReplicas int32
+		desiredReplicas int32
+		expectedError   error
+	}{
+		{
+			exists:          true,
+			currentReplicas: 2,
+			desiredReplicas: 4,
+			expectedError:   nil,
+		},
+		{
+			exists: false,
+			expectedError: fmt.Errorf(
+				"horizontalPodAutoscaler object %s does not exist in namespace %s", defaultHPAName, defaultHPANamespace),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := buildTestClientWithHPAScheme()
+
+		if testCase.exists {
+			testSettings = buildTestClientWithDummyHPA(autoscalingv2.HorizontalPodAutoscalerStatus{
+				CurrentReplicas: testCase.currentReplicas,
+				DesiredReplicas: testCase.desiredReplicas,
+			})
+		}
+
+		testBuilder := buildValidHPATestBuilder(testSettings)
+
+		currentReplicas, err := testBuilder.GetCurrentReplicas()
+		assert.Equal(t, testCase.expectedError, err)
+		assert.Equal(t, testCase.currentReplicas, currentReplicas)
+
+		desiredReplicas, err := testBuilder.GetDesiredReplicas()
+		assert.Equal(t, testCase.expectedError, err)
+		assert.Equal(t, testCase.desiredReplicas, desiredReplicas)
+	}
+}
+
+func TestHPAIsScalingLimited(t *testing.T) {
+	testCases := []struct {
+		conditions     []autoscalingv2.HorizontalPodAutoscalerCondition
+		expectedResult bool
+	}{
+		{
+			conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+				{Type: autoscalingv2.AbleToScale, Status: corev1.ConditionTrue},
+				{Type: autoscalingv2.ScalingLimited, Status: corev1.ConditionTrue, Reason: "TooManyReplicas"},
+			},
+			expectedResult: true,
+		},
+		{
+			conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+				{Type: autoscalingv2.ScalingLimited, Status: corev1.ConditionFalse, Reason: "DesiredWithinRange"},
+			},
+			expectedResult: false,
+		},
+		{
+			conditions:     nil,
+			expectedResult: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidHPATestBuilder(buildTestClientWithDummyHPA(autoscalingv2.HorizontalPodAutoscalerStatus{
+			Conditions: testCase.conditions,
+		}))
+
+		limited, err := testBuilder.IsScalingLimited()
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedResult, limited)
+
+		condition, err := testBuilder.GetCondition(autoscalingv2.ScalingLimited)
+		if len(testCase.conditions) > 0 {
+			assert.Nil(t, err)
+			assert.Equal(t, autoscalingv2.ScalingLimited, condition.Type)
+		} else {
+			assert.Equal(t, fmt.Errorf("horizontalPodAutoscaler %s in namespace %s does not have condition %s",
+				defaultHPAName, defaultHPANamespace, autoscalingv2.ScalingLimited), err)
+		}
+	}
+}
+
+func TestHPAWaitUntilDesiredReplicas(t *testing.T) {
+	testCases := []struct {
+		exists          bool
+		currentReplicas int32
+		desiredReplicas int32
+		expectedError   error
+	}{
+		{
+			exists:          true,
+			currentReplicas: 3,
+			desiredReplicas: 3,
+			expectedError:   nil,
+		},
+		{
+			exists:          true,
+			currentReplicas: 2,
+			desiredReplicas: 3,
+			expectedError:   context.DeadlineExceeded,
+		},
+		{
+			exists: false,
+			expectedError: fmt.Errorf("cannot wait for non-existent horizontalPodAutoscaler %s in namespace %s",
+				defaultHPAName, defaultHPANamespace),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := buildTestClientWithHPAScheme()
+
+		if testCase.exists {
+			testSettings = buildTestClientWithDummyHPA(autoscalingv2.HorizontalPodAutoscalerStatus{
+				CurrentReplicas: testCase.currentReplicas,
+				DesiredReplicas: testCase.desiredReplicas,
+			})
+		}
+
+		err := buildValidHPATestBuilder(testSettings).WaitUntilDesiredReplicas(3, time.Second)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+func TestHPAValidate(t *testing.T) {
+	testCases := []struct {
+		builderNil    bool
+		definitionNil bool
+		apiClientNil  bool
+		errorMsg      string
+		expectedError error
+	}{
+		{
+			expectedError: nil,
+		},
+		{
+			builderNil:    true,
+			expectedError: fmt.Errorf("error: received nil horizontalPodAutoscaler builder"),
+		},
+		{
+			definitionNil: true,
+			expectedError: fmt.Errorf("can not redefine the undefined horizontalPodAutoscaler"),
+		},
+		{
+			apiClientNil:  true,
+			expectedError: fmt.Errorf("horizontalPodAutoscaler builder cannot have nil apiClient"),
+		},
+		{
+			errorMsg:      "test error",
+			expectedError: fmt.Errorf("test error"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidHPATestBuilder(buildTestClientWithHPAScheme())
+
+		if testCase.builderNil {
+			testBuilder = nil
+		}
+
+		if testCase.definitionNil {
+			testBuilder.Definition = nil
+		}
+
+		if testCase.apiClientNil {
+			testBuilder.apiClient = nil
+		}
+
+		if testCase.errorMsg != "" {
+			testBuilder.errorMsg = testCase.errorMsg
+		}
+
+		valid, err := testBuilder.validate()
+		assert.Equal(t, testCase.expectedError, err)
+		assert.Equal(t, testCase.expectedError == nil, valid)
+	}
+}
+
+func buildDummyHPA(status autoscalingv2.HorizontalPodAutoscalerStatus) *autoscalingv2.HorizontalPodAutoscaler {
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      defaultHPAName,
+			Namespace: defaultHPANamespace,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: defaultScaleTargetRef,
+			MaxReplicas:    defaultHPAMaxReplicas,
+		},
+		Status: status,
+	}
+}
+
+func buildTestClientWithHPAScheme() *clients.Settings {
+	return clients.GetTestClients(clients.TestClientParams{
+		SchemeAttachers: hpaTestSchemes,
+	})
+}
+
+func buildTestClientWithDummyHPA(status autoscalingv2.HorizontalPodAutoscalerStatus) *clients.Settings {
+	return clients.GetTestClients(clients.TestClientParams{
+		K8sMockObjects:  []runtime.Object{buildDummyHPA(status)},
+		SchemeAttachers: hpaTestSchemes,
+	})
+}
+
+func buildValidHPATestBuilder(apiClient *clients.Settings) *Builder {
+	return NewBuilder(apiClient, defaultHPAName, defaultHPANamespace, defaultScaleTargetRef, defaultHPAMaxReplicas)
+}
+
+func buildInvalidHPATestBuilder(apiClient *clients.Settings) *Builder {
+	return NewBuilder(apiClient, defaultHPAName, defaultHPANamespace, defaultScaleTargetRef, 0)
+}
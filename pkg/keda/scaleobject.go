@@ -8,12 +8,17 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/hpa"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/msg"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	goclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// defaultHPANamePrefix is prepended by KEDA to the scaledObject name when naming the HorizontalPodAutoscaler it
+// generates, unless a custom name is configured.
+const defaultHPANamePrefix = "keda-hpa-"
+
 // ScaledObjectBuilder provides a struct for ScaledObject object from the cluster
 // and a ScaledObject definition.
 type ScaledObjectBuilder struct {
@@ -339,6 +344,35 @@ func (builder *ScaledObjectBuilder) WithScaleTargetRef(
 	return builder
 }
 
+// GetHPA returns a builder for the HorizontalPodAutoscaler that KEDA generated for the scaledObject. The name
+// reported in the scaledObject status is used when present, otherwise the name is derived the same way KEDA does.
+func (builder *ScaledObjectBuilder) GetHPA() (*hpa.Builder, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting HorizontalPodAutoscaler generated for scaledObject %s in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	if !builder.Exists() {
+		return nil, fmt.Errorf("cannot get HorizontalPodAutoscaler of scaledObject %s in namespace %s "+
+			"because it does not exist", builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	hpaName := builder.Object.Status.HpaName
+
+	if hpaName == "" {
+		hpaName = defaultHPANamePrefix + builder.Object.Name
+
+		if advanced := builder.Object.Spec.Advanced; advanced != nil &&
+			advanced.HorizontalPodAutoscalerConfig != nil && advanced.HorizontalPodAutoscalerConfig.Name != "" {
+			hpaName = advanced.HorizontalPodAutoscalerConfig.Name
+		}
+	}
+
+	return hpa.Pull(builder.apiClient, hpaName, builder.Definition.Namespace)
+}
+
 // validate will check that the builder and builder definition are properly initialized before
 // accessing any member fields.
 func (builder *ScaledObjectBuilder) validate() (bool, error) {
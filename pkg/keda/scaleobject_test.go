@@ -8,6 +8,7 @@ import (
 
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/stretchr/testify/assert"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -557,6 +558,96 @@ func TestScaleObjectWithTriggers(t *testing.T) {
 	}
 }
 
+func TestScaledObjectGetHPA(t *testing.T) {
+	testCases := []struct {
+		hpaName       string
+		customName    string
+		addHPA        bool
+		addSO         bool
+		expectedName  string
+		expectedError error
+	}{
+		{
+			hpaName:       "status-hpa",
+			addHPA:        true,
+			addSO:         true,
+			expectedName:  "status-hpa",
+			expectedError: nil,
+		},
+		{
+			addHPA:        true,
+			addSO:         true,
+			expectedName:  "keda-hpa-" + defaultScaledObjectName,
+			expectedError: nil,
+		},
+		{
+			customName:    "custom-hpa",
+			addHPA:        true,
+			addSO:         true,
+			expectedName:  "custom-hpa",
+			expectedError: nil,
+		},
+		{
+			addHPA: false,
+			addSO:  true,
+			expectedError: fmt.Errorf("horizontalPodAutoscaler object keda-hpa-%s does not exist in namespace %s",
+				defaultScaledObjectName, defaultScaledObjectNamespace),
+		},
+		{
+			addHPA: true,
+			addSO:  false,
+			expectedError: fmt.Errorf("cannot get HorizontalPodAutoscaler of scaledObject %s in namespace %s "+
+				"because it does not exist", defaultScaledObjectName, defaultScaledObjectNamespace),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.addSO {
+			scaledObject := &kedav2v1alpha1.ScaledObject{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      defaultScaledObjectName,
+					Namespace: defaultScaledObjectNamespace,
+				},
+				Status: kedav2v1alpha1.ScaledObjectStatus{HpaName: testCase.hpaName},
+			}
+
+			if testCase.customName != "" {
+				scaledObject.Spec.Advanced = &kedav2v1alpha1.AdvancedConfig{
+					HorizontalPodAutoscalerConfig: &kedav2v1alpha1.HorizontalPodAutoscalerConfig{
+						Name: testCase.customName,
+					},
+				}
+			}
+
+			runtimeObjects = append(runtimeObjects, scaledObject)
+		}
+
+		if testCase.addHPA {
+			runtimeObjects = append(runtimeObjects, &autoscalingv2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testCase.expectedName,
+					Namespace: defaultScaledObjectNamespace,
+				},
+			})
+		}
+
+		testSettings := clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects:  runtimeObjects,
+			SchemeAttachers: append(kedav2v1alpha1TestSchemes, autoscalingv2.AddToScheme),
+		})
+
+		hpaBuilder, err := buildValidScaledObjectBuilder(testSettings).GetHPA()
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, testCase.expectedName, hpaBuilder.Definition.Name)
+			assert.Equal(t, defaultScaledObjectNamespace, hpaBuilder.Definition.Namespace)
+		}
+	}
+}
+
 func buildValidScaledObjectBuilder(apiClient *clients.Settings) *ScaledObjectBuilder {
 	scaleObjectBuilder := NewScaledObjectBuilder(
 		apiClient, defaultScaledObjectName, defaultScaledObjectNamespace)
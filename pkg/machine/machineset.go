@@ -12,6 +12,7 @@ import (
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/msg"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -261,6 +262,110 @@ func WaitForMachineSetReady(
 		})
 }
 
+// GetScale returns the scale subresource of the MachineSet.
+func (builder *SetBuilder) GetScale() (*autoscalingv1.Scale, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting scale of MachineSet %s in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	return builder.getScale(context.TODO())
+}
+
+// Scale sets the number of replicas of the MachineSet using the scale subresource. Only the replica count is sent to
+// the cluster, so the rest of the MachineSet spec is left untouched.
+func (builder *SetBuilder) Scale(ctx context.Context, replicas int32) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Scaling MachineSet %s in namespace %s to %d replicas",
+		builder.Definition.Name, builder.Definition.Namespace, replicas)
+
+	if replicas < 0 {
+		glog.V(100).Infof("The replicas of the MachineSet cannot be negative")
+
+		return fmt.Errorf("MachineSet 'replicas' cannot be negative")
+	}
+
+	scale, err := builder.getScale(ctx)
+	if err != nil {
+		return err
+	}
+
+	scale.Spec.Replicas = replicas
+
+	scaleBytes, err := json.Marshal(scale)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scale of MachineSet %s: %w", builder.Definition.Name, err)
+	}
+
+	err = builder.apiClient.MachineV1beta1Interface.RESTClient().Put().
+		Namespace(builder.Definition.Namespace).
+		Resource("machinesets").
+		Name(builder.Definition.Name).
+		SubResource("scale").
+		Body(scaleBytes).
+		Do(ctx).
+		Error()
+	if err != nil {
+		glog.V(100).Infof("Failed to scale MachineSet %s in namespace %s: %v",
+			builder.Definition.Name, builder.Definition.Namespace, err)
+
+		return fmt.Errorf("failed to scale MachineSet %s in namespace %s: %w",
+			builder.Definition.Name, builder.Definition.Namespace, err)
+	}
+
+	builder.Object, err = builder.apiClient.MachineSets(builder.Definition.Namespace).Get(
+		ctx, builder.Definition.Name, metav1.GetOptions{})
+
+	return err
+}
+
+// WaitUntilScaled waits for the duration of the defined timeout or until the MachineSet has been observed with the
+// provided number of replicas and all of its Machines are ready.
+func (builder *SetBuilder) WaitUntilScaled(replicas int32, timeout time.Duration) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Waiting for the defined period until MachineSet %s in namespace %s has %d ready replicas",
+		builder.Definition.Name, builder.Definition.Namespace, replicas)
+
+	if !builder.Exists() {
+		return fmt.Errorf("cannot wait for MachineSet %s in namespace %s to scale because it does not exist",
+			builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	return wait.PollUntilContextTimeout(
+		context.TODO(), 30*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+			var err error
+			builder.Object, err = builder.apiClient.MachineSets(builder.Definition.Namespace).Get(
+				context.TODO(), builder.Definition.Name, metav1.GetOptions{})
+
+			if err != nil {
+				glog.V(100).Infof("Failed to get MachineSet from cluster: %v", err)
+
+				return false, nil
+			}
+
+			if builder.Object.Status.ObservedGeneration < builder.Object.Generation {
+				return false, nil
+			}
+
+			if builder.Object.Spec.Replicas == nil || *builder.Object.Spec.Replicas != replicas {
+				return false, nil
+			}
+
+			glog.V(100).Infof("MachineSet %s has %d replicas with %d in Ready state",
+				builder.Object.Name, builder.Object.Status.Replicas, builder.Object.Status.ReadyReplicas)
+
+			return builder.Object.Status.Replicas == replicas && builder.Object.Status.ReadyReplicas == replicas, nil
+		})
+}
+
 // ChangeCloudProviderInstanceType calls the cloud-specific function to change the ProviderSpec instance type param.
 func (builder *SetBuilder) ChangeCloudProviderInstanceType(instanceType string) error {
 	if valid, err := builder.validate(); !valid {
@@ -519,6 +624,39 @@ func createNewWorkerMachineSetFromCopy(
 	return copiedSetBuilder, nil
 }
 
+// getScale reads the scale subresource of the MachineSet. The generated MachineSet client does not provide GetScale, so
+// the request is made through the REST client and decoded manually.
+func (builder *SetBuilder) getScale(ctx context.Context) (*autoscalingv1.Scale, error) {
+	if !builder.Exists() {
+		return nil, fmt.Errorf("cannot get scale of MachineSet %s in namespace %s because it does not exist",
+			builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	scaleBytes, err := builder.apiClient.MachineV1beta1Interface.RESTClient().Get().
+		Namespace(builder.Definition.Namespace).
+		Resource("machinesets").
+		Name(builder.Definition.Name).
+		SubResource("scale").
+		Do(ctx).
+		Raw()
+	if err != nil {
+		glog.V(100).Infof("Failed to get scale of MachineSet %s in namespace %s: %v",
+			builder.Definition.Name, builder.Definition.Namespace, err)
+
+		return nil, fmt.Errorf("failed to get scale of MachineSet %s in namespace %s: %w",
+			builder.Definition.Name, builder.Definition.Namespace, err)
+	}
+
+	scale := &autoscalingv1.Scale{}
+
+	err = json.Unmarshal(scaleBytes, scale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scale of MachineSet %s: %w", builder.Definition.Name, err)
+	}
+
+	return scale, nil
+}
+
 // getPublicCloudKind determines the public cloud kind and stores it in the builder struct.
 func (builder *SetBuilder) getPublicCloudKind() error {
 	if valid, err := builder.validate(); !valid {
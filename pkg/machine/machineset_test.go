@@ -0,0 +1,332 @@
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinev1beta1client "github.com/openshift/client-go/machine/clientset/versioned/typed/machine/v1beta1"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/stretchr/testify/assert"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	defaultMachineSetName      = "test-machineset"
+	defaultMachineSetNamespace = "openshift-machine-api"
+)
+
+func TestSetBuilderGetScale(t *testing.T) {
+	testCases := []struct {
+		exists           bool
+		nilClient        bool
+		expectedReplicas int32
+		expectedError    error
+	}{
+		{
+			exists:           true,
+			nilClient:        false,
+			expectedReplicas: 2,
+			expectedError:    nil,
+		},
+		{
+			exists:    false,
+			nilClient: false,
+			expectedError: fmt.Errorf("cannot get scale of MachineSet %s in namespace %s because it does not exist",
+				defaultMachineSetName, defaultMachineSetNamespace),
+		},
+		{
+			exists:        true,
+			nilClient:     true,
+			expectedError: fmt.Errorf("MachineSet builder cannot have nil apiClient"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		server := newFakeMachineSetServer(testCase.exists, false)
+		testBuilder := buildValidSetTestBuilder(server.clientSettings(testCase.nilClient))
+
+		scale, err := testBuilder.GetScale()
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, defaultMachineSetName, scale.Name)
+			assert.Equal(t, testCase.expectedReplicas, scale.Spec.Replicas)
+		}
+
+		server.Close()
+	}
+}
+
+func TestSetBuilderScale(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		nilClient     bool
+		replicas      int32
+		expectedError error
+	}{
+		{
+			exists:        true,
+			nilClient:     false,
+			replicas:      3,
+			expectedError: nil,
+		},
+		{
+			exists:        true,
+			nilClient:     false,
+			replicas:      0,
+			expectedError: nil,
+		},
+		{
+			exists:        true,
+			nilClient:     false,
+			replicas:      -1,
+			expectedError: fmt.Errorf("MachineSet 'replicas' cannot be negative"),
+		},
+		{
+			exists:    false,
+			nilClient: false,
+			replicas:  3,
+			expectedError: fmt.Errorf("cannot get scale of MachineSet %s in namespace %s because it does not exist",
+				defaultMachineSetName, defaultMachineSetNamespace),
+		},
+		{
+			exists:        true,
+			nilClient:     true,
+			replicas:      3,
+			expectedError: fmt.Errorf("MachineSet builder cannot have nil apiClient"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		server := newFakeMachineSetServer(testCase.exists, false)
+		testBuilder := buildValidSetTestBuilder(server.clientSettings(testCase.nilClient))
+
+		err := testBuilder.Scale(t.Context(), testCase.replicas)
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			// Only the replica count of the scale subresource read from the cluster is sent back.
+			scaleRequests := server.getScaleRequests()
+			assert.Len(t, scaleRequests, 1)
+			assert.Equal(t, defaultMachineSetName, scaleRequests[0].Name)
+			assert.Equal(t, testCase.replicas, scaleRequests[0].Spec.Replicas)
+
+			assert.Equal(t, testCase.replicas, *testBuilder.Object.Spec.Replicas)
+		} else {
+			assert.Empty(t, server.getScaleRequests())
+		}
+
+		server.Close()
+	}
+}
+
+func TestSetBuilderWaitUntilScaled(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		ready         bool
+		expectedError error
+	}{
+		{
+			exists:        true,
+			ready:         true,
+			expectedError: nil,
+		},
+		{
+			exists:        true,
+			ready:         false,
+			expectedError: context.DeadlineExceeded,
+		},
+		{
+			exists: false,
+			ready:  true,
+			expectedError: fmt.Errorf("cannot wait for MachineSet %s in namespace %s to scale because it does not exist",
+				defaultMachineSetName, defaultMachineSetNamespace),
+		},
+	}
+
+	for _, testCase := range testCases {
+		server := newFakeMachineSetServer(testCase.exists, testCase.ready)
+		testBuilder := buildValidSetTestBuilder(server.clientSettings(false))
+
+		if testCase.exists {
+			err := testBuilder.Scale(t.Context(), 3)
+			assert.Nil(t, err)
+		}
+
+		err := testBuilder.WaitUntilScaled(3, 100*time.Millisecond)
+		if testCase.expectedError == context.DeadlineExceeded {
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		} else {
+			assert.Equal(t, testCase.expectedError, err)
+		}
+
+		server.Close()
+	}
+}
+
+// fakeMachineSetServer serves a single MachineSet and its scale subresource from memory. The generated MachineSet
+// client cannot be faked for the scale subresource since it is only reachable through the REST client.
+type fakeMachineSetServer struct {
+	*httptest.Server
+
+	mutex         sync.Mutex
+	machineSet    *machinev1beta1.MachineSet
+	readyOnScale  bool
+	scaleRequests []autoscalingv1.Scale
+}
+
+// newFakeMachineSetServer starts a fakeMachineSetServer with a MachineSet of 2 ready replicas if exists is true. If
+// readyOnScale is true, every Machine is reported ready as soon as the MachineSet is scaled.
+func newFakeMachineSetServer(exists, readyOnScale bool) *fakeMachineSetServer {
+	server := &fakeMachineSetServer{readyOnScale: readyOnScale}
+
+	if exists {
+		server.machineSet = buildDummyMachineSet(2)
+	}
+
+	path := fmt.Sprintf("/apis/machine.openshift.io/v1beta1/namespaces/%s/machinesets/%s",
+		defaultMachineSetNamespace, defaultMachineSetName)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+path, server.handleGetMachineSet)
+	mux.HandleFunc("GET "+path+"/scale", server.handleGetScale)
+	mux.HandleFunc("PUT "+path+"/scale", server.handleUpdateScale)
+
+	server.Server = httptest.NewServer(mux)
+
+	return server
+}
+
+// clientSettings returns the client settings to use with the server, or nil if nilClient is true.
+func (server *fakeMachineSetServer) clientSettings(nilClient bool) *clients.Settings {
+	if nilClient {
+		return nil
+	}
+
+	return &clients.Settings{
+		MachineV1beta1Interface: machinev1beta1client.NewForConfigOrDie(&rest.Config{Host: server.URL}),
+	}
+}
+
+// getScaleRequests returns the scale subresources sent to the server.
+func (server *fakeMachineSetServer) getScaleRequests() []autoscalingv1.Scale {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.scaleRequests
+}
+
+func (server *fakeMachineSetServer) handleGetMachineSet(w http.ResponseWriter, r *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if server.machineSet == nil {
+		writeNotFound(w)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, server.machineSet)
+}
+
+func (server *fakeMachineSetServer) handleGetScale(w http.ResponseWriter, r *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if server.machineSet == nil {
+		writeNotFound(w)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &autoscalingv1.Scale{
+		TypeMeta:   metav1.TypeMeta{Kind: "Scale", APIVersion: "autoscaling/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: defaultMachineSetName, Namespace: defaultMachineSetNamespace},
+		Spec:       autoscalingv1.ScaleSpec{Replicas: *server.machineSet.Spec.Replicas},
+		Status:     autoscalingv1.ScaleStatus{Replicas: server.machineSet.Status.Replicas},
+	})
+}
+
+func (server *fakeMachineSetServer) handleUpdateScale(w http.ResponseWriter, r *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	scale := autoscalingv1.Scale{}
+
+	err := json.NewDecoder(r.Body).Decode(&scale)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	server.scaleRequests = append(server.scaleRequests, scale)
+
+	server.machineSet.Spec.Replicas = ptr.To(scale.Spec.Replicas)
+	server.machineSet.Generation++
+
+	if server.readyOnScale {
+		server.machineSet.Status.ObservedGeneration = server.machineSet.Generation
+		server.machineSet.Status.Replicas = scale.Spec.Replicas
+		server.machineSet.Status.ReadyReplicas = scale.Spec.Replicas
+	}
+
+	writeJSON(w, http.StatusOK, &scale)
+}
+
+// writeNotFound writes the Status the API server returns for a missing MachineSet.
+func writeNotFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, &metav1.Status{
+		TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+		Status:   metav1.StatusFailure,
+		Reason:   metav1.StatusReasonNotFound,
+		Code:     http.StatusNotFound,
+		Message:  fmt.Sprintf("machinesets.machine.openshift.io %q not found", defaultMachineSetName),
+	})
+}
+
+// writeJSON writes the object as the JSON body of a response with the provided status code.
+func writeJSON(w http.ResponseWriter, statusCode int, object any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	_ = json.NewEncoder(w).Encode(object)
+}
+
+// buildDummyMachineSet returns a MachineSet whose replicas are all ready.
+func buildDummyMachineSet(replicas int32) *machinev1beta1.MachineSet {
+	return &machinev1beta1.MachineSet{
+		TypeMeta: metav1.TypeMeta{Kind: "MachineSet", APIVersion: "machine.openshift.io/v1beta1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       defaultMachineSetName,
+			Namespace:  defaultMachineSetNamespace,
+			Generation: 1,
+		},
+		Spec: machinev1beta1.MachineSetSpec{Replicas: ptr.To(replicas)},
+		Status: machinev1beta1.MachineSetStatus{
+			Replicas:           replicas,
+			ReadyReplicas:      replicas,
+			ObservedGeneration: 1,
+		},
+	}
+}
+
+// buildValidSetTestBuilder returns a SetBuilder for the default MachineSet using the provided client.
+func buildValidSetTestBuilder(apiClient *clients.Settings) *SetBuilder {
+	return &SetBuilder{
+		apiClient: apiClient,
+		Definition: &machinev1beta1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{Name: defaultMachineSetName, Namespace: defaultMachineSetNamespace},
+		},
+	}
+}
@@ -0,0 +1,313 @@
+package mco
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	ignitionv2 "github.com/coreos/ignition/config/v2_3"
+	ignitionconfig "github.com/coreos/ignition/v2/config"
+	ignitionutil "github.com/coreos/ignition/v2/config/util"
+	ignitionv34 "github.com/coreos/ignition/v2/config/v3_4"
+	ignitiontypes "github.com/coreos/ignition/v2/config/v3_4/types"
+	ignitionlatesttypes "github.com/coreos/ignition/v2/config/v3_6_experimental/types"
+	"github.com/golang/glog"
+	"k8s.io/utils/ptr"
+)
+
+// ignitionSSHUser is the only user whose SSH keys are managed by the Machine Config Operator.
+const ignitionSSHUser = "core"
+
+// WithFile adds a file with the provided mode and contents to the Ignition config of the MachineConfig. The mode is
+// the full permission bits, for example 0644. If a file with the same path already exists in the config, it is
+// replaced.
+func (builder *MCBuilder) WithFile(path string, mode int, contents string) *MCBuilder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Adding file %s with mode %#o to machineconfig %s", path, mode, builder.Definition.Name)
+
+	if path == "" {
+		glog.V(100).Infof("The file path cannot be empty")
+
+		builder.errorMsg = "ignition file 'path' cannot be empty"
+
+		return builder
+	}
+
+	if mode < 0 || mode > 0o7777 {
+		glog.V(100).Infof("The file mode %#o is invalid", mode)
+
+		builder.errorMsg = fmt.Sprintf("ignition file 'mode' %#o is not a valid permission", mode)
+
+		return builder
+	}
+
+	return builder.mutateIgnitionConfig(func(config *ignitiontypes.Config) {
+		file := ignitiontypes.File{
+			Node: ignitiontypes.Node{Path: path, Overwrite: ptr.To(true)},
+			FileEmbedded1: ignitiontypes.FileEmbedded1{
+				Mode: ptr.To(mode),
+				Contents: ignitiontypes.Resource{
+					Source: ptr.To("data:;base64," + base64.StdEncoding.EncodeToString([]byte(contents))),
+				},
+			},
+		}
+
+		for index := range config.Storage.Files {
+			if config.Storage.Files[index].Path == path {
+				config.Storage.Files[index] = file
+
+				return
+			}
+		}
+
+		config.Storage.Files = append(config.Storage.Files, file)
+	})
+}
+
+// WithSystemdUnit adds a systemd unit to the Ignition config of the MachineConfig. If contents is empty, the unit is
+// expected to already exist on the node and only its enablement is configured. If a unit with the same name already
+// exists in the config, its contents and enablement are replaced while its drop-ins are kept.
+func (builder *MCBuilder) WithSystemdUnit(name string, enabled bool, contents string) *MCBuilder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Adding systemd unit %s with enabled %t to machineconfig %s",
+		name, enabled, builder.Definition.Name)
+
+	if name == "" {
+		glog.V(100).Infof("The systemd unit name cannot be empty")
+
+		builder.errorMsg = "systemd unit 'name' cannot be empty"
+
+		return builder
+	}
+
+	return builder.mutateIgnitionConfig(func(config *ignitiontypes.Config) {
+		unit := getOrAddIgnitionUnit(config, name)
+		unit.Enabled = ptr.To(enabled)
+		unit.Contents = nil
+
+		if contents != "" {
+			unit.Contents = ptr.To(contents)
+		}
+	})
+}
+
+// WithSystemdDropIn adds a drop-in for the systemd unit to the Ignition config of the MachineConfig. The unit does
+// not need to be defined in the config. If a drop-in with the same name already exists for the unit, it is
+// replaced.
+func (builder *MCBuilder) WithSystemdDropIn(unitName, dropInName, contents string) *MCBuilder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Adding drop-in %s for systemd unit %s to machineconfig %s",
+		dropInName, unitName, builder.Definition.Name)
+
+	if unitName == "" {
+		glog.V(100).Infof("The systemd unit name cannot be empty")
+
+		builder.errorMsg = "systemd unit 'name' cannot be empty"
+
+		return builder
+	}
+
+	if dropInName == "" {
+		glog.V(100).Infof("The systemd drop-in name cannot be empty")
+
+		builder.errorMsg = "systemd drop-in 'name' cannot be empty"
+
+		return builder
+	}
+
+	if contents == "" {
+		glog.V(100).Infof("The systemd drop-in contents cannot be empty")
+
+		builder.errorMsg = "systemd drop-in 'contents' cannot be empty"
+
+		return builder
+	}
+
+	return builder.mutateIgnitionConfig(func(config *ignitiontypes.Config) {
+		unit := getOrAddIgnitionUnit(config, unitName)
+		dropIn := ignitiontypes.Dropin{Name: dropInName, Contents: ptr.To(contents)}
+
+		for index := range unit.Dropins {
+			if unit.Dropins[index].Name == dropInName {
+				unit.Dropins[index] = dropIn
+
+				return
+			}
+		}
+
+		unit.Dropins = append(unit.Dropins, dropIn)
+	})
+}
+
+// WithSSHKey adds the public SSH key to the core user in the Ignition config of the MachineConfig. Keys that are
+// already present are not added again.
+func (builder *MCBuilder) WithSSHKey(sshKey string) *MCBuilder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Adding SSH key to user %s in machineconfig %s", ignitionSSHUser, builder.Definition.Name)
+
+	if sshKey == "" {
+		glog.V(100).Infof("The SSH key cannot be empty")
+
+		builder.errorMsg = "'sshKey' cannot be empty"
+
+		return builder
+	}
+
+	return builder.mutateIgnitionConfig(func(config *ignitiontypes.Config) {
+		var user *ignitiontypes.PasswdUser
+
+		for index := range config.Passwd.Users {
+			if config.Passwd.Users[index].Name == ignitionSSHUser {
+				user = &config.Passwd.Users[index]
+
+				break
+			}
+		}
+
+		if user == nil {
+			config.Passwd.Users = append(config.Passwd.Users, ignitiontypes.PasswdUser{Name: ignitionSSHUser})
+			user = &config.Passwd.Users[len(config.Passwd.Users)-1]
+		}
+
+		for _, existingKey := range user.SSHAuthorizedKeys {
+			if string(existingKey) == sshKey {
+				return
+			}
+		}
+
+		user.SSHAuthorizedKeys = append(user.SSHAuthorizedKeys, ignitiontypes.SSHAuthorizedKey(sshKey))
+	})
+}
+
+// mutateIgnitionConfig parses the Ignition config of the MachineConfig, applies mutate to it, and stores the result
+// back in the definition. An empty config is treated as a new config with the latest supported version. Configs of
+// older spec 3 versions are translated to the latest supported version, while spec 2 configs and configs of newer
+// versions cannot be modified.
+func (builder *MCBuilder) mutateIgnitionConfig(mutate func(config *ignitiontypes.Config)) *MCBuilder {
+	config := ignitiontypes.Config{
+		Ignition: ignitiontypes.Ignition{Version: ignitiontypes.MaxVersion.String()},
+	}
+
+	if len(builder.Definition.Spec.Config.Raw) != 0 {
+		parsedConfig, report, err := ignitionv34.ParseCompatibleVersion(builder.Definition.Spec.Config.Raw)
+		if err != nil {
+			glog.V(100).Infof("Failed to parse ignition config of machineconfig %s: %v: %s",
+				builder.Definition.Name, err, report.String())
+
+			builder.errorMsg = fmt.Sprintf("failed to parse ignition config: %v",
+				formatIgnitionError(err, report.String()))
+
+			return builder
+		}
+
+		config = parsedConfig
+	}
+
+	mutate(&config)
+
+	rawConfig, err := json.Marshal(config)
+	if err != nil {
+		glog.V(100).Infof("Failed to marshal ignition config of machineconfig %s: %v", builder.Definition.Name, err)
+
+		builder.errorMsg = fmt.Sprintf("failed to marshal ignition config: %v", err)
+
+		return builder
+	}
+
+	builder.Definition.Spec.Config.Raw = rawConfig
+
+	return builder
+}
+
+// validateIgnitionConfig checks that the Ignition config of the MachineConfig, if any, can be parsed and is valid
+// according to the Ignition config parser of its spec version. Both spec 2 and spec 3 configs are accepted, as they are
+// by the Machine Config Operator, and only reports containing errors make the config invalid.
+func (builder *MCBuilder) validateIgnitionConfig() error {
+	if len(builder.Definition.Spec.Config.Raw) == 0 {
+		return nil
+	}
+
+	var err error
+
+	if isSpec2IgnitionConfig(builder.Definition.Spec.Config.Raw) {
+		err = validateSpec2IgnitionConfig(builder.Definition.Spec.Config.Raw)
+	} else {
+		_, err = parseIgnitionConfig(builder.Definition.Spec.Config.Raw)
+	}
+
+	if err != nil {
+		glog.V(100).Infof("The ignition config of machineconfig %s is invalid: %v", builder.Definition.Name, err)
+
+		return fmt.Errorf("machineconfig %s has invalid ignition config: %w", builder.Definition.Name, err)
+	}
+
+	return nil
+}
+
+// parseIgnitionConfig parses a spec 3 Ignition config of any version and returns it translated to the latest spec
+// version known to the Ignition config parser.
+func parseIgnitionConfig(rawConfig []byte) (ignitionlatesttypes.Config, error) {
+	config, report, err := ignitionconfig.Parse(rawConfig)
+	if err != nil {
+		return ignitionlatesttypes.Config{}, formatIgnitionError(err, report.String())
+	}
+
+	return config, nil
+}
+
+// validateSpec2IgnitionConfig checks that a spec 2 Ignition config is valid. Spec 2 configs are translated to spec 3 by
+// the Machine Config Operator, so they are validated but never parsed into spec 3 types.
+func validateSpec2IgnitionConfig(rawConfig []byte) error {
+	_, report, err := ignitionv2.Parse(rawConfig)
+	if err != nil {
+		return formatIgnitionError(err, report.String())
+	}
+
+	return nil
+}
+
+// isSpec2IgnitionConfig returns true if the Ignition config has a spec 2 version. Configs whose version cannot be read
+// are left to the spec 3 parser to report.
+func isSpec2IgnitionConfig(rawConfig []byte) bool {
+	version, _, err := ignitionutil.GetConfigVersion(rawConfig)
+
+	return err == nil && version.Major == 2
+}
+
+// getOrAddIgnitionUnit returns a pointer to the unit with the provided name in the config, adding an empty unit if it
+// does not exist.
+func getOrAddIgnitionUnit(config *ignitiontypes.Config, name string) *ignitiontypes.Unit {
+	for index := range config.Systemd.Units {
+		if config.Systemd.Units[index].Name == name {
+			return &config.Systemd.Units[index]
+		}
+	}
+
+	config.Systemd.Units = append(config.Systemd.Units, ignitiontypes.Unit{Name: name})
+
+	return &config.Systemd.Units[len(config.Systemd.Units)-1]
+}
+
+// formatIgnitionError returns err with the entries of the Ignition parser report appended on a single line, if there
+// are any.
+func formatIgnitionError(err error, report string) error {
+	report = strings.TrimSpace(report)
+	if report == "" {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s", err, strings.ReplaceAll(report, "\n", "; "))
+}
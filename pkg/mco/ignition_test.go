@@ -0,0 +1,310 @@
+package mco
+
+import (
+	"encoding/base64"
+	"testing"
+
+	ignitionv34 "github.com/coreos/ignition/v2/config/v3_4"
+	ignitiontypes "github.com/coreos/ignition/v2/config/v3_4/types"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	defaultIgnitionFilePath = "/etc/test.conf"
+	defaultIgnitionUnitName = "test.service"
+	defaultIgnitionSSHKey   = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAITest test@example.com"
+)
+
+func TestMachineConfigWithFile(t *testing.T) {
+	testCases := []struct {
+		path          string
+		mode          int
+		expectedError string
+	}{
+		{
+			path:          defaultIgnitionFilePath,
+			mode:          0o644,
+			expectedError: "",
+		},
+		{
+			path:          "",
+			mode:          0o644,
+			expectedError: "ignition file 'path' cannot be empty",
+		},
+		{
+			path:          defaultIgnitionFilePath,
+			mode:          0o10000,
+			expectedError: "ignition file 'mode' 010000 is not a valid permission",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidMachineConfigTestBuilder(clients.GetTestClients(clients.TestClientParams{}))
+		testBuilder = testBuilder.WithFile(testCase.path, 0o600, "old").WithFile(testCase.path, testCase.mode, "new")
+
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+		if testCase.expectedError == "" {
+			config := parseTestIgnitionConfig(t, testBuilder)
+
+			assert.Len(t, config.Storage.Files, 1)
+			assert.Equal(t, testCase.path, config.Storage.Files[0].Path)
+			assert.Equal(t, ptr.To(testCase.mode), config.Storage.Files[0].Mode)
+			assert.Equal(t, ptr.To("data:;base64,"+base64.StdEncoding.EncodeToString([]byte("new"))),
+				config.Storage.Files[0].Contents.Source)
+		}
+	}
+}
+
+func TestMachineConfigWithSystemdUnit(t *testing.T) {
+	testCases := []struct {
+		name          string
+		contents      string
+		expectedError string
+	}{
+		{
+			name:          defaultIgnitionUnitName,
+			contents:      "[Unit]\nDescription=test\n",
+			expectedError: "",
+		},
+		{
+			name:          defaultIgnitionUnitName,
+			contents:      "",
+			expectedError: "",
+		},
+		{
+			name:          "",
+			contents:      "[Unit]\nDescription=test\n",
+			expectedError: "systemd unit 'name' cannot be empty",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidMachineConfigTestBuilder(clients.GetTestClients(clients.TestClientParams{}))
+		testBuilder = testBuilder.
+			WithSystemdDropIn(defaultIgnitionUnitName, "10-test.conf", "[Service]\nNice=10\n").
+			WithSystemdUnit(testCase.name, true, testCase.contents)
+
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+		if testCase.expectedError == "" {
+			config := parseTestIgnitionConfig(t, testBuilder)
+
+			assert.Len(t, config.Systemd.Units, 1)
+			assert.Equal(t, ptr.To(true), config.Systemd.Units[0].Enabled)
+			assert.Len(t, config.Systemd.Units[0].Dropins, 1)
+
+			if testCase.contents == "" {
+				assert.Nil(t, config.Systemd.Units[0].Contents)
+			} else {
+				assert.Equal(t, ptr.To(testCase.contents), config.Systemd.Units[0].Contents)
+			}
+		}
+	}
+}
+
+func TestMachineConfigWithSystemdDropIn(t *testing.T) {
+	testCases := []struct {
+		unitName      string
+		dropInName    string
+		contents      string
+		expectedError string
+	}{
+		{
+			unitName:      defaultIgnitionUnitName,
+			dropInName:    "10-test.conf",
+			contents:      "[Service]\nNice=10\n",
+			expectedError: "",
+		},
+		{
+			unitName:      "",
+			dropInName:    "10-test.conf",
+			contents:      "[Service]\nNice=10\n",
+			expectedError: "systemd unit 'name' cannot be empty",
+		},
+		{
+			unitName:      defaultIgnitionUnitName,
+			dropInName:    "",
+			contents:      "[Service]\nNice=10\n",
+			expectedError: "systemd drop-in 'name' cannot be empty",
+		},
+		{
+			unitName:      defaultIgnitionUnitName,
+			dropInName:    "10-test.conf",
+			contents:      "",
+			expectedError: "systemd drop-in 'contents' cannot be empty",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidMachineConfigTestBuilder(clients.GetTestClients(clients.TestClientParams{}))
+		testBuilder = testBuilder.
+			WithSystemdDropIn(defaultIgnitionUnitName, "10-test.conf", "[Service]\nNice=5\n").
+			WithSystemdDropIn(testCase.unitName, testCase.dropInName, testCase.contents)
+
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+		if testCase.expectedError == "" {
+			config := parseTestIgnitionConfig(t, testBuilder)
+
+			assert.Len(t, config.Systemd.Units, 1)
+			assert.Nil(t, config.Systemd.Units[0].Contents)
+			assert.Equal(t, []ignitiontypes.Dropin{{Name: testCase.dropInName, Contents: ptr.To(testCase.contents)}},
+				config.Systemd.Units[0].Dropins)
+		}
+	}
+}
+
+func TestMachineConfigWithSSHKey(t *testing.T) {
+	testCases := []struct {
+		sshKey        string
+		expectedError string
+	}{
+		{
+			sshKey:        defaultIgnitionSSHKey,
+			expectedError: "",
+		},
+		{
+			sshKey:        "",
+			expectedError: "'sshKey' cannot be empty",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidMachineConfigTestBuilder(clients.GetTestClients(clients.TestClientParams{}))
+		testBuilder = testBuilder.WithSSHKey(defaultIgnitionSSHKey).WithSSHKey(testCase.sshKey)
+
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+		if testCase.expectedError == "" {
+			config := parseTestIgnitionConfig(t, testBuilder)
+
+			assert.Equal(t, []ignitiontypes.PasswdUser{{
+				Name:              ignitionSSHUser,
+				SSHAuthorizedKeys: []ignitiontypes.SSHAuthorizedKey{defaultIgnitionSSHKey},
+			}}, config.Passwd.Users)
+		}
+	}
+}
+
+func TestMachineConfigIgnitionExistingConfig(t *testing.T) {
+	testCases := []struct {
+		rawConfig     string
+		expectedError string
+	}{
+		{
+			rawConfig:     `{"ignition":{"version":"3.2.0"},"storage":{"files":[{"path":"/etc/existing.conf"}]}}`,
+			expectedError: "",
+		},
+		{
+			rawConfig:     `{"ignition":{"version":"9.9.9"}}`,
+			expectedError: "failed to parse ignition config: unsupported config version",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidMachineConfigTestBuilder(clients.GetTestClients(clients.TestClientParams{}))
+		testBuilder = testBuilder.WithRawConfig([]byte(testCase.rawConfig)).WithFile(defaultIgnitionFilePath, 0o644, "")
+
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+		if testCase.expectedError == "" {
+			config := parseTestIgnitionConfig(t, testBuilder)
+
+			assert.Equal(t, ignitiontypes.MaxVersion.String(), config.Ignition.Version)
+			assert.Len(t, config.Storage.Files, 2)
+		}
+	}
+}
+
+func TestMachineConfigCreateInvalidIgnition(t *testing.T) {
+	testCases := []struct {
+		path          string
+		expectedError string
+	}{
+		{
+			path:          defaultIgnitionFilePath,
+			expectedError: "",
+		},
+		{
+			path:          "etc/test.conf",
+			expectedError: "path not absolute",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidMachineConfigTestBuilder(clients.GetTestClients(clients.TestClientParams{
+			SchemeAttachers: testSchemes,
+		}))
+
+		_, err := testBuilder.WithFile(testCase.path, 0o644, "test").Create()
+
+		if testCase.expectedError == "" {
+			assert.Nil(t, err)
+			assert.True(t, testBuilder.Exists())
+		} else {
+			assert.ErrorContains(t, err, "machineconfig test-machine-config has invalid ignition config")
+			assert.ErrorContains(t, err, testCase.expectedError)
+			assert.False(t, testBuilder.Exists())
+		}
+	}
+}
+
+func TestMachineConfigCreateRawIgnition(t *testing.T) {
+	testCases := []struct {
+		rawConfig     string
+		expectedError string
+	}{
+		{
+			rawConfig:     `{"ignition":{"version":"3.5.0"},"storage":{"files":[{"path":"/etc/test.conf"}]}}`,
+			expectedError: "",
+		},
+		{
+			rawConfig: `{"ignition":{"version":"2.2.0"},"storage":{"files":[` +
+				`{"filesystem":"root","path":"/etc/test.conf"}]}}`,
+			expectedError: "",
+		},
+		{
+			rawConfig:     `{"ignition":{"version":"3.5.0"},"storage":{"files":[{"path":"etc/test.conf"}]}}`,
+			expectedError: "path not absolute",
+		},
+		{
+			rawConfig: `{"ignition":{"version":"2.2.0"},"storage":{"files":[` +
+				`{"filesystem":"root","path":"etc/test.conf"}]}}`,
+			expectedError: "path not absolute",
+		},
+		{
+			rawConfig:     `{"ignition":{"version":"9.0.0"}}`,
+			expectedError: "unsupported config version",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidMachineConfigTestBuilder(clients.GetTestClients(clients.TestClientParams{
+			SchemeAttachers: testSchemes,
+		}))
+
+		_, err := testBuilder.WithRawConfig([]byte(testCase.rawConfig)).Create()
+
+		if testCase.expectedError == "" {
+			assert.Nil(t, err)
+			assert.True(t, testBuilder.Exists())
+		} else {
+			assert.ErrorContains(t, err, "machineconfig test-machine-config has invalid ignition config")
+			assert.ErrorContains(t, err, testCase.expectedError)
+			assert.False(t, testBuilder.Exists())
+		}
+	}
+}
+
+// parseTestIgnitionConfig parses the Ignition config of the builder, failing the test if it is not valid.
+func parseTestIgnitionConfig(t *testing.T, builder *MCBuilder) ignitiontypes.Config {
+	t.Helper()
+
+	config, report, err := ignitionv34.Parse(builder.Definition.Spec.Config.Raw)
+	assert.Nil(t, err, report.String())
+
+	return config
+}
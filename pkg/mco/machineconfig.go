@@ -136,6 +136,10 @@ func (builder *MCBuilder) Create() (*MCBuilder, error) {
 
 	glog.V(100).Infof("Creating MachineConfig %s", builder.Definition.Name)
 
+	if err := builder.validateIgnitionConfig(); err != nil {
+		return builder, err
+	}
+
 	var err error
 	if !builder.Exists() {
 		err := builder.apiClient.Create(context.TODO(), builder.Definition)
@@ -181,6 +185,10 @@ func (builder *MCBuilder) Update() (*MCBuilder, error) {
 
 	glog.V(100).Infof("Updating machineconfig %s", builder.Definition.Name)
 
+	if err := builder.validateIgnitionConfig(); err != nil {
+		return builder, err
+	}
+
 	err := builder.apiClient.Update(context.TODO(), builder.Definition)
 	if err == nil {
 		builder.Object = builder.Definition
@@ -0,0 +1,560 @@
+package mco
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	ignitiontypes "github.com/coreos/ignition/v2/config/v3_6_experimental/types"
+	"github.com/golang/glog"
+	mcv1 "github.com/openshift/api/machineconfiguration/v1"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/vincent-petithory/dataurl"
+	"k8s.io/utils/ptr"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MachineConfigChange describes how an element of a MachineConfig changed between two configs.
+type MachineConfigChange string
+
+const (
+	// MachineConfigChangeAdded means the element only exists in the new config.
+	MachineConfigChangeAdded MachineConfigChange = "Added"
+	// MachineConfigChangeRemoved means the element only exists in the old config.
+	MachineConfigChangeRemoved MachineConfigChange = "Removed"
+	// MachineConfigChangeModified means the element exists in both configs but differs.
+	MachineConfigChangeModified MachineConfigChange = "Modified"
+)
+
+// noRebootFilePaths are the files that the machine-config-daemon applies without rebooting the node by default. Any
+// other file change requires a reboot.
+var noRebootFilePaths = []string{
+	"/etc/containers/policy.json",
+	"/etc/containers/registries.conf",
+	"/etc/kubernetes/kubelet-ca.crt",
+	"/etc/kubernetes/static-pod-resources/configmaps/cloud-config/ca-bundle.pem",
+	"/etc/mco/internal-registry-pull-secret.json",
+	"/etc/pki/ca-trust/source/anchors/openshift-config-user-ca-bundle.crt",
+	"/var/lib/kubelet/config.json",
+}
+
+// noRebootDirectories are the directories whose files the machine-config-daemon applies without rebooting the node by
+// default.
+var noRebootDirectories = []string{
+	"/etc/containers/registries.d/",
+}
+
+// StringSetDiff contains the values added to and removed from a list of strings.
+type StringSetDiff struct {
+	Added   []string
+	Removed []string
+}
+
+// IsEmpty returns true if no values were added or removed.
+func (setDiff StringSetDiff) IsEmpty() bool {
+	return len(setDiff.Added) == 0 && len(setDiff.Removed) == 0
+}
+
+// StringValueDiff contains the old and new values of a string field that changed.
+type StringValueDiff struct {
+	Old string
+	New string
+}
+
+// MachineConfigFileDiff describes a file in the Ignition config that changed between two MachineConfigs.
+type MachineConfigFileDiff struct {
+	// Path is the path of the file on the node.
+	Path string
+	// Change is how the file changed.
+	Change MachineConfigChange
+	// OldMode is the mode of the file in the old config, if any.
+	OldMode *int
+	// NewMode is the mode of the file in the new config, if any.
+	NewMode *int
+	// ContentDiff is a unified diff of the file contents. It is empty if the contents did not change.
+	ContentDiff string
+}
+
+// MachineConfigUnitDiff describes a systemd unit in the Ignition config that changed between two MachineConfigs.
+type MachineConfigUnitDiff struct {
+	// Name is the name of the unit.
+	Name string
+	// Change is how the unit changed.
+	Change MachineConfigChange
+	// OldEnabled is the enablement of the unit in the old config, if set.
+	OldEnabled *bool
+	// NewEnabled is the enablement of the unit in the new config, if set.
+	NewEnabled *bool
+	// ContentDiff is a unified diff of the unit contents. It is empty if the contents did not change.
+	ContentDiff string
+	// DropIns contains the names of the drop-ins of the unit that were added, removed or modified.
+	DropIns []string
+}
+
+// MachineConfigDiff is the structured difference between two MachineConfigs, typically two rendered configs of the
+// same pool.
+type MachineConfigDiff struct {
+	// OldConfig is the name of the old MachineConfig.
+	OldConfig string
+	// NewConfig is the name of the new MachineConfig.
+	NewConfig string
+	// OSImageURL is set if the OS image changed.
+	OSImageURL *StringValueDiff
+	// KernelType is set if the kernel type changed.
+	KernelType *StringValueDiff
+	// FIPSChanged is true if FIPS mode was enabled or disabled.
+	FIPSChanged bool
+	// KernelArguments contains the kernel arguments added and removed.
+	KernelArguments StringSetDiff
+	// Extensions contains the RHCOS extensions added and removed.
+	Extensions StringSetDiff
+	// SSHKeys contains the SSH keys added to and removed from the core user.
+	SSHKeys StringSetDiff
+	// Files contains the files that changed, sorted by path.
+	Files []MachineConfigFileDiff
+	// Units contains the systemd units that changed, sorted by name.
+	Units []MachineConfigUnitDiff
+}
+
+// IsEmpty returns true if no difference was found between the MachineConfigs.
+func (diff *MachineConfigDiff) IsEmpty() bool {
+	return diff.OSImageURL == nil && diff.KernelType == nil && !diff.FIPSChanged &&
+		diff.KernelArguments.IsEmpty() && diff.Extensions.IsEmpty() && diff.SSHKeys.IsEmpty() &&
+		len(diff.Files) == 0 && len(diff.Units) == 0
+}
+
+// RebootReasons returns the changes that require the machine-config-daemon to reboot the node according to the
+// default rules of the Machine Config Operator. SSH key changes and changes to a small set of files, such as the
+// container registries configuration and the pull secret, are applied without a reboot. Every other change requires
+// a reboot. Node disruption policies configured on the cluster are not taken into account.
+func (diff *MachineConfigDiff) RebootReasons() []string {
+	var reasons []string
+
+	if diff.OSImageURL != nil {
+		reasons = append(reasons, "OS image changed")
+	}
+
+	if diff.KernelType != nil {
+		reasons = append(reasons, "kernel type changed")
+	}
+
+	if diff.FIPSChanged {
+		reasons = append(reasons, "FIPS mode changed")
+	}
+
+	if !diff.KernelArguments.IsEmpty() {
+		reasons = append(reasons, "kernel arguments changed")
+	}
+
+	if !diff.Extensions.IsEmpty() {
+		reasons = append(reasons, "extensions changed")
+	}
+
+	for _, file := range diff.Files {
+		if !isNoRebootFile(file.Path) {
+			reasons = append(reasons, fmt.Sprintf("file %s %s", file.Path, strings.ToLower(string(file.Change))))
+		}
+	}
+
+	for _, unit := range diff.Units {
+		reasons = append(reasons, fmt.Sprintf("systemd unit %s %s", unit.Name, strings.ToLower(string(unit.Change))))
+	}
+
+	return reasons
+}
+
+// RequiresReboot returns true if applying the new MachineConfig requires the node to reboot according to the default
+// rules of the Machine Config Operator.
+func (diff *MachineConfigDiff) RequiresReboot() bool {
+	return len(diff.RebootReasons()) > 0
+}
+
+// DiffMachineConfigs returns the difference between the two MachineConfigs, usually rendered configs, with the
+// provided names.
+func DiffMachineConfigs(apiClient *clients.Settings, oldName, newName string) (*MachineConfigDiff, error) {
+	glog.V(100).Infof("Diffing machineconfig %s against machineconfig %s", newName, oldName)
+
+	if apiClient == nil {
+		glog.V(100).Info("The apiClient of the MachineConfig is nil")
+
+		return nil, fmt.Errorf("machineconfig 'apiClient' cannot be nil")
+	}
+
+	err := apiClient.AttachScheme(mcv1.Install)
+	if err != nil {
+		glog.V(100).Info("Failed to add machineconfig v1 scheme to client schemes")
+
+		return nil, err
+	}
+
+	return diffMachineConfigsByName(apiClient.Client, oldName, newName)
+}
+
+// DiffRenderedConfigs returns the difference between the rendered MachineConfig currently applied to the
+// MachineConfigPool and the rendered MachineConfig it is rolling out.
+func (builder *MCPBuilder) DiffRenderedConfigs() (*MachineConfigDiff, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Diffing the rendered configs of MachineConfigPool %s", builder.Definition.Name)
+
+	if !builder.Exists() {
+		return nil, fmt.Errorf("cannot diff rendered configs of MachineConfigPool %s because it does not exist",
+			builder.Definition.Name)
+	}
+
+	return diffMachineConfigsByName(
+		builder.apiClient, builder.Object.Status.Configuration.Name, builder.Object.Spec.Configuration.Name)
+}
+
+// diffMachineConfigsByName gets the MachineConfigs with the provided names and returns their difference.
+func diffMachineConfigsByName(apiClient runtimeclient.Client, oldName, newName string) (*MachineConfigDiff, error) {
+	if oldName == "" {
+		glog.V(100).Info("The old machineconfig name is empty")
+
+		return nil, fmt.Errorf("old machineconfig 'name' cannot be empty")
+	}
+
+	if newName == "" {
+		glog.V(100).Info("The new machineconfig name is empty")
+
+		return nil, fmt.Errorf("new machineconfig 'name' cannot be empty")
+	}
+
+	oldConfig := &mcv1.MachineConfig{}
+
+	err := apiClient.Get(context.TODO(), runtimeclient.ObjectKey{Name: oldName}, oldConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get machineconfig %s: %w", oldName, err)
+	}
+
+	newConfig := &mcv1.MachineConfig{}
+
+	err = apiClient.Get(context.TODO(), runtimeclient.ObjectKey{Name: newName}, newConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get machineconfig %s: %w", newName, err)
+	}
+
+	return diffMachineConfigs(oldConfig, newConfig)
+}
+
+// diffMachineConfigs returns the difference between the specs of the two MachineConfigs.
+func diffMachineConfigs(oldConfig, newConfig *mcv1.MachineConfig) (*MachineConfigDiff, error) {
+	oldIgnition, err := parseMachineConfigIgnition(oldConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	newIgnition, err := parseMachineConfigIgnition(newConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &MachineConfigDiff{
+		OldConfig:       oldConfig.Name,
+		NewConfig:       newConfig.Name,
+		OSImageURL:      diffStringValues(oldConfig.Spec.OSImageURL, newConfig.Spec.OSImageURL),
+		KernelType:      diffStringValues(oldConfig.Spec.KernelType, newConfig.Spec.KernelType),
+		FIPSChanged:     oldConfig.Spec.FIPS != newConfig.Spec.FIPS,
+		KernelArguments: diffStringSets(oldConfig.Spec.KernelArguments, newConfig.Spec.KernelArguments),
+		Extensions:      diffStringSets(oldConfig.Spec.Extensions, newConfig.Spec.Extensions),
+		SSHKeys:         diffStringSets(getIgnitionSSHKeys(oldIgnition), getIgnitionSSHKeys(newIgnition)),
+		Files:           diffIgnitionFiles(oldIgnition.Storage.Files, newIgnition.Storage.Files),
+		Units:           diffIgnitionUnits(oldIgnition.Systemd.Units, newIgnition.Systemd.Units),
+	}
+
+	return diff, nil
+}
+
+// parseMachineConfigIgnition returns the Ignition config of the MachineConfig, or an empty config if it has none.
+func parseMachineConfigIgnition(machineConfig *mcv1.MachineConfig) (ignitiontypes.Config, error) {
+	if len(machineConfig.Spec.Config.Raw) == 0 {
+		return ignitiontypes.Config{}, nil
+	}
+
+	config, err := parseIgnitionConfig(machineConfig.Spec.Config.Raw)
+	if err != nil {
+		glog.V(100).Infof("Failed to parse ignition config of machineconfig %s: %v", machineConfig.Name, err)
+
+		return ignitiontypes.Config{}, fmt.Errorf("failed to parse ignition config of machineconfig %s: %w",
+			machineConfig.Name, err)
+	}
+
+	return config, nil
+}
+
+// diffIgnitionFiles returns the files that were added, removed or modified, sorted by path.
+func diffIgnitionFiles(oldFiles, newFiles []ignitiontypes.File) []MachineConfigFileDiff {
+	oldByPath := map[string]ignitiontypes.File{}
+	for _, file := range oldFiles {
+		oldByPath[file.Path] = file
+	}
+
+	newByPath := map[string]ignitiontypes.File{}
+	for _, file := range newFiles {
+		newByPath[file.Path] = file
+	}
+
+	var fileDiffs []MachineConfigFileDiff
+
+	for _, path := range getSortedUnion(oldByPath, newByPath) {
+		oldFile, inOld := oldByPath[path]
+		newFile, inNew := newByPath[path]
+		fileDiff := MachineConfigFileDiff{Path: path, OldMode: oldFile.Mode, NewMode: newFile.Mode}
+
+		switch {
+		case !inOld:
+			fileDiff.Change = MachineConfigChangeAdded
+		case !inNew:
+			fileDiff.Change = MachineConfigChangeRemoved
+		default:
+			fileDiff.Change = MachineConfigChangeModified
+		}
+
+		fileDiff.ContentDiff = diffContents(path, getIgnitionFileContents(oldFile), getIgnitionFileContents(newFile))
+
+		if fileDiff.Change == MachineConfigChangeModified &&
+			fileDiff.ContentDiff == "" && ptr.Equal(oldFile.Mode, newFile.Mode) {
+			continue
+		}
+
+		fileDiffs = append(fileDiffs, fileDiff)
+	}
+
+	return fileDiffs
+}
+
+// diffIgnitionUnits returns the systemd units that were added, removed or modified, sorted by name.
+func diffIgnitionUnits(oldUnits, newUnits []ignitiontypes.Unit) []MachineConfigUnitDiff {
+	oldByName := map[string]ignitiontypes.Unit{}
+	for _, unit := range oldUnits {
+		oldByName[unit.Name] = unit
+	}
+
+	newByName := map[string]ignitiontypes.Unit{}
+	for _, unit := range newUnits {
+		newByName[unit.Name] = unit
+	}
+
+	var unitDiffs []MachineConfigUnitDiff
+
+	for _, name := range getSortedUnion(oldByName, newByName) {
+		oldUnit, inOld := oldByName[name]
+		newUnit, inNew := newByName[name]
+		unitDiff := MachineConfigUnitDiff{
+			Name:        name,
+			OldEnabled:  oldUnit.Enabled,
+			NewEnabled:  newUnit.Enabled,
+			ContentDiff: diffContents(name, ptr.Deref(oldUnit.Contents, ""), ptr.Deref(newUnit.Contents, "")),
+			DropIns:     diffIgnitionDropIns(oldUnit.Dropins, newUnit.Dropins),
+		}
+
+		switch {
+		case !inOld:
+			unitDiff.Change = MachineConfigChangeAdded
+		case !inNew:
+			unitDiff.Change = MachineConfigChangeRemoved
+		default:
+			unitDiff.Change = MachineConfigChangeModified
+		}
+
+		if unitDiff.Change == MachineConfigChangeModified && unitDiff.ContentDiff == "" && len(unitDiff.DropIns) == 0 &&
+			ptr.Equal(oldUnit.Enabled, newUnit.Enabled) && ptr.Equal(oldUnit.Mask, newUnit.Mask) {
+			continue
+		}
+
+		unitDiffs = append(unitDiffs, unitDiff)
+	}
+
+	return unitDiffs
+}
+
+// diffIgnitionDropIns returns the sorted names of the drop-ins that were added, removed or modified.
+func diffIgnitionDropIns(oldDropIns, newDropIns []ignitiontypes.Dropin) []string {
+	oldByName := map[string]string{}
+	for _, dropIn := range oldDropIns {
+		oldByName[dropIn.Name] = ptr.Deref(dropIn.Contents, "")
+	}
+
+	newByName := map[string]string{}
+	for _, dropIn := range newDropIns {
+		newByName[dropIn.Name] = ptr.Deref(dropIn.Contents, "")
+	}
+
+	var changed []string
+
+	for _, name := range getSortedUnion(oldByName, newByName) {
+		oldContents, inOld := oldByName[name]
+		newContents, inNew := newByName[name]
+
+		if inOld != inNew || oldContents != newContents {
+			changed = append(changed, name)
+		}
+	}
+
+	return changed
+}
+
+// getIgnitionFileContents returns the decoded contents of the file. Files with a remote source cannot be decoded so
+// their source is returned instead.
+func getIgnitionFileContents(file ignitiontypes.File) string {
+	source := ptr.Deref(file.Contents.Source, "")
+	if !strings.HasPrefix(source, "data:") {
+		return source
+	}
+
+	decoded, err := dataurl.DecodeString(source)
+	if err != nil {
+		glog.V(100).Infof("Failed to decode contents of file %s: %v", file.Path, err)
+
+		return source
+	}
+
+	if ptr.Deref(file.Contents.Compression, "") != "gzip" {
+		return string(decoded.Data)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(decoded.Data))
+	if err != nil {
+		glog.V(100).Infof("Failed to decompress contents of file %s: %v", file.Path, err)
+
+		return source
+	}
+
+	defer reader.Close()
+
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		glog.V(100).Infof("Failed to decompress contents of file %s: %v", file.Path, err)
+
+		return source
+	}
+
+	return string(contents)
+}
+
+// getIgnitionSSHKeys returns the SSH keys of the core user in the Ignition config.
+func getIgnitionSSHKeys(config ignitiontypes.Config) []string {
+	var keys []string
+
+	for _, user := range config.Passwd.Users {
+		if user.Name != ignitionSSHUser {
+			continue
+		}
+
+		for _, key := range user.SSHAuthorizedKeys {
+			keys = append(keys, string(key))
+		}
+	}
+
+	return keys
+}
+
+// diffContents returns a unified diff between the old and new contents of the named file or unit, or an empty string
+// if they are equal.
+func diffContents(name, oldContents, newContents string) string {
+	if oldContents == newContents {
+		return ""
+	}
+
+	contentDiff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitContentLines(oldContents),
+		B:        splitContentLines(newContents),
+		FromFile: "a/" + strings.TrimPrefix(name, "/"),
+		ToFile:   "b/" + strings.TrimPrefix(name, "/"),
+		Context:  3,
+	})
+	if err != nil {
+		glog.V(100).Infof("Failed to diff contents of %s: %v", name, err)
+
+		return fmt.Sprintf("contents of %s changed", name)
+	}
+
+	return contentDiff
+}
+
+// splitContentLines splits the contents into lines that each end with a newline, as expected by difflib. Unlike
+// difflib.SplitLines, no extra empty line is added when the contents end with a newline.
+func splitContentLines(contents string) []string {
+	if contents == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(contents, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+
+	lines[len(lines)-1] += "\n"
+
+	return lines
+}
+
+// diffStringValues returns the old and new values if they differ, otherwise nil.
+func diffStringValues(oldValue, newValue string) *StringValueDiff {
+	if oldValue == newValue {
+		return nil
+	}
+
+	return &StringValueDiff{Old: oldValue, New: newValue}
+}
+
+// diffStringSets returns the values only in newValues as added and the values only in oldValues as removed,
+// preserving their order.
+func diffStringSets(oldValues, newValues []string) StringSetDiff {
+	var setDiff StringSetDiff
+
+	for _, value := range newValues {
+		if !slices.Contains(oldValues, value) {
+			setDiff.Added = append(setDiff.Added, value)
+		}
+	}
+
+	for _, value := range oldValues {
+		if !slices.Contains(newValues, value) {
+			setDiff.Removed = append(setDiff.Removed, value)
+		}
+	}
+
+	return setDiff
+}
+
+// isNoRebootFile returns true if the machine-config-daemon applies changes to the file without a reboot by default.
+func isNoRebootFile(path string) bool {
+	if slices.Contains(noRebootFilePaths, path) {
+		return true
+	}
+
+	for _, directory := range noRebootDirectories {
+		if strings.HasPrefix(path, directory) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getSortedUnion returns the sorted keys present in either map.
+func getSortedUnion[T any](first, second map[string]T) []string {
+	var keys []string
+
+	for key := range first {
+		keys = append(keys, key)
+	}
+
+	for key := range second {
+		if _, ok := first[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
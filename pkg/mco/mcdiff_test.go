@@ -0,0 +1,262 @@
+package mco
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	defaultDiffOldConfig  = "rendered-worker-old"
+	defaultDiffNewConfig  = "rendered-worker-new"
+	defaultDiffRegistries = "/etc/containers/registries.conf"
+)
+
+func TestDiffMachineConfigs(t *testing.T) {
+	testCases := []struct {
+		client        bool
+		oldName       string
+		newName       string
+		expectedError error
+	}{
+		{
+			client:        true,
+			oldName:       defaultDiffOldConfig,
+			newName:       defaultDiffNewConfig,
+			expectedError: nil,
+		},
+		{
+			client:        false,
+			oldName:       defaultDiffOldConfig,
+			newName:       defaultDiffNewConfig,
+			expectedError: fmt.Errorf("machineconfig 'apiClient' cannot be nil"),
+		},
+		{
+			client:        true,
+			oldName:       "",
+			newName:       defaultDiffNewConfig,
+			expectedError: fmt.Errorf("old machineconfig 'name' cannot be empty"),
+		},
+		{
+			client:        true,
+			oldName:       defaultDiffOldConfig,
+			newName:       "",
+			expectedError: fmt.Errorf("new machineconfig 'name' cannot be empty"),
+		},
+		{
+			client:  true,
+			oldName: defaultDiffOldConfig,
+			newName: "rendered-worker-missing",
+			expectedError: fmt.Errorf("failed to get machineconfig rendered-worker-missing: " +
+				"machineconfigs.machineconfiguration.openshift.io \"rendered-worker-missing\" not found"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var testSettings *clients.Settings
+
+		if testCase.client {
+			testSettings = buildTestClientWithDiffMachineConfigs()
+		}
+
+		diff, err := DiffMachineConfigs(testSettings, testCase.oldName, testCase.newName)
+
+		if testCase.expectedError != nil {
+			assert.EqualError(t, err, testCase.expectedError.Error())
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assertDefaultMachineConfigDiff(t, diff)
+	}
+}
+
+func TestMachineConfigDiffRequiresReboot(t *testing.T) {
+	testCases := []struct {
+		newBuilder     func(builder *MCBuilder) *MCBuilder
+		expectedEmpty  bool
+		expectedReboot bool
+	}{
+		{
+			newBuilder:     func(builder *MCBuilder) *MCBuilder { return builder },
+			expectedEmpty:  true,
+			expectedReboot: false,
+		},
+		{
+			newBuilder: func(builder *MCBuilder) *MCBuilder {
+				return builder.WithFile(defaultDiffRegistries, 0o644, "new\n").
+					WithFile("/etc/containers/registries.d/test.yaml", 0o644, "test\n").
+					WithSSHKey(defaultIgnitionSSHKey)
+			},
+			expectedEmpty:  false,
+			expectedReboot: false,
+		},
+		{
+			newBuilder: func(builder *MCBuilder) *MCBuilder {
+				return builder.WithFile(defaultDiffRegistries, 0o600, "old\n")
+			},
+			expectedEmpty:  false,
+			expectedReboot: false,
+		},
+		{
+			newBuilder: func(builder *MCBuilder) *MCBuilder {
+				return builder.WithSystemdUnit(defaultIgnitionUnitName, false, "")
+			},
+			expectedEmpty:  false,
+			expectedReboot: true,
+		},
+		{
+			newBuilder: func(builder *MCBuilder) *MCBuilder {
+				return builder.WithRawConfig([]byte(`{"ignition":{"version":"3.5.0"}}`))
+			},
+			expectedEmpty:  false,
+			expectedReboot: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := clients.GetTestClients(clients.TestClientParams{})
+		oldBuilder := buildDiffBaseMachineConfigBuilder(testSettings, defaultDiffOldConfig)
+		newBuilder := testCase.newBuilder(buildDiffBaseMachineConfigBuilder(testSettings, defaultDiffNewConfig))
+
+		diff, err := diffMachineConfigs(oldBuilder.Definition, newBuilder.Definition)
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedEmpty, diff.IsEmpty())
+		assert.Equal(t, testCase.expectedReboot, diff.RequiresReboot())
+	}
+}
+
+func TestMachineConfigPoolDiffRenderedConfigs(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		expectedError error
+	}{
+		{
+			exists:        true,
+			expectedError: nil,
+		},
+		{
+			exists: false,
+			expectedError: fmt.Errorf(
+				"cannot diff rendered configs of MachineConfigPool %s because it does not exist", defaultMCPName),
+		},
+	}
+
+	for _, testCase := range testCases {
+		runtimeObjects := buildDiffMachineConfigObjects()
+
+		if testCase.exists {
+			mcp := buildDummyMCP(defaultMCPName)
+			mcp.Status.Configuration.Name = defaultDiffOldConfig
+			mcp.Spec.Configuration.Name = defaultDiffNewConfig
+
+			runtimeObjects = append(runtimeObjects, mcp)
+		}
+
+		testBuilder := buildValidMCPTestBuilder(clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects:  runtimeObjects,
+			SchemeAttachers: testSchemes,
+		}))
+
+		diff, err := testBuilder.DiffRenderedConfigs()
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, defaultDiffOldConfig, diff.OldConfig)
+			assert.Equal(t, defaultDiffNewConfig, diff.NewConfig)
+			assert.True(t, diff.RequiresReboot())
+		}
+	}
+}
+
+// assertDefaultMachineConfigDiff checks the diff between the MachineConfigs from buildDiffMachineConfigObjects.
+func assertDefaultMachineConfigDiff(t *testing.T, diff *MachineConfigDiff) {
+	t.Helper()
+
+	assert.Equal(t, defaultDiffOldConfig, diff.OldConfig)
+	assert.Equal(t, defaultDiffNewConfig, diff.NewConfig)
+	assert.Equal(t, &StringValueDiff{Old: "", New: "realtime"}, diff.KernelType)
+	assert.Nil(t, diff.OSImageURL)
+	assert.Equal(t, StringSetDiff{Added: []string{"nosmt"}, Removed: []string{"quiet"}}, diff.KernelArguments)
+	assert.Equal(t, StringSetDiff{Added: []string{"usbguard"}}, diff.Extensions)
+	assert.Equal(t, StringSetDiff{Added: []string{defaultIgnitionSSHKey}}, diff.SSHKeys)
+
+	assert.Equal(t, []MachineConfigFileDiff{
+		{
+			Path:    defaultDiffRegistries,
+			Change:  MachineConfigChangeModified,
+			OldMode: ptr.To(0o644),
+			NewMode: ptr.To(0o644),
+			ContentDiff: "--- a/etc/containers/registries.conf\n+++ b/etc/containers/registries.conf\n" +
+				"@@ -1 +1 @@\n-old\n+new\n",
+		},
+		{
+			Path:        "/etc/new.conf",
+			Change:      MachineConfigChangeAdded,
+			NewMode:     ptr.To(0o600),
+			ContentDiff: "--- a/etc/new.conf\n+++ b/etc/new.conf\n@@ -0,0 +1 @@\n+added\n",
+		},
+	}, diff.Files)
+
+	assert.Equal(t, []MachineConfigUnitDiff{{
+		Name:       defaultIgnitionUnitName,
+		Change:     MachineConfigChangeModified,
+		OldEnabled: ptr.To(true),
+		NewEnabled: ptr.To(true),
+		DropIns:    []string{"10-test.conf"},
+	}}, diff.Units)
+
+	assert.Equal(t, []string{
+		"kernel type changed",
+		"kernel arguments changed",
+		"extensions changed",
+		"file /etc/new.conf added",
+		"systemd unit test.service modified",
+	}, diff.RebootReasons())
+	assert.True(t, diff.RequiresReboot())
+	assert.False(t, diff.IsEmpty())
+}
+
+// buildTestClientWithDiffMachineConfigs returns a client with the old and new rendered MachineConfigs.
+func buildTestClientWithDiffMachineConfigs() *clients.Settings {
+	return clients.GetTestClients(clients.TestClientParams{
+		K8sMockObjects:  buildDiffMachineConfigObjects(),
+		SchemeAttachers: testSchemes,
+	})
+}
+
+// buildDiffMachineConfigObjects returns the old and new rendered MachineConfigs used to test diffing. Compared to the
+// old config, the new one changes the kernel type, kernel arguments, extensions, registries config, SSH keys and the
+// drop-ins of a unit, and adds a file.
+func buildDiffMachineConfigObjects() []runtime.Object {
+	testSettings := clients.GetTestClients(clients.TestClientParams{})
+
+	oldBuilder := buildDiffBaseMachineConfigBuilder(testSettings, defaultDiffOldConfig).
+		WithKernelArguments([]string{"quiet", "mitigations=auto"})
+
+	newBuilder := buildDiffBaseMachineConfigBuilder(testSettings, defaultDiffNewConfig).
+		WithKernelType("realtime").
+		WithKernelArguments([]string{"mitigations=auto", "nosmt"}).
+		WithExtensions([]string{"usbguard"}).
+		WithFile(defaultDiffRegistries, 0o644, "new\n").
+		WithFile("/etc/new.conf", 0o600, "added\n").
+		WithSystemdDropIn(defaultIgnitionUnitName, "10-test.conf", "[Service]\nNice=10\n").
+		WithSSHKey(defaultIgnitionSSHKey)
+
+	return []runtime.Object{oldBuilder.Definition, newBuilder.Definition}
+}
+
+// buildDiffBaseMachineConfigBuilder returns an MCBuilder with the content shared by the old and new configs.
+func buildDiffBaseMachineConfigBuilder(apiClient *clients.Settings, name string) *MCBuilder {
+	builder := NewMCBuilder(apiClient, name).
+		WithFile(defaultDiffRegistries, 0o644, "old\n").
+		WithSystemdUnit(defaultIgnitionUnitName, true, "[Unit]\nDescription=test\n")
+	builder.Definition.Spec.OSImageURL = "quay.io/openshift/rhcos@sha256:test"
+
+	return builder
+}
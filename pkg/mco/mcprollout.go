@@ -0,0 +1,404 @@
+package mco
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	mcv1 "github.com/openshift/api/machineconfiguration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// CurrentConfigAnnotation is the node annotation with the rendered MachineConfig currently applied to the node.
+	CurrentConfigAnnotation = "machineconfiguration.openshift.io/currentConfig"
+	// DesiredConfigAnnotation is the node annotation with the rendered MachineConfig the node is moving to.
+	DesiredConfigAnnotation = "machineconfiguration.openshift.io/desiredConfig"
+	// MachineConfigDaemonStateAnnotation is the node annotation with the state of the machine-config-daemon.
+	MachineConfigDaemonStateAnnotation = "machineconfiguration.openshift.io/state"
+	// MachineConfigDaemonReasonAnnotation is the node annotation with the reason the machine-config-daemon is
+	// degraded.
+	MachineConfigDaemonReasonAnnotation = "machineconfiguration.openshift.io/reason"
+)
+
+// MachineConfigDaemonState is the state reported by the machine-config-daemon in the node state annotation.
+type MachineConfigDaemonState string
+
+const (
+	// MachineConfigDaemonStateDone means the node has applied its desired config.
+	MachineConfigDaemonStateDone MachineConfigDaemonState = "Done"
+	// MachineConfigDaemonStateWorking means the node is applying its desired config.
+	MachineConfigDaemonStateWorking MachineConfigDaemonState = "Working"
+	// MachineConfigDaemonStateDegraded means the node failed to apply its desired config.
+	MachineConfigDaemonStateDegraded MachineConfigDaemonState = "Degraded"
+	// MachineConfigDaemonStateUnreconcilable means the desired config cannot be applied to the node.
+	MachineConfigDaemonStateUnreconcilable MachineConfigDaemonState = "Unreconcilable"
+)
+
+// MCPNodeProgress describes the rollout progress of a single node in a MachineConfigPool.
+type MCPNodeProgress struct {
+	// Name is the name of the node.
+	Name string
+	// CurrentConfig is the rendered MachineConfig currently applied to the node.
+	CurrentConfig string
+	// DesiredConfig is the rendered MachineConfig the node is moving to.
+	DesiredConfig string
+	// State is the state reported by the machine-config-daemon on the node.
+	State MachineConfigDaemonState
+	// Reason is the reason reported by the machine-config-daemon when the node is degraded.
+	Reason string
+}
+
+// IsUpdated returns true if the node has applied the target config of the pool.
+func (progress MCPNodeProgress) IsUpdated(targetConfig string) bool {
+	return progress.State == MachineConfigDaemonStateDone &&
+		progress.CurrentConfig == targetConfig && progress.DesiredConfig == targetConfig
+}
+
+// IsUpdating returns true if the node is moving to a new config and is not degraded.
+func (progress MCPNodeProgress) IsUpdating() bool {
+	return !progress.IsDegraded() &&
+		(progress.State == MachineConfigDaemonStateWorking || progress.CurrentConfig != progress.DesiredConfig)
+}
+
+// IsDegraded returns true if the machine-config-daemon on the node reported it is degraded or unreconcilable.
+func (progress MCPNodeProgress) IsDegraded() bool {
+	return progress.State == MachineConfigDaemonStateDegraded ||
+		progress.State == MachineConfigDaemonStateUnreconcilable
+}
+
+// MCPRolloutStatus describes the rollout progress of a MachineConfigPool and each of its nodes.
+type MCPRolloutStatus struct {
+	// PoolName is the name of the MachineConfigPool.
+	PoolName string
+	// TargetConfig is the rendered MachineConfig the pool is rolling out.
+	TargetConfig string
+	// Paused is true if the rollout of the pool is paused.
+	Paused bool
+	// MachineCount is the number of nodes the pool reports in its status.
+	MachineCount int
+	// Nodes contains the progress of each node in the pool, sorted by name.
+	Nodes []MCPNodeProgress
+	// Conditions contains the conditions of the pool.
+	Conditions []mcv1.MachineConfigPoolCondition
+}
+
+// Updated returns the nodes that have applied the target config of the pool.
+func (status *MCPRolloutStatus) Updated() []MCPNodeProgress {
+	return status.filterNodes(func(progress MCPNodeProgress) bool {
+		return progress.IsUpdated(status.TargetConfig)
+	})
+}
+
+// Updating returns the nodes that are currently applying a new config.
+func (status *MCPRolloutStatus) Updating() []MCPNodeProgress {
+	return status.filterNodes(MCPNodeProgress.IsUpdating)
+}
+
+// Degraded returns the nodes that failed to apply their desired config.
+func (status *MCPRolloutStatus) Degraded() []MCPNodeProgress {
+	return status.filterNodes(MCPNodeProgress.IsDegraded)
+}
+
+// IsComplete returns true if every node in the pool has applied the target config, the pool reports as many nodes as
+// were found, and the pool reports it is updated.
+func (status *MCPRolloutStatus) IsComplete() bool {
+	if status.TargetConfig == "" || len(status.Nodes) != status.MachineCount ||
+		len(status.Updated()) != len(status.Nodes) {
+		return false
+	}
+
+	return status.isConditionTrue(mcv1.MachineConfigPoolUpdated)
+}
+
+// filterNodes returns the nodes for which filter returns true.
+func (status *MCPRolloutStatus) filterNodes(filter func(progress MCPNodeProgress) bool) []MCPNodeProgress {
+	var nodes []MCPNodeProgress
+
+	for _, progress := range status.Nodes {
+		if filter(progress) {
+			nodes = append(nodes, progress)
+		}
+	}
+
+	return nodes
+}
+
+// isConditionTrue returns true if the pool has the condition with status True.
+func (status *MCPRolloutStatus) isConditionTrue(conditionType mcv1.MachineConfigPoolConditionType) bool {
+	for _, condition := range status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MCPNodeEvent is reported by TrackRollout when the progress of a node changes.
+type MCPNodeEvent struct {
+	// Time is when the change was observed.
+	Time time.Time
+	// Node is the new progress of the node.
+	Node MCPNodeProgress
+	// PreviousState is the state of the node before the change. It is empty the first time the node is observed.
+	PreviousState MachineConfigDaemonState
+}
+
+// MCPRolloutOptions configures how TrackRollout follows the rollout of a MachineConfigPool.
+type MCPRolloutOptions struct {
+	// Timeout is the maximum time to wait for the rollout to complete. If zero, only the context limits the wait.
+	Timeout time.Duration
+	// OnEvent is called from the tracking goroutine every time the progress of a node changes. It may be nil.
+	OnEvent func(event MCPNodeEvent)
+}
+
+// MCPRolloutError is returned by TrackRollout when the rollout of a MachineConfigPool fails. It names the degraded
+// nodes along with the reason reported for each of them.
+type MCPRolloutError struct {
+	// PoolName is the name of the MachineConfigPool.
+	PoolName string
+	// DegradedNodes contains the nodes that failed to apply their desired config.
+	DegradedNodes []MCPNodeProgress
+	// Conditions contains the degraded conditions of the pool with status True.
+	Conditions []mcv1.MachineConfigPoolCondition
+}
+
+// Error returns a summary of the degraded nodes and pool conditions.
+func (rolloutErr *MCPRolloutError) Error() string {
+	var reasons []string
+
+	for _, node := range rolloutErr.DegradedNodes {
+		reasons = append(reasons, fmt.Sprintf("node %s is %s: %s", node.Name, node.State, node.Reason))
+	}
+
+	for _, condition := range rolloutErr.Conditions {
+		reasons = append(reasons, fmt.Sprintf("pool condition %s: %s", condition.Type, condition.Message))
+	}
+
+	return fmt.Sprintf("rollout of MachineConfigPool %s failed: %s", rolloutErr.PoolName, strings.Join(reasons, "; "))
+}
+
+// GetRolloutStatus returns the rollout progress of the MachineConfigPool and each of its nodes, based on the
+// machine-config-daemon annotations on the nodes. A node selected by the node selector of the pool only belongs to it
+// if its current or desired config is rendered for the pool, since nodes matching several pools, such as the masters
+// of compact clusters or the nodes of custom pools, only ever receive the config of one of them.
+func (builder *MCPBuilder) GetRolloutStatus() (*MCPRolloutStatus, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting rollout status of MachineConfigPool %s", builder.Definition.Name)
+
+	mcp, err := builder.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MachineConfigPool %s: %w", builder.Definition.Name, err)
+	}
+
+	builder.Object = mcp
+
+	nodeSelector, err := metav1.LabelSelectorAsSelector(mcp.Spec.NodeSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse node selector of MachineConfigPool %s: %w", mcp.Name, err)
+	}
+
+	nodeList := &corev1.NodeList{}
+
+	err = builder.apiClient.List(context.TODO(), nodeList, runtimeclient.MatchingLabelsSelector{Selector: nodeSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes of MachineConfigPool %s: %w", mcp.Name, err)
+	}
+
+	status := &MCPRolloutStatus{
+		PoolName:     mcp.Name,
+		TargetConfig: mcp.Spec.Configuration.Name,
+		Paused:       mcp.Spec.Paused,
+		MachineCount: int(mcp.Status.MachineCount),
+		Conditions:   mcp.Status.Conditions,
+	}
+
+	for _, node := range nodeList.Items {
+		if !isRenderedConfigOfPool(node.Annotations[CurrentConfigAnnotation], mcp.Name) &&
+			!isRenderedConfigOfPool(node.Annotations[DesiredConfigAnnotation], mcp.Name) {
+			glog.V(100).Infof("Skipping node %s selected by MachineConfigPool %s with configs of another pool",
+				node.Name, mcp.Name)
+
+			continue
+		}
+
+		status.Nodes = append(status.Nodes, MCPNodeProgress{
+			Name:          node.Name,
+			CurrentConfig: node.Annotations[CurrentConfigAnnotation],
+			DesiredConfig: node.Annotations[DesiredConfigAnnotation],
+			State:         MachineConfigDaemonState(node.Annotations[MachineConfigDaemonStateAnnotation]),
+			Reason:        node.Annotations[MachineConfigDaemonReasonAnnotation],
+		})
+	}
+
+	slices.SortFunc(status.Nodes, func(first, second MCPNodeProgress) int {
+		return strings.Compare(first.Name, second.Name)
+	})
+
+	return status, nil
+}
+
+// TrackRollout follows the rollout of the MachineConfigPool until every node has applied the target config, a node
+// or the pool becomes degraded, or the context or timeout expires. Changes in the progress of each node are reported
+// through the OnEvent callback. If the rollout fails, the returned error is an *MCPRolloutError. The last observed
+// status is returned whenever it is available, including when an error is returned.
+func (builder *MCPBuilder) TrackRollout(ctx context.Context, options MCPRolloutOptions) (*MCPRolloutStatus, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Tracking rollout of MachineConfigPool %s", builder.Definition.Name)
+
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	var (
+		lastStatus *MCPRolloutStatus
+		rolloutErr error
+	)
+
+	previousStates := map[string]MCPNodeProgress{}
+
+	err := wait.PollUntilContextCancel(ctx, fiveScds, true, func(ctx context.Context) (bool, error) {
+		status, err := builder.GetRolloutStatus()
+		if err != nil {
+			glog.V(100).Infof("Failed to get rollout status of MachineConfigPool %s: %v", builder.Definition.Name, err)
+
+			return false, nil
+		}
+
+		lastStatus = status
+
+		reportMCPNodeEvents(status, previousStates, options.OnEvent)
+
+		if failure := getMCPRolloutError(status); failure != nil {
+			rolloutErr = failure
+
+			return true, nil
+		}
+
+		return status.IsComplete(), nil
+	})
+
+	if rolloutErr != nil {
+		return lastStatus, rolloutErr
+	}
+
+	if err != nil {
+		if lastStatus == nil {
+			return nil, fmt.Errorf("rollout of MachineConfigPool %s did not complete: %w", builder.Definition.Name, err)
+		}
+
+		return lastStatus, fmt.Errorf("rollout of MachineConfigPool %s did not complete, %d of %d nodes updated: %w",
+			builder.Definition.Name, len(lastStatus.Updated()), len(lastStatus.Nodes), err)
+	}
+
+	return lastStatus, nil
+}
+
+// Pause pauses the rollout of the MachineConfigPool so that no new config is applied to its nodes.
+func (builder *MCPBuilder) Pause() error {
+	return builder.setPaused(true)
+}
+
+// Unpause resumes the rollout of the MachineConfigPool.
+func (builder *MCPBuilder) Unpause() error {
+	return builder.setPaused(false)
+}
+
+// setPaused updates the paused field of the MachineConfigPool on the cluster if it differs from paused.
+func (builder *MCPBuilder) setPaused(paused bool) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Setting paused to %t on MachineConfigPool %s", paused, builder.Definition.Name)
+
+	if !builder.Exists() {
+		return fmt.Errorf("cannot set paused on MachineConfigPool %s because it does not exist", builder.Definition.Name)
+	}
+
+	if builder.Object.Spec.Paused == paused {
+		builder.Definition = builder.Object
+
+		return nil
+	}
+
+	builder.Object.Spec.Paused = paused
+
+	err := builder.apiClient.Update(context.TODO(), builder.Object)
+	if err != nil {
+		return fmt.Errorf("failed to set paused to %t on MachineConfigPool %s: %w", paused, builder.Definition.Name, err)
+	}
+
+	builder.Definition = builder.Object
+
+	return nil
+}
+
+// reportMCPNodeEvents calls onEvent for every node whose progress differs from the progress in previousStates, then
+// updates previousStates.
+func reportMCPNodeEvents(
+	status *MCPRolloutStatus, previousStates map[string]MCPNodeProgress, onEvent func(event MCPNodeEvent)) {
+	for _, progress := range status.Nodes {
+		previous, seen := previousStates[progress.Name]
+		if seen && previous == progress {
+			continue
+		}
+
+		previousStates[progress.Name] = progress
+
+		glog.V(100).Infof("Node %s in MachineConfigPool %s is %s with current config %s and desired config %s",
+			progress.Name, status.PoolName, progress.State, progress.CurrentConfig, progress.DesiredConfig)
+
+		if onEvent != nil {
+			onEvent(MCPNodeEvent{Time: time.Now(), Node: progress, PreviousState: previous.State})
+		}
+	}
+}
+
+// getMCPRolloutError returns an error describing the degraded nodes and conditions of the pool, or nil if the pool
+// is not degraded.
+func getMCPRolloutError(status *MCPRolloutStatus) *MCPRolloutError {
+	rolloutErr := &MCPRolloutError{PoolName: status.PoolName, DegradedNodes: status.Degraded()}
+
+	for _, condition := range status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch condition.Type {
+		case mcv1.MachineConfigPoolNodeDegraded, mcv1.MachineConfigPoolRenderDegraded:
+			rolloutErr.Conditions = append(rolloutErr.Conditions, condition)
+		default:
+		}
+	}
+
+	if len(rolloutErr.DegradedNodes) == 0 && len(rolloutErr.Conditions) == 0 {
+		return nil
+	}
+
+	return rolloutErr
+}
+
+// isRenderedConfigOfPool returns true if config is the name of a rendered MachineConfig of the pool, which is the
+// pool name followed by a hash without dashes. The hash check keeps worker from matching configs of worker-cnf.
+func isRenderedConfigOfPool(config, poolName string) bool {
+	hash, found := strings.CutPrefix(config, "rendered-"+poolName+"-")
+
+	return found && hash != "" && !strings.Contains(hash, "-")
+}
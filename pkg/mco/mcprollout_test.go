@@ -0,0 +1,317 @@
+package mco
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	mcv1 "github.com/openshift/api/machineconfiguration/v1"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	defaultRolloutOldConfig = "rendered-" + defaultMCPName + "-1a2b3c"
+	defaultRolloutNewConfig = "rendered-" + defaultMCPName + "-4d5e6f"
+	defaultRolloutPoolLabel = "node-role.kubernetes.io/worker"
+)
+
+func TestMachineConfigPoolGetRolloutStatus(t *testing.T) {
+	testCases := []struct {
+		exists           bool
+		expectedUpdated  []string
+		expectedUpdating []string
+		expectedError    error
+	}{
+		{
+			exists:           true,
+			expectedUpdated:  []string{"node-1"},
+			expectedUpdating: []string{"node-2"},
+			expectedError:    nil,
+		},
+		{
+			exists: false,
+			expectedError: fmt.Errorf("failed to get MachineConfigPool %s: %w", defaultMCPName,
+				fmt.Errorf("machineconfigpools.machineconfiguration.openshift.io \"%s\" not found", defaultMCPName)),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects,
+				buildDummyRolloutMCP(mcv1.MachineConfigPoolUpdating),
+				buildDummyRolloutNode("node-2", defaultRolloutOldConfig, defaultRolloutNewConfig,
+					MachineConfigDaemonStateWorking, ""),
+				buildDummyRolloutNode("node-1", defaultRolloutNewConfig, defaultRolloutNewConfig,
+					MachineConfigDaemonStateDone, ""),
+				&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "master-0"}})
+		}
+
+		testBuilder := buildValidMCPTestBuilder(clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects:  runtimeObjects,
+			SchemeAttachers: testSchemes,
+		}))
+
+		status, err := testBuilder.GetRolloutStatus()
+
+		if testCase.expectedError != nil {
+			assert.EqualError(t, err, testCase.expectedError.Error())
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, defaultRolloutNewConfig, status.TargetConfig)
+		assert.Len(t, status.Nodes, 2)
+		assert.Equal(t, "node-1", status.Nodes[0].Name)
+		assert.Equal(t, testCase.expectedUpdated, getRolloutNodeNames(status.Updated()))
+		assert.Equal(t, testCase.expectedUpdating, getRolloutNodeNames(status.Updating()))
+		assert.Empty(t, status.Degraded())
+		assert.False(t, status.IsComplete())
+	}
+}
+
+func TestMachineConfigPoolGetRolloutStatusSharedNodes(t *testing.T) {
+	testCases := []struct {
+		machineCount     int32
+		expectedComplete bool
+	}{
+		{
+			machineCount:     1,
+			expectedComplete: true,
+		},
+		{
+			machineCount:     2,
+			expectedComplete: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		mcp := buildDummyRolloutMCP(mcv1.MachineConfigPoolUpdated)
+		mcp.Status.MachineCount = testCase.machineCount
+
+		// The master of a compact cluster and the node of a custom pool also carry the worker role label.
+		masterNode := buildDummyRolloutNode("master-0", "rendered-master-7a8b9c", "rendered-master-7a8b9c",
+			MachineConfigDaemonStateDone, "")
+		masterNode.Labels["node-role.kubernetes.io/master"] = ""
+
+		customNode := buildDummyRolloutNode("cnf-0", "rendered-"+defaultMCPName+"-cnf-7a8b9c",
+			"rendered-"+defaultMCPName+"-cnf-7a8b9c", MachineConfigDaemonStateDone, "")
+
+		testBuilder := buildValidMCPTestBuilder(clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects: []runtime.Object{
+				mcp,
+				masterNode,
+				customNode,
+				buildDummyRolloutNode("node-1", defaultRolloutNewConfig, defaultRolloutNewConfig,
+					MachineConfigDaemonStateDone, ""),
+			},
+			SchemeAttachers: testSchemes,
+		}))
+
+		status, err := testBuilder.GetRolloutStatus()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"node-1"}, getRolloutNodeNames(status.Nodes))
+		assert.Equal(t, int(testCase.machineCount), status.MachineCount)
+		assert.Equal(t, testCase.expectedComplete, status.IsComplete())
+	}
+}
+
+func TestMachineConfigPoolTrackRollout(t *testing.T) {
+	testCases := []struct {
+		poolCondition   mcv1.MachineConfigPoolConditionType
+		node2Current    string
+		node2State      MachineConfigDaemonState
+		node2Reason     string
+		expectedError   error
+		expectedUpdated int
+	}{
+		{
+			poolCondition:   mcv1.MachineConfigPoolUpdated,
+			node2Current:    defaultRolloutNewConfig,
+			node2State:      MachineConfigDaemonStateDone,
+			expectedError:   nil,
+			expectedUpdated: 2,
+		},
+		{
+			poolCondition: mcv1.MachineConfigPoolNodeDegraded,
+			node2Current:  defaultRolloutOldConfig,
+			node2State:    MachineConfigDaemonStateDegraded,
+			node2Reason:   "failed to drain node",
+			expectedError: &MCPRolloutError{
+				PoolName: defaultMCPName,
+				DegradedNodes: []MCPNodeProgress{{
+					Name:          "node-2",
+					CurrentConfig: defaultRolloutOldConfig,
+					DesiredConfig: defaultRolloutNewConfig,
+					State:         MachineConfigDaemonStateDegraded,
+					Reason:        "failed to drain node",
+				}},
+				Conditions: []mcv1.MachineConfigPoolCondition{{
+					Type:    mcv1.MachineConfigPoolNodeDegraded,
+					Status:  corev1.ConditionTrue,
+					Message: "test message",
+				}},
+			},
+			expectedUpdated: 1,
+		},
+		{
+			poolCondition: mcv1.MachineConfigPoolUpdating,
+			node2Current:  defaultRolloutOldConfig,
+			node2State:    MachineConfigDaemonStateWorking,
+			expectedError: fmt.Errorf("rollout of MachineConfigPool %s did not complete, 1 of 2 nodes updated: %w",
+				defaultMCPName, context.DeadlineExceeded),
+			expectedUpdated: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidMCPTestBuilder(clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects: []runtime.Object{
+				buildDummyRolloutMCP(testCase.poolCondition),
+				buildDummyRolloutNode("node-1", defaultRolloutNewConfig, defaultRolloutNewConfig,
+					MachineConfigDaemonStateDone, ""),
+				buildDummyRolloutNode("node-2", testCase.node2Current, defaultRolloutNewConfig,
+					testCase.node2State, testCase.node2Reason),
+			},
+			SchemeAttachers: testSchemes,
+		}))
+
+		var events []MCPNodeEvent
+
+		status, err := testBuilder.TrackRollout(context.TODO(), MCPRolloutOptions{
+			Timeout: time.Second,
+			OnEvent: func(event MCPNodeEvent) {
+				events = append(events, event)
+			},
+		})
+
+		assert.Equal(t, testCase.expectedError, err)
+		assert.Len(t, status.Updated(), testCase.expectedUpdated)
+		assert.Len(t, events, 2)
+		assert.Equal(t, "node-1", events[0].Node.Name)
+		assert.Equal(t, MachineConfigDaemonState(""), events[0].PreviousState)
+
+		var rolloutErr *MCPRolloutError
+		assert.Equal(t, testCase.node2State == MachineConfigDaemonStateDegraded, errors.As(err, &rolloutErr))
+	}
+}
+
+func TestMCPRolloutErrorError(t *testing.T) {
+	rolloutErr := &MCPRolloutError{
+		PoolName: defaultMCPName,
+		DegradedNodes: []MCPNodeProgress{{
+			Name:   "node-2",
+			State:  MachineConfigDaemonStateUnreconcilable,
+			Reason: "invalid kernel type",
+		}},
+		Conditions: []mcv1.MachineConfigPoolCondition{{
+			Type:    mcv1.MachineConfigPoolNodeDegraded,
+			Status:  corev1.ConditionTrue,
+			Message: "Node node-2 is reporting: invalid kernel type",
+		}},
+	}
+
+	assert.Equal(t, "rollout of MachineConfigPool test-machine-config-pool failed: "+
+		"node node-2 is Unreconcilable: invalid kernel type; "+
+		"pool condition NodeDegraded: Node node-2 is reporting: invalid kernel type", rolloutErr.Error())
+}
+
+func TestMachineConfigPoolPause(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		expectedError error
+	}{
+		{
+			exists:        true,
+			expectedError: nil,
+		},
+		{
+			exists: false,
+			expectedError: fmt.Errorf(
+				"cannot set paused on MachineConfigPool %s because it does not exist", defaultMCPName),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyMCP(defaultMCPName))
+		}
+
+		testBuilder := buildValidMCPTestBuilder(clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects:  runtimeObjects,
+			SchemeAttachers: testSchemes,
+		}))
+
+		err := testBuilder.Pause()
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError != nil {
+			continue
+		}
+
+		mcp, err := testBuilder.Get()
+		assert.Nil(t, err)
+		assert.True(t, mcp.Spec.Paused)
+
+		err = testBuilder.Unpause()
+		assert.Nil(t, err)
+
+		mcp, err = testBuilder.Get()
+		assert.Nil(t, err)
+		assert.False(t, mcp.Spec.Paused)
+	}
+}
+
+// buildDummyRolloutMCP returns a MachineConfigPool of two worker nodes that is rolling out the new config and has the
+// provided condition set to True.
+func buildDummyRolloutMCP(conditionType mcv1.MachineConfigPoolConditionType) *mcv1.MachineConfigPool {
+	mcp := buildDummyMCP(defaultMCPName)
+	mcp.Spec.NodeSelector = &metav1.LabelSelector{MatchLabels: map[string]string{defaultRolloutPoolLabel: ""}}
+	mcp.Spec.Configuration.Name = defaultRolloutNewConfig
+	mcp.Status.MachineCount = 2
+	mcp.Status.Conditions = []mcv1.MachineConfigPoolCondition{{
+		Type:    conditionType,
+		Status:  corev1.ConditionTrue,
+		Message: "test message",
+	}}
+
+	return mcp
+}
+
+// buildDummyRolloutNode returns a worker node with the provided machine-config-daemon annotations.
+func buildDummyRolloutNode(
+	name, currentConfig, desiredConfig string, state MachineConfigDaemonState, reason string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{defaultRolloutPoolLabel: ""},
+			Annotations: map[string]string{
+				CurrentConfigAnnotation:             currentConfig,
+				DesiredConfigAnnotation:             desiredConfig,
+				MachineConfigDaemonStateAnnotation:  string(state),
+				MachineConfigDaemonReasonAnnotation: reason,
+			},
+		},
+	}
+}
+
+// getRolloutNodeNames returns the names of the nodes in progress.
+func getRolloutNodeNames(progress []MCPNodeProgress) []string {
+	var names []string
+
+	for _, node := range progress {
+		names = append(names, node.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,359 @@
+package nodes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// drainRetryInterval is how long to wait before retrying an eviction that was rejected, matching drain.Helper.
+	drainRetryInterval = 5 * time.Second
+	// mirrorPodAnnotation is set by the kubelet on the API representation of static pods.
+	mirrorPodAnnotation = "kubernetes.io/config.mirror"
+)
+
+// DrainEventType is the type of a DrainEvent.
+type DrainEventType string
+
+const (
+	// DrainEventEvicting is reported when the first eviction request for a pod is sent.
+	DrainEventEvicting DrainEventType = "Evicting"
+	// DrainEventEvicted is reported once an evicted pod is gone from the node.
+	DrainEventEvicted DrainEventType = "Evicted"
+	// DrainEventBlocked is reported the first time the eviction of a pod is rejected by a PodDisruptionBudget.
+	DrainEventBlocked DrainEventType = "BlockedByPodDisruptionBudget"
+	// DrainEventFailed is reported when the eviction of a pod fails with an error that is not retried.
+	DrainEventFailed DrainEventType = "Failed"
+)
+
+// DrainOptions controls the behavior of DrainWithReport.
+type DrainOptions struct {
+	// DryRun only computes which pods would be evicted without evicting them.
+	DryRun bool
+	// Timeout bounds the whole drain. When zero, the drain runs until all pods are evicted or the context is done.
+	Timeout time.Duration
+	// OnEvent, if set, is called for every DrainEvent as it happens. Calls are never concurrent.
+	OnEvent func(event DrainEvent)
+}
+
+// DrainEvent describes a step of the eviction of a single pod.
+type DrainEvent struct {
+	Type DrainEventType
+	Pod  DrainPod
+	Time time.Time
+}
+
+// DrainPod identifies a pod handled by DrainWithReport along with what happened to it.
+type DrainPod struct {
+	Namespace string
+	Name      string
+	// Owner is the kind and name of the controller of the pod, such as ReplicaSet/my-app-5d8f, if it has one.
+	Owner string
+	// PodDisruptionBudget is the name of the PodDisruptionBudget that blocked the eviction, if any.
+	PodDisruptionBudget string
+	// Message contains the reason the pod was skipped or the error that caused the eviction to fail.
+	Message string
+}
+
+// DrainResult is the structured outcome of DrainWithReport.
+type DrainResult struct {
+	// DryRun is true if no eviction was attempted.
+	DryRun bool
+	// ToEvict contains all pods selected for eviction. In a dry run, these are the pods that would be evicted.
+	ToEvict []DrainPod
+	// Skipped contains the pods on the node that are not evicted, such as DaemonSet and mirror pods.
+	Skipped []DrainPod
+	// Evicted contains the pods that were evicted and are gone from the node.
+	Evicted []DrainPod
+	// Blocked contains the pods whose eviction was still rejected by a PodDisruptionBudget when the drain ended.
+	Blocked []DrainPod
+	// Failed contains the pods whose eviction failed for any other reason.
+	Failed []DrainPod
+}
+
+// DrainWithReport evicts the pods on the node using the eviction API and reports each step through the OnEvent
+// callback. Pods are selected with the same filters as Drain, configured through SetDrainHelper. Evictions rejected
+// by a PodDisruptionBudget are retried until the drain times out and the blocking PodDisruptionBudget is named in the
+// result. The node is not cordoned, so Cordon should be called first to prevent pods from being scheduled back.
+func (builder *Builder) DrainWithReport(ctx context.Context, options DrainOptions) (*DrainResult, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	builder.ensureDrainHelperIsSet()
+	glog.V(100).Infof("Draining node %s with options %+v", builder.Definition.Name, options)
+
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	result, toEvict, err := builder.planDrain()
+	if err != nil {
+		return result, err
+	}
+
+	result.DryRun = options.DryRun
+
+	if options.DryRun || len(toEvict) == 0 {
+		return result, nil
+	}
+
+	reporter := &drainReporter{onEvent: options.OnEvent}
+
+	var waitGroup sync.WaitGroup
+
+	for _, pod := range toEvict {
+		waitGroup.Add(1)
+
+		go func(pod corev1.Pod) {
+			defer waitGroup.Done()
+
+			builder.evictAndWait(ctx, pod, reporter, result)
+		}(pod)
+	}
+
+	waitGroup.Wait()
+
+	return result, result.err(builder.Definition.Name)
+}
+
+// planDrain selects the pods to evict using the drain helper filters and builds the initial result.
+func (builder *Builder) planDrain() (*DrainResult, []corev1.Pod, error) {
+	result := &DrainResult{}
+
+	allPods, err := builder.ListPods()
+	if err != nil {
+		return result, nil, fmt.Errorf("failed to list pods on node %s: %w", builder.Definition.Name, err)
+	}
+
+	podDeleteList, errs := builder.drainHelper.GetPodsForDeletion(builder.Definition.Name)
+	if len(errs) > 0 {
+		return result, nil, fmt.Errorf("cannot drain node %s: %w", builder.Definition.Name, errors.Join(errs...))
+	}
+
+	selectedPods := podDeleteList.Pods()
+
+	var toEvict []corev1.Pod
+
+	for _, pod := range allPods {
+		isSelected := slices.ContainsFunc(selectedPods, func(selected corev1.Pod) bool {
+			return selected.UID == pod.UID && selected.Namespace == pod.Namespace && selected.Name == pod.Name
+		})
+
+		if isSelected {
+			toEvict = append(toEvict, pod)
+			result.ToEvict = append(result.ToEvict, newDrainPod(pod))
+
+			continue
+		}
+
+		skipped := newDrainPod(pod)
+		skipped.Message = getDrainSkipReason(pod)
+		result.Skipped = append(result.Skipped, skipped)
+	}
+
+	return result, toEvict, nil
+}
+
+// evictAndWait sends eviction requests for the pod until one is accepted, then waits for the pod to be gone. The
+// outcome is recorded in result.
+func (builder *Builder) evictAndWait(
+	ctx context.Context, pod corev1.Pod, reporter *drainReporter, result *DrainResult) {
+	drainPod := newDrainPod(pod)
+	reporter.report(DrainEventEvicting, drainPod)
+
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+	}
+
+	if builder.drainHelper.GracePeriodSeconds >= 0 {
+		gracePeriod := int64(builder.drainHelper.GracePeriodSeconds)
+		eviction.DeleteOptions = &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod}
+	}
+
+	blockReported := false
+
+	for {
+		err := builder.apiClient.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+		if err == nil || k8serrors.IsNotFound(err) {
+			break
+		}
+
+		if !k8serrors.IsTooManyRequests(err) {
+			drainPod.Message = err.Error()
+			reporter.record(DrainEventFailed, drainPod, &result.Failed)
+
+			return
+		}
+
+		drainPod.PodDisruptionBudget = builder.findBlockingPDB(ctx, pod)
+		drainPod.Message = err.Error()
+
+		if !blockReported {
+			reporter.report(DrainEventBlocked, drainPod)
+
+			blockReported = true
+		}
+
+		select {
+		case <-ctx.Done():
+			reporter.record("", drainPod, &result.Blocked)
+
+			return
+		case <-time.After(drainRetryInterval):
+		}
+	}
+
+	err := builder.waitUntilPodDeleted(ctx, pod)
+	if err != nil {
+		drainPod.PodDisruptionBudget = ""
+		drainPod.Message = fmt.Sprintf("pod was evicted but not deleted: %v", err)
+		reporter.record(DrainEventFailed, drainPod, &result.Failed)
+
+		return
+	}
+
+	drainPod.PodDisruptionBudget = ""
+	drainPod.Message = ""
+	reporter.record(DrainEventEvicted, drainPod, &result.Evicted)
+}
+
+// waitUntilPodDeleted waits until the pod no longer exists or has been replaced by a pod with a different UID.
+func (builder *Builder) waitUntilPodDeleted(ctx context.Context, pod corev1.Pod) error {
+	return wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		currentPod, err := builder.apiClient.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		if err != nil {
+			glog.V(100).Infof("Failed to get pod %s in namespace %s: %v", pod.Name, pod.Namespace, err)
+
+			return false, nil
+		}
+
+		return currentPod.UID != pod.UID, nil
+	})
+}
+
+// findBlockingPDB returns the names of the PodDisruptionBudgets in the pod namespace that select the pod. An empty
+// string is returned if they cannot be listed.
+func (builder *Builder) findBlockingPDB(ctx context.Context, pod corev1.Pod) string {
+	pdbList, err := builder.apiClient.PolicyV1().PodDisruptionBudgets(pod.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		glog.V(100).Infof("Failed to list PodDisruptionBudgets in namespace %s: %v", pod.Namespace, err)
+
+		return ""
+	}
+
+	var names []string
+
+	for _, pdb := range pdbList.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+
+		if selector.Matches(labels.Set(pod.Labels)) {
+			names = append(names, pdb.Name)
+		}
+	}
+
+	return strings.Join(names, ",")
+}
+
+// err returns an error summarizing the pods that were not evicted or nil if all pods were evicted.
+func (result *DrainResult) err(nodeName string) error {
+	if len(result.Blocked) == 0 && len(result.Failed) == 0 {
+		return nil
+	}
+
+	var reasons []string
+
+	for _, pod := range result.Blocked {
+		reasons = append(reasons, fmt.Sprintf("pod %s/%s blocked by PodDisruptionBudget %s",
+			pod.Namespace, pod.Name, pod.PodDisruptionBudget))
+	}
+
+	for _, pod := range result.Failed {
+		reasons = append(reasons, fmt.Sprintf("pod %s/%s failed: %s", pod.Namespace, pod.Name, pod.Message))
+	}
+
+	return fmt.Errorf("failed to drain node %s: %s", nodeName, strings.Join(reasons, "; "))
+}
+
+// drainReporter serializes the calls to the OnEvent callback and the updates to the DrainResult.
+type drainReporter struct {
+	mutex   sync.Mutex
+	onEvent func(event DrainEvent)
+}
+
+// report calls the OnEvent callback, if any, with a new event.
+func (reporter *drainReporter) report(eventType DrainEventType, pod DrainPod) {
+	reporter.mutex.Lock()
+	defer reporter.mutex.Unlock()
+
+	reporter.reportLocked(eventType, pod)
+}
+
+// record appends the pod to list and reports the event if eventType is not empty.
+func (reporter *drainReporter) record(eventType DrainEventType, pod DrainPod, list *[]DrainPod) {
+	reporter.mutex.Lock()
+	defer reporter.mutex.Unlock()
+
+	*list = append(*list, pod)
+
+	if eventType != "" {
+		reporter.reportLocked(eventType, pod)
+	}
+}
+
+// reportLocked logs the event and calls the OnEvent callback. The mutex must be held.
+func (reporter *drainReporter) reportLocked(eventType DrainEventType, pod DrainPod) {
+	glog.V(100).Infof("Drain event %s for pod %s/%s: %s", eventType, pod.Namespace, pod.Name, pod.Message)
+
+	if reporter.onEvent != nil {
+		reporter.onEvent(DrainEvent{Type: eventType, Pod: pod, Time: time.Now()})
+	}
+}
+
+// newDrainPod returns a DrainPod identifying the pod and its controller.
+func newDrainPod(pod corev1.Pod) DrainPod {
+	drainPod := DrainPod{Namespace: pod.Namespace, Name: pod.Name}
+
+	if controllerRef := metav1.GetControllerOf(&pod); controllerRef != nil {
+		drainPod.Owner = fmt.Sprintf("%s/%s", controllerRef.Kind, controllerRef.Name)
+	}
+
+	return drainPod
+}
+
+// getDrainSkipReason returns a human readable reason why the drain helper filters did not select the pod.
+func getDrainSkipReason(pod corev1.Pod) string {
+	switch {
+	case isDaemonSetPod(pod):
+		return "pod is managed by a DaemonSet"
+	case pod.Annotations[mirrorPodAnnotation] != "":
+		return "pod is a mirror pod"
+	case pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed:
+		return "pod is terminated"
+	default:
+		return "pod was filtered out by the drain helper"
+	}
+}
@@ -0,0 +1,194 @@
+package nodes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+const defaultDrainNamespace = "test-ns"
+
+func TestNodeDrainWithReport(t *testing.T) {
+	testCases := []struct {
+		dryRun         bool
+		evictionErr    map[string]error
+		expectedResult DrainResult
+		expectedEvents []DrainEventType
+		expectedError  error
+	}{
+		{
+			dryRun: true,
+			expectedResult: DrainResult{
+				DryRun:  true,
+				ToEvict: []DrainPod{{Namespace: defaultDrainNamespace, Name: "app-pod", Owner: "ReplicaSet/app"}},
+				Skipped: []DrainPod{{Namespace: defaultDrainNamespace, Name: "ds-pod", Owner: "DaemonSet/ds",
+					Message: "pod is managed by a DaemonSet"}},
+			},
+			expectedEvents: nil,
+		},
+		{
+			dryRun: false,
+			expectedResult: DrainResult{
+				ToEvict: []DrainPod{{Namespace: defaultDrainNamespace, Name: "app-pod", Owner: "ReplicaSet/app"}},
+				Skipped: []DrainPod{{Namespace: defaultDrainNamespace, Name: "ds-pod", Owner: "DaemonSet/ds",
+					Message: "pod is managed by a DaemonSet"}},
+				Evicted: []DrainPod{{Namespace: defaultDrainNamespace, Name: "app-pod", Owner: "ReplicaSet/app"}},
+			},
+			expectedEvents: []DrainEventType{DrainEventEvicting, DrainEventEvicted},
+		},
+		{
+			dryRun: false,
+			evictionErr: map[string]error{
+				"app-pod": k8serrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0),
+			},
+			expectedResult: DrainResult{
+				ToEvict: []DrainPod{{Namespace: defaultDrainNamespace, Name: "app-pod", Owner: "ReplicaSet/app"}},
+				Skipped: []DrainPod{{Namespace: defaultDrainNamespace, Name: "ds-pod", Owner: "DaemonSet/ds",
+					Message: "pod is managed by a DaemonSet"}},
+				Blocked: []DrainPod{{Namespace: defaultDrainNamespace, Name: "app-pod", Owner: "ReplicaSet/app",
+					PodDisruptionBudget: "app-pdb",
+					Message:             "Cannot evict pod as it would violate the pod's disruption budget."}},
+			},
+			expectedEvents: []DrainEventType{DrainEventEvicting, DrainEventBlocked},
+			expectedError: fmt.Errorf("failed to drain node %s: pod %s/app-pod blocked by PodDisruptionBudget app-pdb",
+				defaultNodeName, defaultDrainNamespace),
+		},
+		{
+			dryRun:      false,
+			evictionErr: map[string]error{"app-pod": k8serrors.NewInternalError(fmt.Errorf("etcd unavailable"))},
+			expectedResult: DrainResult{
+				ToEvict: []DrainPod{{Namespace: defaultDrainNamespace, Name: "app-pod", Owner: "ReplicaSet/app"}},
+				Skipped: []DrainPod{{Namespace: defaultDrainNamespace, Name: "ds-pod", Owner: "DaemonSet/ds",
+					Message: "pod is managed by a DaemonSet"}},
+				Failed: []DrainPod{{Namespace: defaultDrainNamespace, Name: "app-pod", Owner: "ReplicaSet/app",
+					Message: "Internal error occurred: etcd unavailable"}},
+			},
+			expectedEvents: []DrainEventType{DrainEventEvicting, DrainEventFailed},
+			expectedError: fmt.Errorf("failed to drain node %s: pod %s/app-pod failed: "+
+				"Internal error occurred: etcd unavailable", defaultNodeName, defaultDrainNamespace),
+		},
+	}
+
+	for _, testCase := range testCases {
+		evictions := 0
+		fakeClient := buildDrainFakeClient(testCase.evictionErr, &evictions)
+
+		testBuilder := &Builder{apiClient: fakeClient, Definition: buildDummyNode(defaultNodeName)}
+
+		var events []DrainEventType
+
+		result, err := testBuilder.DrainWithReport(context.TODO(), DrainOptions{
+			DryRun:  testCase.dryRun,
+			Timeout: time.Second,
+			OnEvent: func(event DrainEvent) {
+				events = append(events, event.Type)
+			},
+		})
+
+		assert.Equal(t, testCase.expectedError, err)
+		assert.Equal(t, testCase.expectedResult, *result)
+		assert.Equal(t, testCase.expectedEvents, events)
+		assert.Equal(t, testCase.dryRun, evictions == 0)
+	}
+}
+
+func TestNodeDrainWithReportValidate(t *testing.T) {
+	var testBuilder *Builder
+
+	result, err := testBuilder.DrainWithReport(context.TODO(), DrainOptions{DryRun: true})
+	assert.Nil(t, result)
+	assert.Equal(t, fmt.Errorf("error: received nil node builder"), err)
+}
+
+// buildDrainFakeClient returns a fake clientset with the drain objects that handles eviction requests by deleting
+// the pod, or by returning the error in evictionErr for the pod if there is one. Every eviction is counted.
+func buildDrainFakeClient(evictionErr map[string]error, evictions *int) *k8sfake.Clientset {
+	fakeClient := k8sfake.NewSimpleClientset(buildDummyDrainObjects()...)
+	fakeClient.PrependReactor("create", "pods",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "eviction" {
+				return false, nil, nil
+			}
+
+			createAction, ok := action.(k8stesting.CreateAction)
+			if !ok {
+				return false, nil, nil
+			}
+
+			eviction, ok := createAction.GetObject().(*policyv1.Eviction)
+			if !ok {
+				return false, nil, nil
+			}
+
+			*evictions++
+
+			if err, ok := evictionErr[eviction.Name]; ok {
+				return true, nil, err
+			}
+
+			podsResource := corev1.SchemeGroupVersion.WithResource("pods")
+
+			return true, nil, fakeClient.Tracker().Delete(podsResource, eviction.Namespace, eviction.Name)
+		})
+
+	return fakeClient
+}
+
+// buildDummyDrainObjects returns a node with a ReplicaSet pod protected by a PodDisruptionBudget and a DaemonSet
+// pod, along with the DaemonSet itself and a pod on another node that must be ignored.
+func buildDummyDrainObjects() []runtime.Object {
+	appPod := buildDummyDrainPod("app-pod", defaultNodeName, "ReplicaSet", "app")
+	appPod.Labels = map[string]string{"app": "test"}
+
+	return []runtime.Object{
+		buildDummyNode(defaultNodeName),
+		appPod,
+		buildDummyDrainPod("ds-pod", defaultNodeName, "DaemonSet", "ds"),
+		buildDummyDrainPod("other-pod", "other-node", "ReplicaSet", "other"),
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "ds", Namespace: defaultDrainNamespace}},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-pdb", Namespace: defaultDrainNamespace},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+			},
+		},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "unrelated-pdb", Namespace: defaultDrainNamespace},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "unrelated"}},
+			},
+		},
+	}
+}
+
+// buildDummyDrainPod returns a running pod on the node controlled by the provided owner.
+func buildDummyDrainPod(name, nodeName, ownerKind, ownerName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: defaultDrainNamespace,
+			UID:       types.UID(name + "-uid"),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       ownerKind,
+				Name:       ownerName,
+				Controller: ptr.To(true),
+			}},
+		},
+		Spec:   corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
@@ -290,6 +290,88 @@ func (builder *Builder) RemoveLabel(key, value string) *Builder {
 	return builder
 }
 
+// WithTaint appends the provided taint to the Node spec. A taint with the same key and effect cannot already be
+// present. Update must be called for the taint to be applied on the cluster.
+func (builder *Builder) WithTaint(taint corev1.Taint) *Builder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Adding taint %s to node %s", taint.ToString(), builder.Definition.Name)
+
+	if taint.Key == "" {
+		glog.V(100).Infof("Failed to apply taint with an empty key to node %s", builder.Definition.Name)
+
+		builder.errorMsg = "error to set taint with empty key to node"
+
+		return builder
+	}
+
+	if taint.Effect == "" {
+		glog.V(100).Infof("Failed to apply taint with an empty effect to node %s", builder.Definition.Name)
+
+		builder.errorMsg = "error to set taint with empty effect to node"
+
+		return builder
+	}
+
+	for _, existingTaint := range builder.Definition.Spec.Taints {
+		if existingTaint.MatchTaint(&taint) {
+			builder.errorMsg = fmt.Sprintf("cannot overwrite existing node taint: %s:%s", taint.Key, taint.Effect)
+
+			return builder
+		}
+	}
+
+	builder.Definition.Spec.Taints = append(builder.Definition.Spec.Taints, taint)
+
+	return builder
+}
+
+// RemoveTaint removes the taint with the given key and effect from the Node spec. Update must be called for the
+// change to be applied on the cluster.
+func (builder *Builder) RemoveTaint(key string, effect corev1.TaintEffect) *Builder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Removing taint %s:%s from node %s", key, effect, builder.Definition.Name)
+
+	if key == "" {
+		glog.V(100).Infof("Failed to remove taint with an empty key from node %s", builder.Definition.Name)
+
+		builder.errorMsg = "error to remove taint with empty key from node"
+
+		return builder
+	}
+
+	var taints []corev1.Taint
+
+	for _, taint := range builder.Definition.Spec.Taints {
+		if taint.Key == key && taint.Effect == effect {
+			continue
+		}
+
+		taints = append(taints, taint)
+	}
+
+	builder.Definition.Spec.Taints = taints
+
+	return builder
+}
+
+// WaitForTaint waits for timeout duration or until the node has a taint with the given key and effect. This is useful
+// for taints added by controllers, such as the NoExecute taints applied to unreachable nodes.
+func (builder *Builder) WaitForTaint(key string, effect corev1.TaintEffect, timeout time.Duration) error {
+	return builder.waitForTaintPresence(key, effect, true, timeout)
+}
+
+// WaitForTaintRemoved waits for timeout duration or until the node no longer has a taint with the given key and
+// effect.
+func (builder *Builder) WaitForTaintRemoved(key string, effect corev1.TaintEffect, timeout time.Duration) error {
+	return builder.waitForTaintPresence(key, effect, false, timeout)
+}
+
 // ExternalIPv4Network returns nodes external ip address.
 func (builder *Builder) ExternalIPv4Network() (string, error) {
 	if valid, err := builder.validate(); !valid {
@@ -460,6 +542,41 @@ func (builder *Builder) validate() (bool, error) {
 	return true, nil
 }
 
+// waitForTaintPresence waits for timeout duration or until the presence of the taint with the given key and effect
+// matches the expected presence.
+func (builder *Builder) waitForTaintPresence(
+	key string, effect corev1.TaintEffect, present bool, timeout time.Duration) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Waiting for taint %s:%s presence on node %s to be %v",
+		key, effect, builder.Definition.Name, present)
+
+	if key == "" {
+		glog.V(100).Infof("The taint key is empty")
+
+		return fmt.Errorf("taint 'key' cannot be empty")
+	}
+
+	return wait.PollUntilContextTimeout(
+		context.TODO(), time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+			if !builder.Exists() {
+				return false, fmt.Errorf("node %s object does not exist", builder.Definition.Name)
+			}
+
+			taintToFind := &corev1.Taint{Key: key, Effect: effect}
+
+			for _, taint := range builder.Object.Spec.Taints {
+				if taint.MatchTaint(taintToFind) {
+					return present, nil
+				}
+			}
+
+			return !present, nil
+		})
+}
+
 // ensureDrainHelperIsSet ensures that drainHelper is always set.
 func (builder *Builder) ensureDrainHelperIsSet() {
 	if builder.drainHelper == nil {
@@ -21,6 +21,11 @@ const (
 	defaultExternalIPv6     = "fd00::/8"
 )
 
+var defaultNodeTaint = corev1.Taint{
+	Key:    "node.kubernetes.io/unreachable",
+	Effect: corev1.TaintEffectNoSchedule,
+}
+
 func TestNodePull(t *testing.T) {
 	testCases := []struct {
 		name                string
@@ -258,6 +263,93 @@ func TestNodeRemoveLabel(t *testing.T) {
 	}
 }
 
+func TestNodeWithTaint(t *testing.T) {
+	testCases := []struct {
+		taint         corev1.Taint
+		taintExists   bool
+		expectedError string
+	}{
+		{
+			taint:         defaultNodeTaint,
+			taintExists:   false,
+			expectedError: "",
+		},
+		{
+			taint:         corev1.Taint{Effect: corev1.TaintEffectNoSchedule},
+			taintExists:   false,
+			expectedError: "error to set taint with empty key to node",
+		},
+		{
+			taint:         corev1.Taint{Key: defaultNodeTaint.Key},
+			taintExists:   false,
+			expectedError: "error to set taint with empty effect to node",
+		},
+		{
+			taint:       defaultNodeTaint,
+			taintExists: true,
+			expectedError: fmt.Sprintf(
+				"cannot overwrite existing node taint: %s:%s", defaultNodeTaint.Key, defaultNodeTaint.Effect),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidNodeTestBuilder(clients.GetTestClients(clients.TestClientParams{}))
+
+		if testCase.taintExists {
+			testBuilder.Definition.Spec.Taints = []corev1.Taint{testCase.taint}
+		}
+
+		testBuilder = testBuilder.WithTaint(testCase.taint)
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+		if testCase.expectedError == "" {
+			assert.Equal(t, []corev1.Taint{testCase.taint}, testBuilder.Definition.Spec.Taints)
+		}
+	}
+}
+
+func TestNodeRemoveTaint(t *testing.T) {
+	otherTaint := corev1.Taint{Key: defaultNodeTaint.Key, Effect: corev1.TaintEffectNoExecute}
+
+	testCases := []struct {
+		key           string
+		expectedError string
+	}{
+		{
+			key:           defaultNodeTaint.Key,
+			expectedError: "",
+		},
+		{
+			key:           "",
+			expectedError: "error to remove taint with empty key from node",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testBuilder := buildValidNodeTestBuilder(clients.GetTestClients(clients.TestClientParams{}))
+		testBuilder.Definition.Spec.Taints = []corev1.Taint{defaultNodeTaint, otherTaint}
+
+		testBuilder = testBuilder.RemoveTaint(testCase.key, defaultNodeTaint.Effect)
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+		if testCase.expectedError == "" {
+			assert.Equal(t, []corev1.Taint{otherTaint}, testBuilder.Definition.Spec.Taints)
+		}
+	}
+}
+
+func TestNodeWaitForTaint(t *testing.T) {
+	testNodeWaitForTaintHelper(t, true, func(testBuilder *Builder, key string, timeout time.Duration) error {
+		return testBuilder.WaitForTaint(key, defaultNodeTaint.Effect, timeout)
+	})
+}
+
+func TestNodeWaitForTaintRemoved(t *testing.T) {
+	testNodeWaitForTaintHelper(t, false, func(testBuilder *Builder, key string, timeout time.Duration) error {
+		return testBuilder.WaitForTaintRemoved(key, defaultNodeTaint.Effect, timeout)
+	})
+}
+
 func TestNodeExternalIPv4Network(t *testing.T) {
 	testCases := []struct {
 		objectNil         bool
@@ -567,6 +659,65 @@ func testNodeWaitUntilConditionHelper(
 	}
 }
 
+func testNodeWaitForTaintHelper(
+	t *testing.T,
+	waitForPresence bool,
+	testedFunc func(testBuilder *Builder, key string, timeout time.Duration) error) {
+	t.Helper()
+
+	testCases := []struct {
+		exists        bool
+		hasTaint      bool
+		key           string
+		expectedError error
+	}{
+		{
+			exists:        true,
+			hasTaint:      waitForPresence,
+			key:           defaultNodeTaint.Key,
+			expectedError: nil,
+		},
+		{
+			exists:        true,
+			hasTaint:      !waitForPresence,
+			key:           defaultNodeTaint.Key,
+			expectedError: context.DeadlineExceeded,
+		},
+		{
+			exists:        false,
+			key:           defaultNodeTaint.Key,
+			expectedError: fmt.Errorf("node %s object does not exist", defaultNodeName),
+		},
+		{
+			exists:        true,
+			key:           "",
+			expectedError: fmt.Errorf("taint 'key' cannot be empty"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			node := buildDummyNode(defaultNodeName)
+
+			if testCase.hasTaint {
+				node.Spec.Taints = []corev1.Taint{defaultNodeTaint}
+			}
+
+			runtimeObjects = append(runtimeObjects, node)
+		}
+
+		testSettings := clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects: runtimeObjects,
+		})
+		testBuilder := buildValidNodeTestBuilder(testSettings)
+
+		err := testedFunc(testBuilder, testCase.key, time.Second)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
 // buildDummyNode returns a Node with the provided name.
 func buildDummyNode(name string) *corev1.Node {
 	return &corev1.Node{
@@ -1,5 +1,10 @@
 package nodes
 
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
 // ExternalNetworks contains external node ip4/ipv6 addresses.
 type ExternalNetworks struct {
 	IPv4 string `json:"ipv4,omitempty"`
@@ -7,3 +12,46 @@ type ExternalNetworks struct {
 }
 
 const ovnExternalAddresses = "k8s.ovn.org/node-primary-ifaddr"
+
+// ResourceUsage contains the summed requests of the non-terminated pods scheduled on a node and the node
+// allocatable resources. The pods resource in Requested is the number of pods counted.
+type ResourceUsage struct {
+	// Pods is the number of pods on the node that are not controlled by a DaemonSet.
+	Pods int
+	// DaemonSetPods is the number of pods on the node that are controlled by a DaemonSet.
+	DaemonSetPods int
+	// Requested is the sum of the effective requests of all pods on the node.
+	Requested corev1.ResourceList
+	// Allocatable is the node status.allocatable at the time the usage was computed.
+	Allocatable corev1.ResourceList
+}
+
+// Available returns the allocatable amount of the resource minus the requested amount. The result is negative if
+// the node is overcommitted and zero if the node does not report the resource.
+func (usage *ResourceUsage) Available(resourceName corev1.ResourceName) resource.Quantity {
+	if usage == nil {
+		return resource.Quantity{}
+	}
+
+	available := usage.Allocatable[resourceName].DeepCopy()
+	available.Sub(usage.Requested[resourceName])
+
+	return available
+}
+
+// IsSaturated returns true if the requests for the resource are equal to or greater than the allocatable amount,
+// meaning no more pods requesting the resource can be scheduled on the node.
+func (usage *ResourceUsage) IsSaturated(resourceName corev1.ResourceName) bool {
+	if usage == nil {
+		return false
+	}
+
+	available := usage.Available(resourceName)
+
+	return available.Sign() <= 0
+}
+
+// IsEmpty returns true if the only pods on the node are controlled by DaemonSets.
+func (usage *ResourceUsage) IsEmpty() bool {
+	return usage != nil && usage.Pods == 0
+}
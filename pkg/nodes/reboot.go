@@ -0,0 +1,366 @@
+package nodes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/bmc"
+	"github.com/stmcginnis/gofish/redfish"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// defaultRebootTimeout is used for the reboot waits when the context passed to Reboot has no deadline.
+	defaultRebootTimeout = 30 * time.Minute
+	// rebootPollInterval is how often the node and its pods are checked while waiting for a reboot.
+	rebootPollInterval = 5 * time.Second
+)
+
+// RebootMethod triggers the reboot of a node. It returns once the reboot has been requested and does not wait for
+// the node to go down or come back. Use RebootWithDebugPod, RebootWithBMCPowerCycle or RebootWithGracefulShutdown
+// to get a RebootMethod.
+type RebootMethod func(ctx context.Context, builder *Builder) error
+
+// RebootOptions controls what Reboot waits for once the node is back.
+type RebootOptions struct {
+	// WaitForPodsHealthy makes Reboot wait until all non-terminated pods on the node are running and ready.
+	WaitForPodsHealthy bool
+}
+
+// Reboot reboots the node using the provided method and waits until it has gone through a reboot. The boot ID is
+// recorded before the reboot and the node is only considered rebooted once it is Ready with a different boot ID.
+// The context deadline bounds all the waits, defaulting to 30 minutes when there is no deadline.
+func (builder *Builder) Reboot(ctx context.Context, method RebootMethod, options ...RebootOptions) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	if method == nil {
+		glog.V(100).Infof("The reboot method for node %s is nil", builder.Definition.Name)
+
+		return fmt.Errorf("node reboot 'method' cannot be nil")
+	}
+
+	passedOptions := RebootOptions{}
+
+	if len(options) == 1 {
+		passedOptions = options[0]
+	} else if len(options) > 1 {
+		glog.V(100).Infof("'options' parameter must be empty or single-valued")
+
+		return fmt.Errorf("error: more than one RebootOptions was passed")
+	}
+
+	if !builder.Exists() {
+		return fmt.Errorf("cannot reboot node %s because it does not exist", builder.Definition.Name)
+	}
+
+	bootID := builder.Object.Status.NodeInfo.BootID
+
+	glog.V(100).Infof("Rebooting node %s with boot ID %s", builder.Definition.Name, bootID)
+
+	err := method(ctx, builder)
+	if err != nil {
+		return fmt.Errorf("failed to trigger reboot of node %s: %w", builder.Definition.Name, err)
+	}
+
+	err = builder.waitUntilRebootStarted(ctx, bootID)
+	if err != nil {
+		return fmt.Errorf("node %s did not go down after triggering reboot: %w", builder.Definition.Name, err)
+	}
+
+	err = builder.WaitUntilReady(getRebootTimeout(ctx))
+	if err != nil {
+		return fmt.Errorf("node %s did not become Ready after reboot: %w", builder.Definition.Name, err)
+	}
+
+	if builder.Object.Status.NodeInfo.BootID == bootID {
+		return fmt.Errorf("node %s is Ready but its boot ID %s did not change", builder.Definition.Name, bootID)
+	}
+
+	glog.V(100).Infof("Node %s rebooted, boot ID changed from %s to %s",
+		builder.Definition.Name, bootID, builder.Object.Status.NodeInfo.BootID)
+
+	if passedOptions.WaitForPodsHealthy {
+		err = builder.waitUntilPodsHealthy(ctx)
+		if err != nil {
+			return fmt.Errorf("pods on node %s did not become healthy after reboot: %w", builder.Definition.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// RollingReboot reboots the provided nodes, with at most maxUnavailable nodes rebooting at the same time. The method
+// for each node is obtained from methodForNode, which allows per-node BMCs to be used. Once a reboot fails no new
+// reboots are started, the ones in progress are waited for and all errors are returned.
+func RollingReboot(
+	ctx context.Context,
+	nodes []*Builder,
+	maxUnavailable int,
+	methodForNode func(builder *Builder) RebootMethod,
+	options ...RebootOptions) error {
+	glog.V(100).Infof("Rebooting %d nodes with maxUnavailable %d", len(nodes), maxUnavailable)
+
+	if maxUnavailable < 1 {
+		glog.V(100).Infof("The maxUnavailable for the rolling reboot is less than 1")
+
+		return fmt.Errorf("rolling reboot 'maxUnavailable' must be at least 1")
+	}
+
+	if methodForNode == nil {
+		glog.V(100).Infof("The methodForNode for the rolling reboot is nil")
+
+		return fmt.Errorf("rolling reboot 'methodForNode' cannot be nil")
+	}
+
+	for _, node := range nodes {
+		if valid, err := node.validate(); !valid {
+			return err
+		}
+	}
+
+	var (
+		waitGroup sync.WaitGroup
+		mutex     sync.Mutex
+		errs      []error
+	)
+
+	slots := make(chan struct{}, maxUnavailable)
+
+	for _, node := range nodes {
+		slots <- struct{}{}
+
+		mutex.Lock()
+		failed := len(errs) > 0
+		mutex.Unlock()
+
+		if failed {
+			<-slots
+
+			glog.V(100).Infof("Skipping reboot of node %s because a previous reboot failed", node.Definition.Name)
+
+			break
+		}
+
+		waitGroup.Add(1)
+
+		go func(node *Builder) {
+			defer func() {
+				<-slots
+				waitGroup.Done()
+			}()
+
+			err := node.Reboot(ctx, methodForNode(node), options...)
+			if err != nil {
+				mutex.Lock()
+				errs = append(errs, err)
+				mutex.Unlock()
+			}
+		}(node)
+	}
+
+	waitGroup.Wait()
+
+	return errors.Join(errs...)
+}
+
+// RebootWithDebugPod returns a RebootMethod that runs systemctl reboot on the host from a privileged pod scheduled on
+// the node. The pod is created in nsname using image, which must provide chroot. The pod is deleted once the reboot
+// has been requested.
+func RebootWithDebugPod(image, nsname string) RebootMethod {
+	return func(ctx context.Context, builder *Builder) error {
+		if image == "" {
+			return fmt.Errorf("debug pod 'image' cannot be empty")
+		}
+
+		if nsname == "" {
+			return fmt.Errorf("debug pod 'nsname' cannot be empty")
+		}
+
+		debugPod := buildRebootDebugPod(builder.Definition.Name, nsname, image)
+
+		glog.V(100).Infof("Creating debug pod %s in namespace %s to reboot node %s",
+			debugPod.Name, nsname, builder.Definition.Name)
+
+		_, err := builder.apiClient.CoreV1().Pods(nsname).Create(ctx, debugPod, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create debug pod %s: %w", debugPod.Name, err)
+		}
+
+		defer func() {
+			err := builder.apiClient.CoreV1().Pods(nsname).Delete(
+				context.TODO(), debugPod.Name, metav1.DeleteOptions{})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				glog.V(100).Infof("Failed to delete debug pod %s: %v", debugPod.Name, err)
+			}
+		}()
+
+		// Once the pod leaves the Pending phase the reboot command has been started.
+		return wait.PollUntilContextTimeout(
+			ctx, time.Second, getRebootTimeout(ctx), true, func(ctx context.Context) (bool, error) {
+				pod, err := builder.apiClient.CoreV1().Pods(nsname).Get(ctx, debugPod.Name, metav1.GetOptions{})
+				if err != nil {
+					glog.V(100).Infof("Failed to get debug pod %s: %v", debugPod.Name, err)
+
+					return false, nil
+				}
+
+				if pod.Status.Phase == corev1.PodFailed {
+					return false, fmt.Errorf("debug pod %s failed: %s", debugPod.Name, pod.Status.Message)
+				}
+
+				return pod.Status.Phase != corev1.PodPending, nil
+			})
+	}
+}
+
+// RebootWithBMCPowerCycle returns a RebootMethod that power cycles the node through its BMC.
+func RebootWithBMCPowerCycle(bmcClient *bmc.BMC) RebootMethod {
+	return func(ctx context.Context, builder *Builder) error {
+		if bmcClient == nil {
+			return fmt.Errorf("node reboot 'bmcClient' cannot be nil")
+		}
+
+		glog.V(100).Infof("Power cycling node %s through its BMC", builder.Definition.Name)
+
+		return bmcClient.SystemPowerCycle()
+	}
+}
+
+// RebootWithGracefulShutdown returns a RebootMethod that asks the BMC for a graceful shutdown of the node, waits
+// for the system to be powered off and then powers it back on.
+func RebootWithGracefulShutdown(bmcClient *bmc.BMC) RebootMethod {
+	return func(ctx context.Context, builder *Builder) error {
+		if bmcClient == nil {
+			return fmt.Errorf("node reboot 'bmcClient' cannot be nil")
+		}
+
+		glog.V(100).Infof("Gracefully shutting down node %s through its BMC", builder.Definition.Name)
+
+		err := bmcClient.SystemGracefulShutdown()
+		if err != nil {
+			return fmt.Errorf("failed to gracefully shut down system: %w", err)
+		}
+
+		err = bmcClient.WaitForSystemPowerState(redfish.OffPowerState, getRebootTimeout(ctx))
+		if err != nil {
+			return fmt.Errorf("system did not power off after graceful shutdown: %w", err)
+		}
+
+		return bmcClient.SystemPowerOn()
+	}
+}
+
+// waitUntilRebootStarted waits until the node either stops being Ready or reports a different boot ID. Checking the
+// boot ID covers nodes that reboot faster than the Ready condition is updated.
+func (builder *Builder) waitUntilRebootStarted(ctx context.Context, bootID string) error {
+	return wait.PollUntilContextTimeout(
+		ctx, time.Second, getRebootTimeout(ctx), true, func(ctx context.Context) (bool, error) {
+			if !builder.Exists() {
+				return false, fmt.Errorf("node %s object does not exist", builder.Definition.Name)
+			}
+
+			if builder.Object.Status.NodeInfo.BootID != bootID {
+				return true, nil
+			}
+
+			for _, condition := range builder.Object.Status.Conditions {
+				if condition.Type == corev1.NodeReady {
+					return condition.Status != corev1.ConditionTrue, nil
+				}
+			}
+
+			return false, nil
+		})
+}
+
+// waitUntilPodsHealthy waits until all non-terminated pods on the node are running and ready.
+func (builder *Builder) waitUntilPodsHealthy(ctx context.Context) error {
+	return wait.PollUntilContextTimeout(
+		ctx, rebootPollInterval, getRebootTimeout(ctx), true, func(ctx context.Context) (bool, error) {
+			pods, err := builder.ListPods()
+			if err != nil {
+				glog.V(100).Infof("Failed to list pods on node %s: %v", builder.Definition.Name, err)
+
+				return false, nil
+			}
+
+			for _, pod := range pods {
+				if !isPodHealthy(pod) {
+					glog.V(100).Infof("Pod %s in namespace %s on node %s is not healthy yet",
+						pod.Name, pod.Namespace, builder.Definition.Name)
+
+					return false, nil
+				}
+			}
+
+			return true, nil
+		})
+}
+
+// isPodHealthy returns true if the pod is terminated or is running with the Ready condition set to true.
+func isPodHealthy(pod corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return true
+	}
+
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// getRebootTimeout returns the time left until the context deadline or the default reboot timeout if there is none.
+func getRebootTimeout(ctx context.Context) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline)
+	}
+
+	return defaultRebootTimeout
+}
+
+// buildRebootDebugPod returns a privileged pod pinned to the node which runs systemctl reboot on the host.
+func buildRebootDebugPod(nodeName, nsname, image string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-reboot-debug", nodeName),
+			Namespace: nsname,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      nodeName,
+			HostPID:       true,
+			HostNetwork:   true,
+			RestartPolicy: corev1.RestartPolicyNever,
+			Tolerations:   []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:            "reboot",
+				Image:           image,
+				Command:         []string{"chroot", "/host", "systemctl", "reboot"},
+				SecurityContext: &corev1.SecurityContext{Privileged: ptr.To(true), RunAsUser: ptr.To[int64](0)},
+				VolumeMounts:    []corev1.VolumeMount{{Name: "host", MountPath: "/host"}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "host",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{Path: "/", Type: ptr.To(corev1.HostPathDirectory)},
+				},
+			}},
+		},
+	}
+}
@@ -0,0 +1,284 @@
+package nodes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const defaultBootID = "boot-1"
+
+func TestNodeReboot(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		method        RebootMethod
+		options       []RebootOptions
+		expectedError error
+	}{
+		{
+			exists:        true,
+			method:        changeBootIDRebootMethod,
+			expectedError: nil,
+		},
+		{
+			exists:        true,
+			method:        changeBootIDRebootMethod,
+			options:       []RebootOptions{{WaitForPodsHealthy: true}},
+			expectedError: nil,
+		},
+		{
+			exists:        true,
+			method:        nil,
+			expectedError: fmt.Errorf("node reboot 'method' cannot be nil"),
+		},
+		{
+			exists:        true,
+			method:        changeBootIDRebootMethod,
+			options:       []RebootOptions{{}, {}},
+			expectedError: fmt.Errorf("error: more than one RebootOptions was passed"),
+		},
+		{
+			exists:        false,
+			method:        changeBootIDRebootMethod,
+			expectedError: fmt.Errorf("cannot reboot node %s because it does not exist", defaultNodeName),
+		},
+		{
+			exists: true,
+			method: func(ctx context.Context, builder *Builder) error {
+				return fmt.Errorf("bmc unreachable")
+			},
+			expectedError: fmt.Errorf("failed to trigger reboot of node %s: %w",
+				defaultNodeName, fmt.Errorf("bmc unreachable")),
+		},
+		{
+			exists: true,
+			method: func(ctx context.Context, builder *Builder) error {
+				return nil
+			},
+			expectedError: fmt.Errorf("node %s did not go down after triggering reboot: %w",
+				defaultNodeName, context.DeadlineExceeded),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects,
+				buildDummyRebootNode(defaultNodeName), buildDummyHealthyPod("app-pod", defaultNodeName))
+		}
+
+		testSettings := clients.GetTestClients(clients.TestClientParams{K8sMockObjects: runtimeObjects})
+		testBuilder := buildValidNodeTestBuilder(testSettings)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+
+		err := testBuilder.Reboot(ctx, testCase.method, testCase.options...)
+
+		cancel()
+
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.NotEqual(t, defaultBootID, testBuilder.Object.Status.NodeInfo.BootID)
+		}
+	}
+}
+
+func TestRollingReboot(t *testing.T) {
+	testCases := []struct {
+		maxUnavailable int
+		methodForNode  func(builder *Builder) RebootMethod
+		expectedError  error
+	}{
+		{
+			maxUnavailable: 2,
+			methodForNode:  nil,
+			expectedError:  fmt.Errorf("rolling reboot 'methodForNode' cannot be nil"),
+		},
+		{
+			maxUnavailable: 0,
+			expectedError:  fmt.Errorf("rolling reboot 'maxUnavailable' must be at least 1"),
+		},
+		{
+			maxUnavailable: 2,
+			expectedError:  nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		nodeNames := []string{"node-1", "node-2", "node-3", "node-4"}
+
+		var runtimeObjects []runtime.Object
+		for _, nodeName := range nodeNames {
+			runtimeObjects = append(runtimeObjects, buildDummyRebootNode(nodeName))
+		}
+
+		testSettings := clients.GetTestClients(clients.TestClientParams{K8sMockObjects: runtimeObjects})
+
+		var testBuilders []*Builder
+		for _, nodeName := range nodeNames {
+			testBuilders = append(testBuilders, newNodeBuilder(testSettings, nodeName))
+		}
+
+		var (
+			mutex       sync.Mutex
+			inProgress  int
+			maxObserved int
+		)
+
+		methodForNode := testCase.methodForNode
+
+		if testCase.expectedError == nil {
+			methodForNode = func(builder *Builder) RebootMethod {
+				return func(ctx context.Context, builder *Builder) error {
+					mutex.Lock()
+					inProgress++
+					maxObserved = max(maxObserved, inProgress)
+					mutex.Unlock()
+
+					defer func() {
+						mutex.Lock()
+						inProgress--
+						mutex.Unlock()
+					}()
+
+					time.Sleep(100 * time.Millisecond)
+
+					return changeBootIDRebootMethod(ctx, builder)
+				}
+			}
+		}
+
+		err := RollingReboot(context.TODO(), testBuilders, testCase.maxUnavailable, methodForNode)
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, testCase.maxUnavailable, maxObserved)
+
+			for _, testBuilder := range testBuilders {
+				assert.NotEqual(t, defaultBootID, testBuilder.Object.Status.NodeInfo.BootID)
+			}
+		}
+	}
+}
+
+func TestRebootWithDebugPod(t *testing.T) {
+	testCases := []struct {
+		image         string
+		nsname        string
+		expectedError error
+	}{
+		{
+			image:         "registry.example.com/tools:latest",
+			nsname:        "default",
+			expectedError: nil,
+		},
+		{
+			image:         "",
+			nsname:        "default",
+			expectedError: fmt.Errorf("debug pod 'image' cannot be empty"),
+		},
+		{
+			image:         "registry.example.com/tools:latest",
+			nsname:        "",
+			expectedError: fmt.Errorf("debug pod 'nsname' cannot be empty"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var createdPod *corev1.Pod
+
+		fakeClient := k8sfake.NewSimpleClientset(buildDummyRebootNode(defaultNodeName))
+		fakeClient.PrependReactor("create", "pods",
+			func(action k8stesting.Action) (bool, runtime.Object, error) {
+				createAction, ok := action.(k8stesting.CreateAction)
+				if !ok {
+					return false, nil, nil
+				}
+
+				pod, ok := createAction.GetObject().(*corev1.Pod)
+				if !ok {
+					return false, nil, nil
+				}
+
+				// Simulate the kubelet starting the pod so the method does not wait for it.
+				pod.Status.Phase = corev1.PodRunning
+				createdPod = pod
+
+				return false, nil, nil
+			})
+
+		testBuilder := &Builder{apiClient: fakeClient, Definition: buildDummyNode(defaultNodeName)}
+
+		err := RebootWithDebugPod(testCase.image, testCase.nsname)(context.TODO(), testBuilder)
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.NotNil(t, createdPod)
+			assert.Equal(t, defaultNodeName, createdPod.Spec.NodeName)
+			assert.Equal(t, []string{"chroot", "/host", "systemctl", "reboot"}, createdPod.Spec.Containers[0].Command)
+
+			_, err = fakeClient.CoreV1().Pods(testCase.nsname).Get(
+				context.TODO(), createdPod.Name, metav1.GetOptions{})
+			assert.True(t, err != nil, "debug pod should be deleted once the reboot is requested")
+		}
+	}
+}
+
+func TestRebootWithBMCNil(t *testing.T) {
+	testBuilder := buildValidNodeTestBuilder(clients.GetTestClients(clients.TestClientParams{}))
+
+	err := RebootWithBMCPowerCycle(nil)(context.TODO(), testBuilder)
+	assert.Equal(t, fmt.Errorf("node reboot 'bmcClient' cannot be nil"), err)
+
+	err = RebootWithGracefulShutdown(nil)(context.TODO(), testBuilder)
+	assert.Equal(t, fmt.Errorf("node reboot 'bmcClient' cannot be nil"), err)
+}
+
+// changeBootIDRebootMethod simulates a fast reboot by giving the node a new boot ID while it stays Ready.
+func changeBootIDRebootMethod(ctx context.Context, builder *Builder) error {
+	node, err := builder.apiClient.CoreV1().Nodes().Get(ctx, builder.Definition.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	node.Status.NodeInfo.BootID = defaultBootID + "-rebooted"
+
+	_, err = builder.apiClient.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+
+	return err
+}
+
+// buildDummyRebootNode returns a Ready node with the default boot ID.
+func buildDummyRebootNode(name string) *corev1.Node {
+	node := buildDummyNodeWithCondition(name, corev1.NodeReady, corev1.ConditionTrue)
+	node.Status.NodeInfo.BootID = defaultBootID
+
+	return node
+}
+
+// buildDummyHealthyPod returns a running and ready pod scheduled on the node.
+func buildDummyHealthyPod(name, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test-ns",
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
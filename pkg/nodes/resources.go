@@ -0,0 +1,170 @@
+package nodes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// ListPods returns the pods scheduled on the node in all namespaces. The node is selected using the spec.nodeName
+// field selector, which is combined with the field selector in the provided options, if any.
+func (builder *Builder) ListPods(options ...metav1.ListOptions) ([]corev1.Pod, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	passedOptions := metav1.ListOptions{}
+
+	if len(options) == 1 {
+		passedOptions = options[0]
+	} else if len(options) > 1 {
+		glog.V(100).Infof("'options' parameter must be empty or single-valued")
+
+		return nil, fmt.Errorf("error: more than one ListOptions was passed")
+	}
+
+	nodeSelector := fields.OneTermEqualSelector("spec.nodeName", builder.Definition.Name)
+
+	if passedOptions.FieldSelector == "" {
+		passedOptions.FieldSelector = nodeSelector.String()
+	} else {
+		extraSelector, err := fields.ParseSelector(passedOptions.FieldSelector)
+		if err != nil {
+			glog.V(100).Infof("Failed to parse field selector %s: %v", passedOptions.FieldSelector, err)
+
+			return nil, fmt.Errorf("failed to parse field selector %s: %w", passedOptions.FieldSelector, err)
+		}
+
+		passedOptions.FieldSelector = fields.AndSelectors(nodeSelector, extraSelector).String()
+	}
+
+	glog.V(100).Infof("Listing pods on node %s with the options %v", builder.Definition.Name, passedOptions)
+
+	podList, err := builder.apiClient.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), passedOptions)
+	if err != nil {
+		glog.V(100).Infof("Failed to list pods on node %s due to %v", builder.Definition.Name, err)
+
+		return nil, err
+	}
+
+	var pods []corev1.Pod
+
+	// The field selector is applied by the API server, but the node name is checked again so that clients which
+	// do not honor field selectors still get the pods of this node only.
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName == builder.Definition.Name {
+			pods = append(pods, pod)
+		}
+	}
+
+	return pods, nil
+}
+
+// GetResourceUsage sums the resource requests of all non-terminated pods scheduled on the node and returns them
+// along with the node allocatable resources.
+func (builder *Builder) GetResourceUsage() (*ResourceUsage, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting resource usage of node %s", builder.Definition.Name)
+
+	if !builder.Exists() {
+		return nil, fmt.Errorf("node object %s does not exist", builder.Definition.Name)
+	}
+
+	pods, err := builder.ListPods()
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &ResourceUsage{
+		Requested:   corev1.ResourceList{},
+		Allocatable: builder.Object.Status.Allocatable.DeepCopy(),
+	}
+
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		if isDaemonSetPod(pod) {
+			usage.DaemonSetPods++
+		} else {
+			usage.Pods++
+		}
+
+		addResourceList(usage.Requested, getPodRequests(pod))
+	}
+
+	usage.Requested[corev1.ResourcePods] = *resource.NewQuantity(
+		int64(usage.Pods+usage.DaemonSetPods), resource.DecimalSI)
+
+	return usage, nil
+}
+
+// getPodRequests returns the effective requests of a pod the same way the scheduler computes them: the larger of
+// the sum of the app and sidecar containers and the largest regular init container, plus the pod overhead. A regular
+// init container runs alongside the sidecar containers declared before it, so their requests count towards its own.
+func getPodRequests(pod corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+
+	for _, container := range pod.Spec.Containers {
+		addResourceList(requests, container.Resources.Requests)
+	}
+
+	sidecarRequests := corev1.ResourceList{}
+	initRequests := corev1.ResourceList{}
+
+	for _, container := range pod.Spec.InitContainers {
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			addResourceList(requests, container.Resources.Requests)
+			addResourceList(sidecarRequests, container.Resources.Requests)
+
+			continue
+		}
+
+		containerRequests := corev1.ResourceList{}
+		addResourceList(containerRequests, container.Resources.Requests)
+		addResourceList(containerRequests, sidecarRequests)
+		maxResourceList(initRequests, containerRequests)
+	}
+
+	maxResourceList(requests, initRequests)
+	addResourceList(requests, pod.Spec.Overhead)
+
+	return requests
+}
+
+// maxResourceList sets each quantity in list to the larger of itself and the matching quantity in other in place.
+func maxResourceList(list, other corev1.ResourceList) {
+	for name, quantity := range other {
+		if current, ok := list[name]; !ok || quantity.Cmp(current) > 0 {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// addResourceList adds the quantities in toAdd to list in place.
+func addResourceList(list, toAdd corev1.ResourceList) {
+	for name, quantity := range toAdd {
+		if current, ok := list[name]; ok {
+			current.Add(quantity)
+			list[name] = current
+		} else {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// isDaemonSetPod returns true if the pod is controlled by a DaemonSet.
+func isDaemonSetPod(pod corev1.Pod) bool {
+	controllerRef := metav1.GetControllerOf(&pod)
+
+	return controllerRef != nil && controllerRef.Kind == "DaemonSet"
+}
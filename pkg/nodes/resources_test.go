@@ -0,0 +1,261 @@
+package nodes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+func TestNodeListPods(t *testing.T) {
+	testCases := []struct {
+		options       []metav1.ListOptions
+		expectedPods  []string
+		expectedError error
+	}{
+		{
+			options:       nil,
+			expectedPods:  []string{"app-pod", "ds-pod", "completed-pod"},
+			expectedError: nil,
+		},
+		{
+			options:       []metav1.ListOptions{{FieldSelector: "status.phase=Running"}},
+			expectedPods:  []string{"app-pod", "ds-pod", "completed-pod"},
+			expectedError: nil,
+		},
+		{
+			options:      []metav1.ListOptions{{FieldSelector: "status.phase"}},
+			expectedPods: nil,
+			expectedError: fmt.Errorf("failed to parse field selector status.phase: " +
+				"invalid selector: 'status.phase'; can't understand 'status.phase'"),
+		},
+		{
+			options:       []metav1.ListOptions{{}, {}},
+			expectedPods:  nil,
+			expectedError: fmt.Errorf("error: more than one ListOptions was passed"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := clients.GetTestClients(clients.TestClientParams{K8sMockObjects: buildDummyNodeWithPods()})
+		testBuilder := buildValidNodeTestBuilder(testSettings)
+
+		pods, err := testBuilder.ListPods(testCase.options...)
+		if testCase.expectedError != nil {
+			assert.EqualError(t, err, testCase.expectedError.Error())
+
+			continue
+		}
+
+		assert.Nil(t, err)
+
+		var podNames []string
+		for _, pod := range pods {
+			podNames = append(podNames, pod.Name)
+		}
+
+		assert.ElementsMatch(t, testCase.expectedPods, podNames)
+	}
+}
+
+func TestNodeGetResourceUsage(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		expectedError error
+	}{
+		{
+			exists:        true,
+			expectedError: nil,
+		},
+		{
+			exists:        false,
+			expectedError: fmt.Errorf("node object %s does not exist", defaultNodeName),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = buildDummyNodeWithPods()
+		}
+
+		testSettings := clients.GetTestClients(clients.TestClientParams{K8sMockObjects: runtimeObjects})
+		testBuilder := buildValidNodeTestBuilder(testSettings)
+
+		usage, err := testBuilder.GetResourceUsage()
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, 1, usage.Pods)
+			assert.Equal(t, 1, usage.DaemonSetPods)
+			assert.False(t, usage.IsEmpty())
+
+			// app-pod requests 1500m through its init container and ds-pod requests 500m. The completed pod and the
+			// pod on the other node are ignored.
+			cpu := usage.Requested[corev1.ResourceCPU]
+			assert.Equal(t, int64(2000), cpu.MilliValue())
+
+			memory := usage.Requested[corev1.ResourceMemory]
+			assert.Equal(t, int64(1152*1024*1024), memory.Value())
+
+			pods := usage.Requested[corev1.ResourcePods]
+			assert.Equal(t, int64(2), pods.Value())
+
+			assert.True(t, usage.IsSaturated(corev1.ResourceCPU))
+			assert.False(t, usage.IsSaturated(corev1.ResourceMemory))
+
+			availableMemory := usage.Available(corev1.ResourceMemory)
+			assert.Equal(t, int64(896*1024*1024), availableMemory.Value())
+		}
+	}
+}
+
+func TestGetPodRequests(t *testing.T) {
+	testCases := []struct {
+		initContainers []corev1.Container
+		overhead       corev1.ResourceList
+		expectedCPU    string
+	}{
+		{
+			initContainers: nil,
+			expectedCPU:    "500m",
+		},
+		{
+			initContainers: []corev1.Container{buildDummyInitContainer("init", "2", false)},
+			expectedCPU:    "2",
+		},
+		{
+			initContainers: []corev1.Container{
+				buildDummyInitContainer("sidecar", "1", true), buildDummyInitContainer("init", "2", false),
+			},
+			expectedCPU: "3",
+		},
+		{
+			initContainers: []corev1.Container{
+				buildDummyInitContainer("init", "2", false), buildDummyInitContainer("sidecar", "1", true),
+			},
+			expectedCPU: "2",
+		},
+		{
+			initContainers: []corev1.Container{buildDummyInitContainer("sidecar", "1", true)},
+			overhead:       corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+			expectedCPU:    "1750m",
+		},
+	}
+
+	for _, testCase := range testCases {
+		pod := buildDummyPodOnNode("test-pod", defaultNodeName, "500m", "512Mi", corev1.PodRunning)
+		pod.Spec.InitContainers = testCase.initContainers
+		pod.Spec.Overhead = testCase.overhead
+
+		expectedCPU := resource.MustParse(testCase.expectedCPU)
+		cpu := getPodRequests(*pod)[corev1.ResourceCPU]
+		assert.Equal(t, expectedCPU.MilliValue(), cpu.MilliValue())
+	}
+}
+
+func TestResourceUsageIsEmpty(t *testing.T) {
+	testCases := []struct {
+		usage          *ResourceUsage
+		expectedResult bool
+	}{
+		{
+			usage:          &ResourceUsage{Pods: 0, DaemonSetPods: 3},
+			expectedResult: true,
+		},
+		{
+			usage:          &ResourceUsage{Pods: 1, DaemonSetPods: 3},
+			expectedResult: false,
+		},
+		{
+			usage:          nil,
+			expectedResult: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expectedResult, testCase.usage.IsEmpty())
+	}
+}
+
+// buildDummyNodeWithPods returns a node with allocatable resources along with pods scheduled on it and on another
+// node.
+func buildDummyNodeWithPods() []runtime.Object {
+	node := buildDummyNode(defaultNodeName)
+	node.Status.Allocatable = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("2Gi"),
+		corev1.ResourcePods:   resource.MustParse("110"),
+	}
+
+	appPod := buildDummyPodOnNode("app-pod", defaultNodeName, "500m", "512Mi", corev1.PodRunning)
+	appPod.Spec.InitContainers = []corev1.Container{
+		{
+			Name: "init",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1500m"),
+			}},
+		},
+	}
+
+	dsPod := buildDummyPodOnNode("ds-pod", defaultNodeName, "500m", "640Mi", corev1.PodRunning)
+	dsPod.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "apps/v1",
+		Kind:       "DaemonSet",
+		Name:       "test-ds",
+		Controller: ptr.To(true),
+	}}
+
+	return []runtime.Object{
+		node,
+		appPod,
+		dsPod,
+		buildDummyPodOnNode("completed-pod", defaultNodeName, "4", "4Gi", corev1.PodSucceeded),
+		buildDummyPodOnNode("other-pod", "other-node", "4", "4Gi", corev1.PodRunning),
+	}
+}
+
+// buildDummyInitContainer returns an init container requesting the CPU, which keeps running as a sidecar if sidecar
+// is true.
+func buildDummyInitContainer(name, cpu string, sidecar bool) corev1.Container {
+	container := corev1.Container{
+		Name: name,
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse(cpu),
+		}},
+	}
+
+	if sidecar {
+		container.RestartPolicy = ptr.To(corev1.ContainerRestartPolicyAlways)
+	}
+
+	return container
+}
+
+// buildDummyPodOnNode returns a pod with a single container with the provided requests scheduled on the node.
+func buildDummyPodOnNode(name, nodeName, cpu, memory string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test-ns",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{{
+				Name: "test",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				}},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
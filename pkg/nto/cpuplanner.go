@@ -0,0 +1,510 @@
+package nto //nolint:misspell
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/pod"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/cpuset"
+	goclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// lscpuColumns are the columns requested from lscpu, in the order they are parsed.
+	lscpuColumns = "CPU,CORE,SOCKET,NODE,ONLINE"
+	// nrtNUMAZoneType is the type of the NodeResourceTopology zones that describe NUMA nodes.
+	nrtNUMAZoneType = "Node"
+	// nrtNUMAZonePrefix is the prefix of the names of NodeResourceTopology zones that describe NUMA nodes.
+	nrtNUMAZonePrefix = "node-"
+)
+
+// nodeResourceTopologyGVK is the GroupVersionKind of the NodeResourceTopology objects exported by the
+// NUMA Resources Operator. The API types are not vendored, so the objects are read as unstructured.
+var nodeResourceTopologyGVK = schema.GroupVersionKind{
+	Group:   "topology.node.k8s.io",
+	Version: "v1alpha2",
+	Kind:    "NodeResourceTopology",
+}
+
+// CPUInfo describes a logical CPU of a node.
+type CPUInfo struct {
+	// ID is the logical CPU number used in cpusets.
+	ID int
+	// Core is the physical core of the CPU. CPUs with the same Socket and Core are thread siblings.
+	Core int
+	// Socket is the physical package of the CPU.
+	Socket int
+	// NUMANode is the NUMA node of the CPU.
+	NUMANode int
+	// Online is true if the CPU is online.
+	Online bool
+}
+
+// NUMAZone describes the CPU capacity of a NUMA node.
+type NUMAZone struct {
+	// ID is the NUMA node number.
+	ID int
+	// CPUs is the number of online CPUs in the NUMA node.
+	CPUs int
+}
+
+// CPUTopology describes the CPUs of a node. When read from lscpu, every logical CPU is known. When read from
+// NodeResourceTopology, only the CPU capacity of each NUMA zone is known, so CPUs is empty.
+type CPUTopology struct {
+	// NodeName is the name of the node the topology was read from.
+	NodeName string
+	// CPUs contains every logical CPU of the node, sorted by ID.
+	CPUs []CPUInfo
+	// NUMAZones contains the CPU capacity of each NUMA node, sorted by ID.
+	NUMAZones []NUMAZone
+}
+
+// CPUSetPlan is a proposed split of the online CPUs of a node into reserved and isolated sets.
+type CPUSetPlan struct {
+	// Reserved contains the CPUs for housekeeping and system daemons.
+	Reserved cpuset.CPUSet
+	// Isolated contains the CPUs for latency sensitive workloads.
+	Isolated cpuset.CPUSet
+}
+
+// physicalCore groups the online thread siblings of a physical core.
+type physicalCore struct {
+	socket   int
+	core     int
+	numaNode int
+	cpus     cpuset.CPUSet
+}
+
+// NewCPUTopologyFromLscpu parses the output of lscpu --all --parse=CPU,CORE,SOCKET,NODE,ONLINE into the CPU topology
+// of the node.
+func NewCPUTopologyFromLscpu(nodeName, output string) (*CPUTopology, error) {
+	glog.V(100).Infof("Parsing lscpu output of node %s", nodeName)
+
+	topology := &CPUTopology{NodeName: nodeName}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		cpuInfo, err := parseLscpuLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse lscpu output of node %s: %w", nodeName, err)
+		}
+
+		topology.CPUs = append(topology.CPUs, cpuInfo)
+	}
+
+	if len(topology.CPUs) == 0 {
+		return nil, fmt.Errorf("lscpu output of node %s contains no CPUs", nodeName)
+	}
+
+	slices.SortFunc(topology.CPUs, func(first, second CPUInfo) int {
+		return first.ID - second.ID
+	})
+
+	zoneCPUs := map[int]int{}
+
+	for _, cpuInfo := range topology.CPUs {
+		if cpuInfo.Online {
+			zoneCPUs[cpuInfo.NUMANode]++
+		}
+	}
+
+	for zoneID, cpus := range zoneCPUs {
+		topology.NUMAZones = append(topology.NUMAZones, NUMAZone{ID: zoneID, CPUs: cpus})
+	}
+
+	sortNUMAZones(topology.NUMAZones)
+
+	return topology, nil
+}
+
+// GetCPUTopologyFromLscpu reads the CPU topology of the node by running lscpu in a short-lived pod on the node. The
+// image must provide lscpu. The pod is created in the nsname namespace and removed once the output is read.
+func GetCPUTopologyFromLscpu(
+	apiClient *clients.Settings, nodeName, nsname, image string, timeout time.Duration) (*CPUTopology, error) {
+	glog.V(100).Infof("Reading CPU topology of node %s with lscpu using image %s in namespace %s",
+		nodeName, image, nsname)
+
+	if nodeName == "" {
+		glog.V(100).Infof("The nodeName is empty")
+
+		return nil, fmt.Errorf("cpu topology 'nodeName' cannot be empty")
+	}
+
+	podBuilder := pod.NewBuilder(apiClient, "cpu-topology-"+nodeName, nsname, image).
+		DefineOnNode(nodeName).
+		RedefineDefaultCMD([]string{"lscpu", "--all", "--parse=" + lscpuColumns}).
+		WithRestartPolicy(corev1.RestartPolicyNever)
+
+	podBuilder, err := podBuilder.Create()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lscpu pod on node %s: %w", nodeName, err)
+	}
+
+	defer func() {
+		_, err := podBuilder.DeleteImmediate()
+		if err != nil {
+			glog.V(100).Infof("Failed to delete lscpu pod on node %s: %v", nodeName, err)
+		}
+	}()
+
+	err = podBuilder.WaitUntilInStatus(corev1.PodSucceeded, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("lscpu pod on node %s did not succeed: %w", nodeName, err)
+	}
+
+	output, err := podBuilder.GetFullLog("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lscpu output of node %s: %w", nodeName, err)
+	}
+
+	return NewCPUTopologyFromLscpu(nodeName, output)
+}
+
+// GetCPUTopologyFromNRT reads the CPU capacity of each NUMA zone of the node from the NodeResourceTopology object
+// exported by the NUMA Resources Operator. NodeResourceTopology does not report CPU IDs or thread siblings, so the
+// returned topology can be used to validate CPU sets but not to propose them.
+func GetCPUTopologyFromNRT(apiClient *clients.Settings, nodeName string) (*CPUTopology, error) {
+	glog.V(100).Infof("Reading CPU topology of node %s from NodeResourceTopology", nodeName)
+
+	if apiClient == nil {
+		glog.V(100).Infof("The apiClient cannot be nil")
+
+		return nil, fmt.Errorf("cpu topology 'apiClient' cannot be nil")
+	}
+
+	if nodeName == "" {
+		glog.V(100).Infof("The nodeName is empty")
+
+		return nil, fmt.Errorf("cpu topology 'nodeName' cannot be empty")
+	}
+
+	nrt := &unstructured.Unstructured{}
+	nrt.SetGroupVersionKind(nodeResourceTopologyGVK)
+
+	err := apiClient.Client.Get(context.TODO(), goclient.ObjectKey{Name: nodeName}, nrt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NodeResourceTopology of node %s: %w", nodeName, err)
+	}
+
+	zones, _, err := unstructured.NestedSlice(nrt.Object, "zones")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zones of NodeResourceTopology %s: %w", nodeName, err)
+	}
+
+	topology := &CPUTopology{NodeName: nodeName}
+
+	for _, zone := range zones {
+		zoneMap, ok := zone.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		numaZone, isNUMAZone, err := parseNRTZone(zoneMap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse NodeResourceTopology %s: %w", nodeName, err)
+		}
+
+		if isNUMAZone {
+			topology.NUMAZones = append(topology.NUMAZones, numaZone)
+		}
+	}
+
+	if len(topology.NUMAZones) == 0 {
+		return nil, fmt.Errorf("NodeResourceTopology %s contains no NUMA zones", nodeName)
+	}
+
+	sortNUMAZones(topology.NUMAZones)
+
+	return topology, nil
+}
+
+// OnlineCPUs returns the online CPUs of the node. An error is returned if only NUMA zone capacities are known, since
+// NodeResourceTopology does not report CPU IDs.
+func (topology *CPUTopology) OnlineCPUs() (cpuset.CPUSet, error) {
+	if len(topology.CPUs) == 0 {
+		return cpuset.New(), fmt.Errorf("cpu topology of node %s has no per-CPU information, read it with lscpu to "+
+			"get the online CPUs", topology.NodeName)
+	}
+
+	var cpus []int
+
+	for _, cpuInfo := range topology.CPUs {
+		if cpuInfo.Online {
+			cpus = append(cpus, cpuInfo.ID)
+		}
+	}
+
+	return cpuset.New(cpus...), nil
+}
+
+// ProposeCPUSets splits the online CPUs of the node into reservedCount reserved CPUs and isolated CPUs. Reserved CPUs
+// are taken as whole physical cores so that thread siblings are never split, starting from the lowest NUMA node so
+// that housekeeping stays on as few NUMA nodes as possible. reservedCount must be a multiple of the threads per core.
+func (topology *CPUTopology) ProposeCPUSets(reservedCount int) (*CPUSetPlan, error) {
+	glog.V(100).Infof("Proposing %d reserved CPUs for node %s", reservedCount, topology.NodeName)
+
+	if len(topology.CPUs) == 0 {
+		return nil, fmt.Errorf("cpu topology of node %s has no per-CPU information, read it with lscpu to propose "+
+			"cpu sets", topology.NodeName)
+	}
+
+	onlineCPUs, err := topology.OnlineCPUs()
+	if err != nil {
+		return nil, err
+	}
+
+	if reservedCount <= 0 || reservedCount >= onlineCPUs.Size() {
+		return nil, fmt.Errorf("'reservedCount' must be between 1 and %d for node %s",
+			onlineCPUs.Size()-1, topology.NodeName)
+	}
+
+	cores := topology.getPhysicalCores()
+	threadsPerCore := 0
+
+	for _, core := range cores {
+		threadsPerCore = max(threadsPerCore, core.cpus.Size())
+	}
+
+	if reservedCount%threadsPerCore != 0 {
+		return nil, fmt.Errorf("'reservedCount' %d must be a multiple of the %d threads per core of node %s "+
+			"to keep thread siblings together", reservedCount, threadsPerCore, topology.NodeName)
+	}
+
+	reserved := cpuset.New()
+
+	// Cores with offline siblings are left isolated, so that reserved only contains whole cores.
+	for _, core := range cores {
+		if reserved.Size() == reservedCount {
+			break
+		}
+
+		if core.cpus.Size() == threadsPerCore {
+			reserved = reserved.Union(core.cpus)
+		}
+	}
+
+	if reserved.Size() != reservedCount {
+		return nil, fmt.Errorf("node %s does not have enough whole cores to reserve %d CPUs",
+			topology.NodeName, reservedCount)
+	}
+
+	return &CPUSetPlan{Reserved: reserved, Isolated: onlineCPUs.Difference(reserved)}, nil
+}
+
+// ValidateCPUSets checks the reserved and isolated cpusets against the topology. The sets must not be empty or
+// overlap. When per-CPU information is known, they must only contain online CPUs of the node and must not split the
+// thread siblings of a physical core between them. When only NUMA zone capacities are known, they must not contain
+// more CPUs than the node has.
+func (topology *CPUTopology) ValidateCPUSets(reserved, isolated string) error {
+	glog.V(100).Infof("Validating reserved cpuset %q and isolated cpuset %q against node %s",
+		reserved, isolated, topology.NodeName)
+
+	reservedSet, err := cpuset.Parse(reserved)
+	if err != nil {
+		return fmt.Errorf("failed to parse reserved cpuset %q: %w", reserved, err)
+	}
+
+	isolatedSet, err := cpuset.Parse(isolated)
+	if err != nil {
+		return fmt.Errorf("failed to parse isolated cpuset %q: %w", isolated, err)
+	}
+
+	var problems []string
+
+	if reservedSet.IsEmpty() {
+		problems = append(problems, "reserved cpuset is empty")
+	}
+
+	if overlap := reservedSet.Intersection(isolatedSet); !overlap.IsEmpty() {
+		problems = append(problems, fmt.Sprintf("CPUs %s are both reserved and isolated", overlap))
+	}
+
+	if len(topology.CPUs) == 0 {
+		problems = append(problems, topology.getCapacityProblems(reservedSet.Union(isolatedSet))...)
+	} else {
+		problems = append(problems, topology.getCPUProblems(reservedSet, isolatedSet)...)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid cpusets for node %s: %s", topology.NodeName, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// getCPUProblems returns the CPUs of the reserved and isolated sets that are not online and the physical cores whose
+// thread siblings are split between the sets.
+func (topology *CPUTopology) getCPUProblems(reservedSet, isolatedSet cpuset.CPUSet) []string {
+	var problems []string
+
+	onlineCPUs, _ := topology.OnlineCPUs()
+
+	if notOnline := reservedSet.Union(isolatedSet).Difference(onlineCPUs); !notOnline.IsEmpty() {
+		problems = append(problems, fmt.Sprintf("CPUs %s are offline or do not exist", notOnline))
+	}
+
+	for _, core := range topology.getPhysicalCores() {
+		if !core.cpus.Intersection(reservedSet).IsEmpty() && !core.cpus.Intersection(isolatedSet).IsEmpty() {
+			problems = append(problems, fmt.Sprintf("thread siblings %s of core %d on socket %d are split between "+
+				"reserved and isolated", core.cpus, core.core, core.socket))
+		}
+	}
+
+	return problems
+}
+
+// getCapacityProblems returns a problem if the CPU sets contain more CPUs than the NUMA zones of the node. Without
+// per-CPU information the IDs of the CPUs cannot be checked, so only their number is compared to the capacity.
+func (topology *CPUTopology) getCapacityProblems(cpus cpuset.CPUSet) []string {
+	capacity := 0
+
+	for _, zone := range topology.NUMAZones {
+		capacity += zone.CPUs
+	}
+
+	if cpus.Size() > capacity {
+		return []string{fmt.Sprintf("cpusets contain %d CPUs but the node only has %d", cpus.Size(), capacity)}
+	}
+
+	return nil
+}
+
+// getPhysicalCores returns the physical cores with at least one online CPU, sorted by NUMA node, socket and core.
+func (topology *CPUTopology) getPhysicalCores() []physicalCore {
+	coreIndexes := map[[2]int]int{}
+
+	var cores []physicalCore
+
+	for _, cpuInfo := range topology.CPUs {
+		if !cpuInfo.Online {
+			continue
+		}
+
+		key := [2]int{cpuInfo.Socket, cpuInfo.Core}
+
+		index, ok := coreIndexes[key]
+		if !ok {
+			index = len(cores)
+			coreIndexes[key] = index
+			cores = append(cores, physicalCore{
+				socket: cpuInfo.Socket, core: cpuInfo.Core, numaNode: cpuInfo.NUMANode, cpus: cpuset.New(),
+			})
+		}
+
+		cores[index].cpus = cores[index].cpus.Union(cpuset.New(cpuInfo.ID))
+	}
+
+	slices.SortStableFunc(cores, func(first, second physicalCore) int {
+		if first.numaNode != second.numaNode {
+			return first.numaNode - second.numaNode
+		}
+
+		if first.socket != second.socket {
+			return first.socket - second.socket
+		}
+
+		return first.core - second.core
+	})
+
+	return cores
+}
+
+// parseLscpuLine parses a line of lscpu parsable output with the lscpuColumns columns. Offline CPUs may have empty
+// core, socket and node columns, which are parsed as -1.
+func parseLscpuLine(line string) (CPUInfo, error) {
+	fields := strings.Split(line, ",")
+	if len(fields) != len(strings.Split(lscpuColumns, ",")) {
+		return CPUInfo{}, fmt.Errorf("line %q does not have the %s columns", line, lscpuColumns)
+	}
+
+	var values [4]int
+
+	for index := range values {
+		if fields[index] == "" || fields[index] == "-" {
+			values[index] = -1
+
+			continue
+		}
+
+		value, err := strconv.Atoi(fields[index])
+		if err != nil {
+			return CPUInfo{}, fmt.Errorf("line %q has invalid number %q: %w", line, fields[index], err)
+		}
+
+		values[index] = value
+	}
+
+	if values[0] < 0 {
+		return CPUInfo{}, fmt.Errorf("line %q has no CPU number", line)
+	}
+
+	return CPUInfo{
+		ID:       values[0],
+		Core:     values[1],
+		Socket:   values[2],
+		NUMANode: max(values[3], 0),
+		Online:   strings.EqualFold(fields[4], "Y") || strings.EqualFold(fields[4], "yes"),
+	}, nil
+}
+
+// parseNRTZone returns the NUMA zone described by the NodeResourceTopology zone. The second return value is false if
+// the zone does not describe a NUMA node.
+func parseNRTZone(zone map[string]any) (NUMAZone, bool, error) {
+	zoneType, _, _ := unstructured.NestedString(zone, "type")
+	zoneName, _, _ := unstructured.NestedString(zone, "name")
+
+	if zoneType != nrtNUMAZoneType || !strings.HasPrefix(zoneName, nrtNUMAZonePrefix) {
+		return NUMAZone{}, false, nil
+	}
+
+	zoneID, err := strconv.Atoi(strings.TrimPrefix(zoneName, nrtNUMAZonePrefix))
+	if err != nil {
+		return NUMAZone{}, false, fmt.Errorf("zone %s has an invalid NUMA node number: %w", zoneName, err)
+	}
+
+	resources, _, _ := unstructured.NestedSlice(zone, "resources")
+
+	for _, zoneResource := range resources {
+		resourceMap, ok := zoneResource.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(resourceMap, "name")
+		if name != string(corev1.ResourceCPU) {
+			continue
+		}
+
+		capacity, _, _ := unstructured.NestedString(resourceMap, "capacity")
+
+		quantity, err := resource.ParseQuantity(capacity)
+		if err != nil {
+			return NUMAZone{}, false, fmt.Errorf("zone %s has an invalid cpu capacity %q: %w", zoneName, capacity, err)
+		}
+
+		return NUMAZone{ID: zoneID, CPUs: int(quantity.Value())}, true, nil
+	}
+
+	return NUMAZone{ID: zoneID}, true, nil
+}
+
+// sortNUMAZones sorts the NUMA zones by ID.
+func sortNUMAZones(zones []NUMAZone) {
+	slices.SortFunc(zones, func(first, second NUMAZone) int {
+		return first.ID - second.ID
+	})
+}
@@ -0,0 +1,372 @@
+package nto //nolint:misspell
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/cpuset"
+)
+
+const defaultTopologyNodeName = "worker-0"
+
+func TestNewCPUTopologyFromLscpu(t *testing.T) {
+	testCases := []struct {
+		output        string
+		expectedCPUs  []CPUInfo
+		expectedZones []NUMAZone
+		expectedError error
+	}{
+		{
+			output: "# The following is the parsable format\n# CPU,Core,Socket,Node,Online\n" +
+				"1,1,0,0,Y\n0,0,0,0,Y\n2,0,0,,Y\n3,,,,N\n",
+			expectedCPUs: []CPUInfo{
+				{ID: 0, Core: 0, Socket: 0, NUMANode: 0, Online: true},
+				{ID: 1, Core: 1, Socket: 0, NUMANode: 0, Online: true},
+				{ID: 2, Core: 0, Socket: 0, NUMANode: 0, Online: true},
+				{ID: 3, Core: -1, Socket: -1, NUMANode: 0, Online: false},
+			},
+			expectedZones: []NUMAZone{{ID: 0, CPUs: 3}},
+			expectedError: nil,
+		},
+		{
+			output:        "# CPU,Core,Socket,Node,Online\n",
+			expectedError: fmt.Errorf("lscpu output of node %s contains no CPUs", defaultTopologyNodeName),
+		},
+		{
+			output: "0,0,0,Y\n",
+			expectedError: fmt.Errorf("failed to parse lscpu output of node %s: "+
+				"line \"0,0,0,Y\" does not have the %s columns", defaultTopologyNodeName, lscpuColumns),
+		},
+		{
+			output: "0,a,0,0,Y\n",
+			expectedError: fmt.Errorf("failed to parse lscpu output of node %s: "+
+				"line \"0,a,0,0,Y\" has invalid number \"a\": strconv.Atoi: parsing \"a\": invalid syntax",
+				defaultTopologyNodeName),
+		},
+	}
+
+	for _, testCase := range testCases {
+		topology, err := NewCPUTopologyFromLscpu(defaultTopologyNodeName, testCase.output)
+
+		if testCase.expectedError != nil {
+			assert.EqualError(t, err, testCase.expectedError.Error())
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedCPUs, topology.CPUs)
+		assert.Equal(t, testCase.expectedZones, topology.NUMAZones)
+
+		onlineCPUs, err := topology.OnlineCPUs()
+		assert.Nil(t, err)
+		assert.Equal(t, "0-2", onlineCPUs.String())
+	}
+}
+
+func TestGetCPUTopologyFromNRT(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		nodeName      string
+		expectedZones []NUMAZone
+		expectedError error
+	}{
+		{
+			exists:        true,
+			nodeName:      defaultTopologyNodeName,
+			expectedZones: []NUMAZone{{ID: 0, CPUs: 28}, {ID: 1, CPUs: 28}},
+			expectedError: nil,
+		},
+		{
+			exists:        true,
+			nodeName:      "",
+			expectedError: fmt.Errorf("cpu topology 'nodeName' cannot be empty"),
+		},
+		{
+			exists:   false,
+			nodeName: defaultTopologyNodeName,
+			expectedError: fmt.Errorf("failed to get NodeResourceTopology of node %s: "+
+				"noderesourcetopologies.topology.node.k8s.io \"%s\" not found",
+				defaultTopologyNodeName, defaultTopologyNodeName),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyNodeResourceTopology())
+		}
+
+		testSettings := clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects:  runtimeObjects,
+			SchemeAttachers: paoTestSchemes,
+		})
+
+		topology, err := GetCPUTopologyFromNRT(testSettings, testCase.nodeName)
+
+		if testCase.expectedError != nil {
+			assert.EqualError(t, err, testCase.expectedError.Error())
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Empty(t, topology.CPUs)
+		assert.Equal(t, testCase.expectedZones, topology.NUMAZones)
+
+		_, err = topology.OnlineCPUs()
+		assert.EqualError(t, err, fmt.Sprintf("cpu topology of node %s has no per-CPU information, read it with "+
+			"lscpu to get the online CPUs", testCase.nodeName))
+	}
+}
+
+func TestCPUTopologyProposeCPUSets(t *testing.T) {
+	testCases := []struct {
+		topology         *CPUTopology
+		reservedCount    int
+		expectedReserved string
+		expectedIsolated string
+		expectedError    error
+	}{
+		{
+			topology:         buildDummyCPUTopology(t, ""),
+			reservedCount:    4,
+			expectedReserved: "0-1,28-29",
+			expectedIsolated: "2-27,30-55",
+			expectedError:    nil,
+		},
+		{
+			topology:         buildDummyCPUTopology(t, "29"),
+			reservedCount:    4,
+			expectedReserved: "0,2,28,30",
+			expectedIsolated: "1,3-27,31-55",
+			expectedError:    nil,
+		},
+		{
+			topology:      buildDummyCPUTopology(t, ""),
+			reservedCount: 3,
+			expectedError: fmt.Errorf("'reservedCount' 3 must be a multiple of the 2 threads per core of node %s "+
+				"to keep thread siblings together", defaultTopologyNodeName),
+		},
+		{
+			topology:      buildDummyCPUTopology(t, ""),
+			reservedCount: 56,
+			expectedError: fmt.Errorf("'reservedCount' must be between 1 and 55 for node %s", defaultTopologyNodeName),
+		},
+		{
+			topology:      &CPUTopology{NodeName: defaultTopologyNodeName, NUMAZones: []NUMAZone{{ID: 0, CPUs: 8}}},
+			reservedCount: 2,
+			expectedError: fmt.Errorf("cpu topology of node %s has no per-CPU information, read it with lscpu to "+
+				"propose cpu sets", defaultTopologyNodeName),
+		},
+	}
+
+	for _, testCase := range testCases {
+		plan, err := testCase.topology.ProposeCPUSets(testCase.reservedCount)
+
+		if testCase.expectedError != nil {
+			assert.EqualError(t, err, testCase.expectedError.Error())
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedReserved, plan.Reserved.String())
+		assert.Equal(t, testCase.expectedIsolated, plan.Isolated.String())
+		assert.Nil(t, testCase.topology.ValidateCPUSets(plan.Reserved.String(), plan.Isolated.String()))
+	}
+}
+
+func TestCPUTopologyValidateCPUSets(t *testing.T) {
+	testCases := []struct {
+		topology      *CPUTopology
+		reserved      string
+		isolated      string
+		expectedError string
+	}{
+		{
+			topology: buildDummyCPUTopology(t, ""),
+			reserved: defaultReservedCPU,
+			isolated: defaultIsolatedCPU,
+		},
+		{
+			topology: buildDummyCPUTopology(t, "55"),
+			reserved: "0-1,28-29",
+			isolated: "1-27,30-56",
+			expectedError: "invalid cpusets for node worker-0: CPUs 1 are both reserved and isolated; " +
+				"CPUs 55-56 are offline or do not exist",
+		},
+		{
+			topology: buildDummyCPUTopology(t, ""),
+			reserved: "0-1",
+			isolated: "2-55",
+			expectedError: "invalid cpusets for node worker-0: " +
+				"thread siblings 0,28 of core 0 on socket 0 are split between reserved and isolated; " +
+				"thread siblings 1,29 of core 1 on socket 0 are split between reserved and isolated",
+		},
+		{
+			topology:      buildDummyCPUTopology(t, ""),
+			reserved:      "",
+			isolated:      "0-55",
+			expectedError: "invalid cpusets for node worker-0: reserved cpuset is empty",
+		},
+		{
+			topology:      buildDummyCPUTopology(t, ""),
+			reserved:      "0-a",
+			isolated:      "2-55",
+			expectedError: "failed to parse reserved cpuset \"0-a\"",
+		},
+		{
+			topology: &CPUTopology{NodeName: defaultTopologyNodeName, NUMAZones: []NUMAZone{{ID: 0, CPUs: 8}}},
+			reserved: "0-1",
+			isolated: "2-7",
+		},
+		{
+			// Without per-CPU information the CPU IDs are not checked, only their number.
+			topology: &CPUTopology{NodeName: defaultTopologyNodeName, NUMAZones: []NUMAZone{{ID: 0, CPUs: 8}}},
+			reserved: "0,64",
+			isolated: "65-70",
+		},
+		{
+			topology:      &CPUTopology{NodeName: defaultTopologyNodeName, NUMAZones: []NUMAZone{{ID: 0, CPUs: 8}}},
+			reserved:      "0-1",
+			isolated:      "2-8",
+			expectedError: "invalid cpusets for node worker-0: cpusets contain 9 CPUs but the node only has 8",
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.topology.ValidateCPUSets(testCase.reserved, testCase.isolated)
+
+		if testCase.expectedError == "" {
+			assert.Nil(t, err)
+
+			continue
+		}
+
+		assert.ErrorContains(t, err, testCase.expectedError)
+	}
+}
+
+func TestPerformanceProfileWithCPUTopology(t *testing.T) {
+	testCases := []struct {
+		topology      *CPUTopology
+		reserved      string
+		isolated      string
+		expectedError string
+	}{
+		{
+			topology: buildDummyCPUTopology(t, ""),
+			reserved: defaultReservedCPU,
+			isolated: defaultIsolatedCPU,
+		},
+		{
+			topology: buildDummyCPUTopology(t, ""),
+			reserved: "0-1",
+			isolated: "2-55",
+			expectedError: "invalid cpusets for node worker-0: " +
+				"thread siblings 0,28 of core 0 on socket 0 are split between reserved and isolated; " +
+				"thread siblings 1,29 of core 1 on socket 0 are split between reserved and isolated",
+		},
+		{
+			topology:      nil,
+			reserved:      defaultReservedCPU,
+			isolated:      defaultIsolatedCPU,
+			expectedError: "'topology' argument cannot be nil",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := clients.GetTestClients(clients.TestClientParams{SchemeAttachers: paoTestSchemes})
+		testBuilder := NewBuilder(testSettings, defaultPerformanceProfileName, testCase.isolated, testCase.reserved,
+			defaultNodeSelector).WithCPUTopology(testCase.topology)
+
+		testBuilder, err := testBuilder.Create()
+
+		if testCase.expectedError != "" {
+			assert.EqualError(t, err, testCase.expectedError)
+			assert.False(t, testBuilder.Exists())
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.True(t, testBuilder.Exists())
+	}
+}
+
+// buildDummyCPUTopology returns the topology of a node with two sockets of 14 cores with two threads each, one NUMA
+// node per socket and the thread siblings of CPU n being n+28. The offline CPUs are reported as offline by lscpu.
+func buildDummyCPUTopology(t *testing.T, offline string) *CPUTopology {
+	t.Helper()
+
+	offlineCPUs := mustParseCPUSet(t, offline)
+
+	var output strings.Builder
+
+	output.WriteString("# CPU,Core,Socket,Node,Online\n")
+
+	for cpu := range 56 {
+		core := cpu % 28
+		socket := core / 14
+
+		if offlineCPUs.Contains(cpu) {
+			output.WriteString(fmt.Sprintf("%d,,,,N\n", cpu))
+
+			continue
+		}
+
+		output.WriteString(fmt.Sprintf("%d,%d,%d,%d,Y\n", cpu, core, socket, socket))
+	}
+
+	topology, err := NewCPUTopologyFromLscpu(defaultTopologyNodeName, output.String())
+	assert.Nil(t, err)
+
+	return topology
+}
+
+// buildDummyNodeResourceTopology returns a NodeResourceTopology with two NUMA zones of 28 CPUs and a non-NUMA zone.
+func buildDummyNodeResourceTopology() *unstructured.Unstructured {
+	nrt := &unstructured.Unstructured{Object: map[string]any{
+		"zones": []any{
+			map[string]any{
+				"name": "node-1",
+				"type": "Node",
+				"resources": []any{
+					map[string]any{"name": "memory", "capacity": "64Gi"},
+					map[string]any{"name": "cpu", "capacity": "28"},
+				},
+			},
+			map[string]any{
+				"name":      "node-0",
+				"type":      "Node",
+				"resources": []any{map[string]any{"name": "cpu", "capacity": "28"}},
+			},
+			map[string]any{
+				"name": "cache-0",
+				"type": "L3Cache",
+			},
+		},
+	}}
+
+	nrt.SetGroupVersionKind(nodeResourceTopologyGVK)
+	nrt.SetName(defaultTopologyNodeName)
+
+	return nrt
+}
+
+// mustParseCPUSet parses the cpuset and fails the test if it is invalid.
+func mustParseCPUSet(t *testing.T, cpus string) cpuset.CPUSet {
+	t.Helper()
+
+	parsed, err := cpuset.Parse(cpus)
+	assert.Nil(t, err)
+
+	return parsed
+}
@@ -25,6 +25,8 @@ type Builder struct {
 	errorMsg string
 	// api client to interact with the cluster.
 	apiClient goclient.Client
+	// CPU topology of the target nodes, used to validate the cpusets before creating the PerformanceProfile.
+	cpuTopology *CPUTopology
 }
 
 // NewBuilder creates a new instance of Builder.
@@ -397,6 +399,59 @@ func (builder *Builder) WithAdditionalKernelArgs(additionalKernelArgs []string)
 	return builder
 }
 
+// WithCPUTopology sets the CPU topology of the nodes selected by the PerformanceProfile. When set, Create validates
+// the reserved and isolated cpusets against the topology before creating the PerformanceProfile.
+func (builder *Builder) WithCPUTopology(topology *CPUTopology) *Builder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Setting CPU topology of the PerformanceProfile %s", builder.Definition.Name)
+
+	if topology == nil {
+		glog.V(100).Infof("The CPU topology is nil")
+
+		builder.errorMsg = "'topology' argument cannot be nil"
+
+		return builder
+	}
+
+	builder.cpuTopology = topology
+
+	return builder
+}
+
+// ValidateCPUSets checks the reserved and isolated cpusets of the PerformanceProfile against the provided topology.
+func (builder *Builder) ValidateCPUSets(topology *CPUTopology) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	if topology == nil {
+		glog.V(100).Infof("The CPU topology is nil")
+
+		return fmt.Errorf("cannot validate cpusets of PerformanceProfile %s against nil topology",
+			builder.Definition.Name)
+	}
+
+	glog.V(100).Infof("Validating cpusets of the PerformanceProfile %s against node %s",
+		builder.Definition.Name, topology.NodeName)
+
+	var reserved, isolated string
+
+	if builder.Definition.Spec.CPU != nil {
+		if builder.Definition.Spec.CPU.Reserved != nil {
+			reserved = string(*builder.Definition.Spec.CPU.Reserved)
+		}
+
+		if builder.Definition.Spec.CPU.Isolated != nil {
+			isolated = string(*builder.Definition.Spec.CPU.Isolated)
+		}
+	}
+
+	return topology.ValidateCPUSets(reserved, isolated)
+}
+
 // Create the PerformanceProfile in the cluster and store the created object in Object.
 func (builder *Builder) Create() (*Builder, error) {
 	if valid, err := builder.validate(); !valid {
@@ -405,6 +460,12 @@ func (builder *Builder) Create() (*Builder, error) {
 
 	glog.V(100).Infof("Creating PerformanceProfile %s ", builder.Definition.Name)
 
+	if builder.cpuTopology != nil {
+		if err := builder.ValidateCPUSets(builder.cpuTopology); err != nil {
+			return builder, err
+		}
+	}
+
 	if !builder.Exists() {
 		err := builder.apiClient.Create(context.TODO(), builder.Definition)
 
@@ -0,0 +1,383 @@
+package nto //nolint:misspell
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	tunedv1 "github.com/openshift/cluster-node-tuning-operator/pkg/apis/tuned/v1"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/msg"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	goclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// NodeTuningOperatorNamespace is the namespace of the Node Tuning Operator, where the Tuned and per-node Profile
+	// objects live.
+	NodeTuningOperatorNamespace = "openshift-cluster-node-tuning-operator"
+	// performanceProfileTunedPrefix is the prefix of the tuned profile rendered from a PerformanceProfile.
+	performanceProfileTunedPrefix = "openshift-node-performance-"
+)
+
+// TunedProfileBuilder provides a struct for the per-node tuned Profile object from the cluster. Profiles are created
+// and updated by the Node Tuning Operator, so the builder is read only.
+type TunedProfileBuilder struct {
+	// Profile definition, used to look up the Profile object.
+	Definition *tunedv1.Profile
+	// Pulled Profile object.
+	Object *tunedv1.Profile
+	// api client to interact with the cluster.
+	apiClient goclient.Client
+}
+
+// TunedProfileStatus summarizes the tuned profile application state of a node.
+type TunedProfileStatus struct {
+	// NodeName is the name of the node, which is also the name of its Profile object.
+	NodeName string
+	// TunedProfile is the tuned profile in use by the tuned daemon on the node.
+	TunedProfile string
+	// Applied is true if the Applied condition is True for the current generation of the Profile.
+	Applied bool
+	// Degraded is true if the Degraded condition is True.
+	Degraded bool
+	// Reason is the reason of the condition stalling the profile application, if any.
+	Reason string
+	// Message is the message of the condition stalling the profile application, if any.
+	Message string
+}
+
+// PullTunedProfile pulls the tuned Profile of the node from the cluster.
+func PullTunedProfile(apiClient *clients.Settings, nodeName, nsname string) (*TunedProfileBuilder, error) {
+	glog.V(100).Infof("Pulling existing tuned Profile of node %s in namespace %s from cluster", nodeName, nsname)
+
+	if apiClient == nil {
+		glog.V(100).Infof("The apiClient is empty")
+
+		return nil, fmt.Errorf("tuned profile 'apiClient' cannot be empty")
+	}
+
+	err := apiClient.AttachScheme(tunedv1.AddToScheme)
+	if err != nil {
+		glog.V(100).Infof("Failed to add tuned v1 scheme to client schemes")
+
+		return nil, err
+	}
+
+	builder := TunedProfileBuilder{
+		apiClient: apiClient.Client,
+		Definition: &tunedv1.Profile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      nodeName,
+				Namespace: nsname,
+			},
+		},
+	}
+
+	if nodeName == "" {
+		glog.V(100).Infof("The nodeName of the tuned profile is empty")
+
+		return nil, fmt.Errorf("tuned profile 'nodeName' cannot be empty")
+	}
+
+	if nsname == "" {
+		glog.V(100).Infof("The namespace of the tuned profile is empty")
+
+		return nil, fmt.Errorf("tuned profile 'nsname' cannot be empty")
+	}
+
+	if !builder.Exists() {
+		return nil, fmt.Errorf("tuned profile object %s does not exist in namespace %s", nodeName, nsname)
+	}
+
+	builder.Definition = builder.Object
+
+	return &builder, nil
+}
+
+// ListTunedProfiles returns the per-node tuned Profiles in the namespace.
+func ListTunedProfiles(
+	apiClient *clients.Settings, nsname string, options ...goclient.ListOptions) ([]*TunedProfileBuilder, error) {
+	if apiClient == nil {
+		glog.V(100).Infof("The apiClient cannot be nil")
+
+		return nil, fmt.Errorf("the apiClient cannot be nil")
+	}
+
+	if nsname == "" {
+		glog.V(100).Infof("The namespace of the tuned profiles is empty")
+
+		return nil, fmt.Errorf("failed to list tuned profiles, 'nsname' parameter is empty")
+	}
+
+	err := apiClient.AttachScheme(tunedv1.AddToScheme)
+	if err != nil {
+		glog.V(100).Infof("Failed to add tuned v1 scheme to client schemes")
+
+		return nil, err
+	}
+
+	if len(options) > 1 {
+		glog.V(100).Infof("'options' parameter must be empty or single-valued")
+
+		return nil, fmt.Errorf("error: more than one ListOptions was passed")
+	}
+
+	passedOptions := goclient.ListOptions{}
+	logMessage := fmt.Sprintf("Listing tuned profiles in namespace %s", nsname)
+
+	if len(options) == 1 {
+		passedOptions = options[0]
+		logMessage += fmt.Sprintf(" with the options %v", passedOptions)
+	}
+
+	glog.V(100).Infof(logMessage)
+
+	passedOptions.Namespace = nsname
+
+	var profileList tunedv1.ProfileList
+
+	err = apiClient.List(context.TODO(), &profileList, &passedOptions)
+	if err != nil {
+		glog.V(100).Infof("Failed to list tuned profiles in namespace %s due to %s", nsname, err.Error())
+
+		return nil, err
+	}
+
+	var profileBuilders []*TunedProfileBuilder
+
+	for _, profile := range profileList.Items {
+		copiedProfile := profile
+		profileBuilders = append(profileBuilders, &TunedProfileBuilder{
+			apiClient:  apiClient.Client,
+			Object:     &copiedProfile,
+			Definition: &copiedProfile,
+		})
+	}
+
+	return profileBuilders, nil
+}
+
+// Get fetches the defined tuned Profile from the cluster.
+func (builder *TunedProfileBuilder) Get() (*tunedv1.Profile, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting tuned Profile %s in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	profile := &tunedv1.Profile{}
+
+	err := builder.apiClient.Get(context.TODO(), goclient.ObjectKey{
+		Name:      builder.Definition.Name,
+		Namespace: builder.Definition.Namespace,
+	}, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// Exists checks whether the given tuned Profile exists.
+func (builder *TunedProfileBuilder) Exists() bool {
+	if valid, _ := builder.validate(); !valid {
+		return false
+	}
+
+	glog.V(100).Infof("Checking if tuned Profile %s exists in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	var err error
+	builder.Object, err = builder.Get()
+
+	return err == nil || !k8serrors.IsNotFound(err)
+}
+
+// GetStatus refreshes the tuned Profile and returns the profile application state of its node.
+func (builder *TunedProfileBuilder) GetStatus() (*TunedProfileStatus, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting status of tuned Profile %s in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	if !builder.Exists() {
+		return nil, fmt.Errorf("cannot get status of tuned Profile %s in namespace %s because it does not exist",
+			builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	return newTunedProfileStatus(builder.Object), nil
+}
+
+// IsApplied returns true if the tuned daemon on the node runs profileName and has applied it without errors.
+func (status *TunedProfileStatus) IsApplied(profileName string) bool {
+	return status.TunedProfile == profileName && status.Applied && !status.Degraded
+}
+
+// WaitUntilProfileApplied waits up to timeout until the tuned profile profileName is applied on all the nodes
+// matching nodeSelector. The per-node Profiles are read from the namespace of the Tuned.
+func (builder *TunedBuilder) WaitUntilProfileApplied(
+	nodeSelector map[string]string, profileName string, timeout time.Duration) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	return waitUntilTunedProfileApplied(
+		builder.apiClient, builder.Definition.Namespace, nodeSelector, profileName, timeout)
+}
+
+// WaitUntilTunedProfileApplied waits up to timeout until the tuned profile rendered from the PerformanceProfile is
+// applied on all the nodes matching its node selector.
+func (builder *Builder) WaitUntilTunedProfileApplied(timeout time.Duration) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	return waitUntilTunedProfileApplied(builder.apiClient, NodeTuningOperatorNamespace,
+		builder.Definition.Spec.NodeSelector, performanceProfileTunedPrefix+builder.Definition.Name, timeout)
+}
+
+// waitUntilTunedProfileApplied polls the Profiles of the nodes matching nodeSelector until all of them have applied
+// profileName. On timeout, the returned error lists the nodes that have not applied it and why.
+func waitUntilTunedProfileApplied(apiClient goclient.Client,
+	nsname string, nodeSelector map[string]string, profileName string, timeout time.Duration) error {
+	glog.V(100).Infof("Waiting until tuned profile %s is applied on nodes matching %v", profileName, nodeSelector)
+
+	if profileName == "" {
+		glog.V(100).Infof("The tuned profile name is empty")
+
+		return fmt.Errorf("tuned 'profileName' cannot be empty")
+	}
+
+	var pending []string
+
+	err := wait.PollUntilContextTimeout(
+		context.TODO(), time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+			var err error
+
+			pending, err = getNodesPendingTunedProfile(ctx, apiClient, nsname, nodeSelector, profileName)
+			if err != nil {
+				glog.V(100).Infof("Failed to get tuned profiles of nodes matching %v: %v", nodeSelector, err)
+
+				return false, nil
+			}
+
+			return len(pending) == 0, nil
+		})
+	if err != nil {
+		if len(pending) > 0 {
+			return fmt.Errorf("tuned profile %s is not applied on %s: %w", profileName, strings.Join(pending, "; "), err)
+		}
+
+		return fmt.Errorf("failed waiting for tuned profile %s to be applied: %w", profileName, err)
+	}
+
+	return nil
+}
+
+// getNodesPendingTunedProfile returns a description of every node matching nodeSelector that has not applied
+// profileName. It returns an error if no nodes match.
+func getNodesPendingTunedProfile(ctx context.Context, apiClient goclient.Client,
+	nsname string, nodeSelector map[string]string, profileName string) ([]string, error) {
+	nodeList := &corev1.NodeList{}
+
+	err := apiClient.List(ctx, nodeList, goclient.MatchingLabels(nodeSelector))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nodeList.Items) == 0 {
+		return nil, fmt.Errorf("no nodes match %v", nodeSelector)
+	}
+
+	var pending []string
+
+	for _, node := range nodeList.Items {
+		profile := &tunedv1.Profile{}
+
+		err := apiClient.Get(ctx, goclient.ObjectKey{Name: node.Name, Namespace: nsname}, profile)
+		if err != nil {
+			pending = append(pending, fmt.Sprintf("node %s (no tuned profile: %v)", node.Name, err))
+
+			continue
+		}
+
+		status := newTunedProfileStatus(profile)
+		if status.IsApplied(profileName) {
+			continue
+		}
+
+		description := fmt.Sprintf("node %s (profile %q", node.Name, status.TunedProfile)
+
+		if status.Reason != "" || status.Message != "" {
+			description += fmt.Sprintf(", %s: %s", status.Reason, status.Message)
+		}
+
+		pending = append(pending, description+")")
+	}
+
+	slices.Sort(pending)
+
+	return pending, nil
+}
+
+// newTunedProfileStatus summarizes the status of the tuned Profile. The Applied condition is ignored if it was set
+// for an older generation of the Profile.
+func newTunedProfileStatus(profile *tunedv1.Profile) *TunedProfileStatus {
+	status := &TunedProfileStatus{NodeName: profile.Name, TunedProfile: profile.Status.TunedProfile}
+	current := profile.Status.ObservedGeneration == 0 || profile.Status.ObservedGeneration >= profile.Generation
+
+	for _, condition := range profile.Status.Conditions {
+		switch condition.Type {
+		case tunedv1.TunedProfileApplied:
+			status.Applied = current && condition.Status == corev1.ConditionTrue
+
+			if condition.Status != corev1.ConditionTrue && status.Reason == "" {
+				status.Reason, status.Message = condition.Reason, condition.Message
+			}
+		case tunedv1.TunedDegraded:
+			status.Degraded = condition.Status == corev1.ConditionTrue
+
+			if status.Degraded {
+				status.Reason, status.Message = condition.Reason, condition.Message
+			}
+		}
+	}
+
+	return status
+}
+
+// validate will check that the builder and builder definition are properly initialized before
+// accessing any member fields.
+func (builder *TunedProfileBuilder) validate() (bool, error) {
+	resourceCRD := "tuned Profile"
+
+	if builder == nil {
+		glog.V(100).Infof("The %s builder is uninitialized", resourceCRD)
+
+		return false, fmt.Errorf("error: received nil %s builder", resourceCRD)
+	}
+
+	if builder.Definition == nil {
+		glog.V(100).Infof("The %s is undefined", resourceCRD)
+
+		return false, fmt.Errorf("%s", msg.UndefinedCrdObjectErrString(resourceCRD))
+	}
+
+	if builder.apiClient == nil {
+		glog.V(100).Infof("The %s builder apiclient is nil", resourceCRD)
+
+		return false, fmt.Errorf("%s builder cannot have nil apiClient", resourceCRD)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,282 @@
+package nto //nolint:misspell
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	tunedv1 "github.com/openshift/cluster-node-tuning-operator/pkg/apis/tuned/v1"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const defaultTunedProfileNode = "worker-0"
+
+var tunedProfileTestSchemes = []clients.SchemeAttacher{
+	tunedv1.AddToScheme,
+}
+
+func TestPullTunedProfile(t *testing.T) {
+	testCases := []struct {
+		nodeName      string
+		nsname        string
+		exists        bool
+		client        bool
+		expectedError error
+	}{
+		{
+			nodeName:      defaultTunedProfileNode,
+			nsname:        NodeTuningOperatorNamespace,
+			exists:        true,
+			client:        true,
+			expectedError: nil,
+		},
+		{
+			nodeName:      "",
+			nsname:        NodeTuningOperatorNamespace,
+			exists:        true,
+			client:        true,
+			expectedError: fmt.Errorf("tuned profile 'nodeName' cannot be empty"),
+		},
+		{
+			nodeName:      defaultTunedProfileNode,
+			nsname:        "",
+			exists:        true,
+			client:        true,
+			expectedError: fmt.Errorf("tuned profile 'nsname' cannot be empty"),
+		},
+		{
+			nodeName: defaultTunedProfileNode,
+			nsname:   NodeTuningOperatorNamespace,
+			exists:   false,
+			client:   true,
+			expectedError: fmt.Errorf("tuned profile object %s does not exist in namespace %s",
+				defaultTunedProfileNode, NodeTuningOperatorNamespace),
+		},
+		{
+			nodeName:      defaultTunedProfileNode,
+			nsname:        NodeTuningOperatorNamespace,
+			exists:        true,
+			client:        false,
+			expectedError: fmt.Errorf("tuned profile 'apiClient' cannot be empty"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var (
+			runtimeObjects []runtime.Object
+			testSettings   *clients.Settings
+		)
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyTunedProfile(defaultTunedProfileNode,
+				defaultTunedProfileName, corev1.ConditionTrue, corev1.ConditionFalse))
+		}
+
+		if testCase.client {
+			testSettings = clients.GetTestClients(clients.TestClientParams{
+				K8sMockObjects:  runtimeObjects,
+				SchemeAttachers: tunedProfileTestSchemes,
+			})
+		}
+
+		profileBuilder, err := PullTunedProfile(testSettings, testCase.nodeName, testCase.nsname)
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, testCase.nodeName, profileBuilder.Definition.Name)
+			assert.Equal(t, defaultTunedProfileName, profileBuilder.Object.Status.TunedProfile)
+		}
+	}
+}
+
+func TestListTunedProfiles(t *testing.T) {
+	testCases := []struct {
+		nsname        string
+		expectedCount int
+		expectedError error
+	}{
+		{
+			nsname:        NodeTuningOperatorNamespace,
+			expectedCount: 2,
+			expectedError: nil,
+		},
+		{
+			nsname:        "",
+			expectedError: fmt.Errorf("failed to list tuned profiles, 'nsname' parameter is empty"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := buildTestClientWithDummyTunedProfiles(corev1.ConditionTrue)
+
+		profileBuilders, err := ListTunedProfiles(testSettings, testCase.nsname)
+		assert.Equal(t, testCase.expectedError, err)
+		assert.Len(t, profileBuilders, testCase.expectedCount)
+	}
+}
+
+func TestTunedProfileGetStatus(t *testing.T) {
+	testCases := []struct {
+		profile         *tunedv1.Profile
+		expectedStatus  *TunedProfileStatus
+		expectedApplied bool
+	}{
+		{
+			profile: buildDummyTunedProfile(defaultTunedProfileNode,
+				defaultTunedProfileName, corev1.ConditionTrue, corev1.ConditionFalse),
+			expectedStatus: &TunedProfileStatus{
+				NodeName: defaultTunedProfileNode, TunedProfile: defaultTunedProfileName, Applied: true,
+			},
+			expectedApplied: true,
+		},
+		{
+			profile: buildDummyTunedProfile(defaultTunedProfileNode,
+				defaultTunedProfileName, corev1.ConditionFalse, corev1.ConditionTrue),
+			expectedStatus: &TunedProfileStatus{
+				NodeName: defaultTunedProfileNode, TunedProfile: defaultTunedProfileName, Degraded: true,
+				Reason: "TunedError", Message: "test message",
+			},
+			expectedApplied: false,
+		},
+		{
+			profile: func() *tunedv1.Profile {
+				profile := buildDummyTunedProfile(defaultTunedProfileNode,
+					defaultTunedProfileName, corev1.ConditionTrue, corev1.ConditionFalse)
+				profile.Generation = 2
+				profile.Status.ObservedGeneration = 1
+
+				return profile
+			}(),
+			expectedStatus: &TunedProfileStatus{NodeName: defaultTunedProfileNode, TunedProfile: defaultTunedProfileName},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects:  []runtime.Object{testCase.profile},
+			SchemeAttachers: tunedProfileTestSchemes,
+		})
+
+		profileBuilder, err := PullTunedProfile(testSettings, defaultTunedProfileNode, NodeTuningOperatorNamespace)
+		assert.Nil(t, err)
+
+		status, err := profileBuilder.GetStatus()
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedStatus, status)
+		assert.Equal(t, testCase.expectedApplied, status.IsApplied(defaultTunedProfileName))
+		assert.False(t, status.IsApplied("other"))
+	}
+}
+
+func TestTunedWaitUntilProfileApplied(t *testing.T) {
+	testCases := []struct {
+		workerApplied corev1.ConditionStatus
+		nodeSelector  map[string]string
+		profileName   string
+		expectedError error
+	}{
+		{
+			workerApplied: corev1.ConditionTrue,
+			nodeSelector:  defaultNodeSelector,
+			profileName:   defaultTunedProfileName,
+			expectedError: nil,
+		},
+		{
+			workerApplied: corev1.ConditionFalse,
+			nodeSelector:  defaultNodeSelector,
+			profileName:   defaultTunedProfileName,
+			expectedError: fmt.Errorf("tuned profile %s is not applied on node worker-1 (profile \"%s\", "+
+				"TunedError: test message): %w", defaultTunedProfileName, defaultTunedProfileName,
+				context.DeadlineExceeded),
+		},
+		{
+			workerApplied: corev1.ConditionTrue,
+			nodeSelector:  map[string]string{"node-role.kubernetes.io/missing": ""},
+			profileName:   defaultTunedProfileName,
+			expectedError: fmt.Errorf("failed waiting for tuned profile %s to be applied: %w",
+				defaultTunedProfileName, context.DeadlineExceeded),
+		},
+		{
+			workerApplied: corev1.ConditionTrue,
+			nodeSelector:  defaultNodeSelector,
+			profileName:   "",
+			expectedError: fmt.Errorf("tuned 'profileName' cannot be empty"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := buildTestClientWithDummyTunedProfiles(testCase.workerApplied)
+		testBuilder := NewTunedBuilder(testSettings, defaultTunedName, NodeTuningOperatorNamespace)
+
+		err := testBuilder.WaitUntilProfileApplied(testCase.nodeSelector, testCase.profileName, time.Second)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+func TestPerformanceProfileWaitUntilTunedProfileApplied(t *testing.T) {
+	testSettings := clients.GetTestClients(clients.TestClientParams{
+		K8sMockObjects: []runtime.Object{
+			buildDummyTunedProfileNode(defaultTunedProfileNode),
+			buildDummyTunedProfile(defaultTunedProfileNode, performanceProfileTunedPrefix+defaultPerformanceProfileName,
+				corev1.ConditionTrue, corev1.ConditionFalse),
+		},
+		SchemeAttachers: tunedProfileTestSchemes,
+	})
+
+	err := buildValidPerformanceProfileBuilder(testSettings).WaitUntilTunedProfileApplied(time.Second)
+	assert.Nil(t, err)
+}
+
+// buildTestClientWithDummyTunedProfiles returns a client with two worker nodes and their Profiles. The Applied
+// condition of worker-1 is set to workerApplied, while worker-0 has applied the profile.
+func buildTestClientWithDummyTunedProfiles(workerApplied corev1.ConditionStatus) *clients.Settings {
+	return clients.GetTestClients(clients.TestClientParams{
+		K8sMockObjects: []runtime.Object{
+			buildDummyTunedProfileNode("worker-0"),
+			buildDummyTunedProfileNode("worker-1"),
+			buildDummyTunedProfile("worker-0", defaultTunedProfileName, corev1.ConditionTrue, corev1.ConditionFalse),
+			buildDummyTunedProfile("worker-1", defaultTunedProfileName, workerApplied, corev1.ConditionFalse),
+		},
+		SchemeAttachers: tunedProfileTestSchemes,
+	})
+}
+
+// buildDummyTunedProfileNode returns a worker node matching defaultNodeSelector.
+func buildDummyTunedProfileNode(name string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: defaultNodeSelector}}
+}
+
+// buildDummyTunedProfile returns the Profile of the node running tunedProfile with the provided Applied and Degraded
+// conditions. Conditions that are not True carry a reason and message.
+func buildDummyTunedProfile(
+	nodeName, tunedProfile string, applied, degraded corev1.ConditionStatus) *tunedv1.Profile {
+	profile := &tunedv1.Profile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       nodeName,
+			Namespace:  NodeTuningOperatorNamespace,
+			Generation: 1,
+		},
+		Status: tunedv1.ProfileStatus{
+			TunedProfile:       tunedProfile,
+			ObservedGeneration: 1,
+			Conditions: []tunedv1.StatusCondition{
+				{Type: tunedv1.TunedProfileApplied, Status: applied},
+				{Type: tunedv1.TunedDegraded, Status: degraded},
+			},
+		},
+	}
+
+	for index, condition := range profile.Status.Conditions {
+		if (condition.Type == tunedv1.TunedProfileApplied) != (condition.Status == corev1.ConditionTrue) {
+			profile.Status.Conditions[index].Reason = "TunedError"
+			profile.Status.Conditions[index].Message = "test message"
+		}
+	}
+
+	return profile
+}
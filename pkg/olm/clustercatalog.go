@@ -0,0 +1,415 @@
+package olm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/msg"
+	olmv1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/olm/olmv1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// clusterCatalogAllPath is the path, relative to the catalog base URL, serving the whole FBC content.
+const clusterCatalogAllPath = "/api/v1/all"
+
+// ClusterCatalogBuilder provides a struct for the OLM v1 clustercatalog object from the cluster and a clustercatalog
+// definition.
+type ClusterCatalogBuilder struct {
+	// ClusterCatalog definition. Used to create a clustercatalog object with minimum set of required elements.
+	Definition *olmv1.ClusterCatalog
+	// Created clustercatalog object on the cluster.
+	Object *olmv1.ClusterCatalog
+	// api client to interact with the cluster. The full settings are kept so the catalog content can be fetched
+	// through the service proxy.
+	apiClient *clients.Settings
+	// errorMsg is processed before ClusterCatalogBuilder object is created.
+	errorMsg string
+}
+
+// NewClusterCatalogBuilder creates a new instance of ClusterCatalogBuilder serving the FBC content of the image
+// referenced by imageRef.
+func NewClusterCatalogBuilder(apiClient *clients.Settings, name, imageRef string) *ClusterCatalogBuilder {
+	glog.V(100).Infof("Initializing new clustercatalog %s structure with image %s", name, imageRef)
+
+	if apiClient == nil {
+		glog.V(100).Infof("The apiClient cannot be nil")
+
+		return nil
+	}
+
+	err := apiClient.AttachScheme(olmv1.AddToScheme)
+	if err != nil {
+		glog.V(100).Infof("Failed to add olmv1 scheme to client schemes")
+
+		return nil
+	}
+
+	builder := &ClusterCatalogBuilder{
+		apiClient: apiClient,
+		Definition: &olmv1.ClusterCatalog{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: olmv1.ClusterCatalogSpec{
+				Source: olmv1.CatalogSource{
+					Type:  olmv1.SourceTypeImage,
+					Image: &olmv1.ImageSource{Ref: imageRef},
+				},
+			},
+		},
+	}
+
+	if name == "" {
+		glog.V(100).Infof("The name of the clustercatalog is empty")
+
+		builder.errorMsg = "clustercatalog 'name' cannot be empty"
+
+		return builder
+	}
+
+	if imageRef == "" {
+		glog.V(100).Infof("The imageRef of the clustercatalog is empty")
+
+		builder.errorMsg = "clustercatalog 'imageRef' cannot be empty"
+
+		return builder
+	}
+
+	return builder
+}
+
+// PullClusterCatalog loads an existing clustercatalog into the ClusterCatalogBuilder struct.
+func PullClusterCatalog(apiClient *clients.Settings, name string) (*ClusterCatalogBuilder, error) {
+	glog.V(100).Infof("Pulling existing clustercatalog %s", name)
+
+	if apiClient == nil {
+		glog.V(100).Infof("The apiClient cannot be nil")
+
+		return nil, fmt.Errorf("clustercatalog 'apiClient' cannot be empty")
+	}
+
+	err := apiClient.AttachScheme(olmv1.AddToScheme)
+	if err != nil {
+		glog.V(100).Infof("Failed to add olmv1 scheme to client schemes")
+
+		return nil, err
+	}
+
+	builder := &ClusterCatalogBuilder{
+		apiClient: apiClient,
+		Definition: &olmv1.ClusterCatalog{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+		},
+	}
+
+	if name == "" {
+		glog.V(100).Infof("The name of the clustercatalog is empty")
+
+		return nil, fmt.Errorf("clustercatalog 'name' cannot be empty")
+	}
+
+	if !builder.Exists() {
+		return nil, fmt.Errorf("clustercatalog object %s does not exist", name)
+	}
+
+	builder.Definition = builder.Object
+
+	return builder, nil
+}
+
+// WithPollInterval sets the interval at which the catalog image is polled for new content. Polling is only allowed
+// for tag based image references.
+func (builder *ClusterCatalogBuilder) WithPollInterval(interval time.Duration) *ClusterCatalogBuilder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Setting clustercatalog %s poll interval to %s", builder.Definition.Name, interval)
+
+	if interval < time.Minute || interval%time.Minute != 0 {
+		glog.V(100).Infof("The clustercatalog poll interval %s is not a whole number of minutes", interval)
+
+		builder.errorMsg = "clustercatalog poll interval must be a positive whole number of minutes"
+
+		return builder
+	}
+
+	if builder.Definition.Spec.Source.Image == nil {
+		glog.V(100).Infof("The clustercatalog %s has no image source", builder.Definition.Name)
+
+		builder.errorMsg = "clustercatalog poll interval can only be set for an image source"
+
+		return builder
+	}
+
+	if strings.Contains(builder.Definition.Spec.Source.Image.Ref, "@") {
+		glog.V(100).Infof("The clustercatalog image %s is referenced by digest", builder.Definition.Spec.Source.Image.Ref)
+
+		builder.errorMsg = "clustercatalog poll interval cannot be set for a digest based image reference"
+
+		return builder
+	}
+
+	minutes := int(interval / time.Minute)
+	builder.Definition.Spec.Source.Image.PollIntervalMinutes = &minutes
+
+	return builder
+}
+
+// WithPriority sets the priority of the catalog. When several catalogs provide the same bundle, the one from the
+// catalog with the highest priority is selected.
+func (builder *ClusterCatalogBuilder) WithPriority(priority int32) *ClusterCatalogBuilder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Setting clustercatalog %s priority to %d", builder.Definition.Name, priority)
+
+	builder.Definition.Spec.Priority = priority
+
+	return builder
+}
+
+// Get returns the clustercatalog object if found.
+func (builder *ClusterCatalogBuilder) Get() (*olmv1.ClusterCatalog, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting clustercatalog %s", builder.Definition.Name)
+
+	clusterCatalog := &olmv1.ClusterCatalog{}
+
+	err := builder.apiClient.Get(context.TODO(), runtimeClient.ObjectKey{Name: builder.Definition.Name}, clusterCatalog)
+	if err != nil {
+		glog.V(100).Infof("Failed to get clustercatalog %s: %v", builder.Definition.Name, err)
+
+		return nil, err
+	}
+
+	return clusterCatalog, nil
+}
+
+// Exists checks whether the given clustercatalog exists.
+func (builder *ClusterCatalogBuilder) Exists() bool {
+	if valid, _ := builder.validate(); !valid {
+		return false
+	}
+
+	glog.V(100).Infof("Checking if clustercatalog %s exists", builder.Definition.Name)
+
+	var err error
+	builder.Object, err = builder.Get()
+
+	return err == nil || !k8serrors.IsNotFound(err)
+}
+
+// Create makes a clustercatalog in the cluster and stores the created object in struct.
+func (builder *ClusterCatalogBuilder) Create() (*ClusterCatalogBuilder, error) {
+	if valid, err := builder.validate(); !valid {
+		return builder, err
+	}
+
+	glog.V(100).Infof("Creating the clustercatalog %s", builder.Definition.Name)
+
+	if builder.Exists() {
+		return builder, nil
+	}
+
+	err := builder.apiClient.Create(context.TODO(), builder.Definition)
+	if err != nil {
+		return builder, err
+	}
+
+	builder.Object = builder.Definition
+
+	return builder, nil
+}
+
+// Update renovates the existing clustercatalog object with the clustercatalog definition in builder.
+func (builder *ClusterCatalogBuilder) Update() (*ClusterCatalogBuilder, error) {
+	if valid, err := builder.validate(); !valid {
+		return builder, err
+	}
+
+	glog.V(100).Infof("Updating the clustercatalog %s", builder.Definition.Name)
+
+	if !builder.Exists() {
+		glog.V(100).Infof("Clustercatalog %s does not exist", builder.Definition.Name)
+
+		return builder, fmt.Errorf("failed to update clustercatalog, object does not exist on cluster")
+	}
+
+	builder.Definition.ResourceVersion = builder.Object.ResourceVersion
+
+	err := builder.apiClient.Update(context.TODO(), builder.Definition)
+	if err != nil {
+		return builder, err
+	}
+
+	builder.Object = builder.Definition
+
+	return builder, nil
+}
+
+// Delete removes the clustercatalog from the cluster.
+func (builder *ClusterCatalogBuilder) Delete() error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Deleting clustercatalog %s", builder.Definition.Name)
+
+	if !builder.Exists() {
+		glog.V(100).Infof("Clustercatalog %s cannot be deleted because it does not exist", builder.Definition.Name)
+
+		builder.Object = nil
+
+		return nil
+	}
+
+	err := builder.apiClient.Delete(context.TODO(), builder.Definition)
+	if err != nil {
+		return err
+	}
+
+	builder.Object = nil
+
+	return nil
+}
+
+// WaitUntilServing waits up to timeout for the clustercatalog to unpack its image and serve the content.
+func (builder *ClusterCatalogBuilder) WaitUntilServing(timeout time.Duration) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Waiting up to %s for clustercatalog %s to be serving", timeout, builder.Definition.Name)
+
+	var lastCondition *metav1.Condition
+
+	err := wait.PollUntilContextTimeout(
+		context.TODO(), time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+			if !builder.Exists() || builder.Object == nil {
+				return false, nil
+			}
+
+			if progressing := meta.FindStatusCondition(
+				builder.Object.Status.Conditions, olmv1.TypeProgressing); progressing != nil {
+				lastCondition = progressing
+			}
+
+			return meta.IsStatusConditionTrue(builder.Object.Status.Conditions, olmv1.TypeServing), nil
+		})
+	if err != nil {
+		if lastCondition != nil {
+			return fmt.Errorf("clustercatalog %s is not serving (%s: %s): %w",
+				builder.Definition.Name, lastCondition.Reason, lastCondition.Message, err)
+		}
+
+		return fmt.Errorf("clustercatalog %s is not serving: %w", builder.Definition.Name, err)
+	}
+
+	return nil
+}
+
+// GetContent fetches the FBC content served by the clustercatalog. The content is read through the API server
+// service proxy, so it is reachable from outside the cluster.
+func (builder *ClusterCatalogBuilder) GetContent() (*FileBasedCatalog, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting the content of clustercatalog %s", builder.Definition.Name)
+
+	if !builder.Exists() || builder.Object == nil {
+		return nil, fmt.Errorf("clustercatalog object %s does not exist", builder.Definition.Name)
+	}
+
+	if builder.Object.Status.URLs == nil || builder.Object.Status.URLs.Base == "" {
+		return nil, fmt.Errorf("clustercatalog %s is not serving any content yet", builder.Definition.Name)
+	}
+
+	serviceName, serviceNamespace, port, path, err := parseClusterCatalogURL(builder.Object.Status.URLs.Base)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := builder.apiClient.CoreV1Interface.Services(serviceNamespace).
+		ProxyGet("https", serviceName, port, path+clusterCatalogAllPath, nil).DoRaw(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the content of clustercatalog %s: %w", builder.Definition.Name, err)
+	}
+
+	catalog, err := ParseFileBasedCatalog(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the content of clustercatalog %s: %w", builder.Definition.Name, err)
+	}
+
+	return catalog, nil
+}
+
+// validate will check that the builder and builder definition are properly initialized before
+// accessing any member fields.
+func (builder *ClusterCatalogBuilder) validate() (bool, error) {
+	resourceCRD := "clustercatalog"
+
+	if builder == nil {
+		glog.V(100).Infof("The %s builder is uninitialized", resourceCRD)
+
+		return false, fmt.Errorf("error: received nil %s builder", resourceCRD)
+	}
+
+	if builder.Definition == nil {
+		glog.V(100).Infof("The %s is undefined", resourceCRD)
+
+		return false, fmt.Errorf("%s", msg.UndefinedCrdObjectErrString(resourceCRD))
+	}
+
+	if builder.apiClient == nil {
+		glog.V(100).Infof("The %s builder apiclient is nil", resourceCRD)
+
+		return false, fmt.Errorf("%s builder cannot have nil apiClient", resourceCRD)
+	}
+
+	if builder.errorMsg != "" {
+		glog.V(100).Infof("The %s builder has error message: %s", resourceCRD, builder.errorMsg)
+
+		return false, fmt.Errorf("%s", builder.errorMsg)
+	}
+
+	return true, nil
+}
+
+// parseClusterCatalogURL splits the cluster-internal catalog base URL, https://<service>.<namespace>.svc/<path>, into
+// the service name, namespace, port and path used by the service proxy.
+func parseClusterCatalogURL(baseURL string) (name, nsname, port, path string, err error) {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return "", "", "", "", fmt.Errorf("failed to parse clustercatalog base url %s: %w", baseURL, err)
+	}
+
+	hostParts := strings.Split(parsedURL.Hostname(), ".")
+	if len(hostParts) < 3 || hostParts[2] != "svc" {
+		return "", "", "", "", fmt.Errorf("clustercatalog base url %s does not point to a service", baseURL)
+	}
+
+	port = parsedURL.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	return hostParts[0], hostParts[1], port, strings.TrimSuffix(parsedURL.Path, "/"), nil
+}
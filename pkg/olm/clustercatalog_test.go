@@ -0,0 +1,336 @@
+package olm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	olmv1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/olm/olmv1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	restclient "k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const (
+	defaultClusterCatalogName  = "test-catalog"
+	defaultClusterCatalogImage = "quay.io/test/catalog:latest"
+	defaultClusterCatalogURL   = "https://catalogd-service.olmv1-system.svc/catalogs/test-catalog"
+)
+
+var olmv1TestSchemes = []clients.SchemeAttacher{
+	olmv1.AddToScheme,
+}
+
+func TestNewClusterCatalogBuilder(t *testing.T) {
+	testCases := []struct {
+		name          string
+		imageRef      string
+		client        bool
+		expectedError string
+	}{
+		{
+			name:          defaultClusterCatalogName,
+			imageRef:      defaultClusterCatalogImage,
+			client:        true,
+			expectedError: "",
+		},
+		{
+			name:          "",
+			imageRef:      defaultClusterCatalogImage,
+			client:        true,
+			expectedError: "clustercatalog 'name' cannot be empty",
+		},
+		{
+			name:          defaultClusterCatalogName,
+			imageRef:      "",
+			client:        true,
+			expectedError: "clustercatalog 'imageRef' cannot be empty",
+		},
+		{
+			name:          defaultClusterCatalogName,
+			imageRef:      defaultClusterCatalogImage,
+			client:        false,
+			expectedError: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		var testSettings *clients.Settings
+
+		if testCase.client {
+			testSettings = clients.GetTestClients(clients.TestClientParams{SchemeAttachers: olmv1TestSchemes})
+		}
+
+		testBuilder := NewClusterCatalogBuilder(testSettings, testCase.name, testCase.imageRef)
+
+		if !testCase.client {
+			assert.Nil(t, testBuilder)
+
+			continue
+		}
+
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+		assert.Equal(t, olmv1.SourceTypeImage, testBuilder.Definition.Spec.Source.Type)
+		assert.Equal(t, testCase.imageRef, testBuilder.Definition.Spec.Source.Image.Ref)
+	}
+}
+
+func TestPullClusterCatalog(t *testing.T) {
+	testCases := []struct {
+		name          string
+		exists        bool
+		client        bool
+		expectedError error
+	}{
+		{
+			name:          defaultClusterCatalogName,
+			exists:        true,
+			client:        true,
+			expectedError: nil,
+		},
+		{
+			name:          "",
+			exists:        true,
+			client:        true,
+			expectedError: fmt.Errorf("clustercatalog 'name' cannot be empty"),
+		},
+		{
+			name:          defaultClusterCatalogName,
+			exists:        false,
+			client:        true,
+			expectedError: fmt.Errorf("clustercatalog object %s does not exist", defaultClusterCatalogName),
+		},
+		{
+			name:          defaultClusterCatalogName,
+			exists:        true,
+			client:        false,
+			expectedError: fmt.Errorf("clustercatalog 'apiClient' cannot be empty"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var (
+			runtimeObjects []runtime.Object
+			testSettings   *clients.Settings
+		)
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyClusterCatalog(metav1.ConditionTrue))
+		}
+
+		if testCase.client {
+			testSettings = clients.GetTestClients(clients.TestClientParams{
+				K8sMockObjects:  runtimeObjects,
+				SchemeAttachers: olmv1TestSchemes,
+			})
+		}
+
+		testBuilder, err := PullClusterCatalog(testSettings, testCase.name)
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, testCase.name, testBuilder.Definition.Name)
+		}
+	}
+}
+
+func TestClusterCatalogWithPollInterval(t *testing.T) {
+	testCases := []struct {
+		imageRef        string
+		noImageSource   bool
+		interval        time.Duration
+		expectedMinutes int
+		expectedError   string
+	}{
+		{
+			imageRef:        defaultClusterCatalogImage,
+			interval:        10 * time.Minute,
+			expectedMinutes: 10,
+			expectedError:   "",
+		},
+		{
+			imageRef:      defaultClusterCatalogImage,
+			interval:      90 * time.Second,
+			expectedError: "clustercatalog poll interval must be a positive whole number of minutes",
+		},
+		{
+			imageRef:      "quay.io/test/catalog@sha256:0123456789abcdef",
+			interval:      10 * time.Minute,
+			expectedError: "clustercatalog poll interval cannot be set for a digest based image reference",
+		},
+		{
+			imageRef:      defaultClusterCatalogImage,
+			noImageSource: true,
+			interval:      10 * time.Minute,
+			expectedError: "clustercatalog poll interval can only be set for an image source",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := clients.GetTestClients(clients.TestClientParams{SchemeAttachers: olmv1TestSchemes})
+		testBuilder := NewClusterCatalogBuilder(testSettings, defaultClusterCatalogName, testCase.imageRef)
+
+		if testCase.noImageSource {
+			testBuilder.Definition.Spec.Source.Image = nil
+		}
+
+		testBuilder = testBuilder.WithPollInterval(testCase.interval).WithPriority(5)
+
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+		if testCase.expectedError == "" {
+			assert.Equal(t, testCase.expectedMinutes, *testBuilder.Definition.Spec.Source.Image.PollIntervalMinutes)
+			assert.Equal(t, int32(5), testBuilder.Definition.Spec.Priority)
+		}
+	}
+}
+
+func TestClusterCatalogCreateAndDelete(t *testing.T) {
+	testSettings := clients.GetTestClients(clients.TestClientParams{SchemeAttachers: olmv1TestSchemes})
+	testBuilder := NewClusterCatalogBuilder(testSettings, defaultClusterCatalogName, defaultClusterCatalogImage)
+
+	testBuilder, err := testBuilder.Create()
+	assert.Nil(t, err)
+	assert.True(t, testBuilder.Exists())
+
+	testBuilder.Definition.Spec.Priority = 10
+
+	testBuilder, err = testBuilder.Update()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(10), testBuilder.Object.Spec.Priority)
+
+	err = testBuilder.Delete()
+	assert.Nil(t, err)
+	assert.False(t, testBuilder.Exists())
+}
+
+func TestClusterCatalogWaitUntilServing(t *testing.T) {
+	testCases := []struct {
+		serving       metav1.ConditionStatus
+		expectedError error
+	}{
+		{
+			serving:       metav1.ConditionTrue,
+			expectedError: nil,
+		},
+		{
+			serving: metav1.ConditionFalse,
+			expectedError: fmt.Errorf("clustercatalog %s is not serving (Retrying: failed to pull image): %w",
+				defaultClusterCatalogName, context.DeadlineExceeded),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects:  []runtime.Object{buildDummyClusterCatalog(testCase.serving)},
+			SchemeAttachers: olmv1TestSchemes,
+		})
+
+		testBuilder, err := PullClusterCatalog(testSettings, defaultClusterCatalogName)
+		assert.Nil(t, err)
+
+		err = testBuilder.WaitUntilServing(time.Second)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+func TestClusterCatalogGetContent(t *testing.T) {
+	testCases := []struct {
+		serving       metav1.ConditionStatus
+		expectedError error
+	}{
+		{
+			serving:       metav1.ConditionTrue,
+			expectedError: nil,
+		},
+		{
+			serving:       metav1.ConditionFalse,
+			expectedError: fmt.Errorf("clustercatalog %s is not serving any content yet", defaultClusterCatalogName),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects:  []runtime.Object{buildDummyClusterCatalog(testCase.serving)},
+			SchemeAttachers: olmv1TestSchemes,
+		})
+
+		var proxiedAction k8stesting.ProxyGetAction
+
+		testSettings.K8sClient.(*fake.Clientset).PrependProxyReactor("services",
+			func(action k8stesting.Action) (bool, restclient.ResponseWrapper, error) {
+				proxiedAction, _ = action.(k8stesting.ProxyGetAction)
+
+				return true, &dummyResponseWrapper{content: defaultFBCContent}, nil
+			})
+
+		testBuilder, err := PullClusterCatalog(testSettings, defaultClusterCatalogName)
+		assert.Nil(t, err)
+
+		catalog, err := testBuilder.GetContent()
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError != nil {
+			continue
+		}
+
+		assert.Len(t, catalog.Bundles, 3)
+		assert.Equal(t, "olmv1-system", proxiedAction.GetNamespace())
+		assert.Equal(t, "catalogd-service", proxiedAction.GetName())
+		assert.Equal(t, "https", proxiedAction.GetScheme())
+		assert.Equal(t, "443", proxiedAction.GetPort())
+		assert.Equal(t, "/catalogs/test-catalog/api/v1/all", proxiedAction.GetPath())
+	}
+}
+
+// dummyResponseWrapper is a proxy response returning fixed content.
+type dummyResponseWrapper struct {
+	content string
+}
+
+// DoRaw returns the content of the response.
+func (wrapper *dummyResponseWrapper) DoRaw(context.Context) ([]byte, error) {
+	return []byte(wrapper.content), nil
+}
+
+// Stream returns a reader over the content of the response.
+func (wrapper *dummyResponseWrapper) Stream(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(wrapper.content)), nil
+}
+
+// buildDummyClusterCatalog returns a clustercatalog with the provided Serving condition. A serving catalog has its
+// base URL set while other catalogs report a Retrying Progressing condition.
+func buildDummyClusterCatalog(serving metav1.ConditionStatus) *olmv1.ClusterCatalog {
+	clusterCatalog := &olmv1.ClusterCatalog{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultClusterCatalogName},
+		Spec: olmv1.ClusterCatalogSpec{
+			Source: olmv1.CatalogSource{
+				Type:  olmv1.SourceTypeImage,
+				Image: &olmv1.ImageSource{Ref: defaultClusterCatalogImage},
+			},
+		},
+		Status: olmv1.ClusterCatalogStatus{
+			Conditions: []metav1.Condition{{Type: olmv1.TypeServing, Status: serving}},
+		},
+	}
+
+	if serving == metav1.ConditionTrue {
+		clusterCatalog.Status.URLs = &olmv1.ClusterCatalogURLs{Base: defaultClusterCatalogURL}
+
+		return clusterCatalog
+	}
+
+	clusterCatalog.Status.Conditions = append(clusterCatalog.Status.Conditions, metav1.Condition{
+		Type: olmv1.TypeProgressing, Status: metav1.ConditionTrue, Reason: olmv1.ReasonRetrying,
+		Message: "failed to pull image",
+	})
+
+	return clusterCatalog
+}
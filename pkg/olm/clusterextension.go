@@ -0,0 +1,450 @@
+package olm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/golang/glog"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/msg"
+	olmv1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/olm/olmv1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterExtensionBuilder provides a struct for the OLM v1 clusterextension object from the cluster and a
+// clusterextension definition.
+type ClusterExtensionBuilder struct {
+	// ClusterExtension definition. Used to create a clusterextension object with minimum set of required elements.
+	Definition *olmv1.ClusterExtension
+	// Created clusterextension object on the cluster.
+	Object *olmv1.ClusterExtension
+	// api client to interact with the cluster.
+	apiClient runtimeClient.Client
+	// errorMsg is processed before ClusterExtensionBuilder object is created.
+	errorMsg string
+}
+
+// NewClusterExtensionBuilder creates a new instance of ClusterExtensionBuilder installing packageName from the
+// clustercatalogs into nsname. The serviceAccount must exist in nsname and is used to manage the extension content.
+func NewClusterExtensionBuilder(
+	apiClient *clients.Settings, name, nsname, packageName, serviceAccount string) *ClusterExtensionBuilder {
+	glog.V(100).Infof("Initializing new clusterextension %s structure for package %s in namespace %s "+
+		"with serviceaccount %s", name, packageName, nsname, serviceAccount)
+
+	if apiClient == nil {
+		glog.V(100).Infof("The apiClient cannot be nil")
+
+		return nil
+	}
+
+	err := apiClient.AttachScheme(olmv1.AddToScheme)
+	if err != nil {
+		glog.V(100).Infof("Failed to add olmv1 scheme to client schemes")
+
+		return nil
+	}
+
+	builder := &ClusterExtensionBuilder{
+		apiClient: apiClient.Client,
+		Definition: &olmv1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: olmv1.ClusterExtensionSpec{
+				Namespace:      nsname,
+				ServiceAccount: olmv1.ServiceAccountReference{Name: serviceAccount},
+				Source: olmv1.SourceConfig{
+					SourceType: olmv1.SourceTypeCatalog,
+					Catalog:    &olmv1.CatalogFilter{PackageName: packageName},
+				},
+			},
+		},
+	}
+
+	if name == "" {
+		glog.V(100).Infof("The name of the clusterextension is empty")
+
+		builder.errorMsg = "clusterextension 'name' cannot be empty"
+
+		return builder
+	}
+
+	if nsname == "" {
+		glog.V(100).Infof("The namespace of the clusterextension is empty")
+
+		builder.errorMsg = "clusterextension 'nsname' cannot be empty"
+
+		return builder
+	}
+
+	if packageName == "" {
+		glog.V(100).Infof("The packageName of the clusterextension is empty")
+
+		builder.errorMsg = "clusterextension 'packageName' cannot be empty"
+
+		return builder
+	}
+
+	if serviceAccount == "" {
+		glog.V(100).Infof("The serviceAccount of the clusterextension is empty")
+
+		builder.errorMsg = "clusterextension 'serviceAccount' cannot be empty"
+
+		return builder
+	}
+
+	return builder
+}
+
+// PullClusterExtension loads an existing clusterextension into the ClusterExtensionBuilder struct.
+func PullClusterExtension(apiClient *clients.Settings, name string) (*ClusterExtensionBuilder, error) {
+	glog.V(100).Infof("Pulling existing clusterextension %s", name)
+
+	if apiClient == nil {
+		glog.V(100).Infof("The apiClient cannot be nil")
+
+		return nil, fmt.Errorf("clusterextension 'apiClient' cannot be empty")
+	}
+
+	err := apiClient.AttachScheme(olmv1.AddToScheme)
+	if err != nil {
+		glog.V(100).Infof("Failed to add olmv1 scheme to client schemes")
+
+		return nil, err
+	}
+
+	builder := &ClusterExtensionBuilder{
+		apiClient: apiClient.Client,
+		Definition: &olmv1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+		},
+	}
+
+	if name == "" {
+		glog.V(100).Infof("The name of the clusterextension is empty")
+
+		return nil, fmt.Errorf("clusterextension 'name' cannot be empty")
+	}
+
+	if !builder.Exists() {
+		return nil, fmt.Errorf("clusterextension object %s does not exist", name)
+	}
+
+	builder.Definition = builder.Object
+
+	return builder, nil
+}
+
+// WithChannels restricts the bundles that can be installed to the ones in the provided channels.
+func (builder *ClusterExtensionBuilder) WithChannels(channels ...string) *ClusterExtensionBuilder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Setting clusterextension %s channels to %v", builder.Definition.Name, channels)
+
+	if len(channels) == 0 {
+		glog.V(100).Infof("The clusterextension channels are empty")
+
+		builder.errorMsg = "clusterextension 'channels' cannot be empty"
+
+		return builder
+	}
+
+	for _, channel := range channels {
+		if channel == "" {
+			glog.V(100).Infof("The clusterextension channels contain an empty channel")
+
+			builder.errorMsg = "clusterextension 'channels' cannot contain an empty channel"
+
+			return builder
+		}
+	}
+
+	builder.getCatalogFilter().Channels = channels
+
+	return builder
+}
+
+// WithVersion restricts the bundles that can be installed to the ones satisfying the semver version or version
+// range, for example 1.2.3 or ">=1.2.0, <1.3.0".
+func (builder *ClusterExtensionBuilder) WithVersion(version string) *ClusterExtensionBuilder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Setting clusterextension %s version to %s", builder.Definition.Name, version)
+
+	if _, err := semver.NewConstraint(version); err != nil {
+		glog.V(100).Infof("The clusterextension version %s is invalid: %v", version, err)
+
+		builder.errorMsg = fmt.Sprintf("clusterextension 'version' %q is not a valid version range", version)
+
+		return builder
+	}
+
+	builder.getCatalogFilter().Version = version
+
+	return builder
+}
+
+// WithUpgradeConstraintPolicy sets whether upgrades must follow the edges defined in the catalog.
+func (builder *ClusterExtensionBuilder) WithUpgradeConstraintPolicy(
+	policy olmv1.UpgradeConstraintPolicy) *ClusterExtensionBuilder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Setting clusterextension %s upgrade constraint policy to %s", builder.Definition.Name, policy)
+
+	if policy != olmv1.UpgradeConstraintPolicyCatalogProvided && policy != olmv1.UpgradeConstraintPolicySelfCertified {
+		glog.V(100).Infof("The clusterextension upgrade constraint policy %s is not supported", policy)
+
+		builder.errorMsg = fmt.Sprintf("clusterextension upgrade constraint policy %q is not supported", policy)
+
+		return builder
+	}
+
+	builder.getCatalogFilter().UpgradeConstraintPolicy = policy
+
+	return builder
+}
+
+// WithCatalogSelector restricts the clustercatalogs the bundle is selected from to the ones matching the labels.
+func (builder *ClusterExtensionBuilder) WithCatalogSelector(selector map[string]string) *ClusterExtensionBuilder {
+	if valid, _ := builder.validate(); !valid {
+		return builder
+	}
+
+	glog.V(100).Infof("Setting clusterextension %s catalog selector to %v", builder.Definition.Name, selector)
+
+	if len(selector) == 0 {
+		glog.V(100).Infof("The clusterextension catalog selector is empty")
+
+		builder.errorMsg = "clusterextension 'selector' cannot be empty"
+
+		return builder
+	}
+
+	builder.getCatalogFilter().Selector = &metav1.LabelSelector{MatchLabels: selector}
+
+	return builder
+}
+
+// Get returns the clusterextension object if found.
+func (builder *ClusterExtensionBuilder) Get() (*olmv1.ClusterExtension, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting clusterextension %s", builder.Definition.Name)
+
+	clusterExtension := &olmv1.ClusterExtension{}
+
+	err := builder.apiClient.Get(
+		context.TODO(), runtimeClient.ObjectKey{Name: builder.Definition.Name}, clusterExtension)
+	if err != nil {
+		glog.V(100).Infof("Failed to get clusterextension %s: %v", builder.Definition.Name, err)
+
+		return nil, err
+	}
+
+	return clusterExtension, nil
+}
+
+// Exists checks whether the given clusterextension exists.
+func (builder *ClusterExtensionBuilder) Exists() bool {
+	if valid, _ := builder.validate(); !valid {
+		return false
+	}
+
+	glog.V(100).Infof("Checking if clusterextension %s exists", builder.Definition.Name)
+
+	var err error
+	builder.Object, err = builder.Get()
+
+	return err == nil || !k8serrors.IsNotFound(err)
+}
+
+// Create makes a clusterextension in the cluster and stores the created object in struct.
+func (builder *ClusterExtensionBuilder) Create() (*ClusterExtensionBuilder, error) {
+	if valid, err := builder.validate(); !valid {
+		return builder, err
+	}
+
+	glog.V(100).Infof("Creating the clusterextension %s", builder.Definition.Name)
+
+	if builder.Exists() {
+		return builder, nil
+	}
+
+	err := builder.apiClient.Create(context.TODO(), builder.Definition)
+	if err != nil {
+		return builder, err
+	}
+
+	builder.Object = builder.Definition
+
+	return builder, nil
+}
+
+// Update renovates the existing clusterextension object with the clusterextension definition in builder. Changing
+// the version range or channels triggers an upgrade of the installed bundle.
+func (builder *ClusterExtensionBuilder) Update() (*ClusterExtensionBuilder, error) {
+	if valid, err := builder.validate(); !valid {
+		return builder, err
+	}
+
+	glog.V(100).Infof("Updating the clusterextension %s", builder.Definition.Name)
+
+	if !builder.Exists() {
+		glog.V(100).Infof("Clusterextension %s does not exist", builder.Definition.Name)
+
+		return builder, fmt.Errorf("failed to update clusterextension, object does not exist on cluster")
+	}
+
+	builder.Definition.ResourceVersion = builder.Object.ResourceVersion
+
+	err := builder.apiClient.Update(context.TODO(), builder.Definition)
+	if err != nil {
+		return builder, err
+	}
+
+	builder.Object = builder.Definition
+
+	return builder, nil
+}
+
+// Delete removes the clusterextension from the cluster, which uninstalls its content.
+func (builder *ClusterExtensionBuilder) Delete() error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Deleting clusterextension %s", builder.Definition.Name)
+
+	if !builder.Exists() {
+		glog.V(100).Infof("Clusterextension %s cannot be deleted because it does not exist", builder.Definition.Name)
+
+		builder.Object = nil
+
+		return nil
+	}
+
+	err := builder.apiClient.Delete(context.TODO(), builder.Definition)
+	if err != nil {
+		return err
+	}
+
+	builder.Object = nil
+
+	return nil
+}
+
+// WaitUntilInstalled waits up to timeout for the clusterextension to install a bundle. The reason and message of the
+// Progressing condition are included in the error so resolution failures are reported.
+func (builder *ClusterExtensionBuilder) WaitUntilInstalled(timeout time.Duration) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Waiting up to %s for clusterextension %s to be installed", timeout, builder.Definition.Name)
+
+	var lastCondition *metav1.Condition
+
+	err := wait.PollUntilContextTimeout(
+		context.TODO(), time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+			if !builder.Exists() || builder.Object == nil {
+				return false, nil
+			}
+
+			if progressing := meta.FindStatusCondition(
+				builder.Object.Status.Conditions, olmv1.TypeProgressing); progressing != nil {
+				lastCondition = progressing
+			}
+
+			return meta.IsStatusConditionTrue(builder.Object.Status.Conditions, olmv1.TypeInstalled), nil
+		})
+	if err != nil {
+		if lastCondition != nil {
+			return fmt.Errorf("clusterextension %s is not installed (%s: %s): %w",
+				builder.Definition.Name, lastCondition.Reason, lastCondition.Message, err)
+		}
+
+		return fmt.Errorf("clusterextension %s is not installed: %w", builder.Definition.Name, err)
+	}
+
+	return nil
+}
+
+// GetInstalledBundle returns the name and version of the bundle installed by the clusterextension.
+func (builder *ClusterExtensionBuilder) GetInstalledBundle() (*olmv1.BundleMetadata, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting the installed bundle of clusterextension %s", builder.Definition.Name)
+
+	if !builder.Exists() || builder.Object == nil {
+		return nil, fmt.Errorf("clusterextension object %s does not exist", builder.Definition.Name)
+	}
+
+	if builder.Object.Status.Install == nil {
+		return nil, fmt.Errorf("clusterextension %s has no bundle installed", builder.Definition.Name)
+	}
+
+	bundle := builder.Object.Status.Install.Bundle
+
+	return &bundle, nil
+}
+
+// getCatalogFilter returns the catalog source of the definition, initializing it if needed.
+func (builder *ClusterExtensionBuilder) getCatalogFilter() *olmv1.CatalogFilter {
+	builder.Definition.Spec.Source.SourceType = olmv1.SourceTypeCatalog
+
+	if builder.Definition.Spec.Source.Catalog == nil {
+		builder.Definition.Spec.Source.Catalog = &olmv1.CatalogFilter{}
+	}
+
+	return builder.Definition.Spec.Source.Catalog
+}
+
+// validate will check that the builder and builder definition are properly initialized before
+// accessing any member fields.
+func (builder *ClusterExtensionBuilder) validate() (bool, error) {
+	resourceCRD := "clusterextension"
+
+	if builder == nil {
+		glog.V(100).Infof("The %s builder is uninitialized", resourceCRD)
+
+		return false, fmt.Errorf("error: received nil %s builder", resourceCRD)
+	}
+
+	if builder.Definition == nil {
+		glog.V(100).Infof("The %s is undefined", resourceCRD)
+
+		return false, fmt.Errorf("%s", msg.UndefinedCrdObjectErrString(resourceCRD))
+	}
+
+	if builder.apiClient == nil {
+		glog.V(100).Infof("The %s builder apiclient is nil", resourceCRD)
+
+		return false, fmt.Errorf("%s builder cannot have nil apiClient", resourceCRD)
+	}
+
+	if builder.errorMsg != "" {
+		glog.V(100).Infof("The %s builder has error message: %s", resourceCRD, builder.errorMsg)
+
+		return false, fmt.Errorf("%s", builder.errorMsg)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,312 @@
+package olm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	olmv1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/olm/olmv1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	defaultClusterExtensionName           = "test-extension"
+	defaultClusterExtensionNamespace      = "test-extension-ns"
+	defaultClusterExtensionPackage        = "test-operator"
+	defaultClusterExtensionServiceAccount = "test-installer"
+)
+
+func TestNewClusterExtensionBuilder(t *testing.T) {
+	testCases := []struct {
+		name           string
+		nsname         string
+		packageName    string
+		serviceAccount string
+		client         bool
+		expectedError  string
+	}{
+		{
+			name:           defaultClusterExtensionName,
+			nsname:         defaultClusterExtensionNamespace,
+			packageName:    defaultClusterExtensionPackage,
+			serviceAccount: defaultClusterExtensionServiceAccount,
+			client:         true,
+			expectedError:  "",
+		},
+		{
+			name:           "",
+			nsname:         defaultClusterExtensionNamespace,
+			packageName:    defaultClusterExtensionPackage,
+			serviceAccount: defaultClusterExtensionServiceAccount,
+			client:         true,
+			expectedError:  "clusterextension 'name' cannot be empty",
+		},
+		{
+			name:           defaultClusterExtensionName,
+			nsname:         "",
+			packageName:    defaultClusterExtensionPackage,
+			serviceAccount: defaultClusterExtensionServiceAccount,
+			client:         true,
+			expectedError:  "clusterextension 'nsname' cannot be empty",
+		},
+		{
+			name:           defaultClusterExtensionName,
+			nsname:         defaultClusterExtensionNamespace,
+			packageName:    "",
+			serviceAccount: defaultClusterExtensionServiceAccount,
+			client:         true,
+			expectedError:  "clusterextension 'packageName' cannot be empty",
+		},
+		{
+			name:           defaultClusterExtensionName,
+			nsname:         defaultClusterExtensionNamespace,
+			packageName:    defaultClusterExtensionPackage,
+			serviceAccount: "",
+			client:         true,
+			expectedError:  "clusterextension 'serviceAccount' cannot be empty",
+		},
+		{
+			name:           defaultClusterExtensionName,
+			nsname:         defaultClusterExtensionNamespace,
+			packageName:    defaultClusterExtensionPackage,
+			serviceAccount: defaultClusterExtensionServiceAccount,
+			client:         false,
+			expectedError:  "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		var testSettings *clients.Settings
+
+		if testCase.client {
+			testSettings = clients.GetTestClients(clients.TestClientParams{SchemeAttachers: olmv1TestSchemes})
+		}
+
+		testBuilder := NewClusterExtensionBuilder(
+			testSettings, testCase.name, testCase.nsname, testCase.packageName, testCase.serviceAccount)
+
+		if !testCase.client {
+			assert.Nil(t, testBuilder)
+
+			continue
+		}
+
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+		assert.Equal(t, olmv1.SourceTypeCatalog, testBuilder.Definition.Spec.Source.SourceType)
+		assert.Equal(t, testCase.packageName, testBuilder.Definition.Spec.Source.Catalog.PackageName)
+		assert.Equal(t, testCase.serviceAccount, testBuilder.Definition.Spec.ServiceAccount.Name)
+	}
+}
+
+func TestPullClusterExtension(t *testing.T) {
+	testCases := []struct {
+		name          string
+		exists        bool
+		client        bool
+		expectedError error
+	}{
+		{
+			name:          defaultClusterExtensionName,
+			exists:        true,
+			client:        true,
+			expectedError: nil,
+		},
+		{
+			name:          "",
+			exists:        true,
+			client:        true,
+			expectedError: fmt.Errorf("clusterextension 'name' cannot be empty"),
+		},
+		{
+			name:          defaultClusterExtensionName,
+			exists:        false,
+			client:        true,
+			expectedError: fmt.Errorf("clusterextension object %s does not exist", defaultClusterExtensionName),
+		},
+		{
+			name:          defaultClusterExtensionName,
+			exists:        true,
+			client:        false,
+			expectedError: fmt.Errorf("clusterextension 'apiClient' cannot be empty"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var (
+			runtimeObjects []runtime.Object
+			testSettings   *clients.Settings
+		)
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyClusterExtension(metav1.ConditionTrue))
+		}
+
+		if testCase.client {
+			testSettings = clients.GetTestClients(clients.TestClientParams{
+				K8sMockObjects:  runtimeObjects,
+				SchemeAttachers: olmv1TestSchemes,
+			})
+		}
+
+		testBuilder, err := PullClusterExtension(testSettings, testCase.name)
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, testCase.name, testBuilder.Definition.Name)
+		}
+	}
+}
+
+func TestClusterExtensionWithOptions(t *testing.T) {
+	testCases := []struct {
+		channels      []string
+		version       string
+		policy        olmv1.UpgradeConstraintPolicy
+		expectedError string
+	}{
+		{
+			channels:      []string{"stable"},
+			version:       ">=1.0.0, <2.0.0",
+			policy:        olmv1.UpgradeConstraintPolicySelfCertified,
+			expectedError: "",
+		},
+		{
+			channels:      []string{},
+			version:       "1.0.0",
+			policy:        olmv1.UpgradeConstraintPolicyCatalogProvided,
+			expectedError: "clusterextension 'channels' cannot be empty",
+		},
+		{
+			channels:      []string{"stable", ""},
+			version:       "1.0.0",
+			policy:        olmv1.UpgradeConstraintPolicyCatalogProvided,
+			expectedError: "clusterextension 'channels' cannot contain an empty channel",
+		},
+		{
+			channels:      []string{"stable"},
+			version:       "not-a-range",
+			policy:        olmv1.UpgradeConstraintPolicyCatalogProvided,
+			expectedError: "clusterextension 'version' \"not-a-range\" is not a valid version range",
+		},
+		{
+			channels:      []string{"stable"},
+			version:       "1.0.0",
+			policy:        "Unknown",
+			expectedError: "clusterextension upgrade constraint policy \"Unknown\" is not supported",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := clients.GetTestClients(clients.TestClientParams{SchemeAttachers: olmv1TestSchemes})
+		testBuilder := buildValidClusterExtensionBuilder(testSettings).
+			WithChannels(testCase.channels...).
+			WithVersion(testCase.version).
+			WithUpgradeConstraintPolicy(testCase.policy).
+			WithCatalogSelector(map[string]string{olmv1.MetadataNameLabel: defaultClusterCatalogName})
+
+		assert.Equal(t, testCase.expectedError, testBuilder.errorMsg)
+
+		if testCase.expectedError == "" {
+			catalogFilter := testBuilder.Definition.Spec.Source.Catalog
+			assert.Equal(t, testCase.channels, catalogFilter.Channels)
+			assert.Equal(t, testCase.version, catalogFilter.Version)
+			assert.Equal(t, testCase.policy, catalogFilter.UpgradeConstraintPolicy)
+			assert.Equal(t, defaultClusterCatalogName, catalogFilter.Selector.MatchLabels[olmv1.MetadataNameLabel])
+		}
+	}
+}
+
+func TestClusterExtensionCreateAndDelete(t *testing.T) {
+	testSettings := clients.GetTestClients(clients.TestClientParams{SchemeAttachers: olmv1TestSchemes})
+
+	testBuilder, err := buildValidClusterExtensionBuilder(testSettings).Create()
+	assert.Nil(t, err)
+	assert.True(t, testBuilder.Exists())
+
+	testBuilder, err = testBuilder.WithVersion("1.1.0").Update()
+	assert.Nil(t, err)
+	assert.Equal(t, "1.1.0", testBuilder.Object.Spec.Source.Catalog.Version)
+
+	err = testBuilder.Delete()
+	assert.Nil(t, err)
+	assert.False(t, testBuilder.Exists())
+}
+
+func TestClusterExtensionWaitUntilInstalled(t *testing.T) {
+	testCases := []struct {
+		installed      metav1.ConditionStatus
+		expectedBundle *olmv1.BundleMetadata
+		expectedError  error
+	}{
+		{
+			installed:      metav1.ConditionTrue,
+			expectedBundle: &olmv1.BundleMetadata{Name: "test-operator.v1.0.0", Version: "1.0.0"},
+			expectedError:  nil,
+		},
+		{
+			installed: metav1.ConditionFalse,
+			expectedError: fmt.Errorf("clusterextension %s is not installed (Retrying: no bundles found for "+
+				"package %q): %w", defaultClusterExtensionName, defaultClusterExtensionPackage, context.DeadlineExceeded),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects:  []runtime.Object{buildDummyClusterExtension(testCase.installed)},
+			SchemeAttachers: olmv1TestSchemes,
+		})
+
+		testBuilder, err := PullClusterExtension(testSettings, defaultClusterExtensionName)
+		assert.Nil(t, err)
+
+		err = testBuilder.WaitUntilInstalled(time.Second)
+		assert.Equal(t, testCase.expectedError, err)
+
+		bundle, err := testBuilder.GetInstalledBundle()
+		assert.Equal(t, testCase.expectedBundle, bundle)
+		assert.Equal(t, testCase.expectedBundle == nil, err != nil)
+	}
+}
+
+func buildValidClusterExtensionBuilder(apiClient *clients.Settings) *ClusterExtensionBuilder {
+	return NewClusterExtensionBuilder(apiClient, defaultClusterExtensionName, defaultClusterExtensionNamespace,
+		defaultClusterExtensionPackage, defaultClusterExtensionServiceAccount)
+}
+
+// buildDummyClusterExtension returns a clusterextension with the provided Installed condition. An installed
+// extension reports its bundle while other extensions report a Retrying Progressing condition.
+func buildDummyClusterExtension(installed metav1.ConditionStatus) *olmv1.ClusterExtension {
+	clusterExtension := &olmv1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultClusterExtensionName},
+		Spec: olmv1.ClusterExtensionSpec{
+			Namespace:      defaultClusterExtensionNamespace,
+			ServiceAccount: olmv1.ServiceAccountReference{Name: defaultClusterExtensionServiceAccount},
+			Source: olmv1.SourceConfig{
+				SourceType: olmv1.SourceTypeCatalog,
+				Catalog:    &olmv1.CatalogFilter{PackageName: defaultClusterExtensionPackage},
+			},
+		},
+		Status: olmv1.ClusterExtensionStatus{
+			Conditions: []metav1.Condition{{Type: olmv1.TypeInstalled, Status: installed}},
+		},
+	}
+
+	if installed == metav1.ConditionTrue {
+		clusterExtension.Status.Install = &olmv1.ClusterExtensionInstallStatus{
+			Bundle: olmv1.BundleMetadata{Name: "test-operator.v1.0.0", Version: "1.0.0"},
+		}
+
+		return clusterExtension
+	}
+
+	clusterExtension.Status.Conditions = append(clusterExtension.Status.Conditions, metav1.Condition{
+		Type: olmv1.TypeProgressing, Status: metav1.ConditionTrue, Reason: olmv1.ReasonRetrying,
+		Message: fmt.Sprintf("no bundles found for package %q", defaultClusterExtensionPackage),
+	})
+
+	return clusterExtension
+}
@@ -0,0 +1,264 @@
+package olm
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/golang/glog"
+)
+
+const (
+	fbcSchemaPackage   = "olm.package"
+	fbcSchemaChannel   = "olm.channel"
+	fbcSchemaBundle    = "olm.bundle"
+	fbcPropertyPackage = "olm.package"
+)
+
+// FBCPackage is an olm.package entry of a File-Based Catalog.
+type FBCPackage struct {
+	Name           string `json:"name"`
+	DefaultChannel string `json:"defaultChannel,omitempty"`
+	Description    string `json:"description,omitempty"`
+}
+
+// FBCChannelEntry is a bundle of a channel along with its upgrade edges.
+type FBCChannelEntry struct {
+	Name      string   `json:"name"`
+	Replaces  string   `json:"replaces,omitempty"`
+	Skips     []string `json:"skips,omitempty"`
+	SkipRange string   `json:"skipRange,omitempty"`
+}
+
+// FBCChannel is an olm.channel entry of a File-Based Catalog.
+type FBCChannel struct {
+	Name    string            `json:"name"`
+	Package string            `json:"package"`
+	Entries []FBCChannelEntry `json:"entries"`
+}
+
+// FBCBundle is an olm.bundle entry of a File-Based Catalog. The version is taken from the olm.package property of
+// the bundle.
+type FBCBundle struct {
+	Name    string
+	Package string
+	Image   string
+	Version string
+}
+
+// FileBasedCatalog contains the packages, channels and bundles of a File-Based Catalog, as served by a
+// clustercatalog.
+type FileBasedCatalog struct {
+	Packages []FBCPackage
+	Channels []FBCChannel
+	Bundles  []FBCBundle
+}
+
+// fbcMeta is the union of the fields of the FBC schemas that are decoded.
+type fbcMeta struct {
+	Schema string `json:"schema"`
+	FBCPackage
+	Package    string            `json:"package"`
+	Entries    []FBCChannelEntry `json:"entries"`
+	Image      string            `json:"image"`
+	Properties []fbcProperty     `json:"properties"`
+}
+
+// fbcProperty is a typed property of an FBC bundle.
+type fbcProperty struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// fbcPackageProperty is the value of the olm.package property of an FBC bundle.
+type fbcPackageProperty struct {
+	PackageName string `json:"packageName"`
+	Version     string `json:"version"`
+}
+
+// ParseFileBasedCatalog decodes a stream of FBC JSON objects, such as the one served under the api/v1/all endpoint
+// of a clustercatalog. Objects with schemas other than olm.package, olm.channel and olm.bundle are skipped.
+func ParseFileBasedCatalog(reader io.Reader) (*FileBasedCatalog, error) {
+	if reader == nil {
+		glog.V(100).Infof("The FBC reader is nil")
+
+		return nil, fmt.Errorf("file-based catalog 'reader' cannot be nil")
+	}
+
+	catalog := &FileBasedCatalog{}
+	decoder := json.NewDecoder(reader)
+
+	for {
+		var meta fbcMeta
+
+		err := decoder.Decode(&meta)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode file-based catalog: %w", err)
+		}
+
+		switch meta.Schema {
+		case fbcSchemaPackage:
+			catalog.Packages = append(catalog.Packages, meta.FBCPackage)
+		case fbcSchemaChannel:
+			catalog.Channels = append(catalog.Channels,
+				FBCChannel{Name: meta.Name, Package: meta.Package, Entries: meta.Entries})
+		case fbcSchemaBundle:
+			bundle, err := newFBCBundle(meta)
+			if err != nil {
+				return nil, err
+			}
+
+			catalog.Bundles = append(catalog.Bundles, bundle)
+		default:
+			glog.V(100).Infof("Skipping FBC object %s with schema %q", meta.Name, meta.Schema)
+		}
+	}
+
+	return catalog, nil
+}
+
+// GetPackage returns the package called packageName, or nil if it is not in the catalog.
+func (catalog *FileBasedCatalog) GetPackage(packageName string) *FBCPackage {
+	if catalog == nil {
+		return nil
+	}
+
+	for index := range catalog.Packages {
+		if catalog.Packages[index].Name == packageName {
+			return &catalog.Packages[index]
+		}
+	}
+
+	return nil
+}
+
+// GetChannels returns the channels of the package called packageName.
+func (catalog *FileBasedCatalog) GetChannels(packageName string) []FBCChannel {
+	if catalog == nil {
+		return nil
+	}
+
+	var channels []FBCChannel
+
+	for _, channel := range catalog.Channels {
+		if channel.Package == packageName {
+			channels = append(channels, channel)
+		}
+	}
+
+	return channels
+}
+
+// GetBundles returns the bundles of the package called packageName, sorted by ascending version. When channel is not
+// empty, only the bundles of that channel are returned. When versionRange is not empty, only the bundles whose
+// version satisfies the semver constraint are returned, using the same syntax as the ClusterExtension version.
+func (catalog *FileBasedCatalog) GetBundles(packageName, channel, versionRange string) ([]FBCBundle, error) {
+	if catalog == nil {
+		glog.V(100).Infof("The file-based catalog is nil")
+
+		return nil, fmt.Errorf("file-based catalog cannot be nil")
+	}
+
+	glog.V(100).Infof("Getting bundles of package %s in channel %q within version range %q",
+		packageName, channel, versionRange)
+
+	var constraint *semver.Constraints
+
+	if versionRange != "" {
+		var err error
+
+		constraint, err = semver.NewConstraint(versionRange)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version range %q: %w", versionRange, err)
+		}
+	}
+
+	var channelBundles []string
+
+	if channel != "" {
+		index := slices.IndexFunc(catalog.Channels, func(fbcChannel FBCChannel) bool {
+			return fbcChannel.Package == packageName && fbcChannel.Name == channel
+		})
+
+		if index < 0 {
+			return nil, fmt.Errorf("channel %s of package %s not found in file-based catalog", channel, packageName)
+		}
+
+		for _, entry := range catalog.Channels[index].Entries {
+			channelBundles = append(channelBundles, entry.Name)
+		}
+	}
+
+	var bundles []FBCBundle
+
+	for _, bundle := range catalog.Bundles {
+		if bundle.Package != packageName || (channel != "" && !slices.Contains(channelBundles, bundle.Name)) {
+			continue
+		}
+
+		if constraint != nil {
+			version, err := semver.NewVersion(bundle.Version)
+			if err != nil || !constraint.Check(version) {
+				continue
+			}
+		}
+
+		bundles = append(bundles, bundle)
+	}
+
+	slices.SortStableFunc(bundles, compareFBCBundleVersions)
+
+	return bundles, nil
+}
+
+// newFBCBundle returns the bundle described by meta, reading the package and version from its olm.package property.
+func newFBCBundle(meta fbcMeta) (FBCBundle, error) {
+	bundle := FBCBundle{Name: meta.Name, Package: meta.Package, Image: meta.Image}
+
+	for _, property := range meta.Properties {
+		if property.Type != fbcPropertyPackage {
+			continue
+		}
+
+		var value fbcPackageProperty
+
+		err := json.Unmarshal(property.Value, &value)
+		if err != nil {
+			return bundle, fmt.Errorf("failed to decode %s property of bundle %s: %w", fbcPropertyPackage, meta.Name, err)
+		}
+
+		bundle.Version = value.Version
+
+		if bundle.Package == "" {
+			bundle.Package = value.PackageName
+		}
+	}
+
+	return bundle, nil
+}
+
+// compareFBCBundleVersions orders bundles by semantic version. Bundles without a valid version are sorted last, by
+// name.
+func compareFBCBundleVersions(first, second FBCBundle) int {
+	firstVersion, firstErr := semver.NewVersion(first.Version)
+	secondVersion, secondErr := semver.NewVersion(second.Version)
+
+	switch {
+	case firstErr == nil && secondErr == nil:
+		return firstVersion.Compare(secondVersion)
+	case firstErr == nil:
+		return -1
+	case secondErr == nil:
+		return 1
+	default:
+		return cmp.Compare(first.Name, second.Name)
+	}
+}
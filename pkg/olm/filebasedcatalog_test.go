@@ -0,0 +1,127 @@
+package olm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const defaultFBCContent = `{"schema":"olm.package","name":"test-operator","defaultChannel":"stable"}
+{"schema":"olm.channel","name":"stable","package":"test-operator","entries":[
+	{"name":"test-operator.v1.0.0"},{"name":"test-operator.v1.1.0","replaces":"test-operator.v1.0.0"}]}
+{"schema":"olm.channel","name":"candidate","package":"test-operator","entries":[
+	{"name":"test-operator.v1.1.0"},{"name":"test-operator.v2.0.0","replaces":"test-operator.v1.1.0"}]}
+{"schema":"olm.bundle","name":"test-operator.v2.0.0","package":"test-operator","image":"quay.io/test/bundle:v2.0.0",
+	"properties":[{"type":"olm.package","value":{"packageName":"test-operator","version":"2.0.0"}}]}
+{"schema":"olm.bundle","name":"test-operator.v1.1.0","package":"test-operator","image":"quay.io/test/bundle:v1.1.0",
+	"properties":[{"type":"olm.gvk","value":{}},
+		{"type":"olm.package","value":{"packageName":"test-operator","version":"1.1.0"}}]}
+{"schema":"olm.bundle","name":"test-operator.v1.0.0","package":"test-operator","image":"quay.io/test/bundle:v1.0.0",
+	"properties":[{"type":"olm.package","value":{"packageName":"test-operator","version":"1.0.0"}}]}
+{"schema":"olm.deprecations","package":"test-operator"}
+{"schema":"olm.package","name":"other-operator","defaultChannel":"alpha"}
+`
+
+func TestParseFileBasedCatalog(t *testing.T) {
+	testCases := []struct {
+		content          string
+		expectedPackages int
+		expectedChannels int
+		expectedBundles  int
+		expectedError    string
+	}{
+		{
+			content:          defaultFBCContent,
+			expectedPackages: 2,
+			expectedChannels: 2,
+			expectedBundles:  3,
+			expectedError:    "",
+		},
+		{
+			content:       `{"schema":"olm.package",`,
+			expectedError: "failed to decode file-based catalog: unexpected EOF",
+		},
+		{
+			content: `{"schema":"olm.bundle","name":"bad","properties":[{"type":"olm.package","value":[]}]}`,
+			expectedError: "failed to decode olm.package property of bundle bad: " +
+				"json: cannot unmarshal array into Go value of type olm.fbcPackageProperty",
+		},
+	}
+
+	for _, testCase := range testCases {
+		catalog, err := ParseFileBasedCatalog(strings.NewReader(testCase.content))
+
+		if testCase.expectedError != "" {
+			assert.EqualError(t, err, testCase.expectedError)
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Len(t, catalog.Packages, testCase.expectedPackages)
+		assert.Len(t, catalog.Channels, testCase.expectedChannels)
+		assert.Len(t, catalog.Bundles, testCase.expectedBundles)
+		assert.Equal(t, "stable", catalog.GetPackage("test-operator").DefaultChannel)
+		assert.Nil(t, catalog.GetPackage("missing"))
+		assert.Len(t, catalog.GetChannels("test-operator"), 2)
+	}
+}
+
+func TestFileBasedCatalogGetBundles(t *testing.T) {
+	testCases := []struct {
+		channel         string
+		versionRange    string
+		expectedBundles []string
+		expectedError   error
+	}{
+		{
+			expectedBundles: []string{"test-operator.v1.0.0", "test-operator.v1.1.0", "test-operator.v2.0.0"},
+		},
+		{
+			channel:         "stable",
+			expectedBundles: []string{"test-operator.v1.0.0", "test-operator.v1.1.0"},
+		},
+		{
+			channel:         "candidate",
+			versionRange:    ">=1.1.0, <2.0.0",
+			expectedBundles: []string{"test-operator.v1.1.0"},
+		},
+		{
+			versionRange:    "~1.0",
+			expectedBundles: []string{"test-operator.v1.0.0"},
+		},
+		{
+			channel:       "missing",
+			expectedError: fmt.Errorf("channel missing of package test-operator not found in file-based catalog"),
+		},
+		{
+			versionRange:  "not-a-range",
+			expectedError: fmt.Errorf("invalid version range \"not-a-range\": improper constraint: not-a-range"),
+		},
+	}
+
+	catalog, err := ParseFileBasedCatalog(strings.NewReader(defaultFBCContent))
+	assert.Nil(t, err)
+
+	for _, testCase := range testCases {
+		bundles, err := catalog.GetBundles("test-operator", testCase.channel, testCase.versionRange)
+
+		if testCase.expectedError != nil {
+			assert.EqualError(t, err, testCase.expectedError.Error())
+
+			continue
+		}
+
+		assert.Nil(t, err)
+
+		var bundleNames []string
+
+		for _, bundle := range bundles {
+			bundleNames = append(bundleNames, bundle.Name)
+		}
+
+		assert.Equal(t, testCase.expectedBundles, bundleNames)
+	}
+}
@@ -0,0 +1,518 @@
+package olm
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/namespace"
+	operatorsv1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/olm/operators/v1"
+	operatorsV1alpha1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/olm/operators/v1alpha1"
+	operatorv1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/olm/package-server/operators/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultCatalogSourceNamespace is the namespace of the default OLM catalog sources and their PackageManifests.
+const DefaultCatalogSourceNamespace = "openshift-marketplace"
+
+// OperatorInstaller installs and uninstalls an operator package through OLM by creating its namespace,
+// OperatorGroup and Subscription, approving its InstallPlan and waiting for its ClusterServiceVersion.
+type OperatorInstaller struct {
+	// PackageName is the name of the operator package in the catalog.
+	PackageName string
+	// Namespace is the namespace the operator is installed in.
+	Namespace string
+	// catalogSource is the CatalogSource to install from. If empty, it is taken from the PackageManifest.
+	catalogSource string
+	// catalogSourceNamespace is the namespace of the CatalogSource and the PackageManifest.
+	catalogSourceNamespace string
+	// channel to subscribe to. If empty, the default channel of the package is used.
+	channel string
+	// startingCSV to install. If empty, the current CSV of the channel is used.
+	startingCSV string
+	// targetNamespaces of the OperatorGroup. If nil, they are derived from the supported install modes.
+	targetNamespaces []string
+	// approval is the InstallPlan approval of the Subscription.
+	approval operatorsV1alpha1.Approval
+	// api client to interact with the cluster.
+	apiClient *clients.Settings
+	// errorMsg is processed before the operator is installed.
+	errorMsg string
+}
+
+// OperatorUninstallOptions configures which resources OperatorInstaller.Uninstall removes besides the Subscription
+// and the ClusterServiceVersion.
+type OperatorUninstallOptions struct {
+	// DeleteCRDs removes the CustomResourceDefinitions owned by the ClusterServiceVersion.
+	DeleteCRDs bool
+	// DeleteNamespace removes the operator namespace, including its OperatorGroup, and waits for it to be gone.
+	DeleteNamespace bool
+	// Timeout bounds the wait for the namespace removal. It must be greater than zero when DeleteNamespace is set.
+	Timeout time.Duration
+}
+
+// resolvedPackage is the channel, CSV and install modes resolved from a PackageManifest.
+type resolvedPackage struct {
+	catalogSource          string
+	catalogSourceNamespace string
+	channel                string
+	csvName                string
+	installModes           []operatorsV1alpha1.InstallMode
+}
+
+// NewOperatorInstaller creates a new instance of OperatorInstaller for the package in the namespace.
+func NewOperatorInstaller(apiClient *clients.Settings, packageName, nsname string) *OperatorInstaller {
+	glog.V(100).Infof("Initializing new OperatorInstaller for package %s in namespace %s", packageName, nsname)
+
+	if apiClient == nil {
+		glog.V(100).Infof("The apiClient cannot be nil")
+
+		return nil
+	}
+
+	installer := &OperatorInstaller{
+		PackageName:            packageName,
+		Namespace:              nsname,
+		catalogSourceNamespace: DefaultCatalogSourceNamespace,
+		approval:               operatorsV1alpha1.ApprovalAutomatic,
+		apiClient:              apiClient,
+	}
+
+	if packageName == "" {
+		glog.V(100).Infof("The packageName of the OperatorInstaller is empty")
+
+		installer.errorMsg = "operatorInstaller 'packageName' cannot be empty"
+
+		return installer
+	}
+
+	if nsname == "" {
+		glog.V(100).Infof("The nsname of the OperatorInstaller is empty")
+
+		installer.errorMsg = "operatorInstaller 'nsname' cannot be empty"
+
+		return installer
+	}
+
+	return installer
+}
+
+// WithCatalogSource sets the CatalogSource to install the package from and the namespace of the CatalogSource.
+func (installer *OperatorInstaller) WithCatalogSource(catalogSource, catalogSourceNamespace string) *OperatorInstaller {
+	if valid, _ := installer.validate(); !valid {
+		return installer
+	}
+
+	glog.V(100).Infof("Setting OperatorInstaller catalog source to %s in namespace %s",
+		catalogSource, catalogSourceNamespace)
+
+	if catalogSource == "" || catalogSourceNamespace == "" {
+		installer.errorMsg = "operatorInstaller catalog source name and namespace cannot be empty"
+
+		return installer
+	}
+
+	installer.catalogSource = catalogSource
+	installer.catalogSourceNamespace = catalogSourceNamespace
+
+	return installer
+}
+
+// WithChannel sets the channel to subscribe to instead of the default channel of the package.
+func (installer *OperatorInstaller) WithChannel(channel string) *OperatorInstaller {
+	if valid, _ := installer.validate(); !valid {
+		return installer
+	}
+
+	glog.V(100).Infof("Setting OperatorInstaller channel to %s", channel)
+
+	if channel == "" {
+		installer.errorMsg = "operatorInstaller 'channel' cannot be empty"
+
+		return installer
+	}
+
+	installer.channel = channel
+
+	return installer
+}
+
+// WithStartingCSV sets the ClusterServiceVersion to install instead of the current one of the channel.
+func (installer *OperatorInstaller) WithStartingCSV(startingCSV string) *OperatorInstaller {
+	if valid, _ := installer.validate(); !valid {
+		return installer
+	}
+
+	glog.V(100).Infof("Setting OperatorInstaller starting CSV to %s", startingCSV)
+
+	if startingCSV == "" {
+		installer.errorMsg = "operatorInstaller 'startingCSV' cannot be empty"
+
+		return installer
+	}
+
+	installer.startingCSV = startingCSV
+
+	return installer
+}
+
+// WithTargetNamespaces sets the target namespaces of the OperatorGroup instead of deriving them from the install
+// modes of the package. An empty list targets all namespaces.
+func (installer *OperatorInstaller) WithTargetNamespaces(targetNamespaces []string) *OperatorInstaller {
+	if valid, _ := installer.validate(); !valid {
+		return installer
+	}
+
+	glog.V(100).Infof("Setting OperatorInstaller target namespaces to %v", targetNamespaces)
+
+	installer.targetNamespaces = append([]string{}, targetNamespaces...)
+
+	return installer
+}
+
+// WithManualApproval makes the Subscription use Manual InstallPlan approval. The installer only approves the
+// InstallPlan that installs the resolved CSV, so later upgrades stay pending until approved.
+func (installer *OperatorInstaller) WithManualApproval() *OperatorInstaller {
+	if valid, _ := installer.validate(); !valid {
+		return installer
+	}
+
+	glog.V(100).Infof("Setting OperatorInstaller InstallPlan approval to Manual")
+
+	installer.approval = operatorsV1alpha1.ApprovalManual
+
+	return installer
+}
+
+// Install resolves the channel and CSV from the PackageManifest, creates the namespace, OperatorGroup and
+// Subscription, approves the InstallPlan if approval is Manual and waits up to timeout for the CSV to succeed.
+func (installer *OperatorInstaller) Install(timeout time.Duration) (*ClusterServiceVersionBuilder, error) {
+	if valid, err := installer.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Installing operator package %s in namespace %s", installer.PackageName, installer.Namespace)
+
+	resolved, err := installer.resolvePackage()
+	if err != nil {
+		return nil, err
+	}
+
+	targetNamespaces, err := installer.getTargetNamespaces(resolved.installModes)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = namespace.NewBuilder(installer.apiClient, installer.Namespace).Create()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create namespace %s: %w", installer.Namespace, err)
+	}
+
+	err = installer.ensureOperatorGroup(targetNamespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := NewSubscriptionBuilder(installer.apiClient, installer.PackageName, installer.Namespace,
+		resolved.catalogSource, resolved.catalogSourceNamespace, installer.PackageName).
+		WithChannel(resolved.channel).
+		WithStartingCSV(resolved.csvName).
+		WithInstallPlanApproval(installer.approval).
+		Create()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscription %s: %w", installer.PackageName, err)
+	}
+
+	return waitForSubscriptionCSV(installer.apiClient, subscription, resolved.csvName, timeout)
+}
+
+// Uninstall removes the Subscription and the ClusterServiceVersion it installed, then optionally the CRDs owned by
+// the CSV and the operator namespace.
+func (installer *OperatorInstaller) Uninstall(options OperatorUninstallOptions) error {
+	if valid, err := installer.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Uninstalling operator package %s from namespace %s with options %+v",
+		installer.PackageName, installer.Namespace, options)
+
+	if options.DeleteNamespace && options.Timeout <= 0 {
+		glog.V(100).Infof("The namespace deletion timeout %s is not greater than zero", options.Timeout)
+
+		return fmt.Errorf("timeout must be greater than zero when deleting namespace %s", installer.Namespace)
+	}
+
+	var csvName string
+
+	subscription, err := PullSubscription(installer.apiClient, installer.PackageName, installer.Namespace)
+	if err == nil {
+		csvName = subscription.Object.Status.InstalledCSV
+
+		err = subscription.Delete()
+		if err != nil {
+			return fmt.Errorf("failed to delete subscription %s: %w", installer.PackageName, err)
+		}
+	} else {
+		glog.V(100).Infof("Subscription %s not found in namespace %s, skipping: %v",
+			installer.PackageName, installer.Namespace, err)
+	}
+
+	var ownedCRDs []string
+
+	if csvName != "" {
+		ownedCRDs, err = deleteClusterServiceVersion(installer.apiClient, csvName, installer.Namespace)
+		if err != nil {
+			return err
+		}
+	}
+
+	if options.DeleteCRDs {
+		for _, crdName := range ownedCRDs {
+			err = installer.apiClient.Delete(context.TODO(), &apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: crdName},
+			})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete CustomResourceDefinition %s: %w", crdName, err)
+			}
+		}
+	}
+
+	if options.DeleteNamespace {
+		err = namespace.NewBuilder(installer.apiClient, installer.Namespace).DeleteAndWait(options.Timeout)
+		if err != nil {
+			return fmt.Errorf("failed to delete namespace %s: %w", installer.Namespace, err)
+		}
+	}
+
+	return nil
+}
+
+// resolvePackage reads the PackageManifest of the package and resolves the channel, CSV and install modes to use.
+func (installer *OperatorInstaller) resolvePackage() (*resolvedPackage, error) {
+	var (
+		packageManifest *PackageManifestBuilder
+		err             error
+	)
+
+	if installer.catalogSource != "" {
+		packageManifest, err = PullPackageManifestByCatalog(installer.apiClient,
+			installer.PackageName, installer.catalogSourceNamespace, installer.catalogSource)
+	} else {
+		packageManifest, err = PullPackageManifest(
+			installer.apiClient, installer.PackageName, installer.catalogSourceNamespace)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get packagemanifest %s: %w", installer.PackageName, err)
+	}
+
+	manifest := packageManifest.Object
+	resolved := &resolvedPackage{
+		catalogSource:          manifest.Status.CatalogSource,
+		catalogSourceNamespace: manifest.Status.CatalogSourceNamespace,
+		channel:                installer.channel,
+	}
+
+	if resolved.channel == "" {
+		resolved.channel = manifest.GetDefaultChannel()
+	}
+
+	channelIndex := slices.IndexFunc(manifest.Status.Channels, func(channel operatorv1.PackageChannel) bool {
+		return channel.Name == resolved.channel
+	})
+
+	if channelIndex < 0 {
+		return nil, fmt.Errorf("channel %q not found in packagemanifest %s", resolved.channel, installer.PackageName)
+	}
+
+	channel := manifest.Status.Channels[channelIndex]
+	resolved.csvName = channel.CurrentCSV
+	resolved.installModes = channel.CurrentCSVDesc.InstallModes
+
+	if installer.startingCSV != "" {
+		resolved.csvName = installer.startingCSV
+	}
+
+	glog.V(100).Infof("Resolved package %s to channel %s and CSV %s from catalog source %s in namespace %s",
+		installer.PackageName, resolved.channel, resolved.csvName,
+		resolved.catalogSource, resolved.catalogSourceNamespace)
+
+	return resolved, nil
+}
+
+// getTargetNamespaces returns the explicitly set target namespaces or derives them from the install modes. All
+// namespaces are preferred over the operator namespace, matching the default of the web console.
+func (installer *OperatorInstaller) getTargetNamespaces(
+	installModes []operatorsV1alpha1.InstallMode) ([]string, error) {
+	if installer.targetNamespaces != nil {
+		return installer.targetNamespaces, nil
+	}
+
+	supported := func(modeType operatorsV1alpha1.InstallModeType) bool {
+		return slices.ContainsFunc(installModes, func(mode operatorsV1alpha1.InstallMode) bool {
+			return mode.Type == modeType && mode.Supported
+		})
+	}
+
+	if supported(operatorsV1alpha1.InstallModeTypeAllNamespaces) {
+		return []string{}, nil
+	}
+
+	if supported(operatorsV1alpha1.InstallModeTypeOwnNamespace) {
+		return []string{installer.Namespace}, nil
+	}
+
+	return nil, fmt.Errorf("package %s supports neither AllNamespaces nor OwnNamespace install modes, "+
+		"set the target namespaces explicitly", installer.PackageName)
+}
+
+// ensureOperatorGroup creates an OperatorGroup with the target namespaces unless the operator namespace already has
+// one, since OLM does not allow more than one OperatorGroup per namespace.
+func (installer *OperatorInstaller) ensureOperatorGroup(targetNamespaces []string) error {
+	operatorGroups := &operatorsv1.OperatorGroupList{}
+
+	err := installer.apiClient.List(
+		context.TODO(), operatorGroups, runtimeClient.InNamespace(installer.Namespace))
+	if err != nil {
+		return fmt.Errorf("failed to list operatorgroups in namespace %s: %w", installer.Namespace, err)
+	}
+
+	if len(operatorGroups.Items) > 0 {
+		glog.V(100).Infof("Reusing OperatorGroup %s in namespace %s",
+			operatorGroups.Items[0].Name, installer.Namespace)
+
+		return nil
+	}
+
+	operatorGroup := NewOperatorGroupBuilder(installer.apiClient, installer.Namespace, installer.Namespace)
+	if operatorGroup.Definition != nil {
+		operatorGroup.Definition.Spec.TargetNamespaces = targetNamespaces
+	}
+
+	_, err = operatorGroup.Create()
+	if err != nil {
+		return fmt.Errorf("failed to create operatorgroup in namespace %s: %w", installer.Namespace, err)
+	}
+
+	return nil
+}
+
+// waitForSubscriptionCSV waits up to timeout for the subscription to install csvName and for the CSV to succeed. A
+// pending InstallPlan is approved only if it installs csvName.
+func waitForSubscriptionCSV(apiClient *clients.Settings,
+	subscription *SubscriptionBuilder, csvName string, timeout time.Duration) (*ClusterServiceVersionBuilder, error) {
+	var (
+		csv       *ClusterServiceVersionBuilder
+		lastPhase operatorsV1alpha1.ClusterServiceVersionPhase
+	)
+
+	err := wait.PollUntilContextTimeout(
+		context.TODO(), time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+			if !subscription.Exists() {
+				return false, nil
+			}
+
+			if subscription.Object.Status.InstallPlanRef != nil {
+				err := approveInstallPlan(apiClient, subscription.Object.Status.InstallPlanRef.Name,
+					subscription.Definition.Namespace, csvName)
+				if err != nil {
+					glog.V(100).Infof("Failed to approve installplan of subscription %s: %v",
+						subscription.Definition.Name, err)
+
+					return false, nil
+				}
+			}
+
+			var err error
+
+			csv, err = PullClusterServiceVersion(apiClient, csvName, subscription.Definition.Namespace)
+			if err != nil {
+				return false, nil
+			}
+
+			lastPhase = csv.Object.Status.Phase
+
+			return lastPhase == operatorsV1alpha1.CSVPhaseSucceeded, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("clusterserviceversion %s did not succeed in namespace %s, last phase %q: %w",
+			csvName, subscription.Definition.Namespace, lastPhase, err)
+	}
+
+	return csv, nil
+}
+
+// approveInstallPlan approves the InstallPlan if it is not yet approved and installs csvName. InstallPlans for other
+// CSVs are left pending.
+func approveInstallPlan(apiClient *clients.Settings, name, nsname, csvName string) error {
+	installPlan, err := PullInstallPlan(apiClient, name, nsname)
+	if err != nil {
+		return err
+	}
+
+	if installPlan.Definition.Spec.Approved ||
+		!slices.Contains(installPlan.Definition.Spec.ClusterServiceVersionNames, csvName) {
+		return nil
+	}
+
+	glog.V(100).Infof("Approving installplan %s in namespace %s for CSV %s", name, nsname, csvName)
+
+	installPlan.Definition.Spec.Approved = true
+
+	_, err = installPlan.Update()
+
+	return err
+}
+
+// deleteClusterServiceVersion deletes the CSV and returns the names of the CRDs it owns.
+func deleteClusterServiceVersion(apiClient *clients.Settings, name, nsname string) ([]string, error) {
+	csv, err := PullClusterServiceVersion(apiClient, name, nsname)
+	if err != nil {
+		glog.V(100).Infof("ClusterServiceVersion %s not found in namespace %s, skipping: %v", name, nsname, err)
+
+		return nil, nil
+	}
+
+	var ownedCRDs []string
+
+	for _, crd := range csv.Object.Spec.CustomResourceDefinitions.Owned {
+		ownedCRDs = append(ownedCRDs, crd.Name)
+	}
+
+	err = csv.Delete()
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete clusterserviceversion %s: %w", name, err)
+	}
+
+	return ownedCRDs, nil
+}
+
+// validate will check that the installer is properly initialized before accessing any member fields.
+func (installer *OperatorInstaller) validate() (bool, error) {
+	if installer == nil {
+		glog.V(100).Infof("The OperatorInstaller is uninitialized")
+
+		return false, fmt.Errorf("error: received nil OperatorInstaller")
+	}
+
+	if installer.apiClient == nil {
+		glog.V(100).Infof("The OperatorInstaller apiclient is nil")
+
+		return false, fmt.Errorf("operatorInstaller cannot have nil apiClient")
+	}
+
+	if installer.errorMsg != "" {
+		glog.V(100).Infof("The OperatorInstaller has error message: %s", installer.errorMsg)
+
+		return false, fmt.Errorf("%s", installer.errorMsg)
+	}
+
+	return true, nil
+}
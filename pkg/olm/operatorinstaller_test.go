@@ -0,0 +1,282 @@
+package olm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	operatorsv1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/olm/operators/v1"
+	oplmV1alpha1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/olm/operators/v1alpha1"
+	pkgManifestV1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/olm/package-server/operators/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultInstallerPackage   = "test-operator"
+	defaultInstallerNamespace = "test-operator-ns"
+	defaultInstallerChannel   = "stable"
+	defaultInstallerCSV       = "test-operator.v1.0.0"
+	defaultInstallerPlan      = "install-abcde"
+	defaultInstallerCRD       = "tests.example.com"
+)
+
+var installerTestSchemes = []clients.SchemeAttacher{
+	oplmV1alpha1.AddToScheme,
+	operatorsv1.AddToScheme,
+	pkgManifestV1.AddToScheme,
+	apiextensionsv1.AddToScheme,
+}
+
+func TestNewOperatorInstaller(t *testing.T) {
+	testCases := []struct {
+		packageName   string
+		nsname        string
+		client        bool
+		expectedError string
+	}{
+		{
+			packageName:   defaultInstallerPackage,
+			nsname:        defaultInstallerNamespace,
+			client:        true,
+			expectedError: "",
+		},
+		{
+			packageName:   "",
+			nsname:        defaultInstallerNamespace,
+			client:        true,
+			expectedError: "operatorInstaller 'packageName' cannot be empty",
+		},
+		{
+			packageName:   defaultInstallerPackage,
+			nsname:        "",
+			client:        true,
+			expectedError: "operatorInstaller 'nsname' cannot be empty",
+		},
+		{
+			packageName:   defaultInstallerPackage,
+			nsname:        defaultInstallerNamespace,
+			client:        false,
+			expectedError: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		var testSettings *clients.Settings
+
+		if testCase.client {
+			testSettings = clients.GetTestClients(clients.TestClientParams{SchemeAttachers: installerTestSchemes})
+		}
+
+		installer := NewOperatorInstaller(testSettings, testCase.packageName, testCase.nsname)
+
+		if !testCase.client {
+			assert.Nil(t, installer)
+
+			continue
+		}
+
+		assert.Equal(t, testCase.expectedError, installer.errorMsg)
+		assert.Equal(t, DefaultCatalogSourceNamespace, installer.catalogSourceNamespace)
+		assert.Equal(t, oplmV1alpha1.ApprovalAutomatic, installer.approval)
+	}
+}
+
+func TestOperatorInstallerInstall(t *testing.T) {
+	testCases := []struct {
+		installModes             []oplmV1alpha1.InstallMode
+		channel                  string
+		packageExists            bool
+		expectedTargetNamespaces []string
+		expectedError            error
+	}{
+		{
+			installModes:             buildInstallModes(oplmV1alpha1.InstallModeTypeAllNamespaces),
+			packageExists:            true,
+			expectedTargetNamespaces: nil,
+			expectedError:            nil,
+		},
+		{
+			installModes:             buildInstallModes(oplmV1alpha1.InstallModeTypeOwnNamespace),
+			packageExists:            true,
+			expectedTargetNamespaces: []string{defaultInstallerNamespace},
+			expectedError:            nil,
+		},
+		{
+			installModes:  buildInstallModes(oplmV1alpha1.InstallModeTypeSingleNamespace),
+			packageExists: true,
+			expectedError: fmt.Errorf("package %s supports neither AllNamespaces nor OwnNamespace install modes, "+
+				"set the target namespaces explicitly", defaultInstallerPackage),
+		},
+		{
+			installModes:  buildInstallModes(oplmV1alpha1.InstallModeTypeAllNamespaces),
+			channel:       "missing",
+			packageExists: true,
+			expectedError: fmt.Errorf("channel \"missing\" not found in packagemanifest %s", defaultInstallerPackage),
+		},
+		{
+			packageExists: false,
+			expectedError: fmt.Errorf("failed to get packagemanifest %s: packageManifest object %s does not exist "+
+				"in namespace %s", defaultInstallerPackage, defaultInstallerPackage, DefaultCatalogSourceNamespace),
+		},
+	}
+
+	for _, testCase := range testCases {
+		runtimeObjects := buildDummyInstallerObjects()
+
+		if testCase.packageExists {
+			runtimeObjects = append(runtimeObjects, buildDummyInstallerPackageManifest(testCase.installModes))
+		}
+
+		testSettings := clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects:  runtimeObjects,
+			SchemeAttachers: installerTestSchemes,
+		})
+
+		installer := NewOperatorInstaller(testSettings, defaultInstallerPackage, defaultInstallerNamespace).
+			WithManualApproval()
+
+		if testCase.channel != "" {
+			installer = installer.WithChannel(testCase.channel)
+		}
+
+		csv, err := installer.Install(time.Second)
+
+		if testCase.expectedError != nil {
+			assert.EqualError(t, err, testCase.expectedError.Error())
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, defaultInstallerCSV, csv.Definition.Name)
+
+		installPlan, err := PullInstallPlan(testSettings, defaultInstallerPlan, defaultInstallerNamespace)
+		assert.Nil(t, err)
+		assert.True(t, installPlan.Object.Spec.Approved)
+
+		operatorGroups := &operatorsv1.OperatorGroupList{}
+		err = testSettings.List(t.Context(), operatorGroups, runtimeClient.InNamespace(defaultInstallerNamespace))
+		assert.Nil(t, err)
+		assert.Len(t, operatorGroups.Items, 1)
+		assert.Equal(t, testCase.expectedTargetNamespaces, operatorGroups.Items[0].Spec.TargetNamespaces)
+	}
+}
+
+func TestOperatorInstallerUninstall(t *testing.T) {
+	testCases := []struct {
+		options           OperatorUninstallOptions
+		expectedCRDExists bool
+		expectedError     error
+	}{
+		{
+			options:           OperatorUninstallOptions{DeleteCRDs: true, DeleteNamespace: true, Timeout: time.Second},
+			expectedCRDExists: false,
+			expectedError:     nil,
+		},
+		{
+			options:           OperatorUninstallOptions{},
+			expectedCRDExists: true,
+			expectedError:     nil,
+		},
+		{
+			options:           OperatorUninstallOptions{DeleteCRDs: true, DeleteNamespace: true},
+			expectedCRDExists: true,
+			expectedError: fmt.Errorf(
+				"timeout must be greater than zero when deleting namespace %s", defaultInstallerNamespace),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects: append(buildDummyInstallerObjects(),
+				&apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: defaultInstallerCRD}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: defaultInstallerNamespace}}),
+			SchemeAttachers: installerTestSchemes,
+		})
+
+		err := NewOperatorInstaller(testSettings, defaultInstallerPackage, defaultInstallerNamespace).
+			Uninstall(testCase.options)
+		assert.Equal(t, testCase.expectedError, err)
+
+		_, err = PullSubscription(testSettings, defaultInstallerPackage, defaultInstallerNamespace)
+		assert.Equal(t, testCase.expectedError != nil, err == nil)
+
+		_, err = PullClusterServiceVersion(testSettings, defaultInstallerCSV, defaultInstallerNamespace)
+		assert.Equal(t, testCase.expectedError != nil, err == nil)
+
+		err = testSettings.Get(t.Context(), runtimeClient.ObjectKey{Name: defaultInstallerCRD},
+			&apiextensionsv1.CustomResourceDefinition{})
+		assert.Equal(t, testCase.expectedCRDExists, err == nil)
+	}
+}
+
+// buildInstallModes returns install modes where only the provided mode is supported.
+func buildInstallModes(supported oplmV1alpha1.InstallModeType) []oplmV1alpha1.InstallMode {
+	var installModes []oplmV1alpha1.InstallMode
+
+	for _, modeType := range []oplmV1alpha1.InstallModeType{
+		oplmV1alpha1.InstallModeTypeOwnNamespace,
+		oplmV1alpha1.InstallModeTypeSingleNamespace,
+		oplmV1alpha1.InstallModeTypeMultiNamespace,
+		oplmV1alpha1.InstallModeTypeAllNamespaces,
+	} {
+		installModes = append(installModes, oplmV1alpha1.InstallMode{Type: modeType, Supported: modeType == supported})
+	}
+
+	return installModes
+}
+
+// buildDummyInstallerPackageManifest returns the PackageManifest of the test operator with a single default channel.
+func buildDummyInstallerPackageManifest(installModes []oplmV1alpha1.InstallMode) *pkgManifestV1.PackageManifest {
+	return &pkgManifestV1.PackageManifest{
+		ObjectMeta: metav1.ObjectMeta{Name: defaultInstallerPackage, Namespace: DefaultCatalogSourceNamespace},
+		Status: pkgManifestV1.PackageManifestStatus{
+			CatalogSource:          "redhat-operators",
+			CatalogSourceNamespace: DefaultCatalogSourceNamespace,
+			PackageName:            defaultInstallerPackage,
+			DefaultChannel:         defaultInstallerChannel,
+			Channels: []pkgManifestV1.PackageChannel{{
+				Name:           defaultInstallerChannel,
+				CurrentCSV:     defaultInstallerCSV,
+				CurrentCSVDesc: pkgManifestV1.CSVDescription{InstallModes: installModes},
+			}},
+		},
+	}
+}
+
+// buildDummyInstallerObjects returns the objects OLM creates for the test operator once subscribed: a Subscription
+// pointing to an unapproved InstallPlan and a succeeded CSV owning a CRD.
+func buildDummyInstallerObjects() []runtime.Object {
+	return []runtime.Object{
+		&oplmV1alpha1.Subscription{
+			ObjectMeta: metav1.ObjectMeta{Name: defaultInstallerPackage, Namespace: defaultInstallerNamespace},
+			Spec:       &oplmV1alpha1.SubscriptionSpec{Package: defaultInstallerPackage},
+			Status: oplmV1alpha1.SubscriptionStatus{
+				InstalledCSV:   defaultInstallerCSV,
+				InstallPlanRef: &corev1.ObjectReference{Name: defaultInstallerPlan},
+			},
+		},
+		&oplmV1alpha1.InstallPlan{
+			ObjectMeta: metav1.ObjectMeta{Name: defaultInstallerPlan, Namespace: defaultInstallerNamespace},
+			Spec: oplmV1alpha1.InstallPlanSpec{
+				ClusterServiceVersionNames: []string{defaultInstallerCSV},
+				Approval:                   oplmV1alpha1.ApprovalManual,
+			},
+		},
+		&oplmV1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: defaultInstallerCSV, Namespace: defaultInstallerNamespace},
+			Spec: oplmV1alpha1.ClusterServiceVersionSpec{
+				CustomResourceDefinitions: oplmV1alpha1.CustomResourceDefinitions{
+					Owned: []oplmV1alpha1.CRDDescription{{Name: defaultInstallerCRD}},
+				},
+			},
+			Status: oplmV1alpha1.ClusterServiceVersionStatus{Phase: oplmV1alpha1.CSVPhaseSucceeded},
+		},
+	}
+}
@@ -0,0 +1,334 @@
+package olm
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	operatorsV1alpha1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/olm/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// subscriptionFailureConditions are the Subscription conditions that, when True, mean the upgrade cannot proceed.
+var subscriptionFailureConditions = []operatorsV1alpha1.SubscriptionConditionType{
+	operatorsV1alpha1.SubscriptionResolutionFailed,
+	operatorsV1alpha1.SubscriptionInstallPlanFailed,
+	operatorsV1alpha1.SubscriptionBundleUnpackFailed,
+}
+
+// SubscriptionUpgradeOptions configures a controlled upgrade of the operator installed by a Subscription.
+type SubscriptionUpgradeOptions struct {
+	// Channel to switch the Subscription to before upgrading. If empty, the current channel is kept.
+	Channel string
+	// TargetCSV is the ClusterServiceVersion the upgrade must end on.
+	TargetCSV string
+	// IntermediateCSVs are the ClusterServiceVersions that may be installed on the way to TargetCSV when the
+	// upgrade graph does not allow upgrading to it directly.
+	IntermediateCSVs []string
+	// Timeout bounds the whole upgrade, including the removal of the replaced CSVs. It must be greater than zero.
+	Timeout time.Duration
+}
+
+// SubscriptionUpgradeStep is an InstallPlan applied during the upgrade.
+type SubscriptionUpgradeStep struct {
+	// InstallPlan is the name of the InstallPlan.
+	InstallPlan string
+	// FromCSV is the CSV installed before the InstallPlan was applied.
+	FromCSV string
+	// ToCSV is the CSV installed by the InstallPlan.
+	ToCSV string
+	// Approved is true if the upgrade approved the InstallPlan, false if it was already approved.
+	Approved bool
+}
+
+// SubscriptionUpgradeResult describes the upgrade path taken by a Subscription.
+type SubscriptionUpgradeResult struct {
+	// FromCSV is the CSV installed when the upgrade started.
+	FromCSV string
+	// ToCSV is the CSV installed when the upgrade finished.
+	ToCSV string
+	// Steps are the InstallPlans applied during the upgrade, in order.
+	Steps []SubscriptionUpgradeStep
+}
+
+// SubscriptionResolutionError is returned when the Subscription reports that OLM failed to resolve, unpack or
+// install the upgrade.
+type SubscriptionResolutionError struct {
+	// Subscription is the name of the Subscription.
+	Subscription string
+	// Namespace is the namespace of the Subscription.
+	Namespace string
+	// Conditions are the failure conditions set to True on the Subscription.
+	Conditions []operatorsV1alpha1.SubscriptionCondition
+}
+
+// subscriptionUpgrade holds the state of an upgrade between polls.
+type subscriptionUpgrade struct {
+	builder     *SubscriptionBuilder
+	options     SubscriptionUpgradeOptions
+	result      *SubscriptionUpgradeResult
+	initialPlan string
+}
+
+// Error returns the failure conditions of the Subscription.
+func (resolutionErr *SubscriptionResolutionError) Error() string {
+	var conditions []string
+
+	for _, condition := range resolutionErr.Conditions {
+		conditions = append(conditions, fmt.Sprintf("%s: %s", condition.Type, condition.Message))
+	}
+
+	return fmt.Sprintf("subscription %s in namespace %s failed: %s",
+		resolutionErr.Subscription, resolutionErr.Namespace, strings.Join(conditions, "; "))
+}
+
+// Path returns the CSVs installed during the upgrade, starting with the CSV installed when it started.
+func (result *SubscriptionUpgradeResult) Path() []string {
+	path := []string{result.FromCSV}
+
+	for _, step := range result.Steps {
+		path = append(path, step.ToCSV)
+	}
+
+	return path
+}
+
+// Upgrade switches the Subscription to the channel, if set, and steps it to the target CSV. Pending InstallPlans are
+// approved only if they install the target CSV or one of the intermediate CSVs, otherwise the upgrade fails without
+// approving them. The upgrade completes once the target CSV has succeeded and the replaced CSVs are gone. The result
+// reports the path taken so far, even if the upgrade fails.
+func (builder *SubscriptionBuilder) Upgrade(options SubscriptionUpgradeOptions) (*SubscriptionUpgradeResult, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Upgrading Subscription %s in namespace %s to CSV %s",
+		builder.Definition.Name, builder.Definition.Namespace, options.TargetCSV)
+
+	upgrade, err := newSubscriptionUpgrade(builder, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Channel != "" && options.Channel != builder.Definition.Spec.Channel {
+		glog.V(100).Infof("Switching Subscription %s from channel %s to channel %s",
+			builder.Definition.Name, builder.Definition.Spec.Channel, options.Channel)
+
+		builder.Definition.Spec.Channel = options.Channel
+
+		_, err = builder.Update()
+		if err != nil {
+			return upgrade.result, fmt.Errorf("failed to switch subscription %s to channel %s: %w",
+				builder.Definition.Name, options.Channel, err)
+		}
+	}
+
+	err = wait.PollUntilContextTimeout(context.TODO(), 3*time.Second, options.Timeout, true, upgrade.poll)
+	if err != nil {
+		return upgrade.result, fmt.Errorf("failed to upgrade subscription %s from %s to %s, path taken %v: %w",
+			builder.Definition.Name, upgrade.result.FromCSV, options.TargetCSV, upgrade.result.Path(), err)
+	}
+
+	return upgrade.result, nil
+}
+
+// newSubscriptionUpgrade validates the options and records the state of the Subscription the upgrade starts from.
+func newSubscriptionUpgrade(
+	builder *SubscriptionBuilder, options SubscriptionUpgradeOptions) (*subscriptionUpgrade, error) {
+	if options.TargetCSV == "" {
+		glog.V(100).Infof("The target CSV of the upgrade is empty")
+
+		return nil, fmt.Errorf("subscription upgrade 'TargetCSV' cannot be empty")
+	}
+
+	if options.Timeout <= 0 {
+		glog.V(100).Infof("The timeout of the upgrade %s is not greater than zero", options.Timeout)
+
+		return nil, fmt.Errorf("subscription upgrade 'Timeout' must be greater than zero")
+	}
+
+	if !builder.Exists() {
+		return nil, fmt.Errorf("cannot upgrade subscription %s in namespace %s because it does not exist",
+			builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	builder.Definition = builder.Object
+	upgrade := &subscriptionUpgrade{
+		builder: builder,
+		options: options,
+		result: &SubscriptionUpgradeResult{
+			FromCSV: builder.Object.Status.InstalledCSV,
+			ToCSV:   builder.Object.Status.InstalledCSV,
+		},
+	}
+
+	// An InstallPlan that is already approved when the upgrade starts installed the current CSV, while an unapproved
+	// one is a pending upgrade that has to go through the same checks as later ones.
+	if planRef := builder.Object.Status.InstallPlanRef; planRef != nil {
+		installPlan, err := upgrade.getInstallPlan(planRef.Name)
+		if err == nil && installPlan.Object.Spec.Approved {
+			upgrade.initialPlan = planRef.Name
+		}
+	}
+
+	return upgrade, nil
+}
+
+// poll advances the upgrade by one step. It returns true once the upgrade is complete and an error if it cannot
+// complete.
+func (upgrade *subscriptionUpgrade) poll(context.Context) (bool, error) {
+	subscription, err := upgrade.builder.Get()
+	if err != nil {
+		glog.V(100).Infof("Failed to get Subscription %s: %v", upgrade.builder.Definition.Name, err)
+
+		return false, nil
+	}
+
+	if err := upgrade.getResolutionError(subscription); err != nil {
+		return false, err
+	}
+
+	upgrade.result.ToCSV = subscription.Status.InstalledCSV
+
+	if planRef := subscription.Status.InstallPlanRef; planRef != nil && planRef.Name != upgrade.initialPlan &&
+		!slices.ContainsFunc(upgrade.result.Steps, func(step SubscriptionUpgradeStep) bool {
+			return step.InstallPlan == planRef.Name
+		}) {
+		if err := upgrade.handleInstallPlan(planRef.Name, subscription.Status.InstalledCSV); err != nil {
+			return false, err
+		}
+	}
+
+	if subscription.Status.InstalledCSV != upgrade.options.TargetCSV {
+		return false, nil
+	}
+
+	return upgrade.isTargetCSVReady()
+}
+
+// handleInstallPlan approves the InstallPlan if it installs an allowed CSV and records it as an upgrade step. It
+// returns an error if the InstallPlan is pending and installs a CSV that is not allowed.
+func (upgrade *subscriptionUpgrade) handleInstallPlan(name, installedCSV string) error {
+	installPlan, err := upgrade.getInstallPlan(name)
+	if err != nil {
+		glog.V(100).Infof("Failed to get InstallPlan %s: %v", name, err)
+
+		return nil
+	}
+
+	csvNames := installPlan.Object.Spec.ClusterServiceVersionNames
+	allowedCSVs := append([]string{upgrade.options.TargetCSV}, upgrade.options.IntermediateCSVs...)
+	csvIndex := slices.IndexFunc(csvNames, func(csvName string) bool {
+		return slices.Contains(allowedCSVs, csvName)
+	})
+
+	step := SubscriptionUpgradeStep{InstallPlan: name, FromCSV: installedCSV}
+
+	if csvIndex >= 0 {
+		step.ToCSV = csvNames[csvIndex]
+	} else if len(csvNames) > 0 {
+		step.ToCSV = csvNames[0]
+	}
+
+	if !installPlan.Object.Spec.Approved {
+		if csvIndex < 0 {
+			return fmt.Errorf("installplan %s in namespace %s installs %v, which is neither the target CSV %s "+
+				"nor one of the intermediate CSVs %v", name, installPlan.Definition.Namespace,
+				csvNames, upgrade.options.TargetCSV, upgrade.options.IntermediateCSVs)
+		}
+
+		glog.V(100).Infof("Approving InstallPlan %s for CSV %s", name, step.ToCSV)
+
+		installPlan.Definition.Spec.Approved = true
+
+		_, err = installPlan.Update()
+		if err != nil {
+			glog.V(100).Infof("Failed to approve InstallPlan %s: %v", name, err)
+
+			return nil
+		}
+
+		step.Approved = true
+	}
+
+	upgrade.result.Steps = append(upgrade.result.Steps, step)
+
+	return nil
+}
+
+// isTargetCSVReady returns true if the target CSV has succeeded and the CSVs it replaced no longer exist.
+func (upgrade *subscriptionUpgrade) isTargetCSVReady() (bool, error) {
+	phase, err := upgrade.getClusterServiceVersion(upgrade.options.TargetCSV).GetPhase()
+	if err != nil || phase != operatorsV1alpha1.CSVPhaseSucceeded {
+		return false, nil
+	}
+
+	for _, csvName := range upgrade.result.Path() {
+		if csvName == "" || csvName == upgrade.options.TargetCSV {
+			continue
+		}
+
+		if upgrade.getClusterServiceVersion(csvName).Exists() {
+			glog.V(100).Infof("Waiting for replaced CSV %s to be removed", csvName)
+
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// getResolutionError returns a SubscriptionResolutionError if any of the failure conditions is True.
+func (upgrade *subscriptionUpgrade) getResolutionError(subscription *operatorsV1alpha1.Subscription) error {
+	var failed []operatorsV1alpha1.SubscriptionCondition
+
+	for _, conditionType := range subscriptionFailureConditions {
+		condition := subscription.Status.GetCondition(conditionType)
+		if condition.Status == corev1.ConditionTrue {
+			failed = append(failed, condition)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &SubscriptionResolutionError{
+		Subscription: subscription.Name,
+		Namespace:    subscription.Namespace,
+		Conditions:   failed,
+	}
+}
+
+// getInstallPlan returns a builder for the existing InstallPlan in the namespace of the Subscription.
+func (upgrade *subscriptionUpgrade) getInstallPlan(name string) (*InstallPlanBuilder, error) {
+	installPlan := &InstallPlanBuilder{
+		apiClient: upgrade.builder.apiClient,
+		Definition: &operatorsV1alpha1.InstallPlan{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: upgrade.builder.Definition.Namespace},
+		},
+	}
+
+	if !installPlan.Exists() {
+		return nil, fmt.Errorf("installplan %s does not exist in namespace %s", name, installPlan.Definition.Namespace)
+	}
+
+	installPlan.Definition = installPlan.Object
+
+	return installPlan, nil
+}
+
+// getClusterServiceVersion returns a builder for the CSV in the namespace of the Subscription.
+func (upgrade *subscriptionUpgrade) getClusterServiceVersion(name string) *ClusterServiceVersionBuilder {
+	return &ClusterServiceVersionBuilder{
+		apiClient: upgrade.builder.apiClient,
+		Definition: &operatorsV1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: upgrade.builder.Definition.Namespace},
+		},
+	}
+}
@@ -0,0 +1,177 @@
+package olm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
+	oplmV1alpha1 "github.com/rh-ecosystem-edge/eco-goinfra/pkg/schemes/olm/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	defaultUpgradeSubscription = "test-operator"
+	defaultUpgradeNamespace    = "test-operator-ns"
+	defaultUpgradeFromCSV      = "test-operator.v1.0.0"
+	defaultUpgradeToCSV        = "test-operator.v1.1.0"
+)
+
+func TestSubscriptionUpgrade(t *testing.T) {
+	testCases := []struct {
+		conditions    []oplmV1alpha1.SubscriptionCondition
+		pendingCSV    string
+		options       SubscriptionUpgradeOptions
+		expectedError error
+	}{
+		{
+			pendingCSV:    defaultUpgradeToCSV,
+			options:       SubscriptionUpgradeOptions{TargetCSV: ""},
+			expectedError: fmt.Errorf("subscription upgrade 'TargetCSV' cannot be empty"),
+		},
+		{
+			pendingCSV:    defaultUpgradeToCSV,
+			options:       SubscriptionUpgradeOptions{TargetCSV: defaultUpgradeToCSV},
+			expectedError: fmt.Errorf("subscription upgrade 'Timeout' must be greater than zero"),
+		},
+		{
+			pendingCSV: "test-operator.v2.0.0",
+			options:    SubscriptionUpgradeOptions{TargetCSV: defaultUpgradeToCSV, Timeout: time.Second},
+			expectedError: fmt.Errorf("failed to upgrade subscription %s from %s to %s, path taken [%s]: "+
+				"installplan install-2 in namespace %s installs [test-operator.v2.0.0], which is neither the target "+
+				"CSV %s nor one of the intermediate CSVs []", defaultUpgradeSubscription, defaultUpgradeFromCSV,
+				defaultUpgradeToCSV, defaultUpgradeFromCSV, defaultUpgradeNamespace, defaultUpgradeToCSV),
+		},
+		{
+			conditions: []oplmV1alpha1.SubscriptionCondition{{
+				Type:    oplmV1alpha1.SubscriptionResolutionFailed,
+				Status:  corev1.ConditionTrue,
+				Message: "constraints not satisfiable",
+			}},
+			pendingCSV: defaultUpgradeToCSV,
+			options: SubscriptionUpgradeOptions{
+				Channel: "stable-1.1", TargetCSV: defaultUpgradeToCSV, Timeout: time.Second,
+			},
+			expectedError: fmt.Errorf("failed to upgrade subscription %s from %s to %s, path taken [%s]: "+
+				"subscription %s in namespace %s failed: ResolutionFailed: constraints not satisfiable",
+				defaultUpgradeSubscription, defaultUpgradeFromCSV, defaultUpgradeToCSV, defaultUpgradeFromCSV,
+				defaultUpgradeSubscription, defaultUpgradeNamespace),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testSettings := clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects:  buildDummyUpgradeObjects(testCase.conditions, testCase.pendingCSV),
+			SchemeAttachers: testSchemes,
+		})
+
+		subscription, err := PullSubscription(testSettings, defaultUpgradeSubscription, defaultUpgradeNamespace)
+		assert.Nil(t, err)
+
+		_, err = subscription.Upgrade(testCase.options)
+		assert.EqualError(t, err, testCase.expectedError.Error())
+
+		var resolutionErr *SubscriptionResolutionError
+
+		assert.Equal(t, len(testCase.conditions) > 0, errors.As(err, &resolutionErr))
+
+		if testCase.options.Channel != "" {
+			subscription, err = PullSubscription(testSettings, defaultUpgradeSubscription, defaultUpgradeNamespace)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.options.Channel, subscription.Object.Spec.Channel)
+		}
+
+		installPlan, err := PullInstallPlan(testSettings, "install-2", defaultUpgradeNamespace)
+		assert.Nil(t, err)
+		assert.False(t, installPlan.Object.Spec.Approved)
+	}
+}
+
+func TestSubscriptionUpgradePoll(t *testing.T) {
+	testSettings := clients.GetTestClients(clients.TestClientParams{
+		K8sMockObjects:  buildDummyUpgradeObjects(nil, defaultUpgradeToCSV),
+		SchemeAttachers: testSchemes,
+	})
+
+	subscription, err := PullSubscription(testSettings, defaultUpgradeSubscription, defaultUpgradeNamespace)
+	assert.Nil(t, err)
+
+	upgrade, err := newSubscriptionUpgrade(subscription,
+		SubscriptionUpgradeOptions{TargetCSV: defaultUpgradeToCSV, Timeout: time.Second})
+	assert.Nil(t, err)
+	assert.Equal(t, "", upgrade.initialPlan)
+
+	done, err := upgrade.poll(t.Context())
+	assert.Nil(t, err)
+	assert.False(t, done)
+
+	installPlan, err := PullInstallPlan(testSettings, "install-2", defaultUpgradeNamespace)
+	assert.Nil(t, err)
+	assert.True(t, installPlan.Object.Spec.Approved)
+
+	// OLM installs the new CSV while the old one is being replaced.
+	subscription.Definition.Status.InstalledCSV = defaultUpgradeToCSV
+	assert.Nil(t, testSettings.Update(t.Context(), subscription.Definition))
+	assert.Nil(t, testSettings.Create(t.Context(), buildDummyUpgradeCSV(defaultUpgradeToCSV)))
+
+	done, err = upgrade.poll(t.Context())
+	assert.Nil(t, err)
+	assert.False(t, done)
+
+	assert.Nil(t, testSettings.Delete(t.Context(), buildDummyUpgradeCSV(defaultUpgradeFromCSV)))
+
+	done, err = upgrade.poll(t.Context())
+	assert.Nil(t, err)
+	assert.True(t, done)
+	assert.Equal(t, []string{defaultUpgradeFromCSV, defaultUpgradeToCSV}, upgrade.result.Path())
+	assert.Equal(t, []SubscriptionUpgradeStep{{
+		InstallPlan: "install-2",
+		FromCSV:     defaultUpgradeFromCSV,
+		ToCSV:       defaultUpgradeToCSV,
+		Approved:    true,
+	}}, upgrade.result.Steps)
+}
+
+// buildDummyUpgradeObjects returns a Subscription with the first CSV installed and a pending InstallPlan for
+// pendingCSV, along with the first, approved, InstallPlan and the first CSV.
+func buildDummyUpgradeObjects(
+	conditions []oplmV1alpha1.SubscriptionCondition, pendingCSV string) []runtime.Object {
+	return []runtime.Object{
+		&oplmV1alpha1.Subscription{
+			ObjectMeta: metav1.ObjectMeta{Name: defaultUpgradeSubscription, Namespace: defaultUpgradeNamespace},
+			Spec:       &oplmV1alpha1.SubscriptionSpec{Package: defaultUpgradeSubscription, Channel: "stable-1.0"},
+			Status: oplmV1alpha1.SubscriptionStatus{
+				InstalledCSV:   defaultUpgradeFromCSV,
+				InstallPlanRef: &corev1.ObjectReference{Name: "install-2"},
+				Conditions:     conditions,
+			},
+		},
+		buildDummyUpgradeInstallPlan("install-1", defaultUpgradeFromCSV, true),
+		buildDummyUpgradeInstallPlan("install-2", pendingCSV, false),
+		buildDummyUpgradeCSV(defaultUpgradeFromCSV),
+	}
+}
+
+// buildDummyUpgradeInstallPlan returns an InstallPlan for the CSV.
+func buildDummyUpgradeInstallPlan(name, csvName string, approved bool) *oplmV1alpha1.InstallPlan {
+	return &oplmV1alpha1.InstallPlan{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: defaultUpgradeNamespace},
+		Spec: oplmV1alpha1.InstallPlanSpec{
+			ClusterServiceVersionNames: []string{csvName},
+			Approval:                   oplmV1alpha1.ApprovalManual,
+			Approved:                   approved,
+		},
+	}
+}
+
+// buildDummyUpgradeCSV returns a succeeded CSV.
+func buildDummyUpgradeCSV(name string) *oplmV1alpha1.ClusterServiceVersion {
+	return &oplmV1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: defaultUpgradeNamespace},
+		Status:     oplmV1alpha1.ClusterServiceVersionStatus{Phase: oplmV1alpha1.CSVPhaseSucceeded},
+	}
+}
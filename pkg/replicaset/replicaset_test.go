@@ -1,12 +1,17 @@
 package replicaset
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/stretchr/testify/assert"
@@ -627,3 +632,206 @@ func buildDummyReplicaSet() []runtime.Object {
 		},
 	})
 }
+
+func TestReplicaSetGetScale(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		expectedError error
+	}{
+		{
+			exists:        true,
+			expectedError: nil,
+		},
+		{
+			exists: false,
+			expectedError: fmt.Errorf(
+				"cannot get scale of replicaset test-name in namespace test-namespace because it does not exist"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyScaledReplicaSet(3, 3))
+		}
+
+		testBuilder := buildTestBuilderWithScaleReactor(runtimeObjects)
+
+		scale, err := testBuilder.GetScale()
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, int32(3), scale.Spec.Replicas)
+		}
+	}
+}
+
+func TestReplicaSetScale(t *testing.T) {
+	testCases := []struct {
+		replicas      int32
+		exists        bool
+		expectedError error
+	}{
+		{
+			replicas:      5,
+			exists:        true,
+			expectedError: nil,
+		},
+		{
+			replicas:      -1,
+			exists:        true,
+			expectedError: fmt.Errorf("replicaset 'replicas' cannot be negative"),
+		},
+		{
+			replicas: 5,
+			exists:   false,
+			expectedError: fmt.Errorf(
+				"cannot get scale of replicaset test-name in namespace test-namespace because it does not exist"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyScaledReplicaSet(3, 3))
+		}
+
+		testBuilder := buildTestBuilderWithScaleReactor(runtimeObjects)
+
+		err := testBuilder.Scale(context.TODO(), testCase.replicas)
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, testCase.replicas, *testBuilder.Object.Spec.Replicas)
+		}
+	}
+}
+
+func TestReplicaSetWaitUntilScaled(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		readyReplicas int32
+		expectedError error
+	}{
+		{
+			exists:        true,
+			readyReplicas: 3,
+			expectedError: nil,
+		},
+		{
+			exists:        true,
+			readyReplicas: 1,
+			expectedError: context.DeadlineExceeded,
+		},
+		{
+			exists: false,
+			expectedError: fmt.Errorf(
+				"cannot wait for replicaset test-name in namespace test-namespace to scale because it does not exist"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyScaledReplicaSet(3, testCase.readyReplicas))
+		}
+
+		testBuilder := buildValidReplicaSetBuilder(clients.GetTestClients(clients.TestClientParams{
+			K8sMockObjects: runtimeObjects,
+		}))
+
+		err := testBuilder.WaitUntilScaled(3, time.Second)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+// buildDummyScaledReplicaSet returns a replicaset with the provided number of desired and ready replicas.
+func buildDummyScaledReplicaSet(replicas, readyReplicas int32) *appsv1.ReplicaSet {
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      defaultReplicaSetName,
+			Namespace: defaultReplicaSetNamespace,
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Replicas: &replicas,
+		},
+		Status: appsv1.ReplicaSetStatus{
+			Replicas:      replicas,
+			ReadyReplicas: readyReplicas,
+		},
+	}
+}
+
+// buildTestBuilderWithScaleReactor returns a Builder whose fake client serves the replicaset scale subresource from
+// the underlying replicaset objects, since the fake clientset does not do this on its own.
+func buildTestBuilderWithScaleReactor(runtimeObjects []runtime.Object) *Builder {
+	fakeClient := k8sfake.NewSimpleClientset(runtimeObjects...)
+	testBuilder := buildValidReplicaSetBuilder(&clients.Settings{
+		K8sClient:       fakeClient,
+		AppsV1Interface: fakeClient.AppsV1(),
+	})
+	replicaSetGVR := appsv1.SchemeGroupVersion.WithResource("replicasets")
+
+	fakeClient.PrependReactor("get", "replicasets",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "scale" {
+				return false, nil, nil
+			}
+
+			getAction, ok := action.(k8stesting.GetAction)
+			if !ok {
+				return false, nil, nil
+			}
+
+			object, err := fakeClient.Tracker().Get(replicaSetGVR, getAction.GetNamespace(), getAction.GetName())
+			if err != nil {
+				return true, nil, err
+			}
+
+			replicaSet, ok := object.(*appsv1.ReplicaSet)
+			if !ok {
+				return true, nil, fmt.Errorf("unexpected object type %T", object)
+			}
+
+			return true, &autoscalingv1.Scale{
+				ObjectMeta: metav1.ObjectMeta{Name: replicaSet.Name, Namespace: replicaSet.Namespace},
+				Spec:       autoscalingv1.ScaleSpec{Replicas: *replicaSet.Spec.Replicas},
+			}, nil
+		})
+
+	fakeClient.PrependReactor("update", "replicasets",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "scale" {
+				return false, nil, nil
+			}
+
+			updateAction, ok := action.(k8stesting.UpdateAction)
+			if !ok {
+				return false, nil, nil
+			}
+
+			scale, ok := updateAction.GetObject().(*autoscalingv1.Scale)
+			if !ok {
+				return false, nil, nil
+			}
+
+			object, err := fakeClient.Tracker().Get(replicaSetGVR, scale.Namespace, scale.Name)
+			if err != nil {
+				return true, nil, err
+			}
+
+			replicaSet, ok := object.(*appsv1.ReplicaSet)
+			if !ok {
+				return true, nil, fmt.Errorf("unexpected object type %T", object)
+			}
+
+			replicaSet.Spec.Replicas = &scale.Spec.Replicas
+
+			return true, scale, fakeClient.Tracker().Update(replicaSetGVR, replicaSet, scale.Namespace)
+		})
+
+	return testBuilder
+}
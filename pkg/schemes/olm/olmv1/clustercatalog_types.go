@@ -0,0 +1,140 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package olmv1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SourceType defines the type of source used for catalogs.
+// +enum
+type SourceType string
+
+// AvailabilityMode defines the availability of the catalog.
+type AvailabilityMode string
+
+const (
+	// SourceTypeImage is the only supported catalog source type.
+	SourceTypeImage SourceType = "Image"
+
+	// MetadataNameLabel is the label set on every ClusterCatalog with its name, used by ClusterExtension selectors.
+	MetadataNameLabel = "olm.operatorframework.io/metadata.name"
+
+	// AvailabilityModeAvailable makes the catalog content available to clients.
+	AvailabilityModeAvailable AvailabilityMode = "Available"
+	// AvailabilityModeUnavailable removes the catalog content from clients.
+	AvailabilityModeUnavailable AvailabilityMode = "Unavailable"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:subresource:status
+
+// ClusterCatalog enables users to make File-Based Catalog (FBC) catalog data available to the cluster.
+type ClusterCatalog struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec ClusterCatalogSpec `json:"spec"`
+	// +optional
+	Status ClusterCatalogStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterCatalogList contains a list of ClusterCatalog.
+type ClusterCatalogList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ClusterCatalog `json:"items"`
+}
+
+// ClusterCatalogSpec defines the desired state of ClusterCatalog.
+type ClusterCatalogSpec struct {
+	// source allows a user to define the source of a catalog.
+	Source CatalogSource `json:"source"`
+
+	// priority allows the user to define a priority for a ClusterCatalog. When multiple catalogs provide the same
+	// bundle, the one from the catalog with the highest priority is selected.
+	// +optional
+	Priority int32 `json:"priority"`
+
+	// availabilityMode allows users to define how the ClusterCatalog is made available to clients on the cluster.
+	// +optional
+	AvailabilityMode AvailabilityMode `json:"availabilityMode,omitempty"`
+}
+
+// ClusterCatalogStatus defines the observed state of ClusterCatalog.
+type ClusterCatalogStatus struct {
+	// conditions is a representation of the current state for this ClusterCatalog.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// resolvedSource contains information about the resolved source based on the source type.
+	// +optional
+	ResolvedSource *ResolvedCatalogSource `json:"resolvedSource,omitempty"`
+	// urls contains the URLs that can be used to access the catalog.
+	// +optional
+	URLs *ClusterCatalogURLs `json:"urls,omitempty"`
+	// lastUnpacked represents the last time the contents of the catalog were extracted from their source format.
+	// +optional
+	LastUnpacked *metav1.Time `json:"lastUnpacked,omitempty"`
+}
+
+// ClusterCatalogURLs contains the URLs that can be used to access the catalog.
+type ClusterCatalogURLs struct {
+	// base is a cluster-internal URL that provides endpoints for accessing the content of the catalog.
+	Base string `json:"base"`
+}
+
+// CatalogSource is a discriminated union of possible sources for a Catalog.
+type CatalogSource struct {
+	// type is a reference to the type of source the catalog is sourced from.
+	Type SourceType `json:"type"`
+	// image is used to configure how catalog contents are sourced from an OCI image.
+	// +optional
+	Image *ImageSource `json:"image,omitempty"`
+}
+
+// ResolvedCatalogSource is a discriminated union of resolution information for a Catalog.
+type ResolvedCatalogSource struct {
+	// type is a reference to the type of source the catalog is sourced from.
+	Type SourceType `json:"type"`
+	// image is a field containing resolution information for a catalog sourced from an image.
+	Image *ResolvedImageSource `json:"image"`
+}
+
+// ResolvedImageSource provides information about the resolved source of a Catalog sourced from an image.
+type ResolvedImageSource struct {
+	// ref contains the resolved image digest-based reference.
+	Ref string `json:"ref"`
+}
+
+// ImageSource enables users to define the information required for sourcing a Catalog from an OCI image.
+type ImageSource struct {
+	// ref allows users to define the reference to a container image containing Catalog contents.
+	Ref string `json:"ref"`
+
+	// pollIntervalMinutes allows the user to set the interval, in minutes, at which the image source should be
+	// polled for new content. It cannot be set when ref is a digest-based reference.
+	// +optional
+	PollIntervalMinutes *int `json:"pollIntervalMinutes,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterCatalog{}, &ClusterCatalogList{})
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package olmv1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UpgradeConstraintPolicy defines how upgrade edges are enforced.
+type UpgradeConstraintPolicy string
+
+// CRDUpgradeSafetyEnforcement defines whether the CRD upgrade safety preflight check is enforced.
+type CRDUpgradeSafetyEnforcement string
+
+const (
+	// SourceTypeCatalog is the only supported ClusterExtension source type.
+	SourceTypeCatalog = "Catalog"
+
+	// UpgradeConstraintPolicyCatalogProvided only allows upgrades along the edges defined in the catalog.
+	UpgradeConstraintPolicyCatalogProvided UpgradeConstraintPolicy = "CatalogProvided"
+	// UpgradeConstraintPolicySelfCertified allows upgrades to any version, ignoring the catalog edges.
+	UpgradeConstraintPolicySelfCertified UpgradeConstraintPolicy = "SelfCertified"
+
+	// CRDUpgradeSafetyEnforcementStrict fails the upgrade if the CRD upgrade safety check fails.
+	CRDUpgradeSafetyEnforcementStrict CRDUpgradeSafetyEnforcement = "Strict"
+	// CRDUpgradeSafetyEnforcementNone skips the CRD upgrade safety check.
+	CRDUpgradeSafetyEnforcementNone CRDUpgradeSafetyEnforcement = "None"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:subresource:status
+
+// ClusterExtension is the Schema for the clusterextensions API.
+type ClusterExtension struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterExtensionSpec   `json:"spec,omitempty"`
+	Status ClusterExtensionStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterExtensionList contains a list of ClusterExtension.
+type ClusterExtensionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ClusterExtension `json:"items"`
+}
+
+// ClusterExtensionSpec defines the desired state of ClusterExtension.
+type ClusterExtensionSpec struct {
+	// namespace is a reference to a Kubernetes namespace. This is the namespace in which the provided
+	// ServiceAccount must exist. It also designates the default namespace where namespace-scoped resources for
+	// the extension are applied to the cluster.
+	Namespace string `json:"namespace"`
+
+	// serviceAccount is a reference to a ServiceAccount used to perform all interactions with the cluster that
+	// are required to manage the extension.
+	ServiceAccount ServiceAccountReference `json:"serviceAccount"`
+
+	// source is a required field which selects the installation source of content for this ClusterExtension.
+	Source SourceConfig `json:"source"`
+
+	// install is an optional field used to configure the installation options for the ClusterExtension.
+	// +optional
+	Install *ClusterExtensionInstallConfig `json:"install,omitempty"`
+}
+
+// SourceConfig is a discriminated union which selects the installation source.
+type SourceConfig struct {
+	// sourceType is a required reference to the type of install source.
+	SourceType string `json:"sourceType"`
+
+	// catalog is used to configure how information is sourced from a catalog.
+	// +optional
+	Catalog *CatalogFilter `json:"catalog,omitempty"`
+}
+
+// ClusterExtensionInstallConfig is a union which selects the clusterExtension installation config.
+type ClusterExtensionInstallConfig struct {
+	// preflight is an optional field that can be used to configure the checks that are run before installation
+	// or upgrade of the content for the package specified in the packageName field.
+	// +optional
+	Preflight *PreflightConfig `json:"preflight,omitempty"`
+}
+
+// PreflightConfig holds the configuration for the preflight checks.
+type PreflightConfig struct {
+	// crdUpgradeSafety is used to configure the CRD Upgrade Safety pre-flight checks.
+	CRDUpgradeSafety *CRDUpgradeSafetyPreflightConfig `json:"crdUpgradeSafety"`
+}
+
+// CRDUpgradeSafetyPreflightConfig is the configuration for CRD upgrade safety preflight check.
+type CRDUpgradeSafetyPreflightConfig struct {
+	// enforcement is a required field, used to configure the state of the CRD Upgrade Safety pre-flight check.
+	Enforcement CRDUpgradeSafetyEnforcement `json:"enforcement"`
+}
+
+// CatalogFilter defines the attributes used to identify and filter content from a catalog.
+type CatalogFilter struct {
+	// packageName is a reference to the name of the package to be installed.
+	PackageName string `json:"packageName"`
+
+	// version is an optional semver constraint (a specific version or range of versions).
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// channels is an optional reference to a set of channels belonging to the package specified in the
+	// packageName field.
+	// +optional
+	Channels []string `json:"channels,omitempty"`
+
+	// selector is an optional field that can be used to filter the set of ClusterCatalogs used in the bundle
+	// selection process.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// upgradeConstraintPolicy is an optional field that controls whether the upgrade path(s) defined in the
+	// catalog are enforced for the package referenced in the packageName field.
+	// +optional
+	UpgradeConstraintPolicy UpgradeConstraintPolicy `json:"upgradeConstraintPolicy,omitempty"`
+}
+
+// ServiceAccountReference identifies the serviceAccount used to install a ClusterExtension.
+type ServiceAccountReference struct {
+	// name is a required, immutable reference to the name of the ServiceAccount to be used for installation and
+	// management of the content for the package specified in the packageName field.
+	Name string `json:"name"`
+}
+
+// BundleMetadata is a representation of the identifying attributes of a bundle.
+type BundleMetadata struct {
+	// name is required and follows the DNS subdomain standard as defined in RFC 1123.
+	Name string `json:"name"`
+
+	// version is a required field and is a reference to the version that this bundle represents.
+	Version string `json:"version"`
+}
+
+// ClusterExtensionInstallStatus is a representation of the status of the identified bundle.
+type ClusterExtensionInstallStatus struct {
+	// bundle is a required field which represents the identifying attributes of a bundle.
+	Bundle BundleMetadata `json:"bundle"`
+}
+
+// ClusterExtensionStatus defines the observed state of a ClusterExtension.
+type ClusterExtensionStatus struct {
+	// conditions represents the current state of the ClusterExtension.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// install is a representation of the current installation status for this ClusterExtension.
+	// +optional
+	Install *ClusterExtensionInstallStatus `json:"install,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterExtension{}, &ClusterExtensionList{})
+}
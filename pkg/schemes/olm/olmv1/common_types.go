@@ -0,0 +1,43 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package olmv1
+
+const (
+	// TypeInstalled is the condition type set on a ClusterExtension once its bundle is installed.
+	TypeInstalled = "Installed"
+	// TypeProgressing is the condition type that reports the progress of a ClusterExtension or ClusterCatalog.
+	TypeProgressing = "Progressing"
+	// TypeServing is the condition type set on a ClusterCatalog once its content is served.
+	TypeServing = "Serving"
+
+	// ReasonSucceeded means the operation completed successfully.
+	ReasonSucceeded = "Succeeded"
+	// ReasonDeprecated means the object or its content is deprecated.
+	ReasonDeprecated = "Deprecated"
+	// ReasonFailed means the operation failed and will not be retried.
+	ReasonFailed = "Failed"
+	// ReasonBlocked means the operation is blocked until an external change happens.
+	ReasonBlocked = "Blocked"
+	// ReasonRetrying means the operation failed and will be retried.
+	ReasonRetrying = "Retrying"
+	// ReasonAvailable means the catalog content is available.
+	ReasonAvailable = "Available"
+	// ReasonUnavailable means the catalog content is not available.
+	ReasonUnavailable = "Unavailable"
+	// ReasonUserSpecifiedUnavailable means the catalog was made unavailable by its availabilityMode.
+	ReasonUserSpecifiedUnavailable = "UserSpecifiedUnavailable"
+)
@@ -9,6 +9,7 @@ import (
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/msg"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -304,6 +305,96 @@ func (builder *Builder) IsReady(timeout time.Duration) bool {
 	return err == nil
 }
 
+// GetScale returns the scale subresource of the statefulset.
+func (builder *Builder) GetScale() (*autoscalingv1.Scale, error) {
+	if valid, err := builder.validate(); !valid {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Getting scale of statefulset %s in namespace %s",
+		builder.Definition.Name, builder.Definition.Namespace)
+
+	return builder.getScale(context.TODO())
+}
+
+// Scale sets the number of replicas of the statefulset using the scale subresource. Only the replica count is sent
+// to the cluster, so the rest of the statefulset spec is left untouched.
+func (builder *Builder) Scale(ctx context.Context, replicas int32) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Scaling statefulset %s in namespace %s to %d replicas",
+		builder.Definition.Name, builder.Definition.Namespace, replicas)
+
+	if replicas < 0 {
+		glog.V(100).Infof("The replicas of the statefulset cannot be negative")
+
+		return fmt.Errorf("statefulset 'replicas' cannot be negative")
+	}
+
+	scale, err := builder.getScale(ctx)
+	if err != nil {
+		return err
+	}
+
+	scale.Spec.Replicas = replicas
+
+	_, err = builder.apiClient.StatefulSets(builder.Definition.Namespace).UpdateScale(
+		ctx, builder.Definition.Name, scale, metav1.UpdateOptions{})
+	if err != nil {
+		glog.V(100).Infof("Failed to scale statefulset %s in namespace %s: %v",
+			builder.Definition.Name, builder.Definition.Namespace, err)
+
+		return fmt.Errorf("failed to scale statefulset %s in namespace %s: %w",
+			builder.Definition.Name, builder.Definition.Namespace, err)
+	}
+
+	builder.Object, err = builder.apiClient.StatefulSets(builder.Definition.Namespace).Get(
+		ctx, builder.Definition.Name, metav1.GetOptions{})
+
+	return err
+}
+
+// WaitUntilScaled waits for the duration of the defined timeout or until the statefulset has been observed with the
+// provided number of replicas and all of them are ready.
+func (builder *Builder) WaitUntilScaled(replicas int32, timeout time.Duration) error {
+	if valid, err := builder.validate(); !valid {
+		return err
+	}
+
+	glog.V(100).Infof("Waiting for the defined period until statefulset %s in namespace %s has %d ready replicas",
+		builder.Definition.Name, builder.Definition.Namespace, replicas)
+
+	if !builder.Exists() {
+		return fmt.Errorf("cannot wait for statefulset %s in namespace %s to scale because it does not exist",
+			builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	return wait.PollUntilContextTimeout(
+		context.TODO(), time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+			var err error
+			builder.Object, err = builder.apiClient.StatefulSets(builder.Definition.Namespace).Get(
+				context.TODO(), builder.Definition.Name, metav1.GetOptions{})
+
+			if err != nil {
+				glog.V(100).Infof("Failed to get statefulset from cluster. Error is: '%s'", err.Error())
+
+				return false, nil
+			}
+
+			if builder.Object.Status.ObservedGeneration < builder.Object.Generation {
+				return false, nil
+			}
+
+			if builder.Object.Spec.Replicas == nil || *builder.Object.Spec.Replicas != replicas {
+				return false, nil
+			}
+
+			return builder.Object.Status.Replicas == replicas && builder.Object.Status.ReadyReplicas == replicas, nil
+		})
+}
+
 // GetGVR returns pod's GroupVersionResource which could be used for Clean function.
 func GetGVR() schema.GroupVersionResource {
 	return schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}
@@ -340,3 +431,14 @@ func (builder *Builder) validate() (bool, error) {
 
 	return true, nil
 }
+
+// getScale returns the scale subresource of the statefulset using the provided context.
+func (builder *Builder) getScale(ctx context.Context) (*autoscalingv1.Scale, error) {
+	if !builder.Exists() {
+		return nil, fmt.Errorf("cannot get scale of statefulset %s in namespace %s because it does not exist",
+			builder.Definition.Name, builder.Definition.Namespace)
+	}
+
+	return builder.apiClient.StatefulSets(builder.Definition.Namespace).GetScale(
+		ctx, builder.Definition.Name, metav1.GetOptions{})
+}
@@ -1,15 +1,20 @@
 package statefulset
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/rh-ecosystem-edge/eco-goinfra/pkg/clients"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 //nolint:funlen
@@ -324,3 +329,205 @@ func buildTestBuilderWithFakeObjects(runtimeObjects []runtime.Object) *Builder {
 		},
 	}
 }
+
+func TestStatefulSetGetScale(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		expectedError error
+	}{
+		{
+			exists:        true,
+			expectedError: nil,
+		},
+		{
+			exists: false,
+			expectedError: fmt.Errorf(
+				"cannot get scale of statefulset test-statefulset in namespace test-namespace because it does not exist"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyScaledStatefulSet(3, 3))
+		}
+
+		testBuilder := buildTestBuilderWithScaleReactor(runtimeObjects)
+
+		scale, err := testBuilder.GetScale()
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, int32(3), scale.Spec.Replicas)
+		}
+	}
+}
+
+func TestStatefulSetScale(t *testing.T) {
+	testCases := []struct {
+		replicas      int32
+		exists        bool
+		expectedError error
+	}{
+		{
+			replicas:      5,
+			exists:        true,
+			expectedError: nil,
+		},
+		{
+			replicas:      -1,
+			exists:        true,
+			expectedError: fmt.Errorf("statefulset 'replicas' cannot be negative"),
+		},
+		{
+			replicas: 5,
+			exists:   false,
+			expectedError: fmt.Errorf(
+				"cannot get scale of statefulset test-statefulset in namespace test-namespace because it does not exist"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyScaledStatefulSet(3, 3))
+		}
+
+		testBuilder := buildTestBuilderWithScaleReactor(runtimeObjects)
+
+		err := testBuilder.Scale(context.TODO(), testCase.replicas)
+		assert.Equal(t, testCase.expectedError, err)
+
+		if testCase.expectedError == nil {
+			assert.Equal(t, testCase.replicas, *testBuilder.Object.Spec.Replicas)
+		}
+	}
+}
+
+func TestStatefulSetWaitUntilScaled(t *testing.T) {
+	testCases := []struct {
+		exists        bool
+		readyReplicas int32
+		expectedError error
+	}{
+		{
+			exists:        true,
+			readyReplicas: 3,
+			expectedError: nil,
+		},
+		{
+			exists:        true,
+			readyReplicas: 1,
+			expectedError: context.DeadlineExceeded,
+		},
+		{
+			exists: false,
+			expectedError: fmt.Errorf(
+				"cannot wait for statefulset test-statefulset in namespace test-namespace to scale because it does not exist"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		var runtimeObjects []runtime.Object
+
+		if testCase.exists {
+			runtimeObjects = append(runtimeObjects, buildDummyScaledStatefulSet(3, testCase.readyReplicas))
+		}
+
+		testBuilder := buildTestBuilderWithFakeObjects(runtimeObjects)
+
+		err := testBuilder.WaitUntilScaled(3, time.Second)
+		assert.Equal(t, testCase.expectedError, err)
+	}
+}
+
+// buildDummyScaledStatefulSet returns a statefulset with the provided number of desired and ready replicas.
+func buildDummyScaledStatefulSet(replicas, readyReplicas int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-statefulset",
+			Namespace: "test-namespace",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+		},
+		Status: appsv1.StatefulSetStatus{
+			Replicas:      replicas,
+			ReadyReplicas: readyReplicas,
+		},
+	}
+}
+
+// buildTestBuilderWithScaleReactor returns a Builder whose fake client serves the statefulset scale subresource from
+// the underlying statefulset objects, since the fake clientset does not do this on its own.
+func buildTestBuilderWithScaleReactor(runtimeObjects []runtime.Object) *Builder {
+	fakeClient := k8sfake.NewSimpleClientset(runtimeObjects...)
+	testBuilder := buildTestBuilderWithFakeObjects(nil)
+	testBuilder.apiClient = &clients.Settings{
+		K8sClient:       fakeClient,
+		AppsV1Interface: fakeClient.AppsV1(),
+	}
+	statefulSetGVR := appsv1.SchemeGroupVersion.WithResource("statefulsets")
+
+	fakeClient.PrependReactor("get", "statefulsets",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "scale" {
+				return false, nil, nil
+			}
+
+			getAction, ok := action.(k8stesting.GetAction)
+			if !ok {
+				return false, nil, nil
+			}
+
+			object, err := fakeClient.Tracker().Get(statefulSetGVR, getAction.GetNamespace(), getAction.GetName())
+			if err != nil {
+				return true, nil, err
+			}
+
+			statefulSet, ok := object.(*appsv1.StatefulSet)
+			if !ok {
+				return true, nil, fmt.Errorf("unexpected object type %T", object)
+			}
+
+			return true, &autoscalingv1.Scale{
+				ObjectMeta: metav1.ObjectMeta{Name: statefulSet.Name, Namespace: statefulSet.Namespace},
+				Spec:       autoscalingv1.ScaleSpec{Replicas: *statefulSet.Spec.Replicas},
+			}, nil
+		})
+
+	fakeClient.PrependReactor("update", "statefulsets",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "scale" {
+				return false, nil, nil
+			}
+
+			updateAction, ok := action.(k8stesting.UpdateAction)
+			if !ok {
+				return false, nil, nil
+			}
+
+			scale, ok := updateAction.GetObject().(*autoscalingv1.Scale)
+			if !ok {
+				return false, nil, nil
+			}
+
+			object, err := fakeClient.Tracker().Get(statefulSetGVR, scale.Namespace, scale.Name)
+			if err != nil {
+				return true, nil, err
+			}
+
+			statefulSet, ok := object.(*appsv1.StatefulSet)
+			if !ok {
+				return true, nil, fmt.Errorf("unexpected object type %T", object)
+			}
+
+			statefulSet.Spec.Replicas = &scale.Spec.Replicas
+
+			return true, scale, fakeClient.Tracker().Update(statefulSetGVR, statefulSet, scale.Namespace)
+		})
+
+	return testBuilder
+}
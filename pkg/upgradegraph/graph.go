@@ -0,0 +1,216 @@
+package upgradegraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/golang/glog"
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const (
+	// ChannelsMetadataKey is the node metadata key listing the channels a release belongs to, separated by commas.
+	ChannelsMetadataKey = "io.openshift.upgrades.graph.release.channels"
+	// URLMetadataKey is the node metadata key with the URL of the release errata.
+	URLMetadataKey = "url"
+)
+
+// Node is a release in the update graph.
+type Node struct {
+	// Version is the semantic version of the release.
+	Version string `json:"version"`
+	// Payload is the pull spec of the release image.
+	Payload string `json:"payload"`
+	// Metadata contains the release metadata, such as the channels it belongs to.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Channels returns the channels the release belongs to.
+func (node Node) Channels() []string {
+	var channels []string
+
+	for _, channel := range strings.Split(node.Metadata[ChannelsMetadataKey], ",") {
+		if channel = strings.TrimSpace(channel); channel != "" {
+			channels = append(channels, channel)
+		}
+	}
+
+	return channels
+}
+
+// InChannel returns true if the release belongs to the channel. Every release belongs to the empty channel.
+func (node Node) InChannel(channel string) bool {
+	return channel == "" || slices.Contains(node.Channels(), channel)
+}
+
+// Release returns the release in the format used by the clusterversion status.
+func (node Node) Release() configv1.Release {
+	return configv1.Release{
+		Version:  node.Version,
+		Image:    node.Payload,
+		URL:      configv1.URL(node.Metadata[URLMetadataKey]),
+		Channels: node.Channels(),
+	}
+}
+
+// ConditionalEdge is an update between two versions that is only recommended when none of its risks apply.
+type ConditionalEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ConditionalEdges is a group of conditional updates sharing the same risks.
+type ConditionalEdges struct {
+	// Edges are the conditional updates.
+	Edges []ConditionalEdge `json:"edges"`
+	// Risks are the risks that apply to every update in Edges.
+	Risks []configv1.ConditionalUpdateRisk `json:"risks"`
+}
+
+// Graph is an update graph in the format served by the OpenShift Update Service (Cincinnati).
+type Graph struct {
+	// Nodes are the releases of the graph.
+	Nodes []Node `json:"nodes"`
+	// Edges are the recommended updates, as pairs of indexes in Nodes.
+	Edges [][2]int `json:"edges"`
+	// ConditionalEdges are the updates that are only recommended under conditions.
+	ConditionalEdges []ConditionalEdges `json:"conditionalEdges,omitempty"`
+	// versionIndex maps each version to its index in Nodes.
+	versionIndex map[string]int
+}
+
+// ParseGraph decodes an update graph from JSON and checks that every edge refers to a release of the graph.
+// Conditional edges to releases that are not in the graph are ignored when querying it.
+func ParseGraph(reader io.Reader) (*Graph, error) {
+	if reader == nil {
+		glog.V(100).Infof("The upgrade graph reader is nil")
+
+		return nil, fmt.Errorf("upgrade graph 'reader' cannot be nil")
+	}
+
+	graph := &Graph{}
+
+	err := json.NewDecoder(reader).Decode(graph)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode upgrade graph: %w", err)
+	}
+
+	err = graph.index()
+	if err != nil {
+		return nil, err
+	}
+
+	glog.V(100).Infof("Parsed upgrade graph with %d releases, %d edges and %d conditional edge groups",
+		len(graph.Nodes), len(graph.Edges), len(graph.ConditionalEdges))
+
+	return graph, nil
+}
+
+// LoadGraphFromFile reads an update graph from a JSON file, such as one saved from the update service.
+func LoadGraphFromFile(path string) (*Graph, error) {
+	glog.V(100).Infof("Loading upgrade graph from file %s", path)
+
+	if path == "" {
+		return nil, fmt.Errorf("upgrade graph 'path' cannot be empty")
+	}
+
+	graphFile, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upgrade graph file %s: %w", path, err)
+	}
+
+	defer graphFile.Close()
+
+	return ParseGraph(graphFile)
+}
+
+// FetchGraph downloads the update graph of the channel from an update service, for example
+// https://api.openshift.com/api/upgrades_info/v1/graph. Other query parameters, such as arch, can be included in
+// graphURL. If channel is empty, graphURL is used as is.
+func FetchGraph(graphURL, channel string, timeout time.Duration) (*Graph, error) {
+	glog.V(100).Infof("Fetching upgrade graph of channel %q from %s", channel, graphURL)
+
+	parsedURL, err := url.Parse(graphURL)
+	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return nil, fmt.Errorf("upgrade graph url %q is invalid", graphURL)
+	}
+
+	if channel != "" {
+		query := parsedURL.Query()
+		query.Set("channel", channel)
+		parsedURL.RawQuery = query.Encode()
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch upgrade graph from %s: %w", parsedURL, err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch upgrade graph from %s: unexpected status %s", parsedURL, response.Status)
+	}
+
+	return ParseGraph(response.Body)
+}
+
+// GetNode returns the release with the version.
+func (graph *Graph) GetNode(version string) (*Node, error) {
+	if graph == nil {
+		return nil, fmt.Errorf("upgrade graph cannot be nil")
+	}
+
+	index, found := graph.versionIndex[version]
+	if !found {
+		return nil, fmt.Errorf("version %s not found in upgrade graph", version)
+	}
+
+	return &graph.Nodes[index], nil
+}
+
+// index validates the releases and edges of the graph and builds the version index.
+func (graph *Graph) index() error {
+	graph.versionIndex = make(map[string]int, len(graph.Nodes))
+
+	for index, node := range graph.Nodes {
+		if _, err := semver.NewVersion(node.Version); err != nil {
+			return fmt.Errorf("upgrade graph release %d has invalid version %q: %w", index, node.Version, err)
+		}
+
+		if _, duplicate := graph.versionIndex[node.Version]; duplicate {
+			return fmt.Errorf("upgrade graph has duplicate release %s", node.Version)
+		}
+
+		graph.versionIndex[node.Version] = index
+	}
+
+	for _, edge := range graph.Edges {
+		for _, nodeIndex := range edge {
+			if nodeIndex < 0 || nodeIndex >= len(graph.Nodes) {
+				return fmt.Errorf("upgrade graph edge %v refers to unknown release %d", edge, nodeIndex)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package upgradegraph
+
+import (
+	"bytes"
+	_ "embed"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const defaultGraphPath = "testdata/graph.json"
+
+//go:embed testdata/graph.json
+var defaultGraphContent []byte
+
+func TestParseGraph(t *testing.T) {
+	testCases := []struct {
+		content       string
+		expectedNodes int
+		expectedError string
+	}{
+		{
+			content:       string(defaultGraphContent),
+			expectedNodes: 6,
+			expectedError: "",
+		},
+		{
+			content:       "{",
+			expectedNodes: 0,
+			expectedError: "failed to decode upgrade graph: unexpected EOF",
+		},
+		{
+			content:       `{"nodes": [{"version": "not-a-version"}]}`,
+			expectedNodes: 0,
+			expectedError: `upgrade graph release 0 has invalid version "not-a-version": invalid semantic version`,
+		},
+		{
+			content:       `{"nodes": [{"version": "4.14.10"}, {"version": "4.14.10"}]}`,
+			expectedNodes: 0,
+			expectedError: "upgrade graph has duplicate release 4.14.10",
+		},
+		{
+			content:       `{"nodes": [{"version": "4.14.10"}], "edges": [[0, 1]]}`,
+			expectedNodes: 0,
+			expectedError: "upgrade graph edge [0 1] refers to unknown release 1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		graph, err := ParseGraph(strings.NewReader(testCase.content))
+
+		if testCase.expectedError != "" {
+			assert.EqualError(t, err, testCase.expectedError)
+			assert.Nil(t, graph)
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Len(t, graph.Nodes, testCase.expectedNodes)
+	}
+}
+
+func TestLoadGraphFromFile(t *testing.T) {
+	testCases := []struct {
+		path          string
+		expectedError string
+	}{
+		{
+			path:          defaultGraphPath,
+			expectedError: "",
+		},
+		{
+			path:          "",
+			expectedError: "upgrade graph 'path' cannot be empty",
+		},
+		{
+			path: "testdata/missing.json",
+			expectedError: "failed to open upgrade graph file testdata/missing.json: open testdata/missing.json: " +
+				"no such file or directory",
+		},
+	}
+
+	for _, testCase := range testCases {
+		graph, err := LoadGraphFromFile(testCase.path)
+
+		if testCase.expectedError != "" {
+			assert.EqualError(t, err, testCase.expectedError)
+			assert.Nil(t, graph)
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Len(t, graph.Nodes, 6)
+	}
+}
+
+func TestFetchGraph(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Query().Get("channel") != "eus-4.16" ||
+			request.Header.Get("Accept") != "application/json" {
+			writer.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = writer.Write(defaultGraphContent)
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		graphURL      string
+		channel       string
+		expectedError string
+	}{
+		{
+			graphURL:      server.URL + "/graph",
+			channel:       "eus-4.16",
+			expectedError: "",
+		},
+		{
+			graphURL: server.URL + "/graph",
+			channel:  "stable-4.15",
+			expectedError: "failed to fetch upgrade graph from " + server.URL +
+				"/graph?channel=stable-4.15: unexpected status 404 Not Found",
+		},
+		{
+			graphURL:      "not-a-url",
+			channel:       "eus-4.16",
+			expectedError: `upgrade graph url "not-a-url" is invalid`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		graph, err := FetchGraph(testCase.graphURL, testCase.channel, 5*time.Second)
+
+		if testCase.expectedError != "" {
+			assert.EqualError(t, err, testCase.expectedError)
+			assert.Nil(t, graph)
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Len(t, graph.Nodes, 6)
+	}
+}
+
+func TestGraphGetNode(t *testing.T) {
+	testCases := []struct {
+		version       string
+		expectedError string
+	}{
+		{
+			version:       "4.14.10",
+			expectedError: "",
+		},
+		{
+			version:       "4.13.0",
+			expectedError: "version 4.13.0 not found in upgrade graph",
+		},
+	}
+
+	for _, testCase := range testCases {
+		node, err := buildTestGraph(t).GetNode(testCase.version)
+
+		if testCase.expectedError != "" {
+			assert.EqualError(t, err, testCase.expectedError)
+			assert.Nil(t, node)
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.version, node.Version)
+		assert.Equal(t, []string{"stable-4.15", "eus-4.16"}, node.Channels())
+		assert.Equal(t, "https://access.redhat.com/errata/RHSA-4.14.10", string(node.Release().URL))
+	}
+}
+
+// buildTestGraph returns the graph parsed from the embedded test data.
+func buildTestGraph(t *testing.T) *Graph {
+	t.Helper()
+
+	graph, err := ParseGraph(bytes.NewReader(defaultGraphContent))
+	assert.Nil(t, err)
+
+	return graph
+}
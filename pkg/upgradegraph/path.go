@@ -0,0 +1,205 @@
+package upgradegraph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/golang/glog"
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Update is an update from a release to another in the graph.
+type Update struct {
+	// FromVersion is the version the update starts from.
+	FromVersion string
+	// Release is the release the update goes to.
+	Release configv1.Release
+	// Conditional is true if the update is a conditional edge.
+	Conditional bool
+	// Risks contains the offline evaluation of the risks of a conditional update.
+	Risks []RiskEvaluation
+	// Recommended is True for unconditional updates, False if a risk matches, and Unknown if the risks could not
+	// be evaluated offline.
+	Recommended metav1.ConditionStatus
+}
+
+// UpgradePath is a sequence of updates between two versions.
+type UpgradePath struct {
+	// FromVersion is the version the path starts from.
+	FromVersion string
+	// Updates are the updates of the path, in order.
+	Updates []Update
+}
+
+// Versions returns the versions of the path, starting with FromVersion.
+func (path *UpgradePath) Versions() []string {
+	versions := []string{path.FromVersion}
+
+	for _, update := range path.Updates {
+		versions = append(versions, update.Release.Version)
+	}
+
+	return versions
+}
+
+// IsRecommended returns true if every update of the path is recommended.
+func (path *UpgradePath) IsRecommended() bool {
+	for _, update := range path.Updates {
+		if update.Recommended != metav1.ConditionTrue {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetUpdates returns the updates from fromVersion to the releases of the channel, sorted by descending version.
+// Unconditional updates come from the edges of the graph and conditional updates from its conditional edges, with
+// their risks evaluated offline. If channel is empty, every release of the graph is considered.
+func (graph *Graph) GetUpdates(fromVersion, channel string) ([]Update, error) {
+	glog.V(100).Infof("Getting updates from %s in channel %q", fromVersion, channel)
+
+	fromNode, err := graph.GetNode(fromVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fromNode.InChannel(channel) {
+		return nil, fmt.Errorf("version %s is not in channel %s", fromVersion, channel)
+	}
+
+	updates := map[string]Update{}
+
+	for _, edge := range graph.Edges {
+		toNode := graph.Nodes[edge[1]]
+
+		if graph.Nodes[edge[0]].Version != fromVersion || !toNode.InChannel(channel) {
+			continue
+		}
+
+		updates[toNode.Version] = Update{
+			FromVersion: fromVersion, Release: toNode.Release(), Recommended: metav1.ConditionTrue,
+		}
+	}
+
+	for _, conditionalEdges := range graph.ConditionalEdges {
+		for _, edge := range conditionalEdges.Edges {
+			if edge.From != fromVersion {
+				continue
+			}
+
+			if _, unconditional := updates[edge.To]; unconditional {
+				continue
+			}
+
+			toNode, err := graph.GetNode(edge.To)
+			if err != nil {
+				glog.V(100).Infof("Ignoring conditional edge %s -> %s: %v", edge.From, edge.To, err)
+
+				continue
+			}
+
+			if !toNode.InChannel(channel) {
+				continue
+			}
+
+			updates[edge.To] = newConditionalUpdate(fromVersion, toNode, conditionalEdges.Risks)
+		}
+	}
+
+	return sortUpdates(updates), nil
+}
+
+// GetShortestPath returns a path with the fewest updates from fromVersion to toVersion through releases of the
+// channel. When several paths have the same length, the one going through the highest versions is returned.
+// Conditional updates are only used if allowConditional is true; use UpgradePath.IsRecommended to check whether
+// the path avoids updates that are not recommended.
+func (graph *Graph) GetShortestPath(
+	fromVersion, toVersion, channel string, allowConditional bool) (*UpgradePath, error) {
+	glog.V(100).Infof("Getting shortest upgrade path from %s to %s in channel %q", fromVersion, toVersion, channel)
+
+	toNode, err := graph.GetNode(toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if !toNode.InChannel(channel) {
+		return nil, fmt.Errorf("version %s is not in channel %s", toVersion, channel)
+	}
+
+	if _, err := graph.GetUpdates(fromVersion, channel); err != nil {
+		return nil, err
+	}
+
+	previous := map[string]Update{}
+	visited := map[string]bool{fromVersion: true}
+	queue := []string{fromVersion}
+
+	for len(queue) > 0 && !visited[toVersion] {
+		version := queue[0]
+		queue = queue[1:]
+
+		updates, err := graph.GetUpdates(version, channel)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, update := range updates {
+			if visited[update.Release.Version] || (update.Conditional && !allowConditional) {
+				continue
+			}
+
+			visited[update.Release.Version] = true
+			previous[update.Release.Version] = update
+			queue = append(queue, update.Release.Version)
+		}
+	}
+
+	if !visited[toVersion] {
+		return nil, fmt.Errorf("no upgrade path from %s to %s in channel %q", fromVersion, toVersion, channel)
+	}
+
+	path := &UpgradePath{FromVersion: fromVersion}
+
+	for version := toVersion; version != fromVersion; version = previous[version].FromVersion {
+		path.Updates = append(path.Updates, previous[version])
+	}
+
+	slices.Reverse(path.Updates)
+
+	glog.V(100).Infof("Found upgrade path %s", strings.Join(path.Versions(), " -> "))
+
+	return path, nil
+}
+
+// newConditionalUpdate returns the conditional update to toNode with its risks evaluated offline.
+func newConditionalUpdate(fromVersion string, toNode *Node, risks []configv1.ConditionalUpdateRisk) Update {
+	update := Update{FromVersion: fromVersion, Release: toNode.Release(), Conditional: true}
+
+	for _, risk := range risks {
+		update.Risks = append(update.Risks, EvaluateRisk(risk))
+	}
+
+	update.Recommended = getRecommendation(update.Risks)
+
+	return update
+}
+
+// sortUpdates returns the updates sorted by descending version.
+func sortUpdates(updates map[string]Update) []Update {
+	var sortedUpdates []Update
+
+	for _, update := range updates {
+		sortedUpdates = append(sortedUpdates, update)
+	}
+
+	slices.SortFunc(sortedUpdates, func(first, second Update) int {
+		// Versions are validated when the graph is parsed.
+		return semver.MustParse(second.Release.Version).Compare(semver.MustParse(first.Release.Version))
+	})
+
+	return sortedUpdates
+}
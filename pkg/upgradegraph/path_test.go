@@ -0,0 +1,152 @@
+package upgradegraph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGraphGetUpdates(t *testing.T) {
+	testCases := []struct {
+		fromVersion         string
+		channel             string
+		expectedVersions    []string
+		expectedRecommended []metav1.ConditionStatus
+		expectedError       string
+	}{
+		{
+			fromVersion:      "4.14.10",
+			channel:          "eus-4.16",
+			expectedVersions: []string{"4.15.10", "4.15.5", "4.14.20"},
+			expectedRecommended: []metav1.ConditionStatus{
+				metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionTrue},
+		},
+		{
+			fromVersion:         "4.15.5",
+			channel:             "stable-4.15",
+			expectedVersions:    []string{"4.15.10"},
+			expectedRecommended: []metav1.ConditionStatus{metav1.ConditionTrue},
+		},
+		{
+			fromVersion:         "4.15.10",
+			channel:             "",
+			expectedVersions:    []string{"4.16.5"},
+			expectedRecommended: []metav1.ConditionStatus{metav1.ConditionUnknown},
+		},
+		{
+			fromVersion:   "4.16.3",
+			channel:       "stable-4.15",
+			expectedError: "version 4.16.3 is not in channel stable-4.15",
+		},
+		{
+			fromVersion:   "4.13.0",
+			channel:       "eus-4.16",
+			expectedError: "version 4.13.0 not found in upgrade graph",
+		},
+	}
+
+	for _, testCase := range testCases {
+		updates, err := buildTestGraph(t).GetUpdates(testCase.fromVersion, testCase.channel)
+
+		if testCase.expectedError != "" {
+			assert.EqualError(t, err, testCase.expectedError)
+
+			continue
+		}
+
+		assert.Nil(t, err)
+
+		var (
+			versions    []string
+			recommended []metav1.ConditionStatus
+		)
+
+		for _, update := range updates {
+			assert.Equal(t, testCase.fromVersion, update.FromVersion)
+
+			versions = append(versions, update.Release.Version)
+			recommended = append(recommended, update.Recommended)
+		}
+
+		assert.Equal(t, testCase.expectedVersions, versions)
+		assert.Equal(t, testCase.expectedRecommended, recommended)
+	}
+}
+
+func TestGraphGetShortestPath(t *testing.T) {
+	testCases := []struct {
+		fromVersion         string
+		toVersion           string
+		channel             string
+		allowConditional    bool
+		expectedVersions    []string
+		expectedRecommended bool
+		expectedError       string
+	}{
+		{
+			fromVersion:         "4.14.10",
+			toVersion:           "4.16.3",
+			channel:             "eus-4.16",
+			expectedVersions:    []string{"4.14.10", "4.15.5", "4.16.3"},
+			expectedRecommended: true,
+		},
+		{
+			fromVersion:         "4.14.10",
+			toVersion:           "4.15.10",
+			channel:             "eus-4.16",
+			expectedVersions:    []string{"4.14.10", "4.15.5", "4.15.10"},
+			expectedRecommended: true,
+		},
+		{
+			fromVersion:         "4.14.10",
+			toVersion:           "4.15.10",
+			channel:             "eus-4.16",
+			allowConditional:    true,
+			expectedVersions:    []string{"4.14.10", "4.15.10"},
+			expectedRecommended: false,
+		},
+		{
+			fromVersion:         "4.14.20",
+			toVersion:           "4.16.5",
+			channel:             "eus-4.16",
+			allowConditional:    true,
+			expectedVersions:    []string{"4.14.20", "4.15.10", "4.16.5"},
+			expectedRecommended: false,
+		},
+		{
+			fromVersion:   "4.14.20",
+			toVersion:     "4.16.5",
+			channel:       "eus-4.16",
+			expectedError: `no upgrade path from 4.14.20 to 4.16.5 in channel "eus-4.16"`,
+		},
+		{
+			fromVersion:   "4.14.10",
+			toVersion:     "4.16.3",
+			channel:       "stable-4.15",
+			expectedError: "version 4.16.3 is not in channel stable-4.15",
+		},
+		{
+			fromVersion:   "4.13.0",
+			toVersion:     "4.16.3",
+			channel:       "eus-4.16",
+			expectedError: "version 4.13.0 not found in upgrade graph",
+		},
+	}
+
+	for _, testCase := range testCases {
+		path, err := buildTestGraph(t).GetShortestPath(
+			testCase.fromVersion, testCase.toVersion, testCase.channel, testCase.allowConditional)
+
+		if testCase.expectedError != "" {
+			assert.EqualError(t, err, testCase.expectedError)
+			assert.Nil(t, path)
+
+			continue
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedVersions, path.Versions())
+		assert.Equal(t, testCase.expectedRecommended, path.IsRecommended())
+	}
+}
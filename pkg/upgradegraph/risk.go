@@ -0,0 +1,86 @@
+package upgradegraph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MatchingRuleAlways is the matching rule type that always applies.
+	MatchingRuleAlways = "Always"
+	// MatchingRulePromQL is the matching rule type that applies when a PromQL query against the cluster matches.
+	MatchingRulePromQL = "PromQL"
+)
+
+// RiskStatus is the result of evaluating a conditional update risk offline.
+type RiskStatus string
+
+const (
+	// RiskMatches means the risk applies, so the update is not recommended.
+	RiskMatches RiskStatus = "Matches"
+	// RiskUnevaluated means none of the matching rules of the risk could be evaluated without a cluster, for
+	// example because they are PromQL queries.
+	RiskUnevaluated RiskStatus = "Unevaluated"
+)
+
+// RiskEvaluation is the result of evaluating a conditional update risk offline.
+type RiskEvaluation struct {
+	// Risk is the evaluated risk.
+	Risk configv1.ConditionalUpdateRisk
+	// Status is the result of the evaluation.
+	Status RiskStatus
+	// Reason explains the status.
+	Reason string
+}
+
+// EvaluateRisk evaluates the matching rules of the risk in order, as the cluster-version operator does. The first
+// rule that can be evaluated decides the result. PromQL rules need a cluster to be evaluated, so they are skipped
+// and a risk without any other rule is reported as unevaluated.
+func EvaluateRisk(risk configv1.ConditionalUpdateRisk) RiskEvaluation {
+	glog.V(100).Infof("Evaluating conditional update risk %s", risk.Name)
+
+	var skipped []string
+
+	for _, rule := range risk.MatchingRules {
+		switch rule.Type {
+		case MatchingRuleAlways:
+			return RiskEvaluation{Risk: risk, Status: RiskMatches, Reason: "matched by Always rule"}
+		case MatchingRulePromQL:
+			query := ""
+
+			if rule.PromQL != nil {
+				query = rule.PromQL.PromQL
+			}
+
+			skipped = append(skipped, fmt.Sprintf("PromQL rule %q cannot be evaluated offline", query))
+		default:
+			skipped = append(skipped, fmt.Sprintf("matching rule type %q is not supported", rule.Type))
+		}
+	}
+
+	if len(skipped) == 0 {
+		skipped = append(skipped, "risk has no matching rules")
+	}
+
+	return RiskEvaluation{Risk: risk, Status: RiskUnevaluated, Reason: strings.Join(skipped, "; ")}
+}
+
+// getRecommendation returns whether an update with the evaluated risks is recommended: True if there are no risks,
+// False if any risk matches, and Unknown otherwise.
+func getRecommendation(evaluations []RiskEvaluation) metav1.ConditionStatus {
+	if len(evaluations) == 0 {
+		return metav1.ConditionTrue
+	}
+
+	for _, evaluation := range evaluations {
+		if evaluation.Status == RiskMatches {
+			return metav1.ConditionFalse
+		}
+	}
+
+	return metav1.ConditionUnknown
+}
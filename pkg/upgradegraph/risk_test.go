@@ -0,0 +1,56 @@
+package upgradegraph
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateRisk(t *testing.T) {
+	promQLRule := configv1.ClusterCondition{
+		Type: MatchingRulePromQL, PromQL: &configv1.PromQLClusterCondition{PromQL: "up == 0"},
+	}
+
+	testCases := []struct {
+		rules          []configv1.ClusterCondition
+		expectedStatus RiskStatus
+		expectedReason string
+	}{
+		{
+			rules:          []configv1.ClusterCondition{{Type: MatchingRuleAlways}},
+			expectedStatus: RiskMatches,
+			expectedReason: "matched by Always rule",
+		},
+		{
+			rules:          []configv1.ClusterCondition{promQLRule},
+			expectedStatus: RiskUnevaluated,
+			expectedReason: `PromQL rule "up == 0" cannot be evaluated offline`,
+		},
+		{
+			rules:          []configv1.ClusterCondition{promQLRule, {Type: MatchingRuleAlways}},
+			expectedStatus: RiskMatches,
+			expectedReason: "matched by Always rule",
+		},
+		{
+			rules:          []configv1.ClusterCondition{{Type: "Unknown"}, promQLRule},
+			expectedStatus: RiskUnevaluated,
+			expectedReason: `matching rule type "Unknown" is not supported; ` +
+				`PromQL rule "up == 0" cannot be evaluated offline`,
+		},
+		{
+			rules:          nil,
+			expectedStatus: RiskUnevaluated,
+			expectedReason: "risk has no matching rules",
+		},
+	}
+
+	for _, testCase := range testCases {
+		risk := configv1.ConditionalUpdateRisk{Name: "TestRisk", MatchingRules: testCase.rules}
+		evaluation := EvaluateRisk(risk)
+
+		assert.Equal(t, risk, evaluation.Risk)
+		assert.Equal(t, testCase.expectedStatus, evaluation.Status)
+		assert.Equal(t, testCase.expectedReason, evaluation.Reason)
+	}
+}
@@ -0,0 +1,116 @@
+package upgradegraph
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/golang/glog"
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// VerifyClusterVersion checks the updates the cluster-version operator reports in the clusterversion status against
+// the updates of the graph from the desired version in the channel of the cluster. Unconditional updates must be
+// available and conditional updates must be reported as conditional, with the same risks. Since the cluster
+// evaluates the risks, a conditional update may also be reported as available. All mismatches are returned in a
+// single error.
+func (graph *Graph) VerifyClusterVersion(clusterVersion *configv1.ClusterVersion) error {
+	if graph == nil {
+		return fmt.Errorf("upgrade graph cannot be nil")
+	}
+
+	if clusterVersion == nil {
+		return fmt.Errorf("clusterversion cannot be nil")
+	}
+
+	fromVersion := clusterVersion.Status.Desired.Version
+	channel := clusterVersion.Spec.Channel
+
+	glog.V(100).Infof("Verifying clusterversion updates from %s in channel %q", fromVersion, channel)
+
+	updates, err := graph.GetUpdates(fromVersion, channel)
+	if err != nil {
+		return err
+	}
+
+	available := map[string]bool{}
+
+	for _, release := range clusterVersion.Status.AvailableUpdates {
+		available[release.Version] = true
+	}
+
+	conditional := map[string]configv1.ConditionalUpdate{}
+
+	for _, conditionalUpdate := range clusterVersion.Status.ConditionalUpdates {
+		conditional[conditionalUpdate.Release.Version] = conditionalUpdate
+	}
+
+	var mismatches []string
+
+	for _, update := range updates {
+		version := update.Release.Version
+
+		if !update.Conditional {
+			if !available[version] {
+				mismatches = append(mismatches, fmt.Sprintf("update to %s is not available", version))
+			}
+
+			delete(available, version)
+
+			continue
+		}
+
+		delete(available, version)
+
+		mismatches = append(mismatches, getConditionalUpdateMismatches(update, conditional)...)
+
+		delete(conditional, version)
+	}
+
+	for _, version := range slices.Sorted(maps.Keys(available)) {
+		mismatches = append(mismatches, fmt.Sprintf("update to %s is available but not in the graph", version))
+	}
+
+	for _, version := range slices.Sorted(maps.Keys(conditional)) {
+		mismatches = append(mismatches, fmt.Sprintf("conditional update to %s is not in the graph", version))
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("clusterversion updates from %s in channel %q do not match the upgrade graph: %s",
+			fromVersion, channel, strings.Join(mismatches, "; "))
+	}
+
+	return nil
+}
+
+// getConditionalUpdateMismatches returns the differences between the conditional update from the graph and the one
+// reported by the cluster.
+func getConditionalUpdateMismatches(update Update, reported map[string]configv1.ConditionalUpdate) []string {
+	version := update.Release.Version
+
+	reportedUpdate, found := reported[version]
+	if !found {
+		return []string{fmt.Sprintf("conditional update to %s is not reported", version)}
+	}
+
+	var expectedRisks, reportedRisks []string
+
+	for _, evaluation := range update.Risks {
+		expectedRisks = append(expectedRisks, evaluation.Risk.Name)
+	}
+
+	for _, risk := range reportedUpdate.Risks {
+		reportedRisks = append(reportedRisks, risk.Name)
+	}
+
+	slices.Sort(expectedRisks)
+	slices.Sort(reportedRisks)
+
+	if !slices.Equal(expectedRisks, reportedRisks) {
+		return []string{fmt.Sprintf("conditional update to %s has risks %v instead of %v",
+			version, reportedRisks, expectedRisks)}
+	}
+
+	return nil
+}
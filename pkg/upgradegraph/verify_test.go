@@ -0,0 +1,84 @@
+package upgradegraph
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphVerifyClusterVersion(t *testing.T) {
+	testCases := []struct {
+		available     []string
+		conditional   map[string][]string
+		expectedError string
+	}{
+		{
+			available:     []string{"4.15.5", "4.14.20"},
+			conditional:   map[string][]string{"4.15.10": {"KnownIssue"}},
+			expectedError: "",
+		},
+		{
+			available:     []string{"4.15.5", "4.14.20", "4.15.10"},
+			conditional:   map[string][]string{"4.15.10": {"KnownIssue"}},
+			expectedError: "",
+		},
+		{
+			available:   []string{"4.15.5", "4.16.3"},
+			conditional: map[string][]string{"4.15.10": {"OtherIssue"}, "4.16.5": {"KnownIssue"}},
+			expectedError: `clusterversion updates from 4.14.10 in channel "eus-4.16" do not match the upgrade graph: ` +
+				"conditional update to 4.15.10 has risks [OtherIssue] instead of [KnownIssue]; " +
+				"update to 4.14.20 is not available; update to 4.16.3 is available but not in the graph; " +
+				"conditional update to 4.16.5 is not in the graph",
+		},
+		{
+			available:   []string{"4.15.5", "4.14.20"},
+			conditional: nil,
+			expectedError: `clusterversion updates from 4.14.10 in channel "eus-4.16" do not match the upgrade graph: ` +
+				"conditional update to 4.15.10 is not reported",
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := buildTestGraph(t).VerifyClusterVersion(
+			buildDummyClusterVersion("4.14.10", "eus-4.16", testCase.available, testCase.conditional))
+
+		if testCase.expectedError != "" {
+			assert.EqualError(t, err, testCase.expectedError)
+
+			continue
+		}
+
+		assert.Nil(t, err)
+	}
+
+	err := buildTestGraph(t).VerifyClusterVersion(nil)
+	assert.EqualError(t, err, "clusterversion cannot be nil")
+}
+
+// buildDummyClusterVersion returns a clusterversion at fromVersion reporting the available and conditional updates.
+// The conditional updates map each version to the names of its risks.
+func buildDummyClusterVersion(
+	fromVersion, channel string, available []string, conditional map[string][]string) *configv1.ClusterVersion {
+	clusterVersion := &configv1.ClusterVersion{
+		Spec:   configv1.ClusterVersionSpec{Channel: channel},
+		Status: configv1.ClusterVersionStatus{Desired: configv1.Release{Version: fromVersion}},
+	}
+
+	for _, version := range available {
+		clusterVersion.Status.AvailableUpdates = append(
+			clusterVersion.Status.AvailableUpdates, configv1.Release{Version: version})
+	}
+
+	for version, riskNames := range conditional {
+		conditionalUpdate := configv1.ConditionalUpdate{Release: configv1.Release{Version: version}}
+
+		for _, riskName := range riskNames {
+			conditionalUpdate.Risks = append(conditionalUpdate.Risks, configv1.ConditionalUpdateRisk{Name: riskName})
+		}
+
+		clusterVersion.Status.ConditionalUpdates = append(clusterVersion.Status.ConditionalUpdates, conditionalUpdate)
+	}
+
+	return clusterVersion
+}